@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/api"
+)
+
+func (s *Server) BackingImageList(rw http.ResponseWriter, req *http.Request) (err error) {
+	apiContext := api.GetApiContext(req)
+
+	bis, err := s.m.ListBackingImages()
+	if err != nil {
+		return errors.Wrap(err, "error listing backing image")
+	}
+	apiContext.Write(toBackingImageCollection(bis))
+	return nil
+}
+
+func (s *Server) BackingImageGet(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	bi, err := s.m.GetBackingImage(id)
+	if err != nil {
+		return errors.Wrapf(err, "error get backing image '%s'", id)
+	}
+	if bi == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	apiContext.Write(toBackingImageResource(bi))
+	return nil
+}
+
+func (s *Server) BackingImageCreate(rw http.ResponseWriter, req *http.Request) error {
+	var input BackingImage
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	bi, err := s.m.CreateBackingImage(input.Name, input.ImageURL)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create backing image %v", input.Name)
+	}
+	apiContext.Write(toBackingImageResource(bi))
+	return nil
+}
+
+func (s *Server) BackingImageDelete(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+	if err := s.m.DeleteBackingImage(id); err != nil {
+		return errors.Wrap(err, "unable to delete backing image")
+	}
+
+	return nil
+}
@@ -12,7 +12,9 @@ import (
 func (s *Server) BackupVolumeList(w http.ResponseWriter, req *http.Request) error {
 	apiContext := api.GetApiContext(req)
 
-	volumes, err := s.m.ListBackupVolumes()
+	backupTargetName := req.URL.Query().Get("backupTarget")
+
+	volumes, err := s.m.ListBackupVolumes(backupTargetName)
 	if err != nil {
 		return errors.Wrapf(err, "error listing backups")
 	}
@@ -24,19 +26,25 @@ func (s *Server) BackupVolumeGet(w http.ResponseWriter, req *http.Request) error
 	apiContext := api.GetApiContext(req)
 
 	volName := mux.Vars(req)["volName"]
+	backupTargetName := req.URL.Query().Get("backupTarget")
 
-	bv, err := s.m.GetBackupVolume(volName)
+	bv, err := s.m.GetBackupVolume(backupTargetName, volName)
 	if err != nil {
 		return errors.Wrapf(err, "error get backup volume '%s'", volName)
 	}
+	if bv == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	}
 	apiContext.Write(toBackupVolumeResource(bv, apiContext))
 	return nil
 }
 
 func (s *Server) BackupList(w http.ResponseWriter, req *http.Request) error {
 	volName := mux.Vars(req)["volName"]
+	backupTargetName := req.URL.Query().Get("backupTarget")
 
-	bs, err := s.m.ListBackupsForVolume(volName)
+	bs, err := s.m.ListBackupsForVolume(backupTargetName, volName)
 	if err != nil {
 		return errors.Wrapf(err, "error listing backups for volume '%s'", volName)
 	}
@@ -56,8 +64,9 @@ func (s *Server) BackupGet(w http.ResponseWriter, req *http.Request) error {
 		return errors.Errorf("empty backup name is not allowed")
 	}
 	volName := mux.Vars(req)["volName"]
+	backupTargetName := req.URL.Query().Get("backupTarget")
 
-	backup, err := s.m.GetBackup(input.Name, volName)
+	backup, err := s.m.GetBackup(backupTargetName, input.Name, volName)
 	if err != nil {
 		return errors.Wrapf(err, "error getting backup %v of volume %v", input.Name, volName)
 	}
@@ -83,8 +92,9 @@ func (s *Server) BackupDelete(w http.ResponseWriter, req *http.Request) error {
 	}
 
 	volName := mux.Vars(req)["volName"]
+	backupTargetName := req.URL.Query().Get("backupTarget")
 
-	if err := s.m.DeleteBackup(input.Name, volName); err != nil {
+	if err := s.m.DeleteBackup(backupTargetName, input.Name, volName); err != nil {
 		return errors.Wrapf(err, "error deleting backup %v of volume %v", input.Name, volName)
 	}
 	logrus.Debugf("Removed backup %v of volume %v", input.Name, volName)
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/api"
+)
+
+func (s *Server) BackupOperationList(rw http.ResponseWriter, req *http.Request) (err error) {
+	apiContext := api.GetApiContext(req)
+
+	volumeName := req.URL.Query().Get("volume")
+
+	bos, err := s.m.ListBackupOperations(volumeName)
+	if err != nil {
+		return errors.Wrap(err, "error listing backup operations")
+	}
+	apiContext.Write(toBackupOperationCollection(bos, apiContext))
+	return nil
+}
+
+func (s *Server) BackupOperationGet(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	bo, err := s.m.GetBackupOperation(id)
+	if err != nil {
+		return errors.Wrapf(err, "error get backup operation '%s'", id)
+	}
+	if bo == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	apiContext.Write(toBackupOperationResource(bo, apiContext))
+	return nil
+}
+
+func (s *Server) BackupOperationCancel(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	bo, err := s.m.CancelBackupOperation(id)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toBackupOperationResource(bo, apiContext))
+	return nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/api"
+)
+
+func (s *Server) BackupTargetList(rw http.ResponseWriter, req *http.Request) (err error) {
+	apiContext := api.GetApiContext(req)
+
+	bts, err := s.m.ListBackupTargets()
+	if err != nil {
+		return errors.Wrap(err, "error listing backup targets")
+	}
+	apiContext.Write(toBackupTargetCollection(bts, apiContext))
+	return nil
+}
+
+func (s *Server) BackupTargetGet(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	bt, err := s.m.GetBackupTarget(id)
+	if err != nil {
+		return errors.Wrapf(err, "error get backup target '%s'", id)
+	}
+	if bt == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	apiContext.Write(toBackupTargetResource(bt, apiContext))
+	return nil
+}
+
+func (s *Server) BackupTargetCreate(rw http.ResponseWriter, req *http.Request) error {
+	var input BackupTarget
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	bt, err := s.m.CreateBackupTarget(input.Name, &input.BackupTargetSpec)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toBackupTargetResource(bt, apiContext))
+	return nil
+}
+
+func (s *Server) BackupTargetUpdate(rw http.ResponseWriter, req *http.Request) error {
+	var input BackupTarget
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	id := mux.Vars(req)["name"]
+
+	bt, err := s.m.UpdateBackupTarget(id, &input.BackupTargetSpec)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toBackupTargetResource(bt, apiContext))
+	return nil
+}
+
+func (s *Server) BackupTargetDelete(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+	return s.m.DeleteBackupTarget(id)
+}
+
+func (s *Server) BackupTargetSync(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	bt, err := s.m.SyncBackupTarget(id)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toBackupTargetResource(bt, apiContext))
+	return nil
+}
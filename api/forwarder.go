@@ -1,8 +1,13 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/gorilla/mux"
@@ -27,6 +32,24 @@ func OwnerIDFromVolume(m *manager.VolumeManager) func(req *http.Request) (string
 	}
 }
 
+// OwnerIDFromSnapshotExport returns the node keeping the exported image
+func OwnerIDFromSnapshotExport(m *manager.VolumeManager) func(req *http.Request) (string, error) {
+	return func(req *http.Request) (string, error) {
+		name := mux.Vars(req)["name"]
+		se, err := m.GetSnapshotExport(name)
+		if err != nil {
+			return "", errors.Wrapf(err, "error getting snapshot export '%s'", name)
+		}
+		if se == nil {
+			return "", nil
+		}
+		return se.Spec.NodeID, nil
+	}
+}
+
+// CallTimeout is the timeout of the requests sent by Fwd.Call
+var CallTimeout = 30 * time.Second
+
 type NodeLocator interface {
 	GetCurrentNodeID() string
 	Node2APIAddress(nodeID string) (string, error)
@@ -67,3 +90,39 @@ func (f *Fwd) Handler(getNodeID OwnerIDFunc, h HandleFuncWithError) HandleFuncWi
 		return h(w, req)
 	}
 }
+
+// Call sends the request with the input to the API of the manager on the
+// node, the response is decoded into output if output is not nil
+func (f *Fwd) Call(nodeID, method, path string, input, output interface{}) error {
+	targetNode, err := f.locator.Node2APIAddress(nodeID)
+	if err != nil {
+		return errors.Wrapf(err, "cannot find node %v", nodeID)
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, "http://"+targetNode+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	logrus.Debugf("Calling %v %v on %v", method, path, targetNode)
+	client := &http.Client{Timeout: CallTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "error calling %v %v on node %v", method, path, nodeID)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("error calling %v %v on node %v: %v %s", method, path, nodeID, resp.Status, data)
+	}
+	if output == nil {
+		return nil
+	}
+	return json.Unmarshal(data, output)
+}
@@ -1,12 +1,15 @@
 package api
 
 import (
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/rancher/go-rancher/api"
 	"github.com/rancher/go-rancher/client"
 
+	"github.com/rancher/longhorn-manager/datastore"
 	"github.com/rancher/longhorn-manager/engineapi"
 	"github.com/rancher/longhorn-manager/manager"
 	"github.com/rancher/longhorn-manager/types"
@@ -19,16 +22,38 @@ type Volume struct {
 
 	Name                string               `json:"name"`
 	Size                string               `json:"size"`
+	ActualSize          string               `json:"actualSize"`
 	Frontend            types.VolumeFrontend `json:"frontend"`
 	FromBackup          string               `json:"fromBackup"`
+	FromImage           string               `json:"fromImage"`
+	FromImageFormat     string               `json:"fromImageFormat"`
+	FromImageNodeID     string               `json:"fromImageNodeID"`
 	NumberOfReplicas    int                  `json:"numberOfReplicas"`
 	StaleReplicaTimeout int                  `json:"staleReplicaTimeout"`
 	State               string               `json:"state"`
 	EngineImage         string               `json:"engineImage"`
+	BackingImage        string               `json:"backingImage"`
 	Endpoint            string               `json:"endpoint,omitemtpy"`
 	Created             string               `json:"created,omitemtpy"`
-
-	RecurringJobs []types.RecurringJob `json:"recurringJobs"`
+	ImportState         string               `json:"importState"`
+	ImportProgress      int                  `json:"importProgress"`
+	ImportError         string               `json:"importError"`
+	RestoreRequired     bool                 `json:"restoreRequired"`
+	RestoreStatus       types.RestoreStatus  `json:"restoreStatus"`
+	DeletionProtection  bool                 `json:"deletionProtection"`
+	RecycledAt          string               `json:"recycledAt"`
+	Labels              map[string]string    `json:"labels"`
+	DisableAutoAttach   bool                 `json:"disableAutoAttach"`
+	// AutoAttachedForJobs are the recurring jobs attached the detached
+	// volume temporarily, attaching it takes over the volume
+	AutoAttachedForJobs map[string]string `json:"autoAttachedForJobs"`
+	// SnapshotHooks run in the workload pods around the snapshots
+	SnapshotHooks *types.SnapshotHooks `json:"snapshotHooks"`
+
+	Conditions    map[string]types.Condition `json:"conditions"`
+	RecurringJobs []types.RecurringJob       `json:"recurringJobs"`
+	// RecurringJobStatus has the recent runs of each recurring job
+	RecurringJobStatus map[string]types.RecurringJobRunStatus `json:"recurringJobStatus"`
 
 	Replicas   []Replica   `json:"replicas"`
 	Controller *Controller `json:"controller"`
@@ -49,12 +74,17 @@ type Host struct {
 
 type BackupVolume struct {
 	client.Resource
-	engineapi.BackupVolume
+	Name         string `json:"name"`
+	BackupTarget string `json:"backupTarget"`
+	types.BackupVolumeStatus
 }
 
 type Backup struct {
 	client.Resource
-	engineapi.Backup
+	Name         string `json:"name"`
+	VolumeName   string `json:"volumeName"`
+	BackupTarget string `json:"backupTarget"`
+	types.BackupStatus
 }
 
 type Setting struct {
@@ -78,8 +108,10 @@ type Controller struct {
 type Replica struct {
 	Instance
 
-	Mode     string `json:"mode"`
-	FailedAt string `json:"failedAt"`
+	Mode          string               `json:"mode"`
+	FailedAt      string               `json:"failedAt"`
+	RestoreStatus types.RestoreStatus  `json:"restoreStatus"`
+	RebuildStatus *types.RebuildStatus `json:"rebuildStatus"`
 }
 
 type EngineImage struct {
@@ -91,6 +123,68 @@ type EngineImage struct {
 	types.EngineImageStatus
 }
 
+type BackingImage struct {
+	client.Resource
+
+	Name     string `json:"name"`
+	ImageURL string `json:"imageURL"`
+	types.BackingImageStatus
+}
+
+type Orphan struct {
+	client.Resource
+
+	Name    string `json:"name"`
+	Created string `json:"created"`
+	types.OrphanSpec
+	types.OrphanStatus
+}
+
+type RecurringJobPolicy struct {
+	client.Resource
+
+	Name string `json:"name"`
+	types.RecurringJobSpec
+	types.RecurringJobStatus
+}
+
+type BackupTarget struct {
+	client.Resource
+
+	Name string `json:"name"`
+	types.BackupTargetSpec
+	types.BackupTargetStatus
+}
+
+type BackupOperation struct {
+	client.Resource
+
+	Name string `json:"name"`
+	types.BackupOperationSpec
+	types.BackupOperationStatus
+}
+
+type SnapshotExport struct {
+	client.Resource
+
+	Name string `json:"name"`
+	types.SnapshotExportSpec
+	types.SnapshotExportStatus
+}
+
+type SnapshotGroup struct {
+	client.Resource
+
+	Name string `json:"name"`
+	types.SnapshotGroupSpec
+	types.SnapshotGroupStatus
+}
+
+type GroupSnapshot struct {
+	client.Resource
+	manager.GroupSnapshot
+}
+
 type AttachInput struct {
 	HostID string `json:"hostId"`
 }
@@ -98,12 +192,34 @@ type AttachInput struct {
 type SnapshotInput struct {
 	Name   string            `json:"name"`
 	Labels map[string]string `json:"labels"`
+	// Freeze freezes the filesystem on the volume while taking the
+	// snapshot
+	Freeze bool `json:"freeze"`
+	// BackupTarget is the name of the backup target to back up the
+	// snapshot to, empty for the default target
+	BackupTarget string `json:"backupTarget"`
 }
 
 type BackupInput struct {
 	Name string `json:"name"`
 }
 
+// SnapshotGroupRestoreInput restores the backups of the group snapshot named
+// Snapshot into the volumes named by the volumes in the group prefixed by
+// VolumePrefix, which is required
+type SnapshotGroupRestoreInput struct {
+	Snapshot     string `json:"snapshot"`
+	VolumePrefix string `json:"volumePrefix"`
+	BackupTarget string `json:"backupTarget"`
+}
+
+// SnapshotFreezeOutput is the result of freezing the filesystem on the
+// volume for a group snapshot
+type SnapshotFreezeOutput struct {
+	client.Resource
+	Frozen bool `json:"frozen"`
+}
+
 type RecurringInput struct {
 	Jobs []types.RecurringJob `json:"jobs"`
 }
@@ -116,15 +232,40 @@ type SalvageInput struct {
 	Names []string `json:"names"`
 }
 
+type OrphanKeepInput struct {
+	Keep bool `json:"keep"`
+}
+
 type EngineUpgradeInput struct {
 	Image string `json:"image"`
 }
 
+type DeletionProtectionInput struct {
+	DeletionProtection bool `json:"deletionProtection"`
+}
+
+type AutoAttachInput struct {
+	DisableAutoAttach bool `json:"disableAutoAttach"`
+}
+
+// SnapshotHooksInput without any hook removes the hooks of the volume
+type SnapshotHooksInput struct {
+	types.SnapshotHooks
+}
+
 type Node struct {
 	client.Resource
-	Name            string          `json:"name"`
-	AllowScheduling bool            `json:"allowScheduling"`
-	Status          types.NodeState `json:"status"`
+	Name             string           `json:"name"`
+	AllowScheduling  bool             `json:"allowScheduling"`
+	Status           types.NodeState  `json:"status"`
+	StorageMaximum   string           `json:"storageMaximum"`
+	StorageAvailable string           `json:"storageAvailable"`
+	StorageUsed      string           `json:"storageUsed"`
+	ReplicaSizeMap   map[string]int64 `json:"replicaSizeMap"`
+
+	ClusterStorageMaximum   string `json:"clusterStorageMaximum"`
+	ClusterStorageAvailable string `json:"clusterStorageAvailable"`
+	ClusterStorageUsed      string `json:"clusterStorageUsed"`
 }
 
 func NewSchema() *client.Schemas {
@@ -138,11 +279,25 @@ func NewSchema() *client.Schemas {
 	schemas.AddType("snapshotInput", SnapshotInput{})
 	schemas.AddType("backup", Backup{})
 	schemas.AddType("backupInput", BackupInput{})
-	schemas.AddType("recurringJob", types.RecurringJob{})
+	recurringJobSchema(schemas.AddType("recurringJob", types.RecurringJob{}))
+	schemas.AddType("retentionPolicy", types.RetentionPolicy{})
+	schemas.AddType("snapshotHook", types.SnapshotHook{})
+	snapshotHooksSchema(schemas.AddType("snapshotHooks", types.SnapshotHooks{}))
+	schemas.AddType("recurringJobRun", types.RecurringJobRun{})
+	recurringJobRunStatusSchema(schemas.AddType("recurringJobRunStatus", types.RecurringJobRunStatus{}))
 	schemas.AddType("replicaRemoveInput", ReplicaRemoveInput{})
 	schemas.AddType("salvageInput", SalvageInput{})
 	schemas.AddType("engineUpgradeInput", EngineUpgradeInput{})
-	schemas.AddType("replica", Replica{})
+	schemas.AddType("orphanKeepInput", OrphanKeepInput{})
+	schemas.AddType("deletionProtectionInput", DeletionProtectionInput{})
+	schemas.AddType("autoAttachInput", AutoAttachInput{})
+	snapshotHooksSchema(schemas.AddType("snapshotHooksInput", SnapshotHooksInput{}))
+	schemas.AddType("snapshotGroupRestoreInput", SnapshotGroupRestoreInput{})
+	schemas.AddType("snapshotFreezeOutput", SnapshotFreezeOutput{})
+	schemas.AddType("restoreStatus", types.RestoreStatus{})
+	schemas.AddType("condition", types.Condition{})
+	schemas.AddType("rebuildStatus", types.RebuildStatus{})
+	replicaSchema(schemas.AddType("replica", Replica{}))
 	schemas.AddType("controller", Controller{})
 	schemas.AddType("node", Node{})
 
@@ -153,6 +308,14 @@ func NewSchema() *client.Schemas {
 	recurringSchema(schemas.AddType("recurringInput", RecurringInput{}))
 	engineImageSchema(schemas.AddType("engineImage", EngineImage{}))
 	nodeSchema(schemas.AddType("node", Node{}))
+	backingImageSchema(schemas.AddType("backingImage", BackingImage{}))
+	orphanSchema(schemas.AddType("orphan", Orphan{}))
+	recurringJobPolicySchema(schemas.AddType("recurringJobPolicy", RecurringJobPolicy{}))
+	snapshotGroupSchema(schemas.AddType("snapshotGroup", SnapshotGroup{}))
+	backupTargetSchema(schemas.AddType("backupTarget", BackupTarget{}))
+	backupOperationSchema(schemas.AddType("backupOperation", BackupOperation{}))
+	snapshotExportSchema(schemas.AddType("snapshotExport", SnapshotExport{}))
+	groupSnapshotSchema(schemas.AddType("groupSnapshot", GroupSnapshot{}))
 
 	return schemas
 }
@@ -165,6 +328,10 @@ func nodeSchema(node *client.Schema) {
 	allowScheduling.Required = true
 	allowScheduling.Unique = false
 	node.ResourceFields["allowScheduling"] = allowScheduling
+
+	replicaSizeMap := node.ResourceFields["replicaSizeMap"]
+	replicaSizeMap.Type = "map[int]"
+	node.ResourceFields["replicaSizeMap"] = replicaSizeMap
 }
 
 func engineImageSchema(engineImage *client.Schema) {
@@ -178,6 +345,201 @@ func engineImageSchema(engineImage *client.Schema) {
 	engineImage.ResourceFields["image"] = image
 }
 
+func backingImageSchema(backingImage *client.Schema) {
+	backingImage.CollectionMethods = []string{"GET", "POST"}
+	backingImage.ResourceMethods = []string{"GET", "DELETE"}
+
+	name := backingImage.ResourceFields["name"]
+	name.Create = true
+	name.Required = true
+	name.Unique = true
+	backingImage.ResourceFields["name"] = name
+
+	imageURL := backingImage.ResourceFields["imageURL"]
+	imageURL.Create = true
+	imageURL.Required = true
+	backingImage.ResourceFields["imageURL"] = imageURL
+}
+
+func orphanSchema(orphan *client.Schema) {
+	orphan.CollectionMethods = []string{"GET"}
+	orphan.ResourceMethods = []string{"GET", "DELETE"}
+	orphan.ResourceActions = map[string]client.Action{
+		"keep": {
+			Input:  "orphanKeepInput",
+			Output: "orphan",
+		},
+	}
+}
+
+func recurringJobPolicySchema(policy *client.Schema) {
+	// "recurringJob" is the job set on the volume
+	policy.PluralName = "recurringjobs"
+	policy.CollectionMethods = []string{"GET", "POST"}
+	policy.ResourceMethods = []string{"GET", "PUT", "DELETE"}
+
+	name := policy.ResourceFields["name"]
+	name.Create = true
+	name.Required = true
+	name.Unique = true
+	policy.ResourceFields["name"] = name
+
+	policy.ResourceFields["retention"] = client.Field{
+		Type:     "retentionPolicy",
+		Nullable: true,
+	}
+	policy.ResourceFields["hooks"] = client.Field{
+		Type:     "snapshotHooks",
+		Nullable: true,
+	}
+
+	for _, field := range []string{"task", "cron", "retain", "retention", "freeze", "hooks", "concurrency", "selector", "groups", "backupTarget"} {
+		f := policy.ResourceFields[field]
+		f.Create = true
+		f.Update = true
+		policy.ResourceFields[field] = f
+	}
+
+	selector := policy.ResourceFields["selector"]
+	selector.Type = "map[string]"
+	policy.ResourceFields["selector"] = selector
+
+	groups := policy.ResourceFields["groups"]
+	groups.Type = "array[string]"
+	policy.ResourceFields["groups"] = groups
+
+	volumes := policy.ResourceFields["volumes"]
+	volumes.Type = "array[string]"
+	policy.ResourceFields["volumes"] = volumes
+
+	running := policy.ResourceFields["running"]
+	running.Type = "map[string]"
+	policy.ResourceFields["running"] = running
+}
+
+func snapshotGroupSchema(group *client.Schema) {
+	group.CollectionMethods = []string{"GET", "POST"}
+	group.ResourceMethods = []string{"GET", "PUT", "DELETE"}
+	group.ResourceActions = map[string]client.Action{
+		"snapshotCreate": {
+			Output: "groupSnapshot",
+		},
+		"snapshotList": {},
+		"snapshotBackup": {
+			Input:  "snapshotInput",
+			Output: "backupOperation",
+		},
+		"snapshotRestore": {
+			Input:  "snapshotGroupRestoreInput",
+			Output: "snapshotGroup",
+		},
+	}
+
+	name := group.ResourceFields["name"]
+	name.Create = true
+	name.Required = true
+	name.Unique = true
+	group.ResourceFields["name"] = name
+
+	for _, field := range []string{"volumes", "freeze"} {
+		f := group.ResourceFields[field]
+		f.Create = true
+		f.Update = true
+		group.ResourceFields[field] = f
+	}
+
+	volumes := group.ResourceFields["volumes"]
+	volumes.Type = "array[string]"
+	volumes.Required = true
+	group.ResourceFields["volumes"] = volumes
+}
+
+func backupOperationSchema(operation *client.Schema) {
+	operation.CollectionMethods = []string{"GET"}
+	operation.ResourceMethods = []string{"GET"}
+	operation.ResourceActions = map[string]client.Action{
+		"backupCancel": {
+			Output: "backupOperation",
+		},
+	}
+}
+
+func snapshotExportSchema(export *client.Schema) {
+	export.CollectionMethods = []string{"GET"}
+	export.ResourceMethods = []string{"GET", "DELETE"}
+}
+
+func backupTargetSchema(target *client.Schema) {
+	target.CollectionMethods = []string{"GET", "POST"}
+	target.ResourceMethods = []string{"GET", "PUT", "DELETE"}
+	target.ResourceActions = map[string]client.Action{
+		"backupTargetSync": {
+			Output: "backupTarget",
+		},
+	}
+
+	name := target.ResourceFields["name"]
+	name.Create = true
+	name.Required = true
+	name.Unique = true
+	target.ResourceFields["name"] = name
+
+	for _, field := range []string{"url", "credentialSecret", "pollInterval", "bandwidthLimit"} {
+		f := target.ResourceFields[field]
+		f.Create = true
+		f.Update = true
+		target.ResourceFields[field] = f
+	}
+}
+
+func groupSnapshotSchema(groupSnapshot *client.Schema) {
+	volumes := groupSnapshot.ResourceFields["volumes"]
+	volumes.Type = "array[string]"
+	groupSnapshot.ResourceFields["volumes"] = volumes
+}
+
+func replicaSchema(replica *client.Schema) {
+	restoreStatus := replica.ResourceFields["restoreStatus"]
+	restoreStatus.Type = "restoreStatus"
+	replica.ResourceFields["restoreStatus"] = restoreStatus
+
+	replica.ResourceFields["rebuildStatus"] = client.Field{
+		Type:     "rebuildStatus",
+		Nullable: true,
+	}
+}
+
+func recurringJobSchema(job *client.Schema) {
+	job.ResourceFields["retention"] = client.Field{
+		Type:     "retentionPolicy",
+		Nullable: true,
+	}
+	job.ResourceFields["hooks"] = client.Field{
+		Type:     "snapshotHooks",
+		Nullable: true,
+	}
+}
+
+func snapshotHooksSchema(hooks *client.Schema) {
+	for _, field := range []string{"pre", "post"} {
+		f := hooks.ResourceFields[field]
+		f.Type = "array[snapshotHook]"
+		hooks.ResourceFields[field] = f
+	}
+
+	failurePolicy := hooks.ResourceFields["failurePolicy"]
+	failurePolicy.Type = "enum"
+	failurePolicy.Options = []string{string(types.SnapshotHookFailurePolicyAbort), string(types.SnapshotHookFailurePolicyContinue)}
+	failurePolicy.Default = string(types.SnapshotHookFailurePolicyAbort)
+	hooks.ResourceFields["failurePolicy"] = failurePolicy
+}
+
+func recurringJobRunStatusSchema(status *client.Schema) {
+	runs := status.ResourceFields["runs"]
+	runs.Type = "array[recurringJobRun]"
+	status.ResourceFields["runs"] = runs
+}
+
 func recurringSchema(recurring *client.Schema) {
 	jobs := recurring.ResourceFields["jobs"]
 	jobs.Type = "array[recurringJob]"
@@ -222,7 +584,7 @@ func hostSchema(host *client.Schema) {
 
 func volumeSchema(volume *client.Schema) {
 	volume.CollectionMethods = []string{"GET", "POST"}
-	volume.ResourceMethods = []string{"GET", "DELETE"}
+	volume.ResourceMethods = []string{"GET", "PUT", "DELETE"}
 	volume.ResourceActions = map[string]client.Action{
 		"attach": {
 			Input:  "attachInput",
@@ -235,6 +597,30 @@ func volumeSchema(volume *client.Schema) {
 			Input:  "salvageInput",
 			Output: "volume",
 		},
+		"trim": {
+			Output: "volume",
+		},
+		"deletionProtectionUpdate": {
+			Input:  "deletionProtectionInput",
+			Output: "volume",
+		},
+		"autoAttachUpdate": {
+			Input:  "autoAttachInput",
+			Output: "volume",
+		},
+		"snapshotHooksUpdate": {
+			Input:  "snapshotHooksInput",
+			Output: "volume",
+		},
+		"recover": {
+			Output: "volume",
+		},
+		"importRetry": {
+			Output: "volume",
+		},
+		"restoreRetry": {
+			Output: "volume",
+		},
 
 		"snapshotPurge": {},
 		"snapshotCreate": {
@@ -255,8 +641,19 @@ func volumeSchema(volume *client.Schema) {
 			Output: "snapshot",
 		},
 		"snapshotBackup": {
-			Input: "snapshotInput",
+			Input:  "snapshotInput",
+			Output: "backupOperation",
 		},
+		"snapshotExport": {
+			Input:  "snapshotInput",
+			Output: "snapshotExport",
+		},
+		// snapshotFreeze and snapshotUnfreeze are sent to the manager
+		// owning the volume for the group snapshots
+		"snapshotFreeze": {
+			Output: "snapshotFreezeOutput",
+		},
+		"snapshotUnfreeze": {},
 
 		"recurringUpdate": {
 			Input: "recurringInput",
@@ -299,6 +696,23 @@ func volumeSchema(volume *client.Schema) {
 	volumeFromBackup.Create = true
 	volume.ResourceFields["fromBackup"] = volumeFromBackup
 
+	volumeFromImage := volume.ResourceFields["fromImage"]
+	volumeFromImage.Create = true
+	volume.ResourceFields["fromImage"] = volumeFromImage
+
+	volumeFromImageFormat := volume.ResourceFields["fromImageFormat"]
+	volumeFromImageFormat.Create = true
+	volumeFromImageFormat.Default = string(types.VolumeImageFormatRaw)
+	volume.ResourceFields["fromImageFormat"] = volumeFromImageFormat
+
+	volumeFromImageNodeID := volume.ResourceFields["fromImageNodeID"]
+	volumeFromImageNodeID.Create = true
+	volume.ResourceFields["fromImageNodeID"] = volumeFromImageNodeID
+
+	volumeBackingImage := volume.ResourceFields["backingImage"]
+	volumeBackingImage.Create = true
+	volume.ResourceFields["backingImage"] = volumeBackingImage
+
 	volumeNumberOfReplicas := volume.ResourceFields["numberOfReplicas"]
 	volumeNumberOfReplicas.Create = true
 	volumeNumberOfReplicas.Required = true
@@ -310,6 +724,22 @@ func volumeSchema(volume *client.Schema) {
 	volumeStaleReplicaTimeout.Default = 20
 	volume.ResourceFields["staleReplicaTimeout"] = volumeStaleReplicaTimeout
 
+	volumeDeletionProtection := volume.ResourceFields["deletionProtection"]
+	volumeDeletionProtection.Create = true
+	volumeDeletionProtection.Default = false
+	volume.ResourceFields["deletionProtection"] = volumeDeletionProtection
+
+	volumeDisableAutoAttach := volume.ResourceFields["disableAutoAttach"]
+	volumeDisableAutoAttach.Create = true
+	volumeDisableAutoAttach.Default = false
+	volume.ResourceFields["disableAutoAttach"] = volumeDisableAutoAttach
+
+	volumeLabels := volume.ResourceFields["labels"]
+	volumeLabels.Type = "map[string]"
+	volumeLabels.Create = true
+	volumeLabels.Update = true
+	volume.ResourceFields["labels"] = volumeLabels
+
 	replicas := volume.ResourceFields["replicas"]
 	replicas.Type = "array[replica]"
 	volume.ResourceFields["replicas"] = replicas
@@ -317,6 +747,18 @@ func volumeSchema(volume *client.Schema) {
 	recurringJobs := volume.ResourceFields["recurringJobs"]
 	recurringJobs.Type = "array[recurringJob]"
 	volume.ResourceFields["recurringJobs"] = recurringJobs
+
+	restoreStatus := volume.ResourceFields["restoreStatus"]
+	restoreStatus.Type = "restoreStatus"
+	volume.ResourceFields["restoreStatus"] = restoreStatus
+
+	recurringJobStatus := volume.ResourceFields["recurringJobStatus"]
+	recurringJobStatus.Type = "map[recurringJobRunStatus]"
+	volume.ResourceFields["recurringJobStatus"] = recurringJobStatus
+
+	conditions := volume.ResourceFields["conditions"]
+	conditions.Type = "map[condition]"
+	volume.ResourceFields["conditions"] = conditions
 }
 
 func toSettingResource(name, value string) *Setting {
@@ -335,6 +777,19 @@ func toSettingCollection(settings *types.SettingsInfo) *client.GenericCollection
 		toSettingResource(types.SettingBackupTarget, settings.BackupTarget),
 		toSettingResource(types.SettingDefaultEngineImage, settings.DefaultEngineImage),
 		toSettingResource(types.SettingBackupTargetCredentialSecret, settings.BackupTargetCredentialSecret),
+		toSettingResource(types.SettingAutoDeleteOrphans, strconv.FormatBool(settings.AutoDeleteOrphans)),
+		toSettingResource(types.SettingReplicaRebuildStallTimeout, strconv.Itoa(settings.ReplicaRebuildStallTimeout)),
+		toSettingResource(types.SettingNotificationWebhookURLs, settings.NotificationWebhookURLs),
+		toSettingResource(types.SettingNotificationEventReasons, settings.NotificationEventReasons),
+		toSettingResource(types.SettingNotificationSecret, settings.NotificationSecret),
+		toSettingResource(types.SettingRecycleBinRetention, strconv.Itoa(settings.RecycleBinRetention)),
+		toSettingResource(types.SettingRecurringJobHistoryLimit, strconv.Itoa(settings.RecurringJobHistoryLimit)),
+		toSettingResource(types.SettingRecurringJobFailureThreshold, strconv.Itoa(settings.RecurringJobFailureThreshold)),
+		toSettingResource(types.SettingRecurringJobMode, string(settings.RecurringJobMode)),
+		toSettingResource(types.SettingRecurringJobConcurrentLimit, strconv.Itoa(settings.RecurringJobConcurrentLimit)),
+		toSettingResource(types.SettingBackupConcurrentLimitPerNode, strconv.Itoa(settings.BackupConcurrentLimitPerNode)),
+		toSettingResource(types.SettingBackupConcurrentLimitPerCluster, strconv.Itoa(settings.BackupConcurrentLimitPerCluster)),
+		toSettingResource(types.SettingBackupBandwidthLimit, strconv.Itoa(settings.BackupBandwidthLimit)),
 	}
 	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "setting"}}
 }
@@ -346,6 +801,12 @@ func toVolumeResource(v *longhorn.Volume, ve *longhorn.Engine, vrs map[string]*l
 		if ve != nil && ve.Status.ReplicaModeMap != nil {
 			mode = string(ve.Status.ReplicaModeMap[r.Name])
 		}
+		var rebuildStatus *types.RebuildStatus
+		if ve != nil {
+			if status, exists := ve.Status.RebuildStatus[r.Name]; exists {
+				rebuildStatus = &status
+			}
+		}
 		replicas = append(replicas, Replica{
 			Instance: Instance{
 				Name:        r.Name,
@@ -354,8 +815,10 @@ func toVolumeResource(v *longhorn.Volume, ve *longhorn.Engine, vrs map[string]*l
 				NodeID:      r.Spec.NodeID,
 				EngineImage: r.Status.CurrentImage,
 			},
-			Mode:     mode,
-			FailedAt: r.Spec.FailedAt,
+			Mode:          mode,
+			FailedAt:      r.Spec.FailedAt,
+			RestoreStatus: r.Status.RestoreStatus,
+			RebuildStatus: rebuildStatus,
 		})
 	}
 
@@ -400,8 +863,12 @@ func toVolumeResource(v *longhorn.Volume, ve *longhorn.Engine, vrs map[string]*l
 		},
 		Name:                v.Name,
 		Size:                strconv.FormatInt(v.Spec.Size, 10),
+		ActualSize:          strconv.FormatInt(v.Status.ActualSize, 10),
 		Frontend:            v.Spec.Frontend,
 		FromBackup:          v.Spec.FromBackup,
+		FromImage:           v.Spec.FromImage,
+		FromImageFormat:     string(v.Spec.FromImageFormat),
+		FromImageNodeID:     v.Spec.FromImageNodeID,
 		NumberOfReplicas:    v.Spec.NumberOfReplicas,
 		State:               state,
 		RecurringJobs:       v.Spec.RecurringJobs,
@@ -409,26 +876,69 @@ func toVolumeResource(v *longhorn.Volume, ve *longhorn.Engine, vrs map[string]*l
 		Endpoint:            endpoint,
 		Created:             v.ObjectMeta.CreationTimestamp.String(),
 		EngineImage:         v.Status.CurrentImage,
+		BackingImage:        v.Spec.BackingImage,
+		ImportState:         string(v.Status.ImportState),
+		ImportProgress:      v.Status.ImportProgress,
+		ImportError:         v.Status.ImportError,
+		RestoreRequired:     v.Status.RestoreRequired,
+		RestoreStatus:       v.Status.RestoreStatus,
+		DeletionProtection:  v.Spec.DeletionProtection,
+		RecycledAt:          v.Spec.RecycledAt,
+		Labels:              datastore.GetVolumeUserLabels(v),
+		DisableAutoAttach:   v.Spec.DisableAutoAttach,
+		AutoAttachedForJobs: v.Spec.AutoAttachedForJobs,
+		SnapshotHooks:       v.Spec.SnapshotHooks,
+		Conditions:          v.Status.Conditions,
+		RecurringJobStatus:  v.Status.RecurringJobStatus,
 
 		Controller: controller,
 		Replicas:   replicas,
 	}
 
 	actions := map[string]struct{}{}
-
-	if v.Status.Robustness == types.VolumeRobustnessFaulted {
+	actions["deletionProtectionUpdate"] = struct{}{}
+	actions["autoAttachUpdate"] = struct{}{}
+	actions["snapshotHooksUpdate"] = struct{}{}
+
+	if v.Spec.RecycledAt != "" {
+		// the volume is kept detached in the recycle bin
+		actions["recover"] = struct{}{}
+	} else if v.Status.Robustness == types.VolumeRobustnessFaulted {
 		actions["salvage"] = struct{}{}
 	} else {
 		switch v.Status.State {
 		case types.VolumeStateDetached:
-			actions["attach"] = struct{}{}
+			// volume cannot be used before the image is imported or
+			// the backup is restored
+			if (v.Spec.FromImage == "" || v.Status.ImportState == types.VolumeImportStateCompleted) &&
+				!v.Status.RestoreRequired {
+				actions["attach"] = struct{}{}
+			}
+			if v.Status.ImportState == types.VolumeImportStateFailed {
+				actions["importRetry"] = struct{}{}
+			}
+			if cond, ok := v.Status.Conditions[types.VolumeConditionTypeRestoring]; ok &&
+				cond.Reason == types.VolumeConditionReasonRestoreFailure {
+				actions["restoreRetry"] = struct{}{}
+			}
 			actions["recurringUpdate"] = struct{}{}
 			actions["replicaRemove"] = struct{}{}
 			actions["engineUpgrade"] = struct{}{}
 		case types.VolumeStateAttaching:
-			actions["detach"] = struct{}{}
+			if !v.Status.RestoreRequired {
+				actions["detach"] = struct{}{}
+			}
+			if len(v.Spec.AutoAttachedForJobs) != 0 {
+				actions["attach"] = struct{}{}
+			}
 		case types.VolumeStateAttached:
 			actions["detach"] = struct{}{}
+			if len(v.Spec.AutoAttachedForJobs) != 0 {
+				actions["attach"] = struct{}{}
+			}
+			if v.Spec.Frontend == types.VolumeFrontendBlockDev && !v.Spec.DisableFrontend {
+				actions["trim"] = struct{}{}
+			}
 			actions["snapshotPurge"] = struct{}{}
 			actions["snapshotCreate"] = struct{}{}
 			actions["snapshotList"] = struct{}{}
@@ -436,6 +946,7 @@ func toVolumeResource(v *longhorn.Volume, ve *longhorn.Engine, vrs map[string]*l
 			actions["snapshotDelete"] = struct{}{}
 			actions["snapshotRevert"] = struct{}{}
 			actions["snapshotBackup"] = struct{}{}
+			actions["snapshotExport"] = struct{}{}
 			actions["recurringUpdate"] = struct{}{}
 			actions["replicaRemove"] = struct{}{}
 			actions["engineUpgrade"] = struct{}{}
@@ -492,54 +1003,72 @@ func toHostResource(node, ip string) *Host {
 	}
 }
 
-func toBackupVolumeResource(bv *engineapi.BackupVolume, apiContext *api.ApiContext) *BackupVolume {
+func toBackupVolumeResource(bv *longhorn.BackupVolume, apiContext *api.ApiContext) *BackupVolume {
 	if bv == nil {
 		logrus.Warnf("weird: nil backupVolume")
 		return nil
 	}
 	b := &BackupVolume{
 		Resource: client.Resource{
-			Id:    bv.Name,
+			Id:    bv.Spec.VolumeName,
 			Type:  "backupVolume",
 			Links: map[string]string{},
 		},
-		BackupVolume: *bv,
+		Name:               bv.Spec.VolumeName,
+		BackupTarget:       bv.Spec.BackupTarget,
+		BackupVolumeStatus: bv.Status,
 	}
 	b.Actions = map[string]string{
-		"backupList":   apiContext.UrlBuilder.ActionLink(b.Resource, "backupList"),
-		"backupGet":    apiContext.UrlBuilder.ActionLink(b.Resource, "backupGet"),
-		"backupDelete": apiContext.UrlBuilder.ActionLink(b.Resource, "backupDelete"),
+		"backupList":   backupTargetLink(apiContext.UrlBuilder.ActionLink(b.Resource, "backupList"), bv.Spec.BackupTarget),
+		"backupGet":    backupTargetLink(apiContext.UrlBuilder.ActionLink(b.Resource, "backupGet"), bv.Spec.BackupTarget),
+		"backupDelete": backupTargetLink(apiContext.UrlBuilder.ActionLink(b.Resource, "backupDelete"), bv.Spec.BackupTarget),
 	}
 	return b
 }
 
-func toBackupVolumeCollection(bv []*engineapi.BackupVolume, apiContext *api.ApiContext) *client.GenericCollection {
+// backupTargetLink selects the backup target of the link, the default target
+// is used if backupTargetName is empty
+func backupTargetLink(link, backupTargetName string) string {
+	if backupTargetName == "" {
+		return link
+	}
+	sep := "?"
+	if strings.Contains(link, "?") {
+		sep = "&"
+	}
+	return link + sep + "backupTarget=" + url.QueryEscape(backupTargetName)
+}
+
+func toBackupVolumeCollection(bvs map[string]*longhorn.BackupVolume, apiContext *api.ApiContext) *client.GenericCollection {
 	data := []interface{}{}
-	for _, v := range bv {
-		data = append(data, toBackupVolumeResource(v, apiContext))
+	for _, bv := range bvs {
+		data = append(data, toBackupVolumeResource(bv, apiContext))
 	}
 	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "backupVolume"}}
 }
 
-func toBackupResource(b *engineapi.Backup) *Backup {
+func toBackupResource(b *longhorn.Backup) *Backup {
 	if b == nil {
 		logrus.Warnf("weird: nil backup")
 		return nil
 	}
 	return &Backup{
 		Resource: client.Resource{
-			Id:    b.Name,
+			Id:    b.Spec.BackupName,
 			Type:  "backup",
 			Links: map[string]string{},
 		},
-		Backup: *b,
+		Name:         b.Spec.BackupName,
+		VolumeName:   b.Spec.VolumeName,
+		BackupTarget: b.Spec.BackupTarget,
+		BackupStatus: b.Status,
 	}
 }
 
-func toBackupCollection(bs []*engineapi.Backup) *client.GenericCollection {
+func toBackupCollection(bs map[string]*longhorn.Backup) *client.GenericCollection {
 	data := []interface{}{}
-	for _, v := range bs {
-		data = append(data, toBackupResource(v))
+	for _, b := range bs {
+		data = append(data, toBackupResource(b))
 	}
 	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "backup"}}
 }
@@ -567,6 +1096,195 @@ func toEngineImageCollection(eis map[string]*longhorn.EngineImage, defaultImage
 	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "engineImage"}}
 }
 
+func toBackingImageResource(bi *longhorn.BackingImage) *BackingImage {
+	return &BackingImage{
+		Resource: client.Resource{
+			Id:    bi.Name,
+			Type:  "backingImage",
+			Links: map[string]string{},
+		},
+		Name:               bi.Name,
+		ImageURL:           bi.Spec.ImageURL,
+		BackingImageStatus: bi.Status,
+	}
+}
+
+func toBackingImageCollection(bis map[string]*longhorn.BackingImage) *client.GenericCollection {
+	data := []interface{}{}
+	for _, bi := range bis {
+		data = append(data, toBackingImageResource(bi))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "backingImage"}}
+}
+
+func toOrphanResource(o *longhorn.Orphan, apiContext *api.ApiContext) *Orphan {
+	r := &Orphan{
+		Resource: client.Resource{
+			Id:      o.Name,
+			Type:    "orphan",
+			Actions: map[string]string{},
+			Links:   map[string]string{},
+		},
+		Name:         o.Name,
+		Created:      o.CreationTimestamp.String(),
+		OrphanSpec:   o.Spec,
+		OrphanStatus: o.Status,
+	}
+	if o.DeletionTimestamp == nil {
+		r.Actions["keep"] = apiContext.UrlBuilder.ActionLink(r.Resource, "keep")
+	}
+	return r
+}
+
+func toOrphanCollection(orphans map[string]*longhorn.Orphan, apiContext *api.ApiContext) *client.GenericCollection {
+	data := []interface{}{}
+	for _, o := range orphans {
+		data = append(data, toOrphanResource(o, apiContext))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "orphan"}}
+}
+
+func toRecurringJobPolicyResource(rj *longhorn.RecurringJob) *RecurringJobPolicy {
+	return &RecurringJobPolicy{
+		Resource: client.Resource{
+			Id:    rj.Name,
+			Type:  "recurringJobPolicy",
+			Links: map[string]string{},
+		},
+		Name:               rj.Name,
+		RecurringJobSpec:   rj.Spec,
+		RecurringJobStatus: rj.Status,
+	}
+}
+
+func toRecurringJobPolicyCollection(rjs map[string]*longhorn.RecurringJob) *client.GenericCollection {
+	data := []interface{}{}
+	for _, rj := range rjs {
+		data = append(data, toRecurringJobPolicyResource(rj))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "recurringJobPolicy"}}
+}
+
+func toSnapshotGroupResource(sg *longhorn.SnapshotGroup, apiContext *api.ApiContext) *SnapshotGroup {
+	r := &SnapshotGroup{
+		Resource: client.Resource{
+			Id:      sg.Name,
+			Type:    "snapshotGroup",
+			Actions: map[string]string{},
+			Links:   map[string]string{},
+		},
+		Name:                sg.Name,
+		SnapshotGroupSpec:   sg.Spec,
+		SnapshotGroupStatus: sg.Status,
+	}
+	for _, action := range []string{"snapshotCreate", "snapshotList", "snapshotBackup", "snapshotRestore"} {
+		r.Actions[action] = apiContext.UrlBuilder.ActionLink(r.Resource, action)
+	}
+	return r
+}
+
+func toSnapshotGroupCollection(sgs map[string]*longhorn.SnapshotGroup, apiContext *api.ApiContext) *client.GenericCollection {
+	data := []interface{}{}
+	for _, sg := range sgs {
+		data = append(data, toSnapshotGroupResource(sg, apiContext))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "snapshotGroup"}}
+}
+
+func toBackupTargetResource(bt *longhorn.BackupTarget, apiContext *api.ApiContext) *BackupTarget {
+	r := &BackupTarget{
+		Resource: client.Resource{
+			Id:      bt.Name,
+			Type:    "backupTarget",
+			Actions: map[string]string{},
+			Links:   map[string]string{},
+		},
+		Name:               bt.Name,
+		BackupTargetSpec:   bt.Spec,
+		BackupTargetStatus: bt.Status,
+	}
+	r.Actions["backupTargetSync"] = apiContext.UrlBuilder.ActionLink(r.Resource, "backupTargetSync")
+	r.Links["backupVolumes"] = backupTargetLink(apiContext.UrlBuilder.Collection("backupVolume"), bt.Name)
+	return r
+}
+
+func toBackupTargetCollection(bts map[string]*longhorn.BackupTarget, apiContext *api.ApiContext) *client.GenericCollection {
+	data := []interface{}{}
+	for _, bt := range bts {
+		data = append(data, toBackupTargetResource(bt, apiContext))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "backupTarget"}}
+}
+
+func toBackupOperationResource(bo *longhorn.BackupOperation, apiContext *api.ApiContext) *BackupOperation {
+	r := &BackupOperation{
+		Resource: client.Resource{
+			Id:      bo.Name,
+			Type:    "backupOperation",
+			Actions: map[string]string{},
+			Links:   map[string]string{},
+		},
+		Name:                  bo.Name,
+		BackupOperationSpec:   bo.Spec,
+		BackupOperationStatus: bo.Status,
+	}
+	if !bo.Status.IsFinished() {
+		r.Actions["backupCancel"] = apiContext.UrlBuilder.ActionLink(r.Resource, "backupCancel")
+	}
+	return r
+}
+
+func toBackupOperationCollection(bos map[string]*longhorn.BackupOperation, apiContext *api.ApiContext) *client.GenericCollection {
+	data := []interface{}{}
+	for _, bo := range bos {
+		data = append(data, toBackupOperationResource(bo, apiContext))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "backupOperation"}}
+}
+
+func toSnapshotExportResource(se *longhorn.SnapshotExport, apiContext *api.ApiContext) *SnapshotExport {
+	r := &SnapshotExport{
+		Resource: client.Resource{
+			Id:    se.Name,
+			Type:  "snapshotExport",
+			Links: map[string]string{},
+		},
+		Name:                 se.Name,
+		SnapshotExportSpec:   se.Spec,
+		SnapshotExportStatus: se.Status,
+	}
+	if se.Status.State == types.SnapshotExportStateCompleted {
+		r.Links["download"] = apiContext.UrlBuilder.ReferenceByIdLink("snapshotExport", se.Name) + "/download"
+	}
+	return r
+}
+
+func toSnapshotExportCollection(ses map[string]*longhorn.SnapshotExport, apiContext *api.ApiContext) *client.GenericCollection {
+	data := []interface{}{}
+	for _, se := range ses {
+		data = append(data, toSnapshotExportResource(se, apiContext))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "snapshotExport"}}
+}
+
+func toGroupSnapshotResource(gs *manager.GroupSnapshot) *GroupSnapshot {
+	return &GroupSnapshot{
+		Resource: client.Resource{
+			Id:   gs.Name,
+			Type: "groupSnapshot",
+		},
+		GroupSnapshot: *gs,
+	}
+}
+
+func toGroupSnapshotCollection(gss []*manager.GroupSnapshot) *client.GenericCollection {
+	data := []interface{}{}
+	for _, gs := range gss {
+		data = append(data, toGroupSnapshotResource(gs))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "groupSnapshot"}}
+}
+
 type Server struct {
 	m   *manager.VolumeManager
 	fwd *Fwd
@@ -580,16 +1298,31 @@ func NewServer(m *manager.VolumeManager) *Server {
 	return s
 }
 
-func toNodeResource(node *longhorn.Node) *Node {
+func toNodeResource(node *longhorn.Node, nodeList []*longhorn.Node) *Node {
+	clusterMaximum, clusterAvailable, clusterUsed := int64(0), int64(0), int64(0)
+	for _, cn := range nodeList {
+		clusterMaximum += cn.Status.StorageMaximum
+		clusterAvailable += cn.Status.StorageAvailable
+		clusterUsed += cn.Status.StorageUsed
+	}
+
 	n := &Node{
 		Resource: client.Resource{
 			Id:    node.Name,
 			Type:  "node",
 			Links: map[string]string{},
 		},
-		Name:            node.Name,
-		AllowScheduling: node.Spec.AllowScheduling,
-		Status:          node.Status.State,
+		Name:             node.Name,
+		AllowScheduling:  node.Spec.AllowScheduling,
+		Status:           node.Status.State,
+		StorageMaximum:   strconv.FormatInt(node.Status.StorageMaximum, 10),
+		StorageAvailable: strconv.FormatInt(node.Status.StorageAvailable, 10),
+		StorageUsed:      strconv.FormatInt(node.Status.StorageUsed, 10),
+		ReplicaSizeMap:   node.Status.ReplicaSizeMap,
+
+		ClusterStorageMaximum:   strconv.FormatInt(clusterMaximum, 10),
+		ClusterStorageAvailable: strconv.FormatInt(clusterAvailable, 10),
+		ClusterStorageUsed:      strconv.FormatInt(clusterUsed, 10),
 	}
 
 	return n
@@ -598,7 +1331,7 @@ func toNodeResource(node *longhorn.Node) *Node {
 func toNodeCollection(nodeList []*longhorn.Node) *client.GenericCollection {
 	data := []interface{}{}
 	for _, node := range nodeList {
-		data = append(data, toNodeResource(node))
+		data = append(data, toNodeResource(node, nodeList))
 	}
 	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "node"}}
 }
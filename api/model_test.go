@@ -0,0 +1,34 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+func TestToNodeResourceClusterStorage(t *testing.T) {
+	assert := require.New(t)
+
+	nodeList := []*longhorn.Node{}
+	for i, name := range []string{"node-1", "node-2"} {
+		node := &longhorn.Node{}
+		node.Name = name
+		node.Status = types.NodeStatus{
+			StorageMaximum:   int64(4096 * (i + 1)),
+			StorageAvailable: int64(2048 * (i + 1)),
+			StorageUsed:      int64(1024 * (i + 1)),
+		}
+		nodeList = append(nodeList, node)
+	}
+
+	n := toNodeResource(nodeList[0], nodeList)
+	assert.Equal("4096", n.StorageMaximum)
+	assert.Equal("1024", n.StorageUsed)
+	assert.Equal("12288", n.ClusterStorageMaximum)
+	assert.Equal("6144", n.ClusterStorageAvailable)
+	assert.Equal("3072", n.ClusterStorageUsed)
+}
@@ -53,7 +53,11 @@ func (s *Server) NodeGet(rw http.ResponseWriter, req *http.Request) error {
 	if err != nil {
 		return errors.Wrap(err, "fail to get node")
 	}
-	apiContext.Write(toNodeResource(node))
+	nodeList, err := s.m.GetManagerNode()
+	if err != nil {
+		return errors.Wrap(err, "fail to list nodes")
+	}
+	apiContext.Write(toNodeResource(node, nodeList))
 	return nil
 }
 
@@ -72,6 +76,13 @@ func (s *Server) NodeUpdate(rw http.ResponseWriter, req *http.Request) error {
 	node.Spec.AllowScheduling = n.AllowScheduling
 
 	unode, err := s.m.UpdateNode(node)
-	apiContext.Write(toNodeResource(unode))
+	if err != nil {
+		return err
+	}
+	nodeList, err := s.m.GetManagerNode()
+	if err != nil {
+		return errors.Wrap(err, "fail to list nodes")
+	}
+	apiContext.Write(toNodeResource(unode, nodeList))
 	return nil
 }
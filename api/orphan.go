@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/api"
+)
+
+func (s *Server) OrphanList(rw http.ResponseWriter, req *http.Request) (err error) {
+	apiContext := api.GetApiContext(req)
+
+	orphans, err := s.m.ListOrphans()
+	if err != nil {
+		return errors.Wrap(err, "error listing orphan")
+	}
+	apiContext.Write(toOrphanCollection(orphans, apiContext))
+	return nil
+}
+
+func (s *Server) OrphanGet(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	o, err := s.m.GetOrphan(id)
+	if err != nil {
+		return errors.Wrapf(err, "error get orphan '%s'", id)
+	}
+	if o == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	apiContext.Write(toOrphanResource(o, apiContext))
+	return nil
+}
+
+func (s *Server) OrphanDelete(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+	if err := s.m.DeleteOrphan(id); err != nil {
+		return errors.Wrap(err, "unable to delete orphan")
+	}
+
+	return nil
+}
+
+func (s *Server) OrphanKeep(rw http.ResponseWriter, req *http.Request) error {
+	var input OrphanKeepInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrapf(err, "error reading orphanKeepInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	o, err := s.m.KeepOrphan(id, input.Keep)
+	if err != nil {
+		return errors.Wrapf(err, "unable to update orphan %v", id)
+	}
+	apiContext.Write(toOrphanResource(o, apiContext))
+	return nil
+}
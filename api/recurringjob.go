@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/api"
+)
+
+func (s *Server) RecurringJobList(rw http.ResponseWriter, req *http.Request) (err error) {
+	apiContext := api.GetApiContext(req)
+
+	rjs, err := s.m.ListRecurringJobs()
+	if err != nil {
+		return errors.Wrap(err, "error listing recurring jobs")
+	}
+	apiContext.Write(toRecurringJobPolicyCollection(rjs))
+	return nil
+}
+
+func (s *Server) RecurringJobGet(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	rj, err := s.m.GetRecurringJob(id)
+	if err != nil {
+		return errors.Wrapf(err, "error get recurring job '%s'", id)
+	}
+	if rj == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	apiContext.Write(toRecurringJobPolicyResource(rj))
+	return nil
+}
+
+func (s *Server) RecurringJobCreate(rw http.ResponseWriter, req *http.Request) error {
+	var input RecurringJobPolicy
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	rj, err := s.m.CreateRecurringJob(input.Name, &input.RecurringJobSpec)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create recurring job %v", input.Name)
+	}
+	apiContext.Write(toRecurringJobPolicyResource(rj))
+	return nil
+}
+
+func (s *Server) RecurringJobUpdate(rw http.ResponseWriter, req *http.Request) error {
+	var input RecurringJobPolicy
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	id := mux.Vars(req)["name"]
+
+	rj, err := s.m.UpdateRecurringJob(id, &input.RecurringJobSpec)
+	if err != nil {
+		return errors.Wrapf(err, "unable to update recurring job %v", id)
+	}
+	apiContext.Write(toRecurringJobPolicyResource(rj))
+	return nil
+}
+
+func (s *Server) RecurringJobDelete(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+	if err := s.m.DeleteRecurringJob(id); err != nil {
+		return errors.Wrap(err, "unable to delete recurring job")
+	}
+
+	return nil
+}
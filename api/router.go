@@ -11,6 +11,8 @@ import (
 	"github.com/rancher/go-rancher/client"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/rancher/longhorn-manager/metrics"
 )
 
 var (
@@ -53,12 +55,15 @@ func NewRouter(s *Server) *mux.Router {
 	r.Methods("GET").Path("/v1/schemas").Handler(api.SchemasHandler(schemas))
 	r.Methods("GET").Path("/v1/schemas/{id}").Handler(api.SchemaHandler(schemas))
 
+	r.Methods("GET").Path("/metrics").Handler(metrics.Handler())
+
 	r.Methods("GET").Path("/v1/settings").Handler(f(schemas, s.SettingsList))
 	r.Methods("GET").Path("/v1/settings/{name}").Handler(f(schemas, s.SettingsGet))
 	r.Methods("PUT").Path("/v1/settings/{name}").Handler(f(schemas, s.SettingsSet))
 
 	r.Methods("GET").Path("/v1/volumes").Handler(f(schemas, s.VolumeList))
 	r.Methods("GET").Path("/v1/volumes/{name}").Handler(f(schemas, s.VolumeGet))
+	r.Methods("PUT").Path("/v1/volumes/{name}").Handler(f(schemas, s.VolumeUpdate))
 	r.Methods("DELETE").Path("/v1/volumes/{name}").Handler(f(schemas, s.VolumeDelete))
 	r.Methods("POST").Path("/v1/volumes").Handler(f(schemas, s.VolumeCreate))
 
@@ -67,6 +72,14 @@ func NewRouter(s *Server) *mux.Router {
 		"detach":          s.VolumeDetach,
 		"salvage":         s.VolumeSalvage,
 		"recurringUpdate": s.VolumeRecurringUpdate,
+		"trim":            s.fwd.Handler(OwnerIDFromVolume(s.m), s.VolumeTrim),
+		"recover":         s.VolumeRecover,
+		"importRetry":     s.VolumeImportRetry,
+		"restoreRetry":    s.VolumeRestoreRetry,
+
+		"deletionProtectionUpdate": s.VolumeDeletionProtectionUpdate,
+		"autoAttachUpdate":         s.VolumeAutoAttachUpdate,
+		"snapshotHooksUpdate":      s.VolumeSnapshotHooksUpdate,
 
 		"snapshotPurge":  s.fwd.Handler(OwnerIDFromVolume(s.m), s.SnapshotPurge),
 		"snapshotCreate": s.fwd.Handler(OwnerIDFromVolume(s.m), s.SnapshotCreate),
@@ -75,6 +88,10 @@ func NewRouter(s *Server) *mux.Router {
 		"snapshotDelete": s.fwd.Handler(OwnerIDFromVolume(s.m), s.SnapshotDelete),
 		"snapshotRevert": s.fwd.Handler(OwnerIDFromVolume(s.m), s.SnapshotRevert),
 		"snapshotBackup": s.fwd.Handler(OwnerIDFromVolume(s.m), s.SnapshotBackup),
+		"snapshotExport": s.fwd.Handler(OwnerIDFromVolume(s.m), s.SnapshotExport),
+
+		"snapshotFreeze":   s.fwd.Handler(OwnerIDFromVolume(s.m), s.VolumeSnapshotFreeze),
+		"snapshotUnfreeze": s.fwd.Handler(OwnerIDFromVolume(s.m), s.VolumeSnapshotUnfreeze),
 
 		"replicaRemove": s.fwd.Handler(OwnerIDFromVolume(s.m), s.ReplicaRemove),
 		"engineUpgrade": s.fwd.Handler(OwnerIDFromVolume(s.m), s.EngineUpgrade),
@@ -106,5 +123,57 @@ func NewRouter(s *Server) *mux.Router {
 	r.Methods("DELETE").Path("/v1/engineimages/{name}").Handler(f(schemas, s.EngineImageDelete))
 	r.Methods("POST").Path("/v1/engineimages").Handler(f(schemas, s.EngineImageCreate))
 
+	r.Methods("GET").Path("/v1/backingimages").Handler(f(schemas, s.BackingImageList))
+	r.Methods("GET").Path("/v1/backingimages/{name}").Handler(f(schemas, s.BackingImageGet))
+	r.Methods("DELETE").Path("/v1/backingimages/{name}").Handler(f(schemas, s.BackingImageDelete))
+	r.Methods("POST").Path("/v1/backingimages").Handler(f(schemas, s.BackingImageCreate))
+
+	r.Methods("GET").Path("/v1/orphans").Handler(f(schemas, s.OrphanList))
+	r.Methods("GET").Path("/v1/orphans/{name}").Handler(f(schemas, s.OrphanGet))
+	r.Methods("DELETE").Path("/v1/orphans/{name}").Handler(f(schemas, s.OrphanDelete))
+	orphanActions := map[string]func(http.ResponseWriter, *http.Request) error{
+		"keep": s.OrphanKeep,
+	}
+	for name, action := range orphanActions {
+		r.Methods("POST").Path("/v1/orphans/{name}").Queries("action", name).Handler(f(schemas, action))
+	}
+
+	r.Methods("GET").Path("/v1/recurringjobs").Handler(f(schemas, s.RecurringJobList))
+	r.Methods("GET").Path("/v1/recurringjobs/{name}").Handler(f(schemas, s.RecurringJobGet))
+	r.Methods("PUT").Path("/v1/recurringjobs/{name}").Handler(f(schemas, s.RecurringJobUpdate))
+	r.Methods("DELETE").Path("/v1/recurringjobs/{name}").Handler(f(schemas, s.RecurringJobDelete))
+	r.Methods("POST").Path("/v1/recurringjobs").Handler(f(schemas, s.RecurringJobCreate))
+
+	r.Methods("GET").Path("/v1/backuptargets").Handler(f(schemas, s.BackupTargetList))
+	r.Methods("GET").Path("/v1/backuptargets/{name}").Handler(f(schemas, s.BackupTargetGet))
+	r.Methods("PUT").Path("/v1/backuptargets/{name}").Handler(f(schemas, s.BackupTargetUpdate))
+	r.Methods("DELETE").Path("/v1/backuptargets/{name}").Handler(f(schemas, s.BackupTargetDelete))
+	r.Methods("POST").Path("/v1/backuptargets").Handler(f(schemas, s.BackupTargetCreate))
+	r.Methods("POST").Path("/v1/backuptargets/{name}").Queries("action", "backupTargetSync").Handler(f(schemas, s.BackupTargetSync))
+
+	r.Methods("GET").Path("/v1/backupoperations").Handler(f(schemas, s.BackupOperationList))
+	r.Methods("GET").Path("/v1/backupoperations/{name}").Handler(f(schemas, s.BackupOperationGet))
+	r.Methods("POST").Path("/v1/backupoperations/{name}").Queries("action", "backupCancel").Handler(f(schemas, s.BackupOperationCancel))
+
+	r.Methods("GET").Path("/v1/snapshotexports").Handler(f(schemas, s.SnapshotExportList))
+	r.Methods("GET").Path("/v1/snapshotexports/{name}").Handler(f(schemas, s.SnapshotExportGet))
+	r.Methods("DELETE").Path("/v1/snapshotexports/{name}").Handler(f(schemas, s.SnapshotExportDelete))
+	r.Methods("GET").Path("/v1/snapshotexports/{name}/download").Handler(f(schemas, s.fwd.Handler(OwnerIDFromSnapshotExport(s.m), s.SnapshotExportDownload)))
+
+	r.Methods("GET").Path("/v1/snapshotgroups").Handler(f(schemas, s.SnapshotGroupList))
+	r.Methods("GET").Path("/v1/snapshotgroups/{name}").Handler(f(schemas, s.SnapshotGroupGet))
+	r.Methods("PUT").Path("/v1/snapshotgroups/{name}").Handler(f(schemas, s.SnapshotGroupUpdate))
+	r.Methods("DELETE").Path("/v1/snapshotgroups/{name}").Handler(f(schemas, s.SnapshotGroupDelete))
+	r.Methods("POST").Path("/v1/snapshotgroups").Handler(f(schemas, s.SnapshotGroupCreate))
+	snapshotGroupActions := map[string]func(http.ResponseWriter, *http.Request) error{
+		"snapshotCreate":  s.SnapshotGroupSnapshotCreate,
+		"snapshotList":    s.SnapshotGroupSnapshotList,
+		"snapshotBackup":  s.SnapshotGroupSnapshotBackup,
+		"snapshotRestore": s.SnapshotGroupSnapshotRestore,
+	}
+	for name, action := range snapshotGroupActions {
+		r.Methods("POST").Path("/v1/snapshotgroups/{name}").Queries("action", name).Handler(f(schemas, action))
+	}
+
 	return r
 }
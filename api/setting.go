@@ -2,7 +2,9 @@ package api
 
 import (
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -10,6 +12,7 @@ import (
 	"github.com/rancher/go-rancher/api"
 
 	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
 )
 
 func (s *Server) SettingsList(w http.ResponseWriter, req *http.Request) error {
@@ -39,6 +42,32 @@ func (s *Server) SettingsGet(w http.ResponseWriter, req *http.Request) error {
 		value = si.DefaultEngineImage
 	case types.SettingBackupTargetCredentialSecret:
 		value = si.BackupTargetCredentialSecret
+	case types.SettingAutoDeleteOrphans:
+		value = strconv.FormatBool(si.AutoDeleteOrphans)
+	case types.SettingReplicaRebuildStallTimeout:
+		value = strconv.Itoa(si.ReplicaRebuildStallTimeout)
+	case types.SettingNotificationWebhookURLs:
+		value = si.NotificationWebhookURLs
+	case types.SettingNotificationEventReasons:
+		value = si.NotificationEventReasons
+	case types.SettingNotificationSecret:
+		value = si.NotificationSecret
+	case types.SettingRecycleBinRetention:
+		value = strconv.Itoa(si.RecycleBinRetention)
+	case types.SettingRecurringJobHistoryLimit:
+		value = strconv.Itoa(si.RecurringJobHistoryLimit)
+	case types.SettingRecurringJobFailureThreshold:
+		value = strconv.Itoa(si.RecurringJobFailureThreshold)
+	case types.SettingRecurringJobMode:
+		value = string(si.RecurringJobMode)
+	case types.SettingRecurringJobConcurrentLimit:
+		value = strconv.Itoa(si.RecurringJobConcurrentLimit)
+	case types.SettingBackupConcurrentLimitPerNode:
+		value = strconv.Itoa(si.BackupConcurrentLimitPerNode)
+	case types.SettingBackupConcurrentLimitPerCluster:
+		value = strconv.Itoa(si.BackupConcurrentLimitPerCluster)
+	case types.SettingBackupBandwidthLimit:
+		value = strconv.Itoa(si.BackupBandwidthLimit)
 	default:
 		return errors.Errorf("invalid setting name %v", name)
 	}
@@ -75,6 +104,77 @@ func (s *Server) SettingsSet(w http.ResponseWriter, req *http.Request) error {
 		si.DefaultEngineImage = setting.Value
 	case types.SettingBackupTargetCredentialSecret:
 		si.BackupTargetCredentialSecret = setting.Value
+	case types.SettingAutoDeleteOrphans:
+		autoDelete, err := strconv.ParseBool(setting.Value)
+		if err != nil {
+			return errors.Wrapf(err, "fail to set settings with invalid %v %v", name, setting.Value)
+		}
+		si.AutoDeleteOrphans = autoDelete
+	case types.SettingReplicaRebuildStallTimeout:
+		timeout, err := strconv.Atoi(setting.Value)
+		if err != nil || timeout <= 0 {
+			return errors.Errorf("fail to set settings with invalid %v %v, must be a positive number of minutes", name, setting.Value)
+		}
+		si.ReplicaRebuildStallTimeout = timeout
+	case types.SettingNotificationWebhookURLs:
+		for webhook := range util.SplitStringToMap(setting.Value, ",") {
+			u, err := url.Parse(webhook)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.Errorf("fail to set settings with invalid webhook URL %v", webhook)
+			}
+		}
+		si.NotificationWebhookURLs = setting.Value
+	case types.SettingNotificationEventReasons:
+		si.NotificationEventReasons = setting.Value
+	case types.SettingNotificationSecret:
+		si.NotificationSecret = setting.Value
+	case types.SettingRecycleBinRetention:
+		retention, err := strconv.Atoi(setting.Value)
+		if err != nil || retention < 0 {
+			return errors.Errorf("fail to set settings with invalid %v %v, must be a number of hours, 0 to disable", name, setting.Value)
+		}
+		si.RecycleBinRetention = retention
+	case types.SettingRecurringJobHistoryLimit:
+		limit, err := strconv.Atoi(setting.Value)
+		if err != nil || limit <= 0 {
+			return errors.Errorf("fail to set settings with invalid %v %v, must be a positive number of runs", name, setting.Value)
+		}
+		si.RecurringJobHistoryLimit = limit
+	case types.SettingRecurringJobFailureThreshold:
+		threshold, err := strconv.Atoi(setting.Value)
+		if err != nil || threshold < 0 {
+			return errors.Errorf("fail to set settings with invalid %v %v, must be a number of failures, 0 to disable", name, setting.Value)
+		}
+		si.RecurringJobFailureThreshold = threshold
+	case types.SettingRecurringJobMode:
+		mode := types.RecurringJobMode(setting.Value)
+		if mode != types.RecurringJobModeCronJob && mode != types.RecurringJobModeManager {
+			return errors.Errorf("fail to set settings with invalid %v %v, must be %v or %v", name, setting.Value,
+				types.RecurringJobModeCronJob, types.RecurringJobModeManager)
+		}
+		si.RecurringJobMode = mode
+	case types.SettingRecurringJobConcurrentLimit:
+		limit, err := strconv.Atoi(setting.Value)
+		if err != nil || limit <= 0 {
+			return errors.Errorf("fail to set settings with invalid %v %v, must be a positive number of jobs", name, setting.Value)
+		}
+		si.RecurringJobConcurrentLimit = limit
+	case types.SettingBackupConcurrentLimitPerNode, types.SettingBackupConcurrentLimitPerCluster:
+		limit, err := strconv.Atoi(setting.Value)
+		if err != nil || limit < 0 {
+			return errors.Errorf("fail to set settings with invalid %v %v, must be a number of backups, 0 to disable", name, setting.Value)
+		}
+		if name == types.SettingBackupConcurrentLimitPerNode {
+			si.BackupConcurrentLimitPerNode = limit
+		} else {
+			si.BackupConcurrentLimitPerCluster = limit
+		}
+	case types.SettingBackupBandwidthLimit:
+		limit, err := strconv.Atoi(setting.Value)
+		if err != nil || limit < 0 {
+			return errors.Errorf("fail to set settings with invalid %v %v, must be a number of MiB per second, 0 to disable", name, setting.Value)
+		}
+		si.BackupBandwidthLimit = limit
 	default:
 		return errors.Wrapf(err, "invalid setting name %v", name)
 	}
@@ -21,7 +21,7 @@ func (s *Server) SnapshotCreate(w http.ResponseWriter, req *http.Request) (err e
 
 	volName := mux.Vars(req)["name"]
 
-	snapshot, err := s.m.CreateSnapshot(input.Name, input.Labels, volName)
+	snapshot, err := s.m.CreateSnapshot(input.Name, input.Labels, input.Freeze, volName)
 	if err != nil {
 		return err
 	}
@@ -119,7 +119,12 @@ func (s *Server) SnapshotBackup(w http.ResponseWriter, req *http.Request) (err e
 
 	volName := mux.Vars(req)["name"]
 
-	return s.m.BackupSnapshot(input.Name, nil, volName)
+	bo, err := s.m.CreateBackupOperation(input.Name, nil, volName, input.BackupTarget)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toBackupOperationResource(bo, apiContext))
+	return nil
 }
 
 func (s *Server) SnapshotPurge(w http.ResponseWriter, req *http.Request) (err error) {
@@ -131,3 +136,25 @@ func (s *Server) SnapshotPurge(w http.ResponseWriter, req *http.Request) (err er
 
 	return s.m.PurgeSnapshot(volName)
 }
+
+func (s *Server) SnapshotExport(w http.ResponseWriter, req *http.Request) (err error) {
+	defer func() {
+		err = errors.Wrap(err, "fail to export snapshot")
+	}()
+
+	var input SnapshotInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	volName := mux.Vars(req)["name"]
+
+	se, err := s.m.CreateSnapshotExport(input.Name, volName)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toSnapshotExportResource(se, apiContext))
+	return nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/api"
+
+	"github.com/rancher/longhorn-manager/types"
+)
+
+func (s *Server) SnapshotExportList(rw http.ResponseWriter, req *http.Request) (err error) {
+	apiContext := api.GetApiContext(req)
+
+	volumeName := req.URL.Query().Get("volume")
+
+	ses, err := s.m.ListSnapshotExports(volumeName)
+	if err != nil {
+		return errors.Wrap(err, "error listing snapshot exports")
+	}
+	apiContext.Write(toSnapshotExportCollection(ses, apiContext))
+	return nil
+}
+
+func (s *Server) SnapshotExportGet(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	se, err := s.m.GetSnapshotExport(id)
+	if err != nil {
+		return errors.Wrapf(err, "error get snapshot export '%s'", id)
+	}
+	if se == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	apiContext.Write(toSnapshotExportResource(se, apiContext))
+	return nil
+}
+
+func (s *Server) SnapshotExportDelete(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+
+	if err := s.m.DeleteSnapshotExport(id); err != nil {
+		return errors.Wrapf(err, "unable to delete snapshot export %v", id)
+	}
+	return nil
+}
+
+// SnapshotExportDownload serves the exported image from the manager keeping
+// it, the requests are forwarded there
+func (s *Server) SnapshotExportDownload(w http.ResponseWriter, req *http.Request) (err error) {
+	id := mux.Vars(req)["name"]
+
+	defer func() {
+		err = errors.Wrapf(err, "fail to download snapshot export %v", id)
+	}()
+
+	se, err := s.m.GetSnapshotExport(id)
+	if err != nil {
+		return err
+	}
+	if se == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	if se.Status.State != types.SnapshotExportStateCompleted {
+		return fmt.Errorf("export is %v", se.Status.State)
+	}
+
+	f, err := os.Open(types.GetSnapshotExportFile(types.SnapshotExportDirectory, se.Spec.VolumeName, se.Spec.SnapshotName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// The checksum doubles as the ETag, so a resumed download using
+	// If-Range would restart from the beginning if the content changed
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.img", se.Spec.VolumeName, se.Spec.SnapshotName))
+	w.Header().Set("ETag", strconv.Quote(se.Status.Checksum))
+	w.Header().Set("X-Longhorn-Snapshot-Size", strconv.FormatInt(se.Status.Size, 10))
+	w.Header().Set("X-Longhorn-Snapshot-Checksum", "sha512="+se.Status.Checksum)
+	http.ServeContent(w, req, "", time.Time{}, f)
+	return nil
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/rancher/go-rancher/api"
+	"github.com/rancher/go-rancher/client"
+)
+
+func (s *Server) SnapshotGroupList(rw http.ResponseWriter, req *http.Request) (err error) {
+	apiContext := api.GetApiContext(req)
+
+	sgs, err := s.m.ListSnapshotGroups()
+	if err != nil {
+		return errors.Wrap(err, "error listing snapshot groups")
+	}
+	apiContext.Write(toSnapshotGroupCollection(sgs, apiContext))
+	return nil
+}
+
+func (s *Server) SnapshotGroupGet(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	sg, err := s.m.GetSnapshotGroup(id)
+	if err != nil {
+		return errors.Wrapf(err, "error get snapshot group '%s'", id)
+	}
+	if sg == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	apiContext.Write(toSnapshotGroupResource(sg, apiContext))
+	return nil
+}
+
+func (s *Server) SnapshotGroupCreate(rw http.ResponseWriter, req *http.Request) error {
+	var input SnapshotGroup
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	sg, err := s.m.CreateSnapshotGroup(input.Name, &input.SnapshotGroupSpec)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toSnapshotGroupResource(sg, apiContext))
+	return nil
+}
+
+func (s *Server) SnapshotGroupUpdate(rw http.ResponseWriter, req *http.Request) error {
+	var input SnapshotGroup
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	id := mux.Vars(req)["name"]
+
+	sg, err := s.m.UpdateSnapshotGroup(id, &input.SnapshotGroupSpec)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toSnapshotGroupResource(sg, apiContext))
+	return nil
+}
+
+func (s *Server) SnapshotGroupDelete(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+	return s.m.DeleteSnapshotGroup(id)
+}
+
+func (s *Server) SnapshotGroupSnapshotCreate(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	snapshotName, err := s.m.CreateGroupSnapshot(id, &volumeFreezer{s: s})
+	if err != nil {
+		return err
+	}
+	snapshots, err := s.m.ListGroupSnapshots(id)
+	if err != nil {
+		return err
+	}
+	for _, gs := range snapshots {
+		if gs.Name == snapshotName {
+			apiContext.Write(toGroupSnapshotResource(gs))
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot find just created snapshot %v of group %v", snapshotName, id)
+}
+
+func (s *Server) SnapshotGroupSnapshotList(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	snapshots, err := s.m.ListGroupSnapshots(id)
+	if err != nil {
+		return errors.Wrapf(err, "error listing snapshots of group %v", id)
+	}
+	apiContext.Write(toGroupSnapshotCollection(snapshots))
+	return nil
+}
+
+func (s *Server) SnapshotGroupSnapshotBackup(rw http.ResponseWriter, req *http.Request) error {
+	var input SnapshotInput
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	id := mux.Vars(req)["name"]
+
+	bos, err := s.m.BackupGroupSnapshot(id, input.Name, input.BackupTarget)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(toBackupOperationCollection(bos, apiContext))
+	return nil
+}
+
+func (s *Server) SnapshotGroupSnapshotRestore(rw http.ResponseWriter, req *http.Request) error {
+	var input SnapshotGroupRestoreInput
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	id := mux.Vars(req)["name"]
+
+	if _, err := s.m.RestoreGroupSnapshot(id, input.Snapshot, input.VolumePrefix, input.BackupTarget); err != nil {
+		return err
+	}
+	return s.SnapshotGroupGet(rw, req)
+}
+
+func (s *Server) VolumeSnapshotFreeze(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	frozen, err := s.m.FreezeVolume(id)
+	if err != nil {
+		return err
+	}
+	apiContext.Write(&SnapshotFreezeOutput{
+		Resource: client.Resource{
+			Id:   id,
+			Type: "snapshotFreezeOutput",
+		},
+		Frozen: frozen,
+	})
+	return nil
+}
+
+func (s *Server) VolumeSnapshotUnfreeze(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+	return s.m.UnfreezeVolume(id)
+}
+
+// volumeFreezer freezes the volumes of the group snapshots through the
+// managers owning the volumes
+type volumeFreezer struct {
+	s *Server
+}
+
+func (f *volumeFreezer) ownerID(volumeName string) (string, error) {
+	v, err := f.s.m.Get(volumeName)
+	if err != nil {
+		return "", errors.Wrapf(err, "error getting volume '%s'", volumeName)
+	}
+	if v == nil {
+		return "", fmt.Errorf("cannot find volume %v", volumeName)
+	}
+	if v.Spec.OwnerID == f.s.m.GetCurrentNodeID() {
+		return "", nil
+	}
+	return v.Spec.OwnerID, nil
+}
+
+func (f *volumeFreezer) FreezeVolume(volumeName string) (bool, error) {
+	ownerID, err := f.ownerID(volumeName)
+	if err != nil {
+		return false, err
+	}
+	if ownerID == "" {
+		return f.s.m.FreezeVolume(volumeName)
+	}
+	output := &SnapshotFreezeOutput{}
+	if err := f.s.fwd.Call(ownerID, "POST", "/v1/volumes/"+volumeName+"?action=snapshotFreeze", struct{}{}, output); err != nil {
+		return false, err
+	}
+	return output.Frozen, nil
+}
+
+func (f *volumeFreezer) UnfreezeVolume(volumeName string) error {
+	ownerID, err := f.ownerID(volumeName)
+	if err != nil {
+		return err
+	}
+	if ownerID == "" {
+		return f.s.m.UnfreezeVolume(volumeName)
+	}
+	return f.s.fwd.Call(ownerID, "POST", "/v1/volumes/"+volumeName+"?action=snapshotUnfreeze", struct{}{}, nil)
+}
@@ -3,12 +3,16 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/rancher/go-rancher/api"
 	"github.com/rancher/go-rancher/client"
+	"k8s.io/apimachinery/pkg/labels"
 
+	"github.com/rancher/longhorn-manager/manager"
 	"github.com/rancher/longhorn-manager/types"
 	"github.com/rancher/longhorn-manager/util"
 
@@ -24,7 +28,11 @@ func (s *Server) VolumeList(rw http.ResponseWriter, req *http.Request) (err erro
 
 	resp := &client.GenericCollection{}
 
-	volumes, err := s.m.List()
+	opts, err := parseVolumeListOptions(req.URL.Query())
+	if err != nil {
+		return err
+	}
+	volumes, total, err := s.m.ListWithOptions(opts)
 	if err != nil {
 		return err
 	}
@@ -44,11 +52,94 @@ func (s *Server) VolumeList(rw http.ResponseWriter, req *http.Request) (err erro
 	resp.CreateTypes = map[string]string{
 		"volume": apiContext.UrlBuilder.Collection("volume"),
 	}
+	resp.Pagination = toVolumePagination(req.URL.Query(), opts, len(volumes), total, apiContext)
+	if opts.SortBy != "" {
+		resp.Sort = &client.Sort{
+			Name:  opts.SortBy,
+			Order: req.URL.Query().Get("order"),
+		}
+	}
 	apiContext.Write(resp)
 
 	return nil
 }
 
+// parseVolumeListOptions reads the filters, sorting and pagination from the
+// query, e.g. ?labelSelector=app=db,tier!=test&state=attached&sort=size&order=desc&limit=100
+func parseVolumeListOptions(query url.Values) (*manager.VolumeListOptions, error) {
+	opts := &manager.VolumeListOptions{
+		State:       types.VolumeState(query.Get("state")),
+		Robustness:  types.VolumeRobustness(query.Get("robustness")),
+		NodeID:      query.Get("node"),
+		EngineImage: query.Get("engineImage"),
+		SortBy:      query.Get("sort"),
+	}
+	if selector := query.Get("labelSelector"); selector != "" {
+		labelSelector, err := labels.Parse(selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid label selector %v", selector)
+		}
+		opts.LabelSelector = labelSelector
+	}
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return nil, fmt.Errorf("invalid sort order %v", order)
+	}
+	if marker := query.Get("marker"); marker != "" {
+		n, err := strconv.Atoi(marker)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid marker %v", marker)
+		}
+		opts.Marker = n
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid limit %v", limit)
+		}
+		opts.Limit = n
+	}
+	return opts, nil
+}
+
+func toVolumePagination(query url.Values, opts *manager.VolumeListOptions, count, total int, apiContext *api.ApiContext) *client.Pagination {
+	total64 := int64(total)
+	pagination := &client.Pagination{
+		Marker: strconv.Itoa(opts.Marker),
+		Total:  &total64,
+	}
+	if opts.Limit == 0 {
+		return pagination
+	}
+	limit64 := int64(opts.Limit)
+	pagination.Limit = &limit64
+
+	link := func(marker int) string {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("marker", strconv.Itoa(marker))
+		return apiContext.UrlBuilder.Collection("volume") + "?" + q.Encode()
+	}
+	pagination.First = link(0)
+	if opts.Marker > 0 {
+		previous := opts.Marker - opts.Limit
+		if previous < 0 {
+			previous = 0
+		}
+		pagination.Previous = link(previous)
+	}
+	if opts.Marker+count < total {
+		pagination.Next = link(opts.Marker + count)
+		pagination.Partial = true
+	}
+	return pagination
+}
+
 func (s *Server) VolumeGet(rw http.ResponseWriter, req *http.Request) error {
 	id := mux.Vars(req)["name"]
 	return s.responseWithVolume(rw, req, id, nil)
@@ -105,15 +196,38 @@ func (s *Server) VolumeCreate(rw http.ResponseWriter, req *http.Request) error {
 		Size:                size,
 		Frontend:            volume.Frontend,
 		FromBackup:          volume.FromBackup,
+		FromImage:           volume.FromImage,
+		FromImageFormat:     types.VolumeImageFormat(volume.FromImageFormat),
+		FromImageNodeID:     volume.FromImageNodeID,
+		BackingImage:        volume.BackingImage,
 		NumberOfReplicas:    volume.NumberOfReplicas,
 		StaleReplicaTimeout: volume.StaleReplicaTimeout,
-	})
+		DeletionProtection:  volume.DeletionProtection,
+		DisableAutoAttach:   volume.DisableAutoAttach,
+	}, volume.Labels)
 	if err != nil {
 		return errors.Wrap(err, "unable to create volume")
 	}
 	return s.responseWithVolume(rw, req, "", v)
 }
 
+func (s *Server) VolumeUpdate(rw http.ResponseWriter, req *http.Request) error {
+	var volume Volume
+	apiContext := api.GetApiContext(req)
+
+	if err := apiContext.Read(&volume); err != nil {
+		return err
+	}
+
+	id := mux.Vars(req)["name"]
+
+	v, err := s.m.UpdateLabels(id, volume.Labels)
+	if err != nil {
+		return errors.Wrap(err, "unable to update volume")
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
 func (s *Server) VolumeDelete(rw http.ResponseWriter, req *http.Request) error {
 	id := mux.Vars(req)["name"]
 
@@ -171,6 +285,103 @@ func (s *Server) VolumeSalvage(rw http.ResponseWriter, req *http.Request) error
 	return s.responseWithVolume(rw, req, "", v)
 }
 
+func (s *Server) VolumeRecover(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+
+	v, err := s.m.Recover(id)
+	if err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeImportRetry(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+
+	v, err := s.m.RetryImport(id)
+	if err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeRestoreRetry(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+
+	v, err := s.m.RetryRestore(id)
+	if err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeDeletionProtectionUpdate(rw http.ResponseWriter, req *http.Request) error {
+	var input DeletionProtectionInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrapf(err, "error read deletionProtectionInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	v, err := s.m.UpdateDeletionProtection(id, input.DeletionProtection)
+	if err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeAutoAttachUpdate(rw http.ResponseWriter, req *http.Request) error {
+	var input AutoAttachInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrapf(err, "error read autoAttachInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	v, err := s.m.UpdateDisableAutoAttach(id, input.DisableAutoAttach)
+	if err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeSnapshotHooksUpdate(rw http.ResponseWriter, req *http.Request) error {
+	var input SnapshotHooksInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrapf(err, "error read snapshotHooksInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	v, err := s.m.UpdateSnapshotHooks(id, &input.SnapshotHooks)
+	if err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeTrim(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+
+	if err := s.m.TrimVolume(id); err != nil {
+		return errors.Wrapf(err, "unable to trim volume %v", id)
+	}
+
+	return s.responseWithVolume(rw, req, id, nil)
+}
+
 func (s *Server) VolumeRecurringUpdate(rw http.ResponseWriter, req *http.Request) error {
 	var input RecurringInput
 	id := mux.Vars(req)["name"]
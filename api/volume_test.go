@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher/longhorn-manager/manager"
+	"github.com/rancher/longhorn-manager/types"
+)
+
+func TestParseVolumeListOptions(t *testing.T) {
+	assert := require.New(t)
+
+	testCases := map[string]struct {
+		query     string
+		expect    manager.VolumeListOptions
+		expectErr string
+	}{
+		"empty": {
+			"",
+			manager.VolumeListOptions{},
+			"",
+		},
+		"filters": {
+			"state=attached&robustness=healthy&node=node-1&engineImage=longhorn-engine:v1",
+			manager.VolumeListOptions{
+				State:       types.VolumeStateAttached,
+				Robustness:  types.VolumeRobustnessHealthy,
+				NodeID:      "node-1",
+				EngineImage: "longhorn-engine:v1",
+			},
+			"",
+		},
+		"sort ascending": {
+			"sort=size&order=asc",
+			manager.VolumeListOptions{SortBy: manager.VolumeSortBySize},
+			"",
+		},
+		"sort descending": {
+			"sort=created&order=desc",
+			manager.VolumeListOptions{SortBy: manager.VolumeSortByCreated, Descending: true},
+			"",
+		},
+		"pagination": {
+			"marker=20&limit=10",
+			manager.VolumeListOptions{Marker: 20, Limit: 10},
+			"",
+		},
+		"invalid order": {
+			"sort=size&order=up",
+			manager.VolumeListOptions{},
+			"invalid sort order up",
+		},
+		"invalid label selector": {
+			"labelSelector=app+in+(db",
+			manager.VolumeListOptions{},
+			"invalid label selector app in \\(db.*",
+		},
+		"non-numeric limit": {
+			"limit=ten",
+			manager.VolumeListOptions{},
+			"invalid limit ten",
+		},
+		"negative limit": {
+			"limit=-1",
+			manager.VolumeListOptions{},
+			"invalid limit -1",
+		},
+		"negative marker": {
+			"marker=-5",
+			manager.VolumeListOptions{},
+			"invalid marker -5",
+		},
+	}
+	for name, tc := range testCases {
+		query, err := url.ParseQuery(tc.query)
+		assert.Nil(err, name)
+		opts, err := parseVolumeListOptions(query)
+		if tc.expectErr != "" {
+			assert.NotNil(err, name)
+			assert.Regexp("^"+tc.expectErr+"$", err.Error(), name)
+			continue
+		}
+		assert.Nil(err, name)
+		assert.Equal(tc.expect, *opts, name)
+	}
+
+	// the label selector is parsed, sorting is checked by the manager
+	query, err := url.ParseQuery("labelSelector=app=db,tier!=test&sort=replicas")
+	assert.Nil(err)
+	opts, err := parseVolumeListOptions(query)
+	assert.Nil(err)
+	assert.Equal("replicas", opts.SortBy)
+	assert.Equal("app=db,tier!=test", opts.LabelSelector.String())
+}
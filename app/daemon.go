@@ -15,6 +15,7 @@ import (
 	"github.com/rancher/longhorn-manager/controller"
 	"github.com/rancher/longhorn-manager/datastore"
 	"github.com/rancher/longhorn-manager/manager"
+	"github.com/rancher/longhorn-manager/metrics"
 	"github.com/rancher/longhorn-manager/types"
 	"github.com/rancher/longhorn-manager/util"
 
@@ -93,6 +94,10 @@ func startManager(c *cli.Context) error {
 		return err
 	}
 
+	if err := metrics.RegisterCollector(currentNodeID, ds); err != nil {
+		return err
+	}
+
 	m := manager.NewVolumeManager(currentNodeID, ds)
 
 	if err := initSettings(ds, m, engineImage); err != nil {
@@ -111,6 +116,12 @@ func startManager(c *cli.Context) error {
 		return err
 	}
 
+	rjs, err := NewRecurringJobScheduler(ds, currentNodeID)
+	if err != nil {
+		return err
+	}
+	go rjs.Run(done)
+
 	server := api.NewServer(m)
 	router := http.Handler(api.NewRouter(server))
 
@@ -152,12 +163,31 @@ func initSettings(ds *datastore.DataStore, m *manager.VolumeManager, engineImage
 		setting = &longhorn.Setting{}
 		setting.BackupTarget = ""
 		setting.DefaultEngineImage = engineImage
+		setting.ReplicaRebuildStallTimeout = types.DefaultReplicaRebuildStallTimeout
+		setting.NotificationEventReasons = types.DefaultNotificationEventReasons
+		setting.RecurringJobHistoryLimit = types.DefaultRecurringJobHistoryLimit
+		setting.RecurringJobFailureThreshold = types.DefaultRecurringJobFailureThreshold
+		setting.RecurringJobMode = types.DefaultRecurringJobMode
+		setting.RecurringJobConcurrentLimit = types.DefaultRecurringJobConcurrentLimit
 		if _, err := ds.CreateSetting(setting); err != nil {
 			return err
 		}
 	}
-	if setting.DefaultEngineImage != engineImage {
+	if setting.DefaultEngineImage != engineImage || setting.ReplicaRebuildStallTimeout <= 0 ||
+		setting.RecurringJobHistoryLimit <= 0 || setting.RecurringJobMode == "" {
 		setting.DefaultEngineImage = engineImage
+		if setting.ReplicaRebuildStallTimeout <= 0 {
+			setting.ReplicaRebuildStallTimeout = types.DefaultReplicaRebuildStallTimeout
+		}
+		// the settings added by the upgrade
+		if setting.RecurringJobHistoryLimit <= 0 {
+			setting.RecurringJobHistoryLimit = types.DefaultRecurringJobHistoryLimit
+			setting.RecurringJobFailureThreshold = types.DefaultRecurringJobFailureThreshold
+		}
+		if setting.RecurringJobMode == "" {
+			setting.RecurringJobMode = types.RecurringJobModeCronJob
+			setting.RecurringJobConcurrentLimit = types.DefaultRecurringJobConcurrentLimit
+		}
 		if _, err := ds.UpdateSetting(setting); err != nil {
 			return err
 		}
@@ -0,0 +1,318 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/rancher/longhorn-manager/controller"
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	lhclientset "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned"
+)
+
+const (
+	recurringJobSchedulerInterval = 10 * time.Second
+	// a run not started within the deadline after the scheduled time is
+	// missed, e.g. the previous run is still going on
+	recurringJobStartingDeadline = 10 * time.Minute
+)
+
+// RecurringJobScheduler runs the snapshot and backup jobs of the volumes owned
+// by the manager, when SettingsInfo.RecurringJobMode is
+// manager. A job doesn't start before its previous run of the volume
+// completed, and at most SettingsInfo.RecurringJobConcurrentLimit jobs run
+// at the same time in the manager.
+type RecurringJobScheduler struct {
+	namespace    string
+	controllerID string
+
+	ds         *datastore.DataStore
+	lhClient   lhclientset.Interface
+	kubeClient clientset.Interface
+
+	lock sync.Mutex
+	// keyed by the volume name and the job name
+	entries map[string]*scheduledJob
+	running int
+
+	// for unit test
+	nowHandler       func() time.Time
+	runHandler       func(e *scheduledJob) error
+	missedRunHandler func(volumeName, jobName string, scheduled time.Time)
+}
+
+type scheduledJob struct {
+	volumeName string
+	job        types.AppliedRecurringJob
+	schedule   *util.CronSchedule
+	next       time.Time
+	running    bool
+	// the job is no longer applied to the volume, the entry will be
+	// removed once the run completed
+	removed bool
+}
+
+// desiredJob is a job to be scheduled, lastRun is the start time of its last
+// run recorded in the volume status
+type desiredJob struct {
+	volumeName string
+	job        types.AppliedRecurringJob
+	lastRun    time.Time
+}
+
+func NewRecurringJobScheduler(ds *datastore.DataStore, controllerID string) (*RecurringJobScheduler, error) {
+	namespace := os.Getenv(types.EnvPodNamespace)
+	if namespace == "" {
+		return nil, fmt.Errorf("Cannot detect pod namespace, environment variable %v is missing", types.EnvPodNamespace)
+	}
+	lhClient, kubeClient, err := getInClusterClients()
+	if err != nil {
+		return nil, err
+	}
+	s := &RecurringJobScheduler{
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds:         ds,
+		lhClient:   lhClient,
+		kubeClient: kubeClient,
+
+		entries: map[string]*scheduledJob{},
+
+		nowHandler: time.Now,
+	}
+	s.runHandler = s.runJob
+	s.missedRunHandler = s.recordMissedRun
+	return s, nil
+}
+
+func (s *RecurringJobScheduler) Run(stopCh <-chan struct{}) {
+	logrus.Infof("Start Longhorn recurring job scheduler")
+	defer logrus.Infof("Shutting down Longhorn recurring job scheduler")
+
+	wait.Until(s.sync, recurringJobSchedulerInterval, stopCh)
+}
+
+func (s *RecurringJobScheduler) sync() {
+	setting, err := s.ds.GetSetting()
+	if err != nil {
+		logrus.Warnf("Recurring job scheduler failed to get settings: %v", err)
+		return
+	}
+	desired := map[string]*desiredJob{}
+	if setting.RecurringJobMode == types.RecurringJobModeManager {
+		if desired, err = s.listDesiredJobs(); err != nil {
+			logrus.Warnf("Recurring job scheduler failed to list the jobs: %v", err)
+			return
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.nowHandler()
+	s.updateEntries(desired, now)
+	s.schedule(setting.RecurringJobConcurrentLimit, now)
+}
+
+func (s *RecurringJobScheduler) listDesiredJobs() (map[string]*desiredJob, error) {
+	volumes, err := s.ds.ListVolumes()
+	if err != nil {
+		return nil, err
+	}
+	desired := map[string]*desiredJob{}
+	for _, v := range volumes {
+		if v.Spec.OwnerID != s.controllerID || v.DeletionTimestamp != nil {
+			continue
+		}
+		jobs, err := s.ds.ListVolumeAppliedRecurringJobs(v)
+		if err != nil {
+			return nil, err
+		}
+		for name, job := range jobs {
+			if !types.IsRecurringJobRunByManager(types.RecurringJobModeManager, job.Type) ||
+				!controller.IsRecurringJobRunnable(v, job.Type) {
+				continue
+			}
+			if job.Type == types.RecurringJobTypeBackup {
+				backupTarget, err := s.ds.GetBackupTargetSpec(job.BackupTarget)
+				if err != nil || backupTarget.URL == "" {
+					continue
+				}
+			}
+			d := &desiredJob{
+				volumeName: v.Name,
+				job:        *job,
+			}
+			if status := v.Status.RecurringJobStatus[name]; len(status.Runs) != 0 {
+				if lastRun, err := time.Parse(time.RFC3339, status.Runs[0].StartTime); err == nil {
+					d.lastRun = lastRun
+				}
+			}
+			desired[v.Name+"/"+name] = d
+		}
+	}
+	return desired, nil
+}
+
+// updateEntries must be called with the lock held
+func (s *RecurringJobScheduler) updateEntries(desired map[string]*desiredJob, now time.Time) {
+	for key, e := range s.entries {
+		if _, exists := desired[key]; exists {
+			continue
+		}
+		if e.running {
+			e.removed = true
+			continue
+		}
+		delete(s.entries, key)
+	}
+
+	for key, d := range desired {
+		e := s.entries[key]
+		if e != nil && reflect.DeepEqual(e.job, d.job) {
+			// e.g. the volume was attaching for the running job
+			e.removed = false
+			continue
+		}
+		schedule, err := util.ParseCronSchedule(d.job.Cron)
+		if err != nil {
+			logrus.Warnf("Cannot schedule job %v of volume %v: %v", d.job.Name, d.volumeName, err)
+		}
+		if e == nil {
+			e = &scheduledJob{
+				volumeName: d.volumeName,
+			}
+			s.entries[key] = e
+		}
+		e.job = d.job
+		e.schedule = schedule
+		e.removed = false
+		e.next = time.Time{}
+		if schedule != nil {
+			// catch up the run missed within the deadline, e.g.
+			// while the volume was owned by another manager
+			base := now.Add(-recurringJobStartingDeadline)
+			if d.lastRun.After(base) {
+				base = d.lastRun
+			}
+			e.next = schedule.Next(base)
+		}
+	}
+}
+
+// schedule starts the due jobs, the most overdue first. It must be called
+// with the lock held.
+func (s *RecurringJobScheduler) schedule(concurrentLimit int, now time.Time) {
+	if concurrentLimit <= 0 {
+		concurrentLimit = types.DefaultRecurringJobConcurrentLimit
+	}
+
+	due := []*scheduledJob{}
+	for _, e := range s.entries {
+		if e.removed || e.next.IsZero() || now.Before(e.next) {
+			continue
+		}
+		due = append(due, e)
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].next.Before(due[j].next)
+	})
+
+	for _, e := range due {
+		if now.Sub(e.next) > recurringJobStartingDeadline {
+			go s.missedRunHandler(e.volumeName, e.job.Name, e.next)
+			e.next = e.schedule.Next(now)
+			continue
+		}
+		if e.running || s.running >= concurrentLimit {
+			continue
+		}
+		e.running = true
+		s.running++
+		e.next = e.schedule.Next(now)
+		go s.run(e)
+	}
+}
+
+func (s *RecurringJobScheduler) run(e *scheduledJob) {
+	if err := s.runHandler(e); err != nil {
+		logrus.Warnf("Failed to run job %v of volume %v: %v", e.job.Name, e.volumeName, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e.running = false
+	s.running--
+}
+
+// runJob runs the job the same way as the CronJob pod does, see snapshot()
+func (s *RecurringJobScheduler) runJob(e *scheduledJob) error {
+	recurringJobName := ""
+	if e.job.FromRecurringJob {
+		recurringJobName = e.job.Name
+	}
+	return runSnapshotJob(s.namespace, s.lhClient, e.volumeName, e.job.Name, recurringJobName, func() (*Job, error) {
+		backupTarget := ""
+		var credential map[string]string
+		if e.job.Type == types.RecurringJobTypeBackup {
+			spec, err := s.ds.GetBackupTargetSpec(e.job.BackupTarget)
+			if err != nil {
+				return nil, err
+			}
+			backupTarget = spec.URL
+			if spec.CredentialSecret != "" {
+				if credential, err = s.ds.GetCredentialFromSecret(spec.CredentialSecret); err != nil {
+					return nil, errors.Wrapf(err, "cannot get backup target credential")
+				}
+			}
+		}
+
+		snapshotName := e.job.Name + "-" + util.RandomID()
+		labels := map[string]string{
+			controller.LabelRecurringJob: e.job.Name,
+		}
+		job, err := newJobWithClients(s.namespace, s.lhClient, s.kubeClient, e.volumeName,
+			snapshotName, backupTarget, labels, e.job.Retain, e.job.Retention)
+		if err != nil {
+			return nil, err
+		}
+		job.credential = credential
+		job.backupTargetName = e.job.BackupTarget
+		job.freeze = e.job.Freeze
+		job.hooks = e.job.Hooks
+		return job, nil
+	})
+}
+
+// recordMissedRun records the missed run as a failed one, so it counts for
+// the volume condition RecurringJobFailing
+func (s *RecurringJobScheduler) recordMissedRun(volumeName, jobName string, scheduled time.Time) {
+	v, err := s.ds.GetVolume(volumeName)
+	if err != nil || v == nil {
+		logrus.Warnf("Cannot find volume %v to record the missed run of job %v: %v", volumeName, jobName, err)
+		return
+	}
+	message := fmt.Sprintf("Missed job %v of volume %v scheduled at %v", jobName, volumeName, util.FormatTimeZ(scheduled))
+	job := &Job{
+		namespace:  s.namespace,
+		volumeName: volumeName,
+		volume:     v,
+		lhClient:   s.lhClient,
+		kubeClient: s.kubeClient,
+	}
+	job.recordEvent(v1.EventTypeWarning, types.EventReasonMissedRecurringJob, message)
+	job.recordRun(jobName, util.FormatTimeZ(scheduled), errors.New(message))
+}
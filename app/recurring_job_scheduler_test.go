@@ -0,0 +1,252 @@
+package app
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rancher/longhorn-manager/types"
+)
+
+// fakeRunner blocks the runs until they're released
+type fakeRunner struct {
+	lock    sync.Mutex
+	started chan string
+	release map[string]chan error
+	missed  chan string
+}
+
+func newFakeRunner() *fakeRunner {
+	return &fakeRunner{
+		started: make(chan string, 10),
+		release: map[string]chan error{},
+		missed:  make(chan string, 10),
+	}
+}
+
+func (r *fakeRunner) releaseChan(key string) chan error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.release[key] == nil {
+		r.release[key] = make(chan error)
+	}
+	return r.release[key]
+}
+
+func (r *fakeRunner) run(e *scheduledJob) error {
+	key := e.volumeName + "/" + e.job.Name
+	r.started <- key
+	return <-r.releaseChan(key)
+}
+
+func (r *fakeRunner) recordMissed(volumeName, jobName string, scheduled time.Time) {
+	r.missed <- volumeName + "/" + jobName + "@" + scheduled.Format(time.RFC3339)
+}
+
+func newTestRecurringJobScheduler(r *fakeRunner) *RecurringJobScheduler {
+	return &RecurringJobScheduler{
+		entries:          map[string]*scheduledJob{},
+		runHandler:       r.run,
+		missedRunHandler: r.recordMissed,
+	}
+}
+
+func newDesiredJob(volumeName, jobName, cron string, lastRun time.Time) *desiredJob {
+	return &desiredJob{
+		volumeName: volumeName,
+		job: types.AppliedRecurringJob{
+			RecurringJob: types.RecurringJob{
+				Name:   jobName,
+				Type:   types.RecurringJobTypeSnapshot,
+				Cron:   cron,
+				Retain: 1,
+			},
+		},
+		lastRun: lastRun,
+	}
+}
+
+func expectStarted(t *testing.T, r *fakeRunner, keys ...string) {
+	started := []string{}
+	for range keys {
+		select {
+		case key := <-r.started:
+			started = append(started, key)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %v to start, started %v", keys, started)
+		}
+	}
+	keys = append([]string{}, keys...)
+	sort.Strings(keys)
+	sort.Strings(started)
+	require.Equal(t, keys, started)
+	select {
+	case key := <-r.started:
+		t.Fatalf("unexpected run of %v", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// finish releases the run and waits until the scheduler noticed it
+func finish(t *testing.T, s *RecurringJobScheduler, r *fakeRunner, key string) {
+	r.releaseChan(key) <- nil
+	for i := 0; i < 100; i++ {
+		s.lock.Lock()
+		running := s.entries[key] != nil && s.entries[key].running
+		s.lock.Unlock()
+		if !running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for %v to finish", key)
+}
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func syncScheduler(s *RecurringJobScheduler, desired map[string]*desiredJob, limit int, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.updateEntries(desired, now)
+	s.schedule(limit, now)
+}
+
+func TestRecurringJobSchedulerRun(t *testing.T) {
+	assert := require.New(t)
+
+	r := newFakeRunner()
+	s := newTestRecurringJobScheduler(r)
+	desired := map[string]*desiredJob{
+		"vol-1/hourly": newDesiredJob("vol-1", "hourly", "0 * * * *", time.Time{}),
+	}
+
+	syncScheduler(s, desired, 5, at("2018-01-01T10:30:20Z"))
+	expectStarted(t, r)
+	assert.Equal(at("2018-01-01T11:00:00Z"), s.entries["vol-1/hourly"].next)
+
+	syncScheduler(s, desired, 5, at("2018-01-01T11:00:05Z"))
+	expectStarted(t, r, "vol-1/hourly")
+	assert.Equal(at("2018-01-01T12:00:00Z"), s.entries["vol-1/hourly"].next)
+
+	// the previous run is still going on
+	syncScheduler(s, desired, 5, at("2018-01-01T12:00:05Z"))
+	expectStarted(t, r)
+	syncScheduler(s, desired, 5, at("2018-01-01T12:10:05Z"))
+	expectStarted(t, r)
+	assert.Equal("vol-1/hourly@2018-01-01T12:00:00Z", <-r.missed)
+	assert.Equal(at("2018-01-01T13:00:00Z"), s.entries["vol-1/hourly"].next)
+
+	finish(t, s, r, "vol-1/hourly")
+	syncScheduler(s, desired, 5, at("2018-01-01T13:00:05Z"))
+	expectStarted(t, r, "vol-1/hourly")
+	finish(t, s, r, "vol-1/hourly")
+
+	// the schedule changed
+	desired["vol-1/hourly"] = newDesiredJob("vol-1", "hourly", "30 * * * *", at("2018-01-01T13:00:05Z"))
+	syncScheduler(s, desired, 5, at("2018-01-01T13:05:00Z"))
+	expectStarted(t, r)
+	assert.Equal(at("2018-01-01T13:30:00Z"), s.entries["vol-1/hourly"].next)
+}
+
+func TestRecurringJobSchedulerCatchUp(t *testing.T) {
+	assert := require.New(t)
+
+	r := newFakeRunner()
+	s := newTestRecurringJobScheduler(r)
+	desired := map[string]*desiredJob{
+		// missed the run at 10:00 within the deadline
+		"vol-1/hourly": newDesiredJob("vol-1", "hourly", "0 * * * *", at("2018-01-01T09:00:01Z")),
+		// missed the run at 09:00 beyond the deadline
+		"vol-2/daily": newDesiredJob("vol-2", "daily", "0 9 * * *", at("2017-12-31T09:00:01Z")),
+		// ran already
+		"vol-3/hourly": newDesiredJob("vol-3", "hourly", "0 * * * *", at("2018-01-01T10:00:01Z")),
+	}
+
+	syncScheduler(s, desired, 5, at("2018-01-01T10:05:00Z"))
+	expectStarted(t, r, "vol-1/hourly")
+	assert.Equal(at("2018-01-02T09:00:00Z"), s.entries["vol-2/daily"].next)
+	assert.Equal(at("2018-01-01T11:00:00Z"), s.entries["vol-3/hourly"].next)
+	select {
+	case missed := <-r.missed:
+		t.Fatalf("unexpected missed run %v", missed)
+	default:
+	}
+	finish(t, s, r, "vol-1/hourly")
+}
+
+func TestRecurringJobSchedulerConcurrentLimit(t *testing.T) {
+	assert := require.New(t)
+
+	r := newFakeRunner()
+	s := newTestRecurringJobScheduler(r)
+	desired := map[string]*desiredJob{
+		"vol-1/hourly": newDesiredJob("vol-1", "hourly", "0 * * * *", at("2018-01-01T10:00:01Z")),
+		"vol-2/hourly": newDesiredJob("vol-2", "hourly", "0 * * * *", at("2018-01-01T10:00:01Z")),
+		"vol-3/hourly": newDesiredJob("vol-3", "hourly", "0 * * * *", at("2018-01-01T10:00:01Z")),
+	}
+
+	syncScheduler(s, desired, 2, at("2018-01-01T11:00:05Z"))
+	<-r.started
+	<-r.started
+	expectStarted(t, r)
+	assert.Equal(2, s.running)
+
+	waiting := ""
+	for key, e := range s.entries {
+		if !e.running {
+			waiting = key
+		}
+	}
+	assert.NotEqual("", waiting)
+
+	// the waiting one starts once a slot is free
+	for key, e := range s.entries {
+		if e.running {
+			finish(t, s, r, key)
+			break
+		}
+	}
+	syncScheduler(s, desired, 2, at("2018-01-01T11:01:05Z"))
+	expectStarted(t, r, waiting)
+
+	// the removed job isn't scheduled again, and is dropped once done
+	delete(desired, waiting)
+	syncScheduler(s, desired, 2, at("2018-01-01T12:00:05Z"))
+	assert.True(s.entries[waiting].removed)
+	finish(t, s, r, waiting)
+	syncScheduler(s, desired, 2, at("2018-01-01T12:00:10Z"))
+	assert.Nil(s.entries[waiting])
+}
+
+func TestRecurringJobSchedulerVolumeAttaching(t *testing.T) {
+	assert := require.New(t)
+
+	r := newFakeRunner()
+	s := newTestRecurringJobScheduler(r)
+	desired := map[string]*desiredJob{
+		"vol-1/hourly": newDesiredJob("vol-1", "hourly", "0 * * * *", at("2018-01-01T10:00:01Z")),
+	}
+	syncScheduler(s, desired, 5, at("2018-01-01T11:00:05Z"))
+	expectStarted(t, r, "vol-1/hourly")
+
+	// the detached volume is attaching for the running job, then attached
+	syncScheduler(s, map[string]*desiredJob{}, 5, at("2018-01-01T11:00:15Z"))
+	assert.True(s.entries["vol-1/hourly"].removed)
+	syncScheduler(s, desired, 5, at("2018-01-01T11:00:25Z"))
+	assert.False(s.entries["vol-1/hourly"].removed)
+	finish(t, s, r, "vol-1/hourly")
+
+	// the run isn't repeated
+	syncScheduler(s, desired, 5, at("2018-01-01T11:00:35Z"))
+	expectStarted(t, r)
+	assert.Equal(at("2018-01-01T12:00:00Z"), s.entries["vol-1/hourly"].next)
+}
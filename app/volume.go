@@ -1,30 +1,69 @@
 package app
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
+	"github.com/rancher/longhorn-manager/datastore"
 	"github.com/rancher/longhorn-manager/engineapi"
 	"github.com/rancher/longhorn-manager/types"
 	"github.com/rancher/longhorn-manager/util"
 
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
 	lhclientset "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned"
 )
 
 const (
-	FlagSnapshotName = "snapshot-name"
-	FlagLabels       = "labels"
-	FlagRetain       = "retain"
-	FlagBackupTarget = "backuptarget"
+	FlagSnapshotName     = "snapshot-name"
+	FlagLabels           = "labels"
+	FlagRetain           = "retain"
+	FlagRetainHourly     = "retain-hourly"
+	FlagRetainDaily      = "retain-daily"
+	FlagRetainWeekly     = "retain-weekly"
+	FlagRetainMonthly    = "retain-monthly"
+	FlagBackupTarget     = "backuptarget"
+	FlagBackupTargetName = "backuptarget-name"
+	FlagRecurringJob     = "recurring-job"
+	FlagFreeze           = "freeze"
+
+	FlagFrom       = "from"
+	FlagFormat     = "format"
+	FlagDevice     = "device"
+	FlagScratchDir = "scratch-dir"
+
+	importProgressInterval = 3 * time.Second
+	importUpdateRetryCount = 5
+	importCommandTimeout   = 10 * time.Second
+
+	recurringJobSlotRetryInterval = 10 * time.Second
+	recurringJobSlotRetryCount    = 5
+
+	autoAttachPollInterval = 2 * time.Second
+	autoAttachTimeout      = 5 * time.Minute
+
+	backupOperationPollInterval = 5 * time.Second
 )
 
 func SnapshotCmd() cli.Command {
@@ -43,10 +82,38 @@ func SnapshotCmd() cli.Command {
 				Name:  FlagRetain,
 				Usage: "retain number of snapshots with the same label",
 			},
+			cli.IntFlag{
+				Name:  FlagRetainHourly,
+				Usage: "retain the newest snapshot or backup of each of the given number of recent hours",
+			},
+			cli.IntFlag{
+				Name:  FlagRetainDaily,
+				Usage: "retain the newest snapshot or backup of each of the given number of recent days",
+			},
+			cli.IntFlag{
+				Name:  FlagRetainWeekly,
+				Usage: "retain the newest snapshot or backup of each of the given number of recent weeks",
+			},
+			cli.IntFlag{
+				Name:  FlagRetainMonthly,
+				Usage: "retain the newest snapshot or backup of each of the given number of recent months",
+			},
 			cli.StringFlag{
 				Name:  FlagBackupTarget,
 				Usage: "backup to destination if supplied, would be url like s3://bucket@region/path/ or vfs:///path/",
 			},
+			cli.StringFlag{
+				Name:  FlagBackupTargetName,
+				Usage: "the BackupTarget of the backup destination, the default target if not supplied",
+			},
+			cli.StringFlag{
+				Name:  FlagRecurringJob,
+				Usage: "the recurring job limiting the number of volumes running it at the same time",
+			},
+			cli.BoolFlag{
+				Name:  FlagFreeze,
+				Usage: "freeze the filesystem on the volume while taking the snapshot",
+			},
 		},
 		Action: func(c *cli.Context) {
 			if err := snapshot(c); err != nil {
@@ -56,6 +123,56 @@ func SnapshotCmd() cli.Command {
 	}
 }
 
+func TrimCmd() cli.Command {
+	return cli.Command{
+		Name:  "trim",
+		Usage: "trim the filesystem on the volume and reclaim the space in the volume head",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FlagRecurringJob,
+				Usage: "the recurring job limiting the number of volumes running it at the same time",
+			},
+		},
+		Action: func(c *cli.Context) {
+			if err := trim(c); err != nil {
+				logrus.Fatalf("Error trimming volume: %v", err)
+			}
+		},
+	}
+}
+
+func ImportCmd() cli.Command {
+	return cli.Command{
+		Name:  "import",
+		Usage: "fill the volume block device with a raw or qcow2 image",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FlagFrom,
+				Usage: "image location, would be a local file path or a http(s) URL",
+			},
+			cli.StringFlag{
+				Name:  FlagFormat,
+				Usage: "image format, would be raw or qcow2",
+				Value: string(types.VolumeImageFormatRaw),
+			},
+			cli.StringFlag{
+				Name:  FlagDevice,
+				Usage: "block device of the attached volume",
+			},
+			cli.StringFlag{
+				Name:  FlagScratchDir,
+				Usage: "directory to store the downloaded qcow2 image before conversion",
+				Value: os.TempDir(),
+			},
+		},
+		Action: func(c *cli.Context) {
+			if err := importImage(c); err != nil {
+				logrus.Fatalf("Error importing image: %v", err)
+			}
+		},
+	}
+}
+
 func snapshot(c *cli.Context) error {
 	var err error
 	if c.NArg() == 0 {
@@ -63,6 +180,15 @@ func snapshot(c *cli.Context) error {
 	}
 	volume := c.Args()[0]
 	retain := c.Int(FlagRetain)
+	retention := &types.RetentionPolicy{
+		Hourly:  c.Int(FlagRetainHourly),
+		Daily:   c.Int(FlagRetainDaily),
+		Weekly:  c.Int(FlagRetainWeekly),
+		Monthly: c.Int(FlagRetainMonthly),
+	}
+	if retention.IsEmpty() {
+		retention = nil
+	}
 
 	baseName := c.String(FlagSnapshotName)
 	if baseName == "" {
@@ -81,14 +207,47 @@ func snapshot(c *cli.Context) error {
 	}
 
 	backupTarget := c.String(FlagBackupTarget)
-	job, err := NewJob(volume, snapshotName, backupTarget, labelMap, retain)
+	freeze := c.Bool(FlagFreeze)
+	namespace := os.Getenv(types.EnvPodNamespace)
+	if namespace == "" {
+		return fmt.Errorf("Cannot detect pod namespace, environment variable %v is missing", types.EnvPodNamespace)
+	}
+	lhClient, kubeClient, err := getInClusterClients()
 	if err != nil {
 		return err
 	}
-	if backupTarget != "" {
-		return job.backupAndCleanup()
+	recurringJob := c.String(FlagRecurringJob)
+	return runSnapshotJob(namespace, lhClient, volume, baseName, recurringJob, func() (*Job, error) {
+		job, err := newJobWithClients(namespace, lhClient, kubeClient, volume, snapshotName, backupTarget, labelMap, retain, retention)
+		if err != nil {
+			return nil, err
+		}
+		job.freeze = freeze
+		job.backupTargetName = c.String(FlagBackupTargetName)
+		if job.hooks, err = job.getRecurringJobHooks(baseName, recurringJob); err != nil {
+			return nil, err
+		}
+		return job, nil
+	})
+}
+
+func trim(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return errors.New("volume name is required")
 	}
-	return job.snapshotAndCleanup()
+	volume := c.Args()[0]
+
+	job, err := NewJob(volume, "", "", nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	if recurringJob := c.String(FlagRecurringJob); recurringJob != "" {
+		if err := job.acquireRecurringJobSlot(recurringJob); err != nil {
+			return err
+		}
+		defer job.releaseRecurringJobSlot(recurringJob)
+	}
+	return job.trim()
 }
 
 type Job struct {
@@ -96,28 +255,59 @@ type Job struct {
 	volumeName   string
 	snapshotName string
 	backupTarget string
-	retain       int
-	labels       map[string]string
+	// backupTargetName is the BackupTarget of backupTarget, the backup is
+	// run by the manager owning the volume
+	backupTargetName string
+	credential       map[string]string
+	freeze           bool
+	// hooks of the recurring job, override the ones of the volume
+	hooks     *types.SnapshotHooks
+	retain    int
+	retention *types.RetentionPolicy
+	labels    map[string]string
 
+	// the snapshot and backup created by the run
+	createdSnapshot string
+	createdBackup   string
+
+	volume      *longhorn.Volume
 	engine      engineapi.EngineClient
 	engineImage string
+	kubeClient  clientset.Interface
+	lhClient    lhclientset.Interface
 }
 
-func NewJob(volumeName, snapshotName, backupTarget string, labels map[string]string, retain int) (*Job, error) {
+func NewJob(volumeName, snapshotName, backupTarget string, labels map[string]string, retain int, retention *types.RetentionPolicy) (*Job, error) {
 	namespace := os.Getenv(types.EnvPodNamespace)
 	if namespace == "" {
 		return nil, fmt.Errorf("Cannot detect pod namespace, environment variable %v is missing", types.EnvPodNamespace)
 	}
 
+	lhClient, kubeClient, err := getInClusterClients()
+	if err != nil {
+		return nil, err
+	}
+	return newJobWithClients(namespace, lhClient, kubeClient, volumeName, snapshotName, backupTarget, labels, retain, retention)
+}
+
+func getInClusterClients() (lhclientset.Interface, clientset.Interface, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to get client config")
+		return nil, nil, errors.Wrap(err, "unable to get client config")
 	}
 	lhClient, err := lhclientset.NewForConfig(config)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to get clientset")
+		return nil, nil, errors.Wrap(err, "unable to get clientset")
 	}
+	kubeClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to get k8s client")
+	}
+	return lhClient, kubeClient, nil
+}
 
+func newJobWithClients(namespace string, lhClient lhclientset.Interface, kubeClient clientset.Interface,
+	volumeName, snapshotName, backupTarget string, labels map[string]string, retain int, retention *types.RetentionPolicy) (*Job, error) {
 	v, err := lhClient.LonghornV1alpha1().Volumes(namespace).Get(volumeName, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -152,16 +342,342 @@ func NewJob(volumeName, snapshotName, backupTarget string, labels map[string]str
 		backupTarget: backupTarget,
 		labels:       labels,
 		retain:       retain,
+		retention:    retention,
+		volume:       v,
 		engine:       engineClient,
 		engineImage:  engineImage,
+		kubeClient:   kubeClient,
+		lhClient:     lhClient,
 	}, nil
 }
 
+// acquireRecurringJobSlot waits until the number of volumes running the
+// recurring job is below its concurrency, then records the volume as running
+func (job *Job) acquireRecurringJobSlot(name string) error {
+	deadline := time.Now().Add(types.RecurringJobRunTimeout)
+	for {
+		rj, err := job.lhClient.LonghornV1alpha1().RecurringJobs(job.namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "cannot get recurring job %v", name)
+		}
+		if rj.Spec.Concurrency <= 0 {
+			return nil
+		}
+
+		running := 0
+		for volumeName, startedAt := range rj.Status.Running {
+			// the cron job won't start two runs for the same volume, a
+			// record left for the volume is stale
+			if volumeName == job.volumeName || util.TimestampAfterTimeout(startedAt, types.RecurringJobRunTimeout) {
+				continue
+			}
+			running++
+		}
+		if running < rj.Spec.Concurrency {
+			if rj.Status.Running == nil {
+				rj.Status.Running = map[string]string{}
+			}
+			rj.Status.Running[job.volumeName] = util.Now()
+			_, err := job.lhClient.LonghornV1alpha1().RecurringJobs(job.namespace).Update(rj)
+			if err == nil {
+				return nil
+			}
+			if !apierrors.IsConflict(err) {
+				return errors.Wrapf(err, "cannot update recurring job %v", name)
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for recurring job %v to run on volume %v, %v volumes are running it",
+				name, job.volumeName, running)
+		}
+		logrus.Debugf("Waiting for recurring job %v to run on volume %v, %v volumes are running it", name, job.volumeName, running)
+		time.Sleep(recurringJobSlotRetryInterval)
+	}
+}
+
+func (job *Job) releaseRecurringJobSlot(name string) {
+	for i := 0; i < recurringJobSlotRetryCount; i++ {
+		rj, err := job.lhClient.LonghornV1alpha1().RecurringJobs(job.namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return
+			}
+			logrus.Warnf("Failed to get recurring job %v: %v", name, err)
+			continue
+		}
+		if _, exists := rj.Status.Running[job.volumeName]; !exists {
+			return
+		}
+		delete(rj.Status.Running, job.volumeName)
+		if _, err := job.lhClient.LonghornV1alpha1().RecurringJobs(job.namespace).Update(rj); err != nil {
+			logrus.Warnf("Failed to release recurring job %v for volume %v: %v", name, job.volumeName, err)
+			continue
+		}
+		return
+	}
+	// the controller will release it after types.RecurringJobRunTimeout
+	logrus.Errorf("Cannot release recurring job %v for volume %v", name, job.volumeName)
+}
+
+func (job *Job) run(recurringJob string) error {
+	if recurringJob != "" {
+		if err := job.acquireRecurringJobSlot(recurringJob); err != nil {
+			return err
+		}
+		defer job.releaseRecurringJobSlot(recurringJob)
+	}
+	if job.backupTarget != "" {
+		return job.backupAndCleanup()
+	}
+	return job.snapshotAndCleanup()
+}
+
+// runSnapshotJob runs the snapshot or backup job created by newJob, and
+// records the run in the volume status. A detached volume is attached without
+// the frontend for the run, see autoAttachVolume.
+func runSnapshotJob(namespace string, lhClient lhclientset.Interface, volumeName, jobName, recurringJob string,
+	newJob func() (*Job, error)) (err error) {
+	startTime := util.Now()
+	job := &Job{
+		namespace:  namespace,
+		volumeName: volumeName,
+		lhClient:   lhClient,
+	}
+	defer func() {
+		job.recordRun(jobName, startTime, err)
+	}()
+
+	attached, err := autoAttachVolume(namespace, lhClient, volumeName, jobName)
+	if attached {
+		defer autoDetachVolume(namespace, lhClient, volumeName, jobName)
+	}
+	if err != nil {
+		return err
+	}
+	createdJob, err := newJob()
+	if err != nil {
+		return err
+	}
+	job = createdJob
+	return job.run(recurringJob)
+}
+
+// autoAttachVolume attaches the detached volume without the frontend to the
+// node of its owner for the job, and waits until it's attached. It returns
+// false if the volume is attached by a workload. The jobs running at the
+// same time share the attachment.
+func autoAttachVolume(namespace string, lhClient lhclientset.Interface, volumeName, jobName string) (bool, error) {
+	deadline := time.Now().Add(autoAttachTimeout)
+	for {
+		v, err := lhClient.LonghornV1alpha1().Volumes(namespace).Get(volumeName, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot get volume %v", volumeName)
+		}
+		if _, ok := v.Spec.AutoAttachedForJobs[jobName]; ok {
+			break
+		}
+		if len(v.Spec.AutoAttachedForJobs) == 0 {
+			if v.Spec.NodeID != "" {
+				return false, nil
+			}
+			if v.Spec.DisableAutoAttach {
+				return false, fmt.Errorf("volume %v is detached and auto attach is disabled", volumeName)
+			}
+			if v.Status.RestoreRequired || v.Spec.RecycledAt != "" || v.Spec.OwnerID == "" {
+				return false, fmt.Errorf("volume %v cannot be attached for job %v", volumeName, jobName)
+			}
+			// wait for the volume to finish detaching
+			if v.Status.State != types.VolumeStateDetached {
+				if time.Now().After(deadline) {
+					return false, fmt.Errorf("timeout waiting for volume %v to be detached, state %v", volumeName, v.Status.State)
+				}
+				time.Sleep(autoAttachPollInterval)
+				continue
+			}
+			v.Spec.NodeID = v.Spec.OwnerID
+			v.Spec.DisableFrontend = true
+		}
+		if v.Spec.AutoAttachedForJobs == nil {
+			v.Spec.AutoAttachedForJobs = map[string]string{}
+		}
+		v.Spec.AutoAttachedForJobs[jobName] = util.Now()
+		if _, err := lhClient.LonghornV1alpha1().Volumes(namespace).Update(v); err != nil {
+			if apierrors.IsConflict(err) {
+				continue
+			}
+			return false, errors.Wrapf(err, "cannot attach volume %v for job %v", volumeName, jobName)
+		}
+		logrus.Infof("Attached volume %v to %v without frontend for job %v", volumeName, v.Spec.NodeID, jobName)
+		break
+	}
+
+	for {
+		v, err := lhClient.LonghornV1alpha1().Volumes(namespace).Get(volumeName, metav1.GetOptions{})
+		if err != nil {
+			return true, errors.Wrapf(err, "cannot get volume %v", volumeName)
+		}
+		// the workload may have taken it over in the meantime
+		if v.Status.State == types.VolumeStateAttached && v.Spec.NodeID != "" {
+			return true, nil
+		}
+		if v.Spec.NodeID == "" {
+			return true, fmt.Errorf("volume %v was detached while attaching it for job %v", volumeName, jobName)
+		}
+		if time.Now().After(deadline) {
+			return true, fmt.Errorf("timeout waiting for volume %v to be attached for job %v, state %v", volumeName, jobName, v.Status.State)
+		}
+		time.Sleep(autoAttachPollInterval)
+	}
+}
+
+// autoDetachVolume releases the volume attached by autoAttachVolume, and
+// detaches it if no other jobs are using it. The volume taken over by a
+// workload is left attached.
+func autoDetachVolume(namespace string, lhClient lhclientset.Interface, volumeName, jobName string) {
+	for i := 0; i < recurringJobSlotRetryCount; i++ {
+		v, err := lhClient.LonghornV1alpha1().Volumes(namespace).Get(volumeName, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return
+			}
+			logrus.Warnf("Failed to get volume %v: %v", volumeName, err)
+			continue
+		}
+		if _, ok := v.Spec.AutoAttachedForJobs[jobName]; !ok {
+			return
+		}
+		delete(v.Spec.AutoAttachedForJobs, jobName)
+		jobs := v.Spec.AutoAttachedForJobs
+		if len(jobs) == 0 {
+			v.Spec.AutoAttachedForJobs = nil
+			v.Spec.NodeID = ""
+			v.Spec.DisableFrontend = false
+		}
+		if _, err := lhClient.LonghornV1alpha1().Volumes(namespace).Update(v); err != nil {
+			logrus.Warnf("Failed to detach volume %v for job %v: %v", volumeName, jobName, err)
+			continue
+		}
+		if len(jobs) == 0 {
+			logrus.Infof("Detached volume %v attached for job %v", volumeName, jobName)
+		}
+		return
+	}
+	logrus.Errorf("Cannot detach volume %v attached for job %v", volumeName, jobName)
+}
+
+// recordRun adds the run to the history of the job in the volume status and
+// counts the consecutive failures. The history is trimmed to
+// SettingsInfo.RecurringJobHistoryLimit.
+func (job *Job) recordRun(name, startTime string, runErr error) {
+	run := types.RecurringJobRun{
+		StartTime: startTime,
+		EndTime:   util.Now(),
+		Result:    types.RecurringJobRunResultSucceeded,
+		Snapshot:  job.createdSnapshot,
+		Backup:    job.createdBackup,
+	}
+	if runErr != nil {
+		run.Result = types.RecurringJobRunResultFailed
+		run.Error = runErr.Error()
+	}
+
+	historyLimit := types.DefaultRecurringJobHistoryLimit
+	setting, err := job.lhClient.LonghornV1alpha1().Settings(job.namespace).Get(datastore.SettingName, metav1.GetOptions{})
+	if err != nil {
+		logrus.Warnf("Failed to get settings, keep %v runs of recurring job %v: %v", historyLimit, name, err)
+	} else if setting.RecurringJobHistoryLimit > 0 {
+		historyLimit = setting.RecurringJobHistoryLimit
+	}
+
+	for i := 0; i < recurringJobSlotRetryCount; i++ {
+		v, err := job.lhClient.LonghornV1alpha1().Volumes(job.namespace).Get(job.volumeName, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return
+			}
+			logrus.Warnf("Failed to get volume %v: %v", job.volumeName, err)
+			continue
+		}
+		if v.Status.RecurringJobStatus == nil {
+			v.Status.RecurringJobStatus = map[string]types.RecurringJobRunStatus{}
+		}
+		status := v.Status.RecurringJobStatus[name]
+		status.Runs = append([]types.RecurringJobRun{run}, status.Runs...)
+		if len(status.Runs) > historyLimit {
+			status.Runs = status.Runs[:historyLimit]
+		}
+		if run.Result == types.RecurringJobRunResultFailed {
+			status.ConsecutiveFailures++
+		} else {
+			status.ConsecutiveFailures = 0
+		}
+		v.Status.RecurringJobStatus[name] = status
+		if _, err := job.lhClient.LonghornV1alpha1().Volumes(job.namespace).Update(v); err != nil {
+			logrus.Warnf("Failed to record run of recurring job %v for volume %v: %v", name, job.volumeName, err)
+			continue
+		}
+		return
+	}
+	logrus.Errorf("Cannot record run of recurring job %v for volume %v", name, job.volumeName)
+}
+
+// getRecurringJobHooks returns the hooks of the job applied to the volume,
+// either the RecurringJob named recurringJob or the job of the volume named
+// jobName
+func (job *Job) getRecurringJobHooks(jobName, recurringJob string) (*types.SnapshotHooks, error) {
+	if recurringJob != "" {
+		rj, err := job.lhClient.LonghornV1alpha1().RecurringJobs(job.namespace).Get(recurringJob, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get recurring job %v", recurringJob)
+		}
+		return rj.Spec.Hooks, nil
+	}
+	for _, j := range job.volume.Spec.RecurringJobs {
+		if j.Name == jobName {
+			return j.Hooks, nil
+		}
+	}
+	return nil, nil
+}
+
 func (job *Job) snapshotAndCleanup() error {
 	engine := job.engine
-	if _, err := engine.SnapshotCreate(job.snapshotName, job.labels); err != nil {
+	create := func() error {
+		if job.freeze {
+			device := ""
+			if job.volume.Spec.Frontend == types.VolumeFrontendBlockDev && !job.volume.Spec.DisableFrontend {
+				device = job.volume.Status.Endpoint
+			}
+			_, err := engineapi.SnapshotCreateWithFreeze(engine, device, job.snapshotName, job.labels)
+			return err
+		}
+		_, err := engine.SnapshotCreate(job.snapshotName, job.labels)
 		return err
 	}
+	hooks := job.hooks
+	if hooks == nil {
+		hooks = job.volume.Spec.SnapshotHooks
+	}
+	if hooks.IsEmpty() {
+		if err := create(); err != nil {
+			return err
+		}
+	} else {
+		hookRunner, err := engineapi.NewSnapshotHookRunner(job.volumeName)
+		if err != nil {
+			return err
+		}
+		failures, err := hookRunner.Run(hooks, create)
+		if len(failures) != 0 {
+			job.recordEvent(v1.EventTypeWarning, types.EventReasonFailedSnapshotHook, strings.Join(failures, "; "))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	job.createdSnapshot = job.snapshotName
 	snapshots, err := job.engine.SnapshotList()
 	if err != nil {
 		return err
@@ -177,10 +693,34 @@ func (job *Job) snapshotAndCleanup() error {
 		if err := engine.SnapshotPurge(); err != nil {
 			return err
 		}
+		job.deleteSnapshotExports(cleanupSnapshotNames)
 	}
 	return nil
 }
 
+// deleteSnapshotExports deletes the exports of the cleaned up snapshots, the
+// exported images are removed by the managers keeping them
+func (job *Job) deleteSnapshotExports(snapshotNames []string) {
+	for _, snapshotName := range snapshotNames {
+		name := types.GetSnapshotExportName(job.volumeName, snapshotName)
+		err := job.lhClient.LonghornV1alpha1().SnapshotExports(job.namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			logrus.Warnf("Failed to delete snapshot export %v of %v: %v", name, job.volumeName, err)
+		}
+	}
+}
+
+func (job *Job) trim() error {
+	return engineapi.TrimVolume(job.engine, job.volume, job.recordEvent)
+}
+
+func (job *Job) recordEvent(eventType, reason, message string) {
+	event := engineapi.NewVolumeEvent(job.volume, eventType, reason, message, "longhorn-recurring-job")
+	if _, err := job.kubeClient.CoreV1().Events(job.namespace).Create(event); err != nil {
+		logrus.Warnf("Failed to record event %v for volume %v: %v", reason, job.volume.Name, err)
+	}
+}
+
 type NameWithTimestamp struct {
 	Name      string
 	Timestamp time.Time
@@ -220,20 +760,57 @@ func (job *Job) listSnapshotNamesForCleanup(snapshots map[string]*engineapi.Snap
 			})
 		}
 	}
-	return job.getCleanupList(sts)
+	return getCleanupList(sts, retain, job.retention)
 }
 
-func (job *Job) getCleanupList(sts []*NameWithTimestamp) []string {
+// getCleanupList returns the names not retained, from the oldest to the
+// newest. The newest retain ones are kept, plus the ones kept by retention.
+func getCleanupList(sts []*NameWithTimestamp, retain int, retention *types.RetentionPolicy) []string {
+	// newest first, don't reorder the caller's slice
+	sts = append([]*NameWithTimestamp{}, sts...)
 	sort.Slice(sts, func(i, j int) bool {
-		if sts[i].Timestamp.Before(sts[j].Timestamp) {
-			return true
-		}
-		return false
+		return sts[i].Timestamp.After(sts[j].Timestamp)
 	})
 
+	kept := map[string]bool{}
+	for i := 0; i < retain && i < len(sts); i++ {
+		kept[sts[i].Name] = true
+	}
+	if !retention.IsEmpty() {
+		tiers := []struct {
+			count  int
+			period func(t time.Time) string
+		}{
+			{retention.Hourly, func(t time.Time) string { return t.Format("2006-01-02T15") }},
+			{retention.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+			{retention.Weekly, func(t time.Time) string {
+				year, week := t.ISOWeek()
+				return fmt.Sprintf("%d-W%02d", year, week)
+			}},
+			{retention.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		}
+		for _, tier := range tiers {
+			// the first one seen of a period is the newest of it
+			periods := map[string]struct{}{}
+			for _, st := range sts {
+				if len(periods) >= tier.count {
+					break
+				}
+				period := tier.period(st.Timestamp.UTC())
+				if _, exists := periods[period]; exists {
+					continue
+				}
+				periods[period] = struct{}{}
+				kept[st.Name] = true
+			}
+		}
+	}
+
 	ret := []string{}
-	for i := 0; i < len(sts)-job.retain; i++ {
-		ret = append(ret, sts[i].Name)
+	for i := len(sts) - 1; i >= 0; i-- {
+		if !kept[sts[i].Name] {
+			ret = append(ret, sts[i].Name)
+		}
 	}
 	return ret
 }
@@ -242,11 +819,15 @@ func (job *Job) backupAndCleanup() error {
 	if err := job.snapshotAndCleanup(); err != nil {
 		return err
 	}
-	// CronJob template has covered the credential already, so we don't need to get the credential secret.
-	if err := job.engine.SnapshotBackup(job.snapshotName, job.backupTarget, job.labels, nil); err != nil {
+	backupURL, err := job.backup()
+	if err != nil {
 		return err
 	}
-	target := engineapi.NewBackupTarget(job.backupTarget, job.engineImage, nil)
+	job.createdBackup = backupURL
+
+	// CronJob template has covered the credential already, job.credential
+	// is only set when the job runs in the manager
+	target := engineapi.NewBackupTarget(job.backupTarget, job.engineImage, job.credential)
 	backups, err := target.List(job.volumeName)
 	if err != nil {
 		return err
@@ -261,6 +842,56 @@ func (job *Job) backupAndCleanup() error {
 	return nil
 }
 
+// backup requests the manager owning the volume to back up the snapshot,
+// and waits for the backup to finish. The failure is recorded as the volume
+// event by the manager.
+func (job *Job) backup() (string, error) {
+	backupTargetName := job.backupTargetName
+	if backupTargetName == "" {
+		backupTargetName = types.DefaultBackupTargetName
+	}
+	bo, err := job.lhClient.LonghornV1alpha1().BackupOperations(job.namespace).Create(&longhorn.BackupOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            types.GetBackupOperationName(job.volumeName),
+			OwnerReferences: datastore.GetOwnerReferencesForVolume(job.volume),
+		},
+		Spec: types.BackupOperationSpec{
+			VolumeName:   job.volumeName,
+			SnapshotName: job.snapshotName,
+			BackupTarget: backupTargetName,
+			Labels:       job.labels,
+		},
+		Status: types.BackupOperationStatus{
+			State: types.BackupOperationStatePending,
+		},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot create backup operation for snapshot %v of volume %v", job.snapshotName, job.volumeName)
+	}
+	logrus.Debugf("Created backup operation %v for snapshot %v of volume %v", bo.Name, job.snapshotName, job.volumeName)
+
+	name := bo.Name
+	deadline := time.Now().Add(types.BackupOperationWaitTimeout)
+	for {
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("timed out waiting for backup operation %v", name)
+		}
+		time.Sleep(backupOperationPollInterval)
+		bo, err = job.lhClient.LonghornV1alpha1().BackupOperations(job.namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot get backup operation %v", name)
+		}
+		switch bo.Status.State {
+		case types.BackupOperationStateCompleted:
+			return bo.Status.BackupURL, nil
+		case types.BackupOperationStateError:
+			return "", fmt.Errorf("backup operation %v failed: %v", name, bo.Status.Error)
+		case types.BackupOperationStateCancelled:
+			return "", fmt.Errorf("backup operation %v was cancelled", name)
+		}
+	}
+}
+
 func (job *Job) listBackupURLsForCleanup(backups []*engineapi.Backup) []string {
 	sts := []*NameWithTimestamp{}
 
@@ -295,5 +926,287 @@ func (job *Job) listBackupURLsForCleanup(backups []*engineapi.Backup) []string {
 			})
 		}
 	}
-	return job.getCleanupList(sts)
+	return getCleanupList(sts, job.retain, job.retention)
+}
+
+func importImage(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return errors.New("volume name is required")
+	}
+	volume := c.Args()[0]
+	from := c.String(FlagFrom)
+	if from == "" {
+		return fmt.Errorf("Missing required parameter --" + FlagFrom)
+	}
+	device := c.String(FlagDevice)
+	if device == "" {
+		return fmt.Errorf("Missing required parameter --" + FlagDevice)
+	}
+	format := types.VolumeImageFormat(c.String(FlagFormat))
+	if format != types.VolumeImageFormatRaw && format != types.VolumeImageFormatQcow2 {
+		return fmt.Errorf("invalid image format %v", format)
+	}
+
+	importer, err := NewImporter(volume, from, format, device, c.String(FlagScratchDir))
+	if err != nil {
+		return err
+	}
+	if err := importer.Run(); err != nil {
+		if uerr := importer.updateStatus(importer.lastProgress, err.Error()); uerr != nil {
+			logrus.Warnf("Cannot update import error of volume %v: %v", volume, uerr)
+		}
+		return err
+	}
+	return importer.updateStatus(100, "")
+}
+
+type Importer struct {
+	namespace  string
+	volumeName string
+	volumeSize int64
+	from       string
+	format     types.VolumeImageFormat
+	device     string
+	scratchDir string
+
+	lhClient     lhclientset.Interface
+	lastProgress int
+	lastUpdate   time.Time
+}
+
+func NewImporter(volumeName, from string, format types.VolumeImageFormat, device, scratchDir string) (*Importer, error) {
+	namespace := os.Getenv(types.EnvPodNamespace)
+	if namespace == "" {
+		return nil, fmt.Errorf("Cannot detect pod namespace, environment variable %v is missing", types.EnvPodNamespace)
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get client config")
+	}
+	lhClient, err := lhclientset.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get clientset")
+	}
+
+	v, err := lhClient.LonghornV1alpha1().Volumes(namespace).Get(volumeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &Importer{
+		namespace:  namespace,
+		volumeName: volumeName,
+		volumeSize: v.Spec.Size,
+		from:       from,
+		format:     format,
+		device:     device,
+		scratchDir: scratchDir,
+		lhClient:   lhClient,
+	}, nil
+}
+
+func (imp *Importer) Run() error {
+	if err := util.WaitForDevice(imp.device, 30); err != nil {
+		return err
+	}
+	logrus.Infof("Importing %v image %v to volume %v", imp.format, imp.from, imp.volumeName)
+	if imp.format == types.VolumeImageFormatRaw {
+		return imp.copyToFile(imp.device, 0, 100, true)
+	}
+
+	source := imp.from
+	if !filepath.IsAbs(source) {
+		// qemu-img can only read local file, download it first
+		source = filepath.Join(imp.scratchDir, imp.volumeName+".qcow2")
+		defer os.Remove(source)
+		if err := imp.copyToFile(source, 0, 50, false); err != nil {
+			return err
+		}
+		return imp.convertQcow2(source, 50, 100)
+	}
+	return imp.convertQcow2(source, 0, 100)
+}
+
+// copyToFile copies the source to the destination, report progress between
+// `start` and `end`
+func (imp *Importer) copyToFile(dst string, start, end int, checkSize bool) error {
+	src, size, err := imp.openSource()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if checkSize && size > imp.volumeSize {
+		return fmt.Errorf("image size %v is larger than volume size %v", size, imp.volumeSize)
+	}
+
+	flags := os.O_WRONLY
+	if !checkSize {
+		flags |= os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(dst, flags, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "cannot open %v", dst)
+	}
+	defer f.Close()
+
+	reader := &progressReader{
+		reader: src,
+		callback: func(copied int64) {
+			if size <= 0 {
+				return
+			}
+			imp.reportProgress(start + int(copied*int64(end-start)/size))
+		},
+	}
+	if checkSize {
+		// the size of image from http may be unknown
+		if _, err := io.Copy(f, io.LimitReader(reader, imp.volumeSize+1)); err != nil {
+			return errors.Wrapf(err, "cannot copy image to %v", dst)
+		}
+		if reader.copied > imp.volumeSize {
+			return fmt.Errorf("image size is larger than volume size %v", imp.volumeSize)
+		}
+	} else if _, err := io.Copy(f, reader); err != nil {
+		return errors.Wrapf(err, "cannot copy image to %v", dst)
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Wrapf(err, "cannot sync %v", dst)
+	}
+	imp.reportProgress(end)
+	return nil
+}
+
+func (imp *Importer) openSource() (io.ReadCloser, int64, error) {
+	if filepath.IsAbs(imp.from) {
+		f, err := os.Open(imp.from)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "cannot open image file %v", imp.from)
+		}
+		st, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, errors.Wrapf(err, "cannot stat image file %v", imp.from)
+		}
+		return f, st.Size(), nil
+	}
+	resp, err := http.Get(imp.from)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "cannot download image %v", imp.from)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("cannot download image %v: %v", imp.from, resp.Status)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+type qemuImageInfo struct {
+	VirtualSize int64  `json:"virtual-size"`
+	Format      string `json:"format"`
+}
+
+var qemuProgressRegexp = regexp.MustCompile(`\(([0-9.]+)/100%\)`)
+
+func (imp *Importer) convertQcow2(source string, start, end int) error {
+	output, err := util.ExecuteWithTimeout(importCommandTimeout, "qemu-img", "info", "--output=json", source)
+	if err != nil {
+		return err
+	}
+	info := &qemuImageInfo{}
+	if err := json.Unmarshal([]byte(output), info); err != nil {
+		return errors.Wrapf(err, "cannot parse image info of %v", source)
+	}
+	if info.Format != string(types.VolumeImageFormatQcow2) {
+		return fmt.Errorf("image %v is in format %v rather than %v", imp.from, info.Format, types.VolumeImageFormatQcow2)
+	}
+	if info.VirtualSize > imp.volumeSize {
+		return fmt.Errorf("image virtual size %v is larger than volume size %v", info.VirtualSize, imp.volumeSize)
+	}
+
+	cmd := exec.Command("qemu-img", "convert", "-p", "-n", "-f", "qcow2", "-O", "raw", source, imp.device)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "cannot start qemu-img")
+	}
+	// qemu-img refreshes the progress with carriage return
+	scanner := bufio.NewScanner(out)
+	scanner.Split(scanProgress)
+	for scanner.Scan() {
+		m := qemuProgressRegexp.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		percent, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		imp.reportProgress(start + int(percent)*(end-start)/100)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to convert image %v: %v, %v", imp.from, err, stderr.String())
+	}
+	imp.reportProgress(end)
+	return nil
+}
+
+func scanProgress(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[0:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+type progressReader struct {
+	reader   io.Reader
+	copied   int64
+	callback func(copied int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.copied += int64(n)
+	r.callback(r.copied)
+	return n, err
+}
+
+func (imp *Importer) reportProgress(progress int) {
+	if progress == imp.lastProgress || time.Since(imp.lastUpdate) < importProgressInterval {
+		return
+	}
+	if err := imp.updateStatus(progress, ""); err != nil {
+		logrus.Warnf("Cannot update import progress of volume %v: %v", imp.volumeName, err)
+	}
+}
+
+func (imp *Importer) updateStatus(progress int, errMsg string) error {
+	var err error
+	for i := 0; i < importUpdateRetryCount; i++ {
+		var v *longhorn.Volume
+		v, err = imp.lhClient.LonghornV1alpha1().Volumes(imp.namespace).Get(imp.volumeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		v.Status.ImportProgress = progress
+		v.Status.ImportError = errMsg
+		if _, err = imp.lhClient.LonghornV1alpha1().Volumes(imp.namespace).Update(v); err == nil {
+			imp.lastProgress = progress
+			imp.lastUpdate = time.Now()
+			return nil
+		}
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+	}
+	return err
 }
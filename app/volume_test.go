@@ -0,0 +1,268 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+)
+
+// newTimestamps returns count entries named by their timestamps, starting
+// from start and apart from each other by interval
+func newTimestamps(start time.Time, interval time.Duration, count int, nameFormat string) []*NameWithTimestamp {
+	sts := []*NameWithTimestamp{}
+	for i := 0; i < count; i++ {
+		t := start.Add(time.Duration(i) * interval)
+		sts = append(sts, &NameWithTimestamp{
+			Name:      t.Format(nameFormat),
+			Timestamp: t,
+		})
+	}
+	return sts
+}
+
+// excludeNames returns the names of sts not in kept, in the order of sts
+func excludeNames(sts []*NameWithTimestamp, kept []string) []string {
+	keptMap := map[string]bool{}
+	for _, name := range kept {
+		keptMap[name] = true
+	}
+	ret := []string{}
+	for _, st := range sts {
+		if !keptMap[st.Name] {
+			ret = append(ret, st.Name)
+		}
+	}
+	return ret
+}
+
+func TestGetCleanupListRetain(t *testing.T) {
+	assert := require.New(t)
+
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	sts := newTimestamps(start, time.Hour, 4, time.RFC3339)
+	// the order of the input doesn't matter
+	shuffled := []*NameWithTimestamp{sts[2], sts[0], sts[3], sts[1]}
+
+	ret := getCleanupList(shuffled, 2, nil)
+	assert.Equal([]string{sts[0].Name, sts[1].Name}, ret)
+
+	ret = getCleanupList(sts, 5, nil)
+	assert.Equal([]string{}, ret)
+
+	ret = getCleanupList(sts, 1, &types.RetentionPolicy{})
+	assert.Equal([]string{sts[0].Name, sts[1].Name, sts[2].Name}, ret)
+}
+
+func TestGetCleanupListHourly(t *testing.T) {
+	assert := require.New(t)
+
+	// 10:00, 10:30, ... 12:30
+	start := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	sts := newTimestamps(start, 30*time.Minute, 6, "15:04")
+
+	ret := getCleanupList(sts, 1, &types.RetentionPolicy{Hourly: 3})
+	assert.Equal([]string{"10:00", "11:00", "12:00"}, ret)
+
+	ret = getCleanupList(sts, 1, &types.RetentionPolicy{Hourly: 2})
+	assert.Equal([]string{"10:00", "10:30", "11:00", "12:00"}, ret)
+
+	// retain keeps the newest ones regardless of the periods
+	ret = getCleanupList(sts, 3, &types.RetentionPolicy{Hourly: 1})
+	assert.Equal([]string{"10:00", "10:30", "11:00"}, ret)
+}
+
+func TestGetCleanupListGrandfatherFatherSon(t *testing.T) {
+	assert := require.New(t)
+
+	// daily at 01:00 from 2018-01-01 (Monday) to 2018-03-31 (Saturday)
+	start := time.Date(2018, 1, 1, 1, 0, 0, 0, time.UTC)
+	sts := newTimestamps(start, 24*time.Hour, 90, "2006-01-02")
+	assert.Equal("2018-03-31", sts[len(sts)-1].Name)
+
+	retention := &types.RetentionPolicy{
+		Daily:   7,
+		Weekly:  4,
+		Monthly: 3,
+	}
+	kept := []string{
+		// daily
+		"2018-03-25", "2018-03-26", "2018-03-27", "2018-03-28",
+		"2018-03-29", "2018-03-30", "2018-03-31",
+		// weekly, the newest of the ISO weeks, the week of 2018-03-31
+		// is covered by the daily
+		"2018-03-11", "2018-03-18",
+		// monthly
+		"2018-01-31", "2018-02-28",
+	}
+	ret := getCleanupList(sts, 1, retention)
+	assert.Len(ret, 90-len(kept))
+	assert.Equal(excludeNames(sts, kept), ret)
+}
+
+func TestGetCleanupListTiersSkipEmptyPeriods(t *testing.T) {
+	assert := require.New(t)
+
+	// the periods without any copy don't count
+	sts := []*NameWithTimestamp{}
+	for _, ts := range []string{
+		"2017-10-15T08:00:00Z",
+		"2017-12-01T08:00:00Z",
+		"2017-12-31T08:00:00Z",
+		"2018-01-01T08:00:00Z",
+		"2018-01-01T09:00:00Z",
+	} {
+		t, err := time.Parse(time.RFC3339, ts)
+		assert.Nil(err)
+		sts = append(sts, &NameWithTimestamp{Name: ts, Timestamp: t})
+	}
+
+	ret := getCleanupList(sts, 1, &types.RetentionPolicy{Monthly: 3})
+	assert.Equal([]string{"2017-12-01T08:00:00Z", "2018-01-01T08:00:00Z"}, ret)
+
+	// 2017-12-31 is in the ISO week 2017-W52, 2018-01-01 starts 2018-W01
+	ret = getCleanupList(sts, 1, &types.RetentionPolicy{Weekly: 2})
+	assert.Equal([]string{"2017-10-15T08:00:00Z", "2017-12-01T08:00:00Z", "2018-01-01T08:00:00Z"}, ret)
+}
+
+func TestRecordRun(t *testing.T) {
+	assert := require.New(t)
+
+	namespace := "longhorn-system"
+	volumeName := "vol-1"
+	lhClient := lhfake.NewSimpleClientset()
+	_, err := lhClient.LonghornV1alpha1().Settings(namespace).Create(&longhorn.Setting{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: datastore.SettingName,
+		},
+		SettingsInfo: types.SettingsInfo{
+			RecurringJobHistoryLimit: 2,
+		},
+	})
+	assert.Nil(err)
+	_, err = lhClient.LonghornV1alpha1().Volumes(namespace).Create(&longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: volumeName,
+		},
+	})
+	assert.Nil(err)
+
+	job := &Job{
+		namespace:  namespace,
+		volumeName: volumeName,
+		lhClient:   lhClient,
+	}
+	getStatus := func() types.RecurringJobRunStatus {
+		v, err := lhClient.LonghornV1alpha1().Volumes(namespace).Get(volumeName, metav1.GetOptions{})
+		assert.Nil(err)
+		return v.Status.RecurringJobStatus["backup"]
+	}
+
+	job.createdSnapshot = "backup-1"
+	job.createdBackup = "vfs:///var/backup?backup=backup-1&volume=vol-1"
+	job.recordRun("backup", "2018-01-01T00:00:00Z", nil)
+	status := getStatus()
+	assert.Len(status.Runs, 1)
+	assert.Equal(types.RecurringJobRunResultSucceeded, status.Runs[0].Result)
+	assert.Equal("2018-01-01T00:00:00Z", status.Runs[0].StartTime)
+	assert.Equal("backup-1", status.Runs[0].Snapshot)
+	assert.Equal(job.createdBackup, status.Runs[0].Backup)
+	assert.Equal(0, status.ConsecutiveFailures)
+
+	job.createdSnapshot = ""
+	job.createdBackup = ""
+	job.recordRun("backup", "2018-01-02T00:00:00Z", errors.New("timeout"))
+	job.recordRun("backup", "2018-01-03T00:00:00Z", errors.New("target unavailable"))
+	status = getStatus()
+	// the newest first, trimmed to the history limit
+	assert.Len(status.Runs, 2)
+	assert.Equal("2018-01-03T00:00:00Z", status.Runs[0].StartTime)
+	assert.Equal(types.RecurringJobRunResultFailed, status.Runs[0].Result)
+	assert.Equal("target unavailable", status.Runs[0].Error)
+	assert.Equal("2018-01-02T00:00:00Z", status.Runs[1].StartTime)
+	assert.Equal(2, status.ConsecutiveFailures)
+
+	job.recordRun("backup", "2018-01-04T00:00:00Z", nil)
+	status = getStatus()
+	assert.Equal(types.RecurringJobRunResultSucceeded, status.Runs[0].Result)
+	assert.Equal(0, status.ConsecutiveFailures)
+}
+
+func TestAutoAttachVolume(t *testing.T) {
+	assert := require.New(t)
+
+	namespace := "longhorn-system"
+	lhClient := lhfake.NewSimpleClientset()
+	newVolume := func(name string, spec types.VolumeSpec, state types.VolumeState) {
+		_, err := lhClient.LonghornV1alpha1().Volumes(namespace).Create(&longhorn.Volume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: spec,
+			Status: types.VolumeStatus{
+				State: state,
+			},
+		})
+		assert.Nil(err)
+	}
+	getVolume := func(name string) *longhorn.Volume {
+		v, err := lhClient.LonghornV1alpha1().Volumes(namespace).Get(name, metav1.GetOptions{})
+		assert.Nil(err)
+		return v
+	}
+
+	// attached by the workload
+	newVolume("attached", types.VolumeSpec{OwnerID: "node-1", NodeID: "node-2"}, types.VolumeStateAttached)
+	attached, err := autoAttachVolume(namespace, lhClient, "attached", "backup")
+	assert.Nil(err)
+	assert.False(attached)
+	assert.Len(getVolume("attached").Spec.AutoAttachedForJobs, 0)
+
+	// opted out
+	newVolume("disabled", types.VolumeSpec{OwnerID: "node-1", DisableAutoAttach: true}, types.VolumeStateDetached)
+	attached, err = autoAttachVolume(namespace, lhClient, "disabled", "backup")
+	assert.NotNil(err)
+	assert.False(attached)
+	assert.Equal("", getVolume("disabled").Spec.NodeID)
+
+	// shared with the job attached it already
+	newVolume("shared", types.VolumeSpec{
+		OwnerID:             "node-1",
+		NodeID:              "node-1",
+		DisableFrontend:     true,
+		AutoAttachedForJobs: map[string]string{"backup": util.Now()},
+	}, types.VolumeStateAttached)
+	attached, err = autoAttachVolume(namespace, lhClient, "shared", "snapshot")
+	assert.Nil(err)
+	assert.True(attached)
+	jobs := getVolume("shared").Spec.AutoAttachedForJobs
+	assert.Len(jobs, 2)
+	assert.Contains(jobs, "backup")
+	assert.Contains(jobs, "snapshot")
+
+	autoDetachVolume(namespace, lhClient, "shared", "backup")
+	v := getVolume("shared")
+	assert.Len(v.Spec.AutoAttachedForJobs, 1)
+	assert.Contains(v.Spec.AutoAttachedForJobs, "snapshot")
+	assert.Equal("node-1", v.Spec.NodeID)
+	autoDetachVolume(namespace, lhClient, "shared", "snapshot")
+	v = getVolume("shared")
+	assert.Len(v.Spec.AutoAttachedForJobs, 0)
+	assert.Equal("", v.Spec.NodeID)
+	assert.False(v.Spec.DisableFrontend)
+
+	// taken over by the workload in the meantime
+	newVolume("taken", types.VolumeSpec{OwnerID: "node-2", NodeID: "node-2"}, types.VolumeStateAttached)
+	autoDetachVolume(namespace, lhClient, "taken", "backup")
+	assert.Equal("node-2", getVolume("taken").Spec.NodeID)
+}
@@ -7,17 +7,17 @@ const (
 type BackupVolume struct {
 	Resource `yaml:"-"`
 
-	Backups map[string]string `json:"backups,omitempty" yaml:"backups,omitempty"`
+	BackupTarget string `json:"backupTarget,omitempty" yaml:"backup_target,omitempty"`
 
 	Created string `json:"created,omitempty" yaml:"created,omitempty"`
 
+	LastBackupAt string `json:"lastBackupAt,omitempty" yaml:"last_backup_at,omitempty"`
+
 	LastBackupName string `json:"lastBackupName,omitempty" yaml:"last_backup_name,omitempty"`
 
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
 
 	Size string `json:"size,omitempty" yaml:"size,omitempty"`
-
-	SpaceUsage string `json:"spaceUsage,omitempty" yaml:"space_usage,omitempty"`
 }
 
 type BackupVolumeCollection struct {
@@ -7,6 +7,10 @@ const (
 type SnapshotInput struct {
 	Resource `yaml:"-"`
 
+	BackupTarget string `json:"backupTarget,omitempty" yaml:"backup_target,omitempty"`
+
+	Freeze bool `json:"freeze,omitempty" yaml:"freeze,omitempty"`
+
 	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
@@ -7,6 +7,8 @@ const (
 type Volume struct {
 	Resource `yaml:"-"`
 
+	AutoAttachedForJobs map[string]string `json:"autoAttachedForJobs,omitempty" yaml:"auto_attached_for_jobs,omitempty"`
+
 	Controller *Controller `json:"controller,omitempty" yaml:"controller,omitempty"`
 
 	Created string `json:"created,omitempty" yaml:"created,omitempty"`
@@ -17,6 +19,8 @@ type Volume struct {
 
 	FromBackup string `json:"fromBackup,omitempty" yaml:"from_backup,omitempty"`
 
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
 
 	NumberOfReplicas int64 `json:"numberOfReplicas,omitempty" yaml:"number_of_replicas,omitempty"`
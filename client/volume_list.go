@@ -0,0 +1,66 @@
+package client
+
+import (
+	"strconv"
+)
+
+const (
+	VolumeSortByName    = "name"
+	VolumeSortBySize    = "size"
+	VolumeSortByCreated = "created"
+	VolumeSortByState   = "state"
+)
+
+// VolumeListOptions are the filters, sorting and pagination supported by
+// the volume list. Empty fields are not sent.
+type VolumeListOptions struct {
+	// LabelSelector uses the Kubernetes label selector syntax, e.g.
+	// "app=db,tier!=test"
+	LabelSelector string
+	State         string
+	Robustness    string
+	Node          string
+	EngineImage   string
+
+	SortBy     string
+	Descending bool
+
+	Marker int
+	Limit  int
+}
+
+func (o *VolumeListOptions) ListOpts() *ListOpts {
+	opts := NewListOpts()
+	if o == nil {
+		return opts
+	}
+	filters := map[string]string{
+		"labelSelector": o.LabelSelector,
+		"state":         o.State,
+		"robustness":    o.Robustness,
+		"node":          o.Node,
+		"engineImage":   o.EngineImage,
+		"sort":          o.SortBy,
+	}
+	for k, v := range filters {
+		if v != "" {
+			opts.Filters[k] = v
+		}
+	}
+	if o.Descending {
+		opts.Filters["order"] = "desc"
+	}
+	if o.Marker > 0 {
+		opts.Filters["marker"] = strconv.Itoa(o.Marker)
+	}
+	if o.Limit > 0 {
+		opts.Filters["limit"] = strconv.Itoa(o.Limit)
+	}
+	return opts
+}
+
+// ListWithOptions lists the volumes matching the options. Use Next() on the
+// result to get the following pages when Limit is set.
+func (c *VolumeClient) ListWithOptions(opts *VolumeListOptions) (*VolumeCollection, error) {
+	return c.List(opts.ListOpts())
+}
@@ -0,0 +1,701 @@
+package controller
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	appsinformers_v1beta2 "k8s.io/client-go/informers/apps/v1beta2"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/controller"
+	kubeletapis "k8s.io/kubernetes/pkg/kubelet/apis"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhinformers "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions/longhorn/v1alpha1"
+)
+
+var (
+	ownerKindBackingImage = longhorn.SchemeGroupVersion.WithKind("BackingImage").String()
+
+	ExpiredBackingImageTimeout = 60 * time.Minute
+
+	backingImageSourceDirectoryInContainer = "/source/"
+)
+
+type BackingImageController struct {
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the backing image
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds *datastore.DataStore
+
+	biStoreSynced cache.InformerSynced
+	vStoreSynced  cache.InformerSynced
+	rStoreSynced  cache.InformerSynced
+	dsStoreSynced cache.InformerSynced
+	pStoreSynced  cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+}
+
+func NewBackingImageController(
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	backingImageInformer lhinformers.BackingImageInformer,
+	volumeInformer lhinformers.VolumeInformer,
+	replicaInformer lhinformers.ReplicaInformer,
+	dsInformer appsinformers_v1beta2.DaemonSetInformer,
+	podInformer coreinformers.PodInformer,
+	kubeClient clientset.Interface,
+	namespace string, controllerID string) *BackingImageController {
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	// TODO: remove the wrapper when every clients have moved to use the clientset.
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	bic := &BackingImageController{
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, v1.EventSource{Component: "longhorn-backing-image-controller"}),
+
+		ds: ds,
+
+		biStoreSynced: backingImageInformer.Informer().HasSynced,
+		vStoreSynced:  volumeInformer.Informer().HasSynced,
+		rStoreSynced:  replicaInformer.Informer().HasSynced,
+		dsStoreSynced: dsInformer.Informer().HasSynced,
+		pStoreSynced:  podInformer.Informer().HasSynced,
+
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "longhorn-backing-image"),
+	}
+
+	backingImageInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			bi := obj.(*longhorn.BackingImage)
+			bic.enqueueBackingImage(bi)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curBI := cur.(*longhorn.BackingImage)
+			bic.enqueueBackingImage(curBI)
+		},
+		DeleteFunc: func(obj interface{}) {
+			bi := obj.(*longhorn.BackingImage)
+			bic.enqueueBackingImage(bi)
+		},
+	})
+
+	volumeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			v := obj.(*longhorn.Volume)
+			bic.enqueueVolumes(v)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldV := old.(*longhorn.Volume)
+			curV := cur.(*longhorn.Volume)
+			bic.enqueueVolumes(oldV, curV)
+		},
+		DeleteFunc: func(obj interface{}) {
+			v := obj.(*longhorn.Volume)
+			bic.enqueueVolumes(v)
+		},
+	})
+
+	// the copies are needed on the nodes the replicas are scheduled to
+	replicaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			r := obj.(*longhorn.Replica)
+			bic.enqueueReplicas(r)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldR := old.(*longhorn.Replica)
+			curR := cur.(*longhorn.Replica)
+			if oldR.Spec.NodeID == curR.Spec.NodeID {
+				return
+			}
+			bic.enqueueReplicas(curR)
+		},
+		DeleteFunc: func(obj interface{}) {
+			r := obj.(*longhorn.Replica)
+			bic.enqueueReplicas(r)
+		},
+	})
+
+	// the daemon set and its pods are labeled with the backing image name
+	dsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			bic.enqueueLabeledObject(obj)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			bic.enqueueLabeledObject(cur)
+		},
+		DeleteFunc: func(obj interface{}) {
+			bic.enqueueLabeledObject(obj)
+		},
+	})
+
+	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			bic.enqueueLabeledObject(obj)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			bic.enqueueLabeledObject(cur)
+		},
+		DeleteFunc: func(obj interface{}) {
+			bic.enqueueLabeledObject(obj)
+		},
+	})
+
+	return bic
+}
+
+func (bic *BackingImageController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer bic.queue.ShutDown()
+
+	logrus.Infof("Start Longhorn Backing Image controller")
+	defer logrus.Infof("Shutting down Longhorn Backing Image controller")
+
+	if !controller.WaitForCacheSync("longhorn backing images", stopCh, bic.biStoreSynced, bic.vStoreSynced, bic.rStoreSynced, bic.dsStoreSynced, bic.pStoreSynced) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(bic.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (bic *BackingImageController) worker() {
+	for bic.processNextWorkItem() {
+	}
+}
+
+func (bic *BackingImageController) processNextWorkItem() bool {
+	key, quit := bic.queue.Get()
+
+	if quit {
+		return false
+	}
+	defer bic.queue.Done(key)
+
+	err := bic.syncBackingImage(key.(string))
+	bic.handleErr(err, key)
+
+	return true
+}
+
+func (bic *BackingImageController) handleErr(err error, key interface{}) {
+	if err == nil {
+		bic.queue.Forget(key)
+		return
+	}
+
+	if bic.queue.NumRequeues(key) < maxRetries {
+		logrus.Warnf("Error syncing Longhorn backing image %v: %v", key, err)
+		bic.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	logrus.Warnf("Dropping Longhorn backing image %v out of the queue: %v", key, err)
+	bic.queue.Forget(key)
+}
+
+func (bic *BackingImageController) syncBackingImage(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to sync backing image for %v", key)
+	}()
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != bic.namespace {
+		// Not ours, don't do anything
+		return nil
+	}
+
+	backingImage, err := bic.ds.GetBackingImage(name)
+	if err != nil {
+		return err
+	}
+	if backingImage == nil {
+		logrus.Infof("Longhorn backing image %v has been deleted", key)
+		return nil
+	}
+
+	if backingImage.Spec.OwnerID == "" {
+		// Claim it
+		backingImage.Spec.OwnerID = bic.controllerID
+		backingImage, err = bic.ds.UpdateBackingImage(backingImage)
+		if err != nil {
+			// we don't mind others coming first
+			if apierrors.IsConflict(errors.Cause(err)) {
+				return nil
+			}
+			return err
+		}
+		logrus.Debugf("Backing Image Controller %v picked up %v (%v)", bic.controllerID, backingImage.Name, backingImage.Spec.ImageURL)
+	} else if backingImage.Spec.OwnerID != bic.controllerID {
+		// Not ours
+		return nil
+	}
+
+	dsName := getBackingImageDaemonSetName(backingImage.Name)
+	if backingImage.DeletionTimestamp != nil {
+		if err := bic.ds.DeleteBackingImageDaemonSet(dsName); err != nil {
+			return errors.Wrapf(err, "cannot cleanup daemonset of backing image %v", backingImage.Name)
+		}
+		logrus.Infof("Removed daemon set %v for backing image %v", dsName, backingImage.Name)
+		return bic.ds.RemoveFinalizerForBackingImage(backingImage)
+	}
+
+	oldState := backingImage.Status.State
+	savedBackingImage := backingImage.DeepCopy()
+	defer func() {
+		// we're going to update the object assume things changes
+		if err == nil && !reflect.DeepEqual(backingImage, savedBackingImage) {
+			_, err = bic.ds.UpdateBackingImage(backingImage)
+		}
+		// requeue if it's conflict
+		if apierrors.IsConflict(errors.Cause(err)) {
+			logrus.Debugf("Requeue %v due to conflict", key)
+			bic.enqueueBackingImage(backingImage)
+			err = nil
+		}
+	}()
+
+	if err := bic.updateBackingImageRefCount(backingImage); err != nil {
+		return err
+	}
+
+	ds, err := bic.ds.GetBackingImageDaemonSet(dsName)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get daemonset for backing image %v", backingImage.Name)
+	}
+
+	if bic.isBackingImageExpired(backingImage) {
+		if ds != nil {
+			logrus.Infof("Backing image %v has not been used since %v, clean up the copies on the nodes",
+				backingImage.Name, backingImage.Status.NoRefSince)
+			if err := bic.ds.DeleteBackingImageDaemonSet(dsName); err != nil {
+				return errors.Wrapf(err, "cannot cleanup daemonset of unused backing image %v", backingImage.Name)
+			}
+		}
+		backingImage.Status.State = types.BackingImageStateUnused
+		backingImage.Status.DiskStatusMap = nil
+		return nil
+	}
+
+	nodeIDs, err := bic.getBackingImageNodeIDs(backingImage)
+	if err != nil {
+		return err
+	}
+	if len(nodeIDs) == 0 && ds == nil {
+		// none of the replicas using the image has been scheduled
+		backingImage.Status.State = types.BackingImageStateUnused
+		backingImage.Status.DiskStatusMap = nil
+		return nil
+	}
+
+	if ds == nil {
+		if _, err := types.ParseImageURL(backingImage.Spec.ImageURL); err != nil {
+			// synced again once the image URL is updated
+			backingImage.Status.State = types.BackingImageStateError
+			bic.eventRecorder.Eventf(backingImage, v1.EventTypeWarning, types.EventReasonFailedCreating, "Cannot deploy backing image %v: %v", backingImage.Name, err)
+			return nil
+		}
+		dsSpec, err := bic.createBackingImageDaemonSetSpec(backingImage, nodeIDs)
+		if err != nil {
+			return errors.Wrapf(err, "cannot create daemonset spec for backing image %v", backingImage.Name)
+		}
+		if err = bic.ds.CreateBackingImageDaemonSet(backingImage.Name, dsSpec); err != nil {
+			return errors.Wrapf(err, "fail to create daemonset for backing image %v", backingImage.Name)
+		}
+		logrus.Infof("Created daemon set %v for backing image %v (%v)", dsSpec.Name, backingImage.Name, backingImage.Spec.ImageURL)
+		backingImage.Status.State = types.BackingImageStateDownloading
+		return nil
+	}
+
+	// the copies are kept on the nodes until the image expires once no
+	// replica uses it
+	if len(nodeIDs) != 0 {
+		affinity := getBackingImageNodeAffinity(nodeIDs)
+		if !reflect.DeepEqual(ds.Spec.Template.Spec.Affinity, affinity) {
+			ds.Spec.Template.Spec.Affinity = affinity
+			if err := bic.ds.UpdateBackingImageDaemonSet(ds); err != nil {
+				return errors.Wrapf(err, "fail to update the nodes of daemonset for backing image %v", backingImage.Name)
+			}
+			logrus.Infof("Updated daemon set %v for backing image %v to nodes %v", dsName, backingImage.Name, nodeIDs)
+		}
+	}
+
+	if err := bic.updateDiskStatus(backingImage); err != nil {
+		return err
+	}
+
+	backingImage.Status.State = types.BackingImageStateReady
+	if ds.Status.DesiredNumberScheduled == 0 || ds.Status.NumberAvailable != ds.Status.DesiredNumberScheduled {
+		backingImage.Status.State = types.BackingImageStateDownloading
+	}
+	for _, diskStatus := range backingImage.Status.DiskStatusMap {
+		if diskStatus.State == types.BackingImageDiskStateFailed {
+			backingImage.Status.State = types.BackingImageStateError
+			break
+		}
+		if diskStatus.State != types.BackingImageDiskStateReady {
+			backingImage.Status.State = types.BackingImageStateDownloading
+		}
+	}
+
+	if oldState != backingImage.Status.State {
+		switch backingImage.Status.State {
+		case types.BackingImageStateReady:
+			logrus.Infof("Backing image %v (%v) become ready", backingImage.Name, backingImage.Spec.ImageURL)
+		case types.BackingImageStateError:
+			bic.eventRecorder.Eventf(backingImage, v1.EventTypeWarning, types.EventReasonFailedCreating, "Fail to download backing image %v on some nodes", backingImage.Name)
+		}
+	}
+	return nil
+}
+
+func (bic *BackingImageController) updateBackingImageRefCount(bi *longhorn.BackingImage) error {
+	volumes, err := bic.ds.ListVolumes()
+	if err != nil {
+		return errors.Wrap(err, "cannot list volumes when updateBackingImageRefCount")
+	}
+	refCount := 0
+	for _, v := range volumes {
+		if v.Spec.BackingImage == bi.Name {
+			refCount++
+		}
+	}
+	bi.Status.RefCount = refCount
+	if bi.Status.RefCount == 0 {
+		if bi.Status.NoRefSince == "" {
+			bi.Status.NoRefSince = util.Now()
+		}
+	} else {
+		bi.Status.NoRefSince = ""
+	}
+	return nil
+}
+
+// getBackingImageNodeIDs returns the sorted nodes of the replicas of the
+// volumes using the backing image
+func (bic *BackingImageController) getBackingImageNodeIDs(bi *longhorn.BackingImage) ([]string, error) {
+	volumes, err := bic.ds.ListVolumes()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list volumes when getBackingImageNodeIDs")
+	}
+	replicas, err := bic.ds.ListReplicasRO()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list replicas when getBackingImageNodeIDs")
+	}
+	nodes := map[string]struct{}{}
+	for _, r := range replicas {
+		v, exists := volumes[r.Spec.VolumeName]
+		if !exists || v.Spec.BackingImage != bi.Name || r.Spec.NodeID == "" {
+			continue
+		}
+		nodes[r.Spec.NodeID] = struct{}{}
+	}
+	nodeIDs := []string{}
+	for nodeID := range nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+	return nodeIDs, nil
+}
+
+func getBackingImageNodeAffinity(nodeIDs []string) *v1.Affinity {
+	return &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{
+								Key:      kubeletapis.LabelHostname,
+								Operator: v1.NodeSelectorOpIn,
+								Values:   nodeIDs,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (bic *BackingImageController) isBackingImageExpired(bi *longhorn.BackingImage) bool {
+	if bi.Status.RefCount != 0 || bi.Status.NoRefSince == "" {
+		return false
+	}
+	return util.TimestampAfterTimeout(bi.Status.NoRefSince, ExpiredBackingImageTimeout)
+}
+
+func (bic *BackingImageController) updateDiskStatus(bi *longhorn.BackingImage) error {
+	pods, err := bic.ds.ListBackingImagePods(bi.Name)
+	if err != nil {
+		return errors.Wrapf(err, "cannot list pods for backing image %v", bi.Name)
+	}
+	diskStatusMap := map[string]types.BackingImageDiskStatus{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		diskStatusMap[pod.Spec.NodeName] = getBackingImageDiskStatusFromPod(pod)
+	}
+	bi.Status.DiskStatusMap = diskStatusMap
+	return nil
+}
+
+func getBackingImageDiskStatusFromPod(pod *v1.Pod) types.BackingImageDiskStatus {
+	if pod.Status.Phase == v1.PodFailed {
+		return types.BackingImageDiskStatus{
+			State:   types.BackingImageDiskStateFailed,
+			Message: pod.Status.Message,
+		}
+	}
+	for _, st := range pod.Status.ContainerStatuses {
+		if st.LastTerminationState.Terminated != nil && st.LastTerminationState.Terminated.ExitCode != 0 {
+			return types.BackingImageDiskStatus{
+				State:   types.BackingImageDiskStateFailed,
+				Message: st.LastTerminationState.Terminated.Message,
+			}
+		}
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady && cond.Status == v1.ConditionTrue {
+			return types.BackingImageDiskStatus{
+				State: types.BackingImageDiskStateReady,
+			}
+		}
+	}
+	return types.BackingImageDiskStatus{
+		State: types.BackingImageDiskStateDownloading,
+	}
+}
+
+func (bic *BackingImageController) enqueueBackingImage(backingImage *longhorn.BackingImage) {
+	key, err := controller.KeyFunc(backingImage)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", backingImage, err))
+		return
+	}
+
+	bic.queue.AddRateLimited(key)
+}
+
+func (bic *BackingImageController) enqueueBackingImageByName(name string) {
+	backingImage, err := bic.ds.GetBackingImage(name)
+	if err != nil || backingImage == nil {
+		return
+	}
+	// Not ours
+	if backingImage.Spec.OwnerID != bic.controllerID {
+		return
+	}
+	bic.enqueueBackingImage(backingImage)
+}
+
+func (bic *BackingImageController) enqueueVolumes(volumes ...*longhorn.Volume) {
+	names := map[string]struct{}{}
+	for _, v := range volumes {
+		if v.Spec.BackingImage != "" {
+			names[v.Spec.BackingImage] = struct{}{}
+		}
+	}
+	for name := range names {
+		bic.enqueueBackingImageByName(name)
+	}
+}
+
+func (bic *BackingImageController) enqueueReplicas(replicas ...*longhorn.Replica) {
+	volumes := []*longhorn.Volume{}
+	for _, r := range replicas {
+		v, err := bic.ds.GetVolume(r.Spec.VolumeName)
+		if err != nil || v == nil {
+			continue
+		}
+		volumes = append(volumes, v)
+	}
+	bic.enqueueVolumes(volumes...)
+}
+
+func (bic *BackingImageController) enqueueLabeledObject(obj interface{}) {
+	var labels map[string]string
+	switch o := obj.(type) {
+	case *appsv1beta2.DaemonSet:
+		labels = o.Labels
+	case *v1.Pod:
+		labels = o.Labels
+	default:
+		return
+	}
+	if labels[types.LonghornSystemKey] != types.LonghornSystemValueBackingImage {
+		return
+	}
+	name := labels[types.LonghornBackingImageKey]
+	if name == "" {
+		return
+	}
+	bic.enqueueBackingImageByName(name)
+}
+
+func getBackingImageDaemonSetName(backingImageName string) string {
+	return "backing-image-" + backingImageName
+}
+
+func (bic *BackingImageController) createBackingImageDaemonSetSpec(bi *longhorn.BackingImage, nodeIDs []string) (*appsv1beta2.DaemonSet, error) {
+	source, err := types.ParseImageURL(bi.Spec.ImageURL)
+	if err != nil {
+		return nil, err
+	}
+	setting, err := bic.ds.GetSetting()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get setting")
+	}
+	// the default engine image has been deployed to every node already
+	image := setting.DefaultEngineImage
+	if image == "" {
+		return nil, fmt.Errorf("BUG: Invalid empty Setting.EngineImage")
+	}
+
+	dsName := getBackingImageDaemonSetName(bi.Name)
+	fileInContainer := types.GetBackingImageFileInContainer()
+	volumes := []v1.Volume{
+		{
+			Name: "data",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: types.GetBackingImageDirectoryOnHost(bi.Name),
+				},
+			},
+		},
+	}
+	volumeMounts := []v1.VolumeMount{
+		{
+			Name:      "data",
+			MountPath: types.BackingImageDirectoryInContainer,
+		},
+	}
+	// the image location is passed by environment variable to avoid
+	// being interpreted by the shell
+	fetch := `curl -sSfL -o "$DST.tmp" "$IMAGE_URL"`
+	imageURL := bi.Spec.ImageURL
+	if source.IsLocalFile {
+		volumes = append(volumes, v1.Volume{
+			Name: "source",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: filepath.Dir(source.Path),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      "source",
+			MountPath: backingImageSourceDirectoryInContainer,
+			ReadOnly:  true,
+		})
+		fetch = `cp "$IMAGE_URL" "$DST.tmp"`
+		imageURL = filepath.Join(backingImageSourceDirectoryInContainer, filepath.Base(source.Path))
+	}
+
+	cmd := []string{
+		"/bin/bash",
+	}
+	args := []string{
+		"-c",
+		"set -e && if [ ! -f \"$DST\" ]; then " + fetch + " && mv \"$DST.tmp\" \"$DST\"; fi && echo downloaded && " +
+			"trap 'rm -f \"$DST\" \"$DST.tmp\" && echo cleaned up' EXIT && sleep infinity",
+	}
+	d := &appsv1beta2.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: dsName,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: longhorn.SchemeGroupVersion.String(),
+					Kind:       ownerKindBackingImage,
+					UID:        bi.UID,
+					Name:       bi.Name,
+				},
+			},
+		},
+		Spec: appsv1beta2.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: types.GetBackingImageLabel(bi.Name),
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   dsName,
+					Labels: types.GetBackingImageLabel(bi.Name),
+				},
+				Spec: v1.PodSpec{
+					Affinity: getBackingImageNodeAffinity(nodeIDs),
+					Containers: []v1.Container{
+						{
+							Name:    dsName,
+							Image:   image,
+							Command: cmd,
+							Args:    args,
+							Env: []v1.EnvVar{
+								{
+									Name:  "IMAGE_URL",
+									Value: imageURL,
+								},
+								{
+									Name:  "DST",
+									Value: fileInContainer,
+								},
+							},
+							VolumeMounts: volumeMounts,
+							ReadinessProbe: &v1.Probe{
+								Handler: v1.Handler{
+									Exec: &v1.ExecAction{
+										Command: []string{
+											"ls", fileInContainer,
+										},
+									},
+								},
+								InitialDelaySeconds: 5,
+								PeriodSeconds:       5,
+							},
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	return d, nil
+}
@@ -0,0 +1,406 @@
+package controller
+
+import (
+	"time"
+
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+	lhinformerfactory "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestBackingImageName = "test-backing-image"
+	TestBackingImageURL  = "https://longhorn-backing-image.s3.amazonaws.com/parrot.qcow2"
+)
+
+func newTestBackingImageController(lhInformerFactory lhinformerfactory.SharedInformerFactory, kubeInformerFactory informers.SharedInformerFactory,
+	lhClient *lhfake.Clientset, kubeClient *fake.Clientset, controllerID string) *BackingImageController {
+	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
+	engineInformer := lhInformerFactory.Longhorn().V1alpha1().Engines()
+	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
+	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
+	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
+
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
+	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
+
+	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
+	initSettings(ds)
+
+	bic := NewBackingImageController(ds, scheme.Scheme, backingImageInformer, volumeInformer, replicaInformer, daemonSetInformer, podInformer, kubeClient, TestNamespace, controllerID)
+	fakeRecorder := record.NewFakeRecorder(100)
+	bic.eventRecorder = fakeRecorder
+
+	bic.biStoreSynced = alwaysReady
+	bic.vStoreSynced = alwaysReady
+	bic.dsStoreSynced = alwaysReady
+	bic.pStoreSynced = alwaysReady
+
+	return bic
+}
+
+func newBackingImage(name, ownerID, imageURL string) *longhorn.BackingImage {
+	return &longhorn.BackingImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  TestNamespace,
+			Finalizers: []string{longhorn.SchemeGroupVersion.Group},
+		},
+		Spec: types.BackingImageSpec{
+			OwnerID:  ownerID,
+			ImageURL: imageURL,
+		},
+	}
+}
+
+// newBackingImagePod returns the pod downloading the backing image on the
+// node, ready if the image has been downloaded
+func newBackingImagePod(backingImageName, nodeName string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getBackingImageDaemonSetName(backingImageName) + "-" + nodeName,
+			Namespace: TestNamespace,
+			Labels:    types.GetBackingImageLabel(backingImageName),
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			Conditions: []v1.PodCondition{
+				{
+					Type:   v1.PodReady,
+					Status: status,
+				},
+			},
+		},
+	}
+}
+
+type backingImageTestEnv struct {
+	bic        *BackingImageController
+	lhClient   *lhfake.Clientset
+	kubeClient *fake.Clientset
+
+	biIndexer cache.Indexer
+	vIndexer  cache.Indexer
+	rIndexer  cache.Indexer
+	dsIndexer cache.Indexer
+	pIndexer  cache.Indexer
+}
+
+func newBackingImageTestEnv() *backingImageTestEnv {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+	lhClient := lhfake.NewSimpleClientset()
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+	return &backingImageTestEnv{
+		bic:        newTestBackingImageController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient, TestNode1),
+		lhClient:   lhClient,
+		kubeClient: kubeClient,
+
+		biIndexer: lhInformerFactory.Longhorn().V1alpha1().BackingImages().Informer().GetIndexer(),
+		vIndexer:  lhInformerFactory.Longhorn().V1alpha1().Volumes().Informer().GetIndexer(),
+		rIndexer:  lhInformerFactory.Longhorn().V1alpha1().Replicas().Informer().GetIndexer(),
+		dsIndexer: kubeInformerFactory.Apps().V1beta2().DaemonSets().Informer().GetIndexer(),
+		pIndexer:  kubeInformerFactory.Core().V1().Pods().Informer().GetIndexer(),
+	}
+}
+
+func (env *backingImageTestEnv) create(bi *longhorn.BackingImage, c *C) {
+	bi, err := env.lhClient.LonghornV1alpha1().BackingImages(TestNamespace).Create(bi)
+	c.Assert(err, IsNil)
+	err = env.biIndexer.Add(bi)
+	c.Assert(err, IsNil)
+}
+
+// addVolume adds the volume using the backing image with a replica scheduled
+// to each of the nodes
+func (env *backingImageTestEnv) addVolume(name, backingImageName string, c *C, nodeIDs ...string) *longhorn.Volume {
+	v := newVolume(name, len(nodeIDs))
+	v.Namespace = TestNamespace
+	v.Spec.BackingImage = backingImageName
+	err := env.vIndexer.Add(v)
+	c.Assert(err, IsNil)
+	for _, nodeID := range nodeIDs {
+		env.addReplica(name, nodeID, c)
+	}
+	return v
+}
+
+func (env *backingImageTestEnv) addReplica(volumeName, nodeID string, c *C) *longhorn.Replica {
+	r := newReplica(types.InstanceStateStopped, types.InstanceStateStopped, "")
+	r.Name = volumeName + "-r-" + nodeID
+	r.Spec.VolumeName = volumeName
+	r.Spec.NodeID = nodeID
+	err := env.rIndexer.Add(r)
+	c.Assert(err, IsNil)
+	return r
+}
+
+// sync syncs the backing image and returns the updated one
+func (env *backingImageTestEnv) sync(name string, c *C) *longhorn.BackingImage {
+	err := env.bic.syncBackingImage(TestNamespace + "/" + name)
+	c.Assert(err, IsNil)
+	bi, err := env.lhClient.LonghornV1alpha1().BackingImages(TestNamespace).Get(name, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	err = env.biIndexer.Update(bi)
+	c.Assert(err, IsNil)
+	return bi
+}
+
+// getDaemonSet returns the daemon set of the backing image created by the
+// controller, and adds it to the indexer
+func (env *backingImageTestEnv) getDaemonSet(backingImageName string, c *C) *appsv1beta2.DaemonSet {
+	d, err := env.kubeClient.AppsV1beta2().DaemonSets(TestNamespace).Get(getBackingImageDaemonSetName(backingImageName), metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	err = env.dsIndexer.Add(d)
+	c.Assert(err, IsNil)
+	return d
+}
+
+func (s *TestSuite) TestBackingImageClaim(c *C) {
+	env := newBackingImageTestEnv()
+	env.addVolume(TestVolumeName, TestBackingImageName, c, TestNode1)
+
+	// the backing image without owner is claimed
+	env.create(newBackingImage(TestBackingImageName, "", TestBackingImageURL), c)
+	bi := env.sync(TestBackingImageName, c)
+	c.Assert(bi.Spec.OwnerID, Equals, TestNode1)
+	env.getDaemonSet(TestBackingImageName, c)
+
+	// the backing image owned by the other manager is left alone
+	env.create(newBackingImage("other", TestNode2, TestBackingImageURL), c)
+	bi = env.sync("other", c)
+	c.Assert(bi.Spec.OwnerID, Equals, TestNode2)
+	c.Assert(bi.Status.State, Equals, types.BackingImageState(""))
+	_, err := env.kubeClient.AppsV1beta2().DaemonSets(TestNamespace).Get(getBackingImageDaemonSetName("other"), metav1.GetOptions{})
+	c.Assert(err, NotNil)
+}
+
+func (s *TestSuite) TestBackingImageSync(c *C) {
+	env := newBackingImageTestEnv()
+	env.addVolume(TestVolumeName, TestBackingImageName, c, TestNode1)
+
+	// the daemon set downloading the image is deployed to the node of the
+	// replica
+	env.create(newBackingImage(TestBackingImageName, TestNode1, TestBackingImageURL), c)
+	bi := env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateDownloading)
+	c.Assert(bi.Status.RefCount, Equals, 1)
+	c.Assert(bi.Status.NoRefSince, Equals, "")
+	d := env.getDaemonSet(TestBackingImageName, c)
+	c.Assert(d.Labels, DeepEquals, types.GetBackingImageLabel(TestBackingImageName))
+	c.Assert(d.OwnerReferences, HasLen, 1)
+	c.Assert(d.OwnerReferences[0].Name, Equals, TestBackingImageName)
+	container := d.Spec.Template.Spec.Containers[0]
+	c.Assert(container.Image, Equals, TestEngineImage)
+	c.Assert(container.Env[0], DeepEquals, v1.EnvVar{Name: "IMAGE_URL", Value: TestBackingImageURL})
+	c.Assert(container.VolumeMounts, HasLen, 1)
+	c.Assert(d.Spec.Template.Spec.Affinity, DeepEquals, getBackingImageNodeAffinity([]string{TestNode1}))
+
+	// the replica scheduled to the other node
+	env.addReplica(TestVolumeName, TestNode2, c)
+	// the replica of the volume without the image doesn't need the copy
+	env.addVolume("other", "", c, "other-node")
+	bi = env.sync(TestBackingImageName, c)
+	d = env.getDaemonSet(TestBackingImageName, c)
+	c.Assert(d.Spec.Template.Spec.Affinity, DeepEquals, getBackingImageNodeAffinity([]string{TestNode1, TestNode2}))
+
+	// downloading on one of the nodes
+	d.Status.DesiredNumberScheduled = 2
+	d.Status.NumberAvailable = 1
+	err := env.dsIndexer.Update(d)
+	c.Assert(err, IsNil)
+	err = env.pIndexer.Add(newBackingImagePod(TestBackingImageName, TestNode1, true))
+	c.Assert(err, IsNil)
+	pod2 := newBackingImagePod(TestBackingImageName, TestNode2, false)
+	err = env.pIndexer.Add(pod2)
+	c.Assert(err, IsNil)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateDownloading)
+	c.Assert(bi.Status.DiskStatusMap, DeepEquals, map[string]types.BackingImageDiskStatus{
+		TestNode1: {State: types.BackingImageDiskStateReady},
+		TestNode2: {State: types.BackingImageDiskStateDownloading},
+	})
+
+	// downloaded on all the nodes
+	d.Status.NumberAvailable = 2
+	err = env.dsIndexer.Update(d)
+	c.Assert(err, IsNil)
+	pod2 = newBackingImagePod(TestBackingImageName, TestNode2, true)
+	err = env.pIndexer.Update(pod2)
+	c.Assert(err, IsNil)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateReady)
+
+	// failed to download on one of the nodes
+	pod2.Status.ContainerStatuses = []v1.ContainerStatus{
+		{
+			LastTerminationState: v1.ContainerState{
+				Terminated: &v1.ContainerStateTerminated{
+					ExitCode: 22,
+					Message:  "404 Not Found",
+				},
+			},
+		},
+	}
+	err = env.pIndexer.Update(pod2)
+	c.Assert(err, IsNil)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateError)
+	c.Assert(bi.Status.DiskStatusMap[TestNode2], DeepEquals, types.BackingImageDiskStatus{
+		State:   types.BackingImageDiskStateFailed,
+		Message: "404 Not Found",
+	})
+	c.Assert(<-env.bic.eventRecorder.(*record.FakeRecorder).Events, Matches, "Warning "+types.EventReasonFailedCreating+" .*")
+}
+
+func (s *TestSuite) TestBackingImageSyncLocalFile(c *C) {
+	env := newBackingImageTestEnv()
+	env.addVolume(TestVolumeName, TestBackingImageName, c, TestNode1)
+
+	env.create(newBackingImage(TestBackingImageName, TestNode1, "/var/lib/images/parrot.qcow2"), c)
+	bi := env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateDownloading)
+	d := env.getDaemonSet(TestBackingImageName, c)
+	spec := d.Spec.Template.Spec
+	c.Assert(spec.Volumes, HasLen, 2)
+	c.Assert(spec.Volumes[1].HostPath.Path, Equals, "/var/lib/images")
+	c.Assert(spec.Containers[0].Env[0].Value, Equals, backingImageSourceDirectoryInContainer+"parrot.qcow2")
+	c.Assert(spec.Containers[0].VolumeMounts[1].ReadOnly, Equals, true)
+}
+
+func (s *TestSuite) TestBackingImageSyncInvalidURL(c *C) {
+	env := newBackingImageTestEnv()
+	env.addVolume(TestVolumeName, TestBackingImageName, c, TestNode1)
+
+	env.create(newBackingImage(TestBackingImageName, TestNode1, "ftp://images/parrot.qcow2"), c)
+	bi := env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateError)
+	c.Assert(<-env.bic.eventRecorder.(*record.FakeRecorder).Events, Matches, "Warning "+types.EventReasonFailedCreating+" Cannot deploy .*unsupported scheme ftp.*")
+	_, err := env.kubeClient.AppsV1beta2().DaemonSets(TestNamespace).Get(getBackingImageDaemonSetName(TestBackingImageName), metav1.GetOptions{})
+	c.Assert(err, NotNil)
+
+	// deployed once the URL is fixed
+	bi.Spec.ImageURL = TestBackingImageURL
+	bi, err = env.lhClient.LonghornV1alpha1().BackingImages(TestNamespace).Update(bi)
+	c.Assert(err, IsNil)
+	err = env.biIndexer.Update(bi)
+	c.Assert(err, IsNil)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateDownloading)
+	env.getDaemonSet(TestBackingImageName, c)
+}
+
+func (s *TestSuite) TestBackingImageCleanup(c *C) {
+	env := newBackingImageTestEnv()
+
+	// not deployed until a replica using the image is scheduled
+	env.create(newBackingImage(TestBackingImageName, TestNode1, TestBackingImageURL), c)
+	bi := env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateUnused)
+	c.Assert(bi.Status.RefCount, Equals, 0)
+	c.Assert(bi.Status.NoRefSince, Not(Equals), "")
+	_, err := env.kubeClient.AppsV1beta2().DaemonSets(TestNamespace).Get(getBackingImageDaemonSetName(TestBackingImageName), metav1.GetOptions{})
+	c.Assert(err, NotNil)
+
+	v := env.addVolume(TestVolumeName, TestBackingImageName, c, TestNode1)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.RefCount, Equals, 1)
+	d := env.getDaemonSet(TestBackingImageName, c)
+	d.Status.DesiredNumberScheduled = 1
+	d.Status.NumberAvailable = 1
+	err = env.dsIndexer.Update(d)
+	c.Assert(err, IsNil)
+	err = env.pIndexer.Add(newBackingImagePod(TestBackingImageName, TestNode1, true))
+	c.Assert(err, IsNil)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateReady)
+
+	// recently unused, the copies on the nodes are kept
+	err = env.vIndexer.Delete(v)
+	c.Assert(err, IsNil)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateReady)
+	c.Assert(bi.Status.RefCount, Equals, 0)
+	c.Assert(bi.Status.NoRefSince, Not(Equals), "")
+	d = env.getDaemonSet(TestBackingImageName, c)
+	c.Assert(d.Spec.Template.Spec.Affinity, DeepEquals, getBackingImageNodeAffinity([]string{TestNode1}))
+
+	// unused for long, the copies on the nodes are cleaned up
+	bi.Status.NoRefSince = util.FormatTimeZ(time.Now().Add(-2 * ExpiredBackingImageTimeout))
+	bi, err = env.lhClient.LonghornV1alpha1().BackingImages(TestNamespace).Update(bi)
+	c.Assert(err, IsNil)
+	err = env.biIndexer.Update(bi)
+	c.Assert(err, IsNil)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateUnused)
+	c.Assert(bi.Status.DiskStatusMap, IsNil)
+	_, err = env.kubeClient.AppsV1beta2().DaemonSets(TestNamespace).Get(d.Name, metav1.GetOptions{})
+	c.Assert(err, NotNil)
+
+	// used again, the image is downloaded again
+	err = env.dsIndexer.Delete(d)
+	c.Assert(err, IsNil)
+	env.addVolume(TestVolumeName, TestBackingImageName, c, TestNode1)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Status.State, Equals, types.BackingImageStateDownloading)
+	c.Assert(bi.Status.RefCount, Equals, 1)
+	c.Assert(bi.Status.NoRefSince, Equals, "")
+	env.getDaemonSet(TestBackingImageName, c)
+}
+
+func (s *TestSuite) TestBackingImageDeleted(c *C) {
+	env := newBackingImageTestEnv()
+	env.addVolume(TestVolumeName, TestBackingImageName, c, TestNode1)
+
+	env.create(newBackingImage(TestBackingImageName, TestNode1, TestBackingImageURL), c)
+	bi := env.sync(TestBackingImageName, c)
+	d := env.getDaemonSet(TestBackingImageName, c)
+
+	// the daemon set is removed before the finalizer
+	now := metav1.Now()
+	bi.DeletionTimestamp = &now
+	bi, err := env.lhClient.LonghornV1alpha1().BackingImages(TestNamespace).Update(bi)
+	c.Assert(err, IsNil)
+	err = env.biIndexer.Update(bi)
+	c.Assert(err, IsNil)
+	bi = env.sync(TestBackingImageName, c)
+	c.Assert(bi.Finalizers, HasLen, 0)
+	_, err = env.kubeClient.AppsV1beta2().DaemonSets(TestNamespace).Get(d.Name, metav1.GetOptions{})
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,720 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/metrics"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhinformers "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions/longhorn/v1alpha1"
+)
+
+const (
+	// BackupOperationPollInterval is how often the backup progress is polled
+	BackupOperationPollInterval = 10 * time.Second
+
+	// backupSlotGracePeriod is how long the reservation of a backup not
+	// running yet is kept, covering the caches lagging behind
+	backupSlotGracePeriod = time.Minute
+)
+
+// BackupOperationController runs the backups of the volumes owned by the
+// manager. The progress is polled from the engine while the backup is
+// running, and the backup is stopped in the engine once cancel is requested.
+// The BackupOperations are deleted BackupOperationRetention after they
+// finished.
+type BackupOperationController struct {
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the backup operation
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds *datastore.DataStore
+
+	engines engineapi.EngineClientCollection
+
+	boStoreSynced cache.InformerSynced
+	vStoreSynced  cache.InformerSynced
+	nStoreSynced  cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+
+	lock sync.Mutex
+	// the backups running in the manager, keyed by the BackupOperation
+	running map[string]*runningBackup
+}
+
+type runningBackup struct {
+	engine       engineapi.EngineClient
+	snapshotName string
+	// cancelled is set once the engine is asked to stop the backup
+	cancelled bool
+	done      bool
+	backupURL string
+	err       error
+}
+
+func NewBackupOperationController(
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	backupOperationInformer lhinformers.BackupOperationInformer,
+	volumeInformer lhinformers.VolumeInformer,
+	nodeInformer lhinformers.NodeInformer,
+	kubeClient clientset.Interface,
+	engines engineapi.EngineClientCollection,
+	namespace string, controllerID string) *BackupOperationController {
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	// TODO: remove the wrapper when every clients have moved to use the clientset.
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	boc := &BackupOperationController{
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, v1.EventSource{Component: "longhorn-backup-operation-controller"}),
+
+		ds: ds,
+
+		engines: engines,
+
+		boStoreSynced: backupOperationInformer.Informer().HasSynced,
+		vStoreSynced:  volumeInformer.Informer().HasSynced,
+		nStoreSynced:  nodeInformer.Informer().HasSynced,
+
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "longhorn-backup-operation"),
+
+		running: map[string]*runningBackup{},
+	}
+
+	backupOperationInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			bo := obj.(*longhorn.BackupOperation)
+			boc.enqueueBackupOperation(bo)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curBO := cur.(*longhorn.BackupOperation)
+			boc.enqueueBackupOperation(curBO)
+		},
+		DeleteFunc: func(obj interface{}) {
+			bo := obj.(*longhorn.BackupOperation)
+			boc.enqueueBackupOperation(bo)
+		},
+	})
+
+	// the BackupOperations are taken over once the volume moved to another
+	// manager or the owner node is down
+	volumeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			oldV := old.(*longhorn.Volume)
+			curV := cur.(*longhorn.Volume)
+			if oldV.Spec.OwnerID != curV.Spec.OwnerID {
+				boc.enqueueBackupOperationsFor(curV.Name, "")
+			}
+		},
+	})
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			oldNode := old.(*longhorn.Node)
+			curNode := cur.(*longhorn.Node)
+			if oldNode.Status.State != curNode.Status.State {
+				boc.enqueueBackupOperationsFor("", curNode.Name)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			node, ok := obj.(*longhorn.Node)
+			if ok {
+				boc.enqueueBackupOperationsFor("", node.Name)
+			}
+		},
+	})
+
+	return boc
+}
+
+func (boc *BackupOperationController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer boc.queue.ShutDown()
+
+	logrus.Infof("Start Longhorn Backup Operation controller")
+	defer logrus.Infof("Shutting down Longhorn Backup Operation controller")
+
+	if !controller.WaitForCacheSync("longhorn backup operations", stopCh, boc.boStoreSynced, boc.vStoreSynced, boc.nStoreSynced) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(boc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (boc *BackupOperationController) worker() {
+	for boc.processNextWorkItem() {
+	}
+}
+
+func (boc *BackupOperationController) processNextWorkItem() bool {
+	key, quit := boc.queue.Get()
+
+	if quit {
+		return false
+	}
+	defer boc.queue.Done(key)
+
+	err := boc.syncBackupOperation(key.(string))
+	boc.handleErr(err, key)
+
+	return true
+}
+
+func (boc *BackupOperationController) handleErr(err error, key interface{}) {
+	if err == nil {
+		boc.queue.Forget(key)
+		return
+	}
+
+	if boc.queue.NumRequeues(key) < maxRetries {
+		logrus.Warnf("Error syncing Longhorn backup operation %v: %v", key, err)
+		boc.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	logrus.Warnf("Dropping Longhorn backup operation %v out of the queue: %v", key, err)
+	boc.queue.Forget(key)
+}
+
+func (boc *BackupOperationController) syncBackupOperation(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to sync backup operation for %v", key)
+	}()
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != boc.namespace {
+		// Not ours, don't do anything
+		return nil
+	}
+
+	bo, err := boc.ds.GetBackupOperation(name)
+	if err != nil {
+		return err
+	}
+	if bo == nil {
+		logrus.Infof("Longhorn backup operation %v has been deleted", key)
+		boc.stopDeletedBackup(name)
+		return nil
+	}
+
+	// the manager owning the volume runs the backup
+	v, err := boc.ds.GetVolume(bo.Spec.VolumeName)
+	if err != nil {
+		return err
+	}
+	if bo.Spec.OwnerID == "" {
+		if v == nil || v.Spec.OwnerID != boc.controllerID {
+			return nil
+		}
+		// Claim it
+		bo.Spec.OwnerID = boc.controllerID
+		bo, err = boc.ds.UpdateBackupOperation(bo)
+		if err != nil {
+			// we don't mind others coming first
+			if apierrors.IsConflict(errors.Cause(err)) {
+				return nil
+			}
+			return err
+		}
+		logrus.Debugf("Backup Operation Controller %v picked up %v", boc.controllerID, bo.Name)
+	} else if bo.Spec.OwnerID != boc.controllerID {
+		// the backup moved on without this manager
+		boc.forgetFinishedBackup(bo.Name)
+
+		ownerGone, err := boc.isOwnerGone(bo, v)
+		if err != nil || !ownerGone {
+			return err
+		}
+		return boc.takeOverBackupOperation(bo, v)
+	}
+
+	if bo.Status.IsFinished() {
+		return boc.cleanupBackupOperation(bo)
+	}
+	if bo.Status.State == types.BackupOperationStateInProgress {
+		return boc.syncBackupProgress(bo)
+	}
+	if bo.Spec.Cancel {
+		return boc.finishBackupOperation(bo, types.BackupOperationStateCancelled, "")
+	}
+	return boc.startBackup(bo)
+}
+
+// startBackup runs the backup in the background, the failure before the
+// engine starts the backup is recorded in the BackupOperation. The backup
+// stays pending until the concurrent backup limits allow it to start.
+func (boc *BackupOperationController) startBackup(bo *longhorn.BackupOperation) error {
+	e, err := boc.ds.GetVolumeEngine(bo.Spec.VolumeName)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return boc.finishBackupOperation(bo, types.BackupOperationStateError,
+			fmt.Sprintf("cannot get engine for %v", bo.Spec.VolumeName))
+	}
+	engine, err := GetClientForEngine(e, boc.engines, e.Status.CurrentImage)
+	if err != nil {
+		return boc.finishBackupOperation(bo, types.BackupOperationStateError, err.Error())
+	}
+	spec, err := boc.ds.GetBackupTargetSpec(bo.Spec.BackupTarget)
+	if err != nil {
+		return boc.finishBackupOperation(bo, types.BackupOperationStateError, err.Error())
+	}
+	if spec.URL == "" {
+		return boc.finishBackupOperation(bo, types.BackupOperationStateError, "backup target is not set")
+	}
+	var credential map[string]string
+	if spec.CredentialSecret != "" {
+		if credential, err = boc.ds.GetCredentialFromSecret(spec.CredentialSecret); err != nil {
+			return boc.finishBackupOperation(bo, types.BackupOperationStateError,
+				fmt.Sprintf("cannot get backup target credential: %v", err))
+		}
+	}
+
+	setting, err := boc.ds.GetSetting()
+	if err != nil {
+		return err
+	}
+	bandwidthLimit := spec.BandwidthLimit
+	if bandwidthLimit == 0 {
+		bandwidthLimit = setting.BackupBandwidthLimit
+	}
+	reserved, err := boc.reserveBackupSlot(bo.Name, e.Spec.NodeID, setting)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		logrus.Debugf("Backup %v of volume %v is waiting for the running backups to finish", bo.Name, bo.Spec.VolumeName)
+		boc.enqueueBackupOperationAfter(bo, BackupOperationPollInterval)
+		return nil
+	}
+
+	name := bo.Name
+	bo.Status.State = types.BackupOperationStateInProgress
+	bo.Status.StartTime = util.Now()
+	bo, err = boc.ds.UpdateBackupOperation(bo)
+	if err != nil {
+		boc.releaseBackupSlot(name)
+		return err
+	}
+
+	r := &runningBackup{
+		engine:       engine,
+		snapshotName: bo.Spec.SnapshotName,
+	}
+	boc.lock.Lock()
+	boc.running[bo.Name] = r
+	boc.lock.Unlock()
+
+	metricLabels := []string{bo.Spec.VolumeName, e.Spec.NodeID, e.Status.CurrentImage}
+	go func() {
+		start := time.Now()
+		backupURL, err := engine.SnapshotBackup(bo.Spec.SnapshotName, spec.URL, bo.Spec.Labels, credential, bandwidthLimit)
+		if err != nil {
+			metrics.BackupFailureTotal.WithLabelValues(metricLabels...).Inc()
+		} else {
+			metrics.BackupDurationSeconds.WithLabelValues(metricLabels...).Observe(time.Since(start).Seconds())
+		}
+
+		boc.lock.Lock()
+		r.done = true
+		r.backupURL = backupURL
+		r.err = err
+		boc.lock.Unlock()
+		boc.enqueueBackupOperation(bo)
+	}()
+
+	logrus.Debugf("Started backup %v of volume %v snapshot %v", bo.Name, bo.Spec.VolumeName, bo.Spec.SnapshotName)
+	boc.enqueueBackupOperationAfter(bo, BackupOperationPollInterval)
+	return nil
+}
+
+// reserveBackupSlot records the backup in the ConfigMap shared by the
+// managers if the backups running on the node nodeID of the engine and in
+// the cluster are below the limits. The ConfigMap is read from the API server
+// and updated on its latest version, so two managers cannot take the last
+// slot together. The reservations of the finished or deleted
+// BackupOperations don't count.
+func (boc *BackupOperationController) reserveBackupSlot(name, nodeID string, setting *longhorn.Setting) (bool, error) {
+	nodeLimit := setting.BackupConcurrentLimitPerNode
+	clusterLimit := setting.BackupConcurrentLimitPerCluster
+	if nodeLimit <= 0 && clusterLimit <= 0 {
+		return true, nil
+	}
+
+	cm, err := boc.ds.GetConfigMap(types.BackupSlotsConfigMapName)
+	if err != nil {
+		return false, err
+	}
+	exists := cm != nil
+	if !exists {
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: types.BackupSlotsConfigMapName,
+			},
+		}
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+
+	running := 0
+	runningOnNode := 0
+	for boName, slot := range cm.Data {
+		slotNodeID, reservedAt := parseBackupSlot(slot)
+		if boName == name || boc.isBackupSlotStale(boName, reservedAt) {
+			delete(cm.Data, boName)
+			continue
+		}
+		running++
+		if slotNodeID == nodeID {
+			runningOnNode++
+		}
+	}
+	if (nodeLimit > 0 && runningOnNode >= nodeLimit) || (clusterLimit > 0 && running >= clusterLimit) {
+		return false, nil
+	}
+
+	cm.Data[name] = nodeID + "," + util.Now()
+	if !exists {
+		_, err = boc.ds.CreateConfigMap(cm)
+	} else {
+		_, err = boc.ds.UpdateConfigMap(cm)
+	}
+	if err != nil {
+		// another manager took a slot meanwhile, check again later
+		if apierrors.IsConflict(errors.Cause(err)) || apierrors.IsAlreadyExists(errors.Cause(err)) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func parseBackupSlot(slot string) (nodeID, reservedAt string) {
+	parts := strings.SplitN(slot, ",", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// isBackupSlotStale returns true if the backup holding the slot finished or
+// never started
+func (boc *BackupOperationController) isBackupSlotStale(name, reservedAt string) bool {
+	bo, err := boc.ds.GetBackupOperation(name)
+	if err != nil {
+		logrus.Warnf("Cannot get backup operation %v holding the backup slot: %v", name, err)
+		return false
+	}
+	if bo != nil && bo.Status.State == types.BackupOperationStateInProgress {
+		return false
+	}
+	if bo != nil && bo.Status.IsFinished() {
+		return true
+	}
+	if _, err := util.ParseTime(reservedAt); err != nil {
+		return true
+	}
+	return util.TimestampAfterTimeout(reservedAt, backupSlotGracePeriod)
+}
+
+// releaseBackupSlot drops the reservation of the backup. The reservation
+// left behind on failure doesn't count once the backup finished.
+func (boc *BackupOperationController) releaseBackupSlot(name string) {
+	cm, err := boc.ds.GetConfigMap(types.BackupSlotsConfigMapName)
+	if err != nil {
+		logrus.Warnf("Failed to release backup slot of %v: %v", name, err)
+		return
+	}
+	if cm == nil {
+		return
+	}
+	if _, ok := cm.Data[name]; !ok {
+		return
+	}
+	delete(cm.Data, name)
+	if _, err := boc.ds.UpdateConfigMap(cm); err != nil {
+		logrus.Warnf("Failed to release backup slot of %v: %v", name, err)
+	}
+}
+
+func (boc *BackupOperationController) syncBackupProgress(bo *longhorn.BackupOperation) error {
+	boc.lock.Lock()
+	r := boc.running[bo.Name]
+	var result runningBackup
+	if r != nil {
+		result = *r
+	}
+	boc.lock.Unlock()
+
+	if r == nil {
+		// the manager restarted while the backup was running
+		return boc.finishBackupOperation(bo, types.BackupOperationStateError, "backup was interrupted")
+	}
+
+	if result.done {
+		var err error
+		switch {
+		case result.err == nil:
+			bo.Status.Progress = 100
+			bo.Status.BackupURL = result.backupURL
+			err = boc.finishBackupOperation(bo, types.BackupOperationStateCompleted, "")
+			if err == nil {
+				boc.requestBackupTargetSync(bo.Spec.BackupTarget)
+			}
+		case result.cancelled:
+			err = boc.finishBackupOperation(bo, types.BackupOperationStateCancelled, "")
+		default:
+			err = boc.finishBackupOperation(bo, types.BackupOperationStateError, result.err.Error())
+			if err == nil {
+				boc.recordVolumeEvent(bo.Spec.VolumeName, v1.EventTypeWarning, types.EventReasonFailedBackup,
+					"Failed to back up snapshot %v of volume %v: %v", bo.Spec.SnapshotName, bo.Spec.VolumeName, result.err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		boc.lock.Lock()
+		delete(boc.running, bo.Name)
+		boc.lock.Unlock()
+		return nil
+	}
+
+	if bo.Spec.Cancel && !result.cancelled {
+		if err := result.engine.SnapshotBackupCancel(result.snapshotName); err != nil {
+			logrus.Warnf("Failed to cancel backup %v: %v", bo.Name, err)
+		} else {
+			boc.lock.Lock()
+			r.cancelled = true
+			boc.lock.Unlock()
+		}
+	}
+
+	progress, err := result.engine.SnapshotBackupStatus(bo.Spec.SnapshotName)
+	if engineapi.IsUnsupportedByEngine(err) {
+		// the progress is unknown, the BackupOperation is enqueued once
+		// the backup finished in the engine
+		return nil
+	}
+	boc.enqueueBackupOperationAfter(bo, BackupOperationPollInterval)
+	if err != nil {
+		logrus.Warnf("Failed to get progress of backup %v: %v", bo.Name, err)
+		return nil
+	}
+	if progress.Progress == bo.Status.Progress && progress.BytesTransferred == bo.Status.BytesTransferred {
+		return nil
+	}
+	bo.Status.Progress = progress.Progress
+	bo.Status.BytesTransferred = progress.BytesTransferred
+	_, err = boc.ds.UpdateBackupOperation(bo)
+	return err
+}
+
+// isOwnerGone returns true if the owner of the BackupOperation cannot run
+// or finish the backup anymore: the volume moved to this manager, or the
+// owner node is down
+func (boc *BackupOperationController) isOwnerGone(bo *longhorn.BackupOperation, v *longhorn.Volume) (bool, error) {
+	if v != nil && v.Spec.OwnerID == boc.controllerID {
+		return true, nil
+	}
+	node, err := boc.ds.GetNode(bo.Spec.OwnerID)
+	if err != nil {
+		return false, err
+	}
+	return node == nil || node.Status.State == types.NodeStateDown, nil
+}
+
+// takeOverBackupOperation fails the backup running in the gone owner since
+// it's lost with the owner's engine process. The pending backup is left to
+// the manager owning the volume.
+func (boc *BackupOperationController) takeOverBackupOperation(bo *longhorn.BackupOperation, v *longhorn.Volume) error {
+	previousOwnerID := bo.Spec.OwnerID
+	if bo.Status.State == types.BackupOperationStatePending &&
+		(v == nil || v.Spec.OwnerID != boc.controllerID) {
+		bo.Spec.OwnerID = ""
+	} else {
+		bo.Spec.OwnerID = boc.controllerID
+	}
+	if bo.Status.State == types.BackupOperationStateInProgress {
+		bo.Status.State = types.BackupOperationStateError
+		bo.Status.Error = fmt.Sprintf("backup was interrupted, manager %v is gone", previousOwnerID)
+		bo.Status.EndTime = util.Now()
+	}
+	if _, err := boc.ds.UpdateBackupOperation(bo); err != nil {
+		// we don't mind others coming first
+		if apierrors.IsConflict(errors.Cause(err)) {
+			return nil
+		}
+		return err
+	}
+	if bo.Status.IsFinished() {
+		boc.releaseBackupSlot(bo.Name)
+	}
+	logrus.Debugf("Backup Operation Controller %v took over %v from %v", boc.controllerID, bo.Name, previousOwnerID)
+	return nil
+}
+
+// forgetFinishedBackup drops the record of the backup once it finished in the
+// engine, after the BackupOperation is deleted or taken over
+func (boc *BackupOperationController) forgetFinishedBackup(name string) {
+	boc.lock.Lock()
+	defer boc.lock.Unlock()
+	if r := boc.running[name]; r != nil && r.done {
+		delete(boc.running, name)
+	}
+}
+
+// stopDeletedBackup cancels the backup still running for the deleted
+// BackupOperation
+func (boc *BackupOperationController) stopDeletedBackup(name string) {
+	boc.lock.Lock()
+	r := boc.running[name]
+	if r == nil {
+		boc.lock.Unlock()
+		return
+	}
+	if r.done {
+		delete(boc.running, name)
+		boc.lock.Unlock()
+		return
+	}
+	cancelled := r.cancelled
+	r.cancelled = true
+	boc.lock.Unlock()
+
+	if cancelled {
+		return
+	}
+	if err := r.engine.SnapshotBackupCancel(r.snapshotName); err != nil {
+		logrus.Warnf("Failed to cancel backup %v: %v", name, err)
+	}
+}
+
+func (boc *BackupOperationController) finishBackupOperation(bo *longhorn.BackupOperation, state types.BackupOperationState, errMsg string) error {
+	bo.Status.State = state
+	bo.Status.Error = errMsg
+	bo.Status.EndTime = util.Now()
+	if _, err := boc.ds.UpdateBackupOperation(bo); err != nil {
+		return err
+	}
+	boc.releaseBackupSlot(bo.Name)
+	logrus.Debugf("Backup %v of volume %v snapshot %v finished as %v", bo.Name, bo.Spec.VolumeName, bo.Spec.SnapshotName, state)
+	return nil
+}
+
+// cleanupBackupOperation deletes the BackupOperation finished longer than
+// BackupOperationRetention ago
+func (boc *BackupOperationController) cleanupBackupOperation(bo *longhorn.BackupOperation) error {
+	if endTime, err := util.ParseTime(bo.Status.EndTime); err == nil {
+		if remaining := endTime.Add(types.BackupOperationRetention).Sub(time.Now()); remaining > 0 {
+			boc.enqueueBackupOperationAfter(bo, remaining)
+			return nil
+		}
+	}
+	if err := boc.ds.DeleteBackupOperation(bo.Name); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// requestBackupTargetSync refreshes the BackupVolumes and Backups after the
+// backup is created
+func (boc *BackupOperationController) requestBackupTargetSync(backupTargetName string) {
+	if backupTargetName == "" {
+		backupTargetName = types.DefaultBackupTargetName
+	}
+	bt, err := boc.ds.GetBackupTarget(backupTargetName)
+	if err != nil || bt == nil {
+		logrus.Warnf("Cannot find backup target %v to sync: %v", backupTargetName, err)
+		return
+	}
+	bt.Spec.SyncRequestedAt = util.Now()
+	if _, err := boc.ds.UpdateBackupTarget(bt); err != nil {
+		logrus.Warnf("Failed to request syncing backup target %v: %v", backupTargetName, err)
+	}
+}
+
+func (boc *BackupOperationController) recordVolumeEvent(volumeName, eventType, reason, messageFmt string, args ...interface{}) {
+	v, err := boc.ds.GetVolume(volumeName)
+	if err != nil || v == nil {
+		logrus.Warnf("Cannot find volume %v to record event %v: %v", volumeName, reason, err)
+		return
+	}
+	boc.eventRecorder.Eventf(v, eventType, reason, messageFmt, args...)
+}
+
+func (boc *BackupOperationController) enqueueBackupOperation(bo *longhorn.BackupOperation) {
+	key, err := controller.KeyFunc(bo)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", bo, err))
+		return
+	}
+
+	boc.queue.AddRateLimited(key)
+}
+
+// enqueueBackupOperationsFor enqueues the BackupOperations of the volume, or
+// the ones owned by ownerID if volumeName is empty
+func (boc *BackupOperationController) enqueueBackupOperationsFor(volumeName, ownerID string) {
+	bos, err := boc.ds.ListBackupOperations(volumeName)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't list backup operations: %v", err))
+		return
+	}
+	for _, bo := range bos {
+		if ownerID == "" || bo.Spec.OwnerID == ownerID {
+			boc.enqueueBackupOperation(bo)
+		}
+	}
+}
+
+func (boc *BackupOperationController) enqueueBackupOperationAfter(bo *longhorn.BackupOperation, delay time.Duration) {
+	key, err := controller.KeyFunc(bo)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", bo, err))
+		return
+	}
+
+	boc.queue.AddAfter(key, delay)
+}
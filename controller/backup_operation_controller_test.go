@@ -0,0 +1,540 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+	lhinformerfactory "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestBackupURL = TestBackupTargetURL + "?backup=backup-1&volume=" + TestVolumeName
+)
+
+// fakeBackupEngine runs the backup until the result is sent
+type fakeBackupEngine struct {
+	engineapi.EngineClient
+
+	lock      sync.Mutex
+	progress  engineapi.BackupProgress
+	cancelled bool
+	// unsupported makes the engine too old to report the progress
+	unsupported bool
+	// bandwidthLimits are the limits the backups started with
+	bandwidthLimits []int
+	result          chan error
+}
+
+func (e *fakeBackupEngine) SnapshotBackup(snapName, backupTarget string, labels map[string]string, credential map[string]string, bandwidthLimit int) (string, error) {
+	e.lock.Lock()
+	e.bandwidthLimits = append(e.bandwidthLimits, bandwidthLimit)
+	e.lock.Unlock()
+	if err := <-e.result; err != nil {
+		return "", err
+	}
+	return TestBackupURL, nil
+}
+
+func (e *fakeBackupEngine) SnapshotBackupStatus(snapName string) (*engineapi.BackupProgress, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.unsupported {
+		return nil, engineapi.ErrUnsupportedByEngine
+	}
+	progress := e.progress
+	return &progress, nil
+}
+
+func (e *fakeBackupEngine) SnapshotBackupCancel(snapName string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.cancelled = true
+	e.result <- fmt.Errorf("backup cancelled")
+	return nil
+}
+
+type fakeBackupEngineCollection struct {
+	engine *fakeBackupEngine
+}
+
+func (c *fakeBackupEngineCollection) NewEngineClient(request *engineapi.EngineClientRequest) (engineapi.EngineClient, error) {
+	return c.engine, nil
+}
+
+func newTestBackupOperationController(lhInformerFactory lhinformerfactory.SharedInformerFactory, kubeInformerFactory informers.SharedInformerFactory,
+	lhClient *lhfake.Clientset, kubeClient *fake.Clientset, engines engineapi.EngineClientCollection, controllerID string) *BackupOperationController {
+	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
+	engineInformer := lhInformerFactory.Longhorn().V1alpha1().Engines()
+	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
+	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
+	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
+
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
+	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
+
+	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
+	initSettings(ds)
+
+	boc := NewBackupOperationController(ds, scheme.Scheme, backupOperationInformer, volumeInformer, nodeInformer, kubeClient, engines, TestNamespace, controllerID)
+	fakeRecorder := record.NewFakeRecorder(100)
+	boc.eventRecorder = fakeRecorder
+
+	boc.boStoreSynced = alwaysReady
+	boc.vStoreSynced = alwaysReady
+	boc.nStoreSynced = alwaysReady
+
+	return boc
+}
+
+func newBackupOperation(name, volumeName string) *longhorn.BackupOperation {
+	return &longhorn.BackupOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: TestNamespace,
+		},
+		Spec: types.BackupOperationSpec{
+			VolumeName:   volumeName,
+			SnapshotName: "snapshot-1",
+			BackupTarget: types.DefaultBackupTargetName,
+		},
+		Status: types.BackupOperationStatus{
+			State: types.BackupOperationStatePending,
+		},
+	}
+}
+
+type backupOperationTestEnv struct {
+	boc        *BackupOperationController
+	engine     *fakeBackupEngine
+	lhClient   *lhfake.Clientset
+	kubeClient *fake.Clientset
+	boIndexer  cache.Indexer
+	btIndexer  cache.Indexer
+	vIndexer   cache.Indexer
+	eIndexer   cache.Indexer
+	nIndexer   cache.Indexer
+}
+
+func newBackupOperationTestEnv(c *C) *backupOperationTestEnv {
+	return newBackupOperationTestEnvForManager(c, lhfake.NewSimpleClientset(), fake.NewSimpleClientset(), TestOwnerID1, TestVolumeName)
+}
+
+// newBackupOperationTestEnvForManager creates the controller of the manager
+// owning the volume, the managers sharing the clients have separate caches
+func newBackupOperationTestEnvForManager(c *C, lhClient *lhfake.Clientset, kubeClient *fake.Clientset,
+	controllerID, volumeName string) *backupOperationTestEnv {
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+	vIndexer := lhInformerFactory.Longhorn().V1alpha1().Volumes().Informer().GetIndexer()
+	eIndexer := lhInformerFactory.Longhorn().V1alpha1().Engines().Informer().GetIndexer()
+
+	engine := &fakeBackupEngine{
+		result: make(chan error, 1),
+	}
+	env := &backupOperationTestEnv{
+		boc:        newTestBackupOperationController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient, &fakeBackupEngineCollection{engine}, controllerID),
+		engine:     engine,
+		lhClient:   lhClient,
+		kubeClient: kubeClient,
+		boIndexer:  lhInformerFactory.Longhorn().V1alpha1().BackupOperations().Informer().GetIndexer(),
+		btIndexer:  lhInformerFactory.Longhorn().V1alpha1().BackupTargets().Informer().GetIndexer(),
+		vIndexer:   vIndexer,
+		eIndexer:   eIndexer,
+		nIndexer:   lhInformerFactory.Longhorn().V1alpha1().Nodes().Informer().GetIndexer(),
+	}
+
+	setting, err := env.boc.ds.GetSetting()
+	c.Assert(err, IsNil)
+	setting.BackupTarget = TestBackupTargetURL
+	_, err = env.boc.ds.UpdateSetting(setting)
+	c.Assert(err, IsNil)
+
+	v := newVolume(volumeName, 2)
+	v.Namespace = TestNamespace
+	v.Spec.OwnerID = controllerID
+	v.Spec.NodeID = controllerID
+	err = vIndexer.Add(v)
+	c.Assert(err, IsNil)
+	e := newEngineForVolume(v)
+	e.Namespace = TestNamespace
+	e.Spec.NodeID = v.Spec.NodeID
+	e.Status.CurrentState = types.InstanceStateRunning
+	e.Status.CurrentImage = TestEngineImage
+	e.Status.IP = randomIP()
+	err = eIndexer.Add(e)
+	c.Assert(err, IsNil)
+
+	return env
+}
+
+func (env *backupOperationTestEnv) create(bo *longhorn.BackupOperation, c *C) {
+	bo, err := env.lhClient.LonghornV1alpha1().BackupOperations(TestNamespace).Create(bo)
+	c.Assert(err, IsNil)
+	err = env.boIndexer.Add(bo)
+	c.Assert(err, IsNil)
+}
+
+// sync syncs the BackupOperation and returns the updated one
+func (env *backupOperationTestEnv) sync(name string, c *C) *longhorn.BackupOperation {
+	err := env.boc.syncBackupOperation(TestNamespace + "/" + name)
+	c.Assert(err, IsNil)
+	bo, err := env.lhClient.LonghornV1alpha1().BackupOperations(TestNamespace).Get(name, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	err = env.boIndexer.Update(bo)
+	c.Assert(err, IsNil)
+	return bo
+}
+
+func (env *backupOperationTestEnv) waitForBackupDone(name string, c *C) {
+	for i := 0; i < 100; i++ {
+		env.boc.lock.Lock()
+		done := env.boc.running[name] != nil && env.boc.running[name].done
+		env.boc.lock.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatalf("backup %v is not done", name)
+}
+
+func (s *TestSuite) TestBackupOperationCompleted(c *C) {
+	env := newBackupOperationTestEnv(c)
+
+	bt := &longhorn.BackupTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      types.DefaultBackupTargetName,
+			Namespace: TestNamespace,
+		},
+	}
+	bt, err := env.lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Create(bt)
+	c.Assert(err, IsNil)
+	err = env.btIndexer.Add(bt)
+	c.Assert(err, IsNil)
+
+	env.create(newBackupOperation("bo-1", TestVolumeName), c)
+
+	bo := env.sync("bo-1", c)
+	c.Assert(bo.Spec.OwnerID, Equals, TestOwnerID1)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+	c.Assert(bo.Status.StartTime, Not(Equals), "")
+
+	env.engine.lock.Lock()
+	env.engine.progress = engineapi.BackupProgress{Progress: 50, BytesTransferred: 1024}
+	env.engine.lock.Unlock()
+	bo = env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+	c.Assert(bo.Status.Progress, Equals, 50)
+	c.Assert(bo.Status.BytesTransferred, Equals, int64(1024))
+
+	env.engine.result <- nil
+	env.waitForBackupDone("bo-1", c)
+	bo = env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateCompleted)
+	c.Assert(bo.Status.Progress, Equals, 100)
+	c.Assert(bo.Status.BackupURL, Equals, TestBackupURL)
+	c.Assert(bo.Status.EndTime, Not(Equals), "")
+	c.Assert(env.boc.running, HasLen, 0)
+
+	// the new backup is synced from the target
+	bt, err = env.lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Get(types.DefaultBackupTargetName, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bt.Spec.SyncRequestedAt, Not(Equals), "")
+}
+
+func (s *TestSuite) TestBackupOperationCancelled(c *C) {
+	env := newBackupOperationTestEnv(c)
+
+	env.create(newBackupOperation("bo-1", TestVolumeName), c)
+	bo := env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+
+	bo.Spec.Cancel = true
+	bo, err := env.lhClient.LonghornV1alpha1().BackupOperations(TestNamespace).Update(bo)
+	c.Assert(err, IsNil)
+	err = env.boIndexer.Update(bo)
+	c.Assert(err, IsNil)
+
+	bo = env.sync("bo-1", c)
+	c.Assert(env.engine.cancelled, Equals, true)
+	env.waitForBackupDone("bo-1", c)
+	bo = env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateCancelled)
+	c.Assert(bo.Status.Error, Equals, "")
+
+	// cancelled before started
+	bo = newBackupOperation("bo-2", TestVolumeName)
+	bo.Spec.Cancel = true
+	env.create(bo, c)
+	bo = env.sync("bo-2", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateCancelled)
+	c.Assert(bo.Status.StartTime, Equals, "")
+}
+
+func (s *TestSuite) TestBackupOperationProgressUnsupported(c *C) {
+	env := newBackupOperationTestEnv(c)
+	env.engine.unsupported = true
+
+	env.create(newBackupOperation("bo-1", TestVolumeName), c)
+	bo := env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+
+	// the progress is unknown until the backup is done
+	bo = env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+	c.Assert(bo.Status.Progress, Equals, 0)
+
+	env.engine.result <- nil
+	env.waitForBackupDone("bo-1", c)
+	bo = env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateCompleted)
+	c.Assert(bo.Status.Progress, Equals, 100)
+}
+
+func (s *TestSuite) TestBackupOperationFailed(c *C) {
+	env := newBackupOperationTestEnv(c)
+
+	env.create(newBackupOperation("bo-1", TestVolumeName), c)
+	env.sync("bo-1", c)
+	env.engine.result <- fmt.Errorf("no space left")
+	env.waitForBackupDone("bo-1", c)
+	bo := env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateError)
+	c.Assert(bo.Status.Error, Equals, "no space left")
+	c.Assert(env.boc.eventRecorder.(*record.FakeRecorder).Events, HasLen, 1)
+
+	// the manager restarted while the backup was running
+	bo = newBackupOperation("bo-2", TestVolumeName)
+	bo.Spec.OwnerID = TestOwnerID1
+	bo.Status.State = types.BackupOperationStateInProgress
+	env.create(bo, c)
+	bo = env.sync("bo-2", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateError)
+	c.Assert(bo.Status.Error, Equals, "backup was interrupted")
+
+	// the volume is gone
+	env.create(newBackupOperation("bo-3", "nonexistent"), c)
+	bo = env.sync("bo-3", c)
+	c.Assert(bo.Spec.OwnerID, Equals, "")
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStatePending)
+}
+
+func (s *TestSuite) TestBackupOperationTakeOver(c *C) {
+	env := newBackupOperationTestEnv(c)
+
+	err := env.nIndexer.Add(newNode(TestNode2, TestNamespace, true, types.NodeStateUp))
+	c.Assert(err, IsNil)
+
+	// the volume moved to this manager while the other one was running
+	// the backup
+	bo := newBackupOperation("bo-running", TestVolumeName)
+	bo.Spec.OwnerID = TestOwnerID2
+	bo.Status.State = types.BackupOperationStateInProgress
+	env.create(bo, c)
+	bo = newBackupOperation("bo-pending", TestVolumeName)
+	bo.Spec.OwnerID = TestOwnerID2
+	env.create(bo, c)
+
+	bo = env.sync("bo-running", c)
+	c.Assert(bo.Spec.OwnerID, Equals, TestOwnerID1)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateError)
+	c.Assert(bo.Status.Error, Equals, "backup was interrupted, manager "+TestOwnerID2+" is gone")
+	bo = env.sync("bo-pending", c)
+	c.Assert(bo.Spec.OwnerID, Equals, TestOwnerID1)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStatePending)
+	bo = env.sync("bo-pending", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+	env.engine.result <- nil
+	env.waitForBackupDone("bo-pending", c)
+
+	// the owner node of the other volume goes down
+	v := newVolume("other-volume", 2)
+	v.Namespace = TestNamespace
+	v.Spec.OwnerID = TestOwnerID2
+	err = env.vIndexer.Add(v)
+	c.Assert(err, IsNil)
+	bo = newBackupOperation("bo-other-running", v.Name)
+	bo.Spec.OwnerID = TestOwnerID2
+	bo.Status.State = types.BackupOperationStateInProgress
+	env.create(bo, c)
+	bo = newBackupOperation("bo-other-pending", v.Name)
+	bo.Spec.OwnerID = TestOwnerID2
+	env.create(bo, c)
+
+	bo = env.sync("bo-other-running", c)
+	c.Assert(bo.Spec.OwnerID, Equals, TestOwnerID2)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+
+	err = env.nIndexer.Update(newNode(TestNode2, TestNamespace, true, types.NodeStateDown))
+	c.Assert(err, IsNil)
+	bo = env.sync("bo-other-running", c)
+	c.Assert(bo.Spec.OwnerID, Equals, TestOwnerID1)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateError)
+	// left to the manager owning the volume
+	bo = env.sync("bo-other-pending", c)
+	c.Assert(bo.Spec.OwnerID, Equals, "")
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStatePending)
+}
+
+func (s *TestSuite) TestBackupOperationCleanup(c *C) {
+	env := newBackupOperationTestEnv(c)
+
+	for name, endTime := range map[string]time.Time{
+		"bo-expired": time.Now().Add(-types.BackupOperationRetention - time.Hour),
+		"bo-recent":  time.Now().Add(-time.Hour),
+	} {
+		bo := newBackupOperation(name, TestVolumeName)
+		bo.Spec.OwnerID = TestOwnerID1
+		bo.Status.State = types.BackupOperationStateCompleted
+		bo.Status.EndTime = util.FormatTimeZ(endTime)
+		env.create(bo, c)
+		err := env.boc.syncBackupOperation(TestNamespace + "/" + name)
+		c.Assert(err, IsNil)
+	}
+
+	bos, err := env.lhClient.LonghornV1alpha1().BackupOperations(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bos.Items, HasLen, 1)
+	c.Assert(bos.Items[0].Name, Equals, "bo-recent")
+}
+
+func (s *TestSuite) TestBackupOperationConcurrentLimit(c *C) {
+	env := newBackupOperationTestEnv(c)
+
+	setting, err := env.boc.ds.GetSetting()
+	c.Assert(err, IsNil)
+	setting.BackupConcurrentLimitPerNode = 1
+	setting.BackupBandwidthLimit = 100
+	_, err = env.boc.ds.UpdateSetting(setting)
+	c.Assert(err, IsNil)
+
+	env.create(newBackupOperation("bo-1", TestVolumeName), c)
+	env.create(newBackupOperation("bo-2", TestVolumeName), c)
+	bo := env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+	bo = env.sync("bo-2", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStatePending)
+
+	// the limit of the target overrides the setting
+	bt := &longhorn.BackupTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      types.DefaultBackupTargetName,
+			Namespace: TestNamespace,
+		},
+		Spec: types.BackupTargetSpec{
+			BandwidthLimit: 20,
+		},
+	}
+	bt, err = env.lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Create(bt)
+	c.Assert(err, IsNil)
+	err = env.btIndexer.Add(bt)
+	c.Assert(err, IsNil)
+
+	env.engine.result <- nil
+	env.waitForBackupDone("bo-1", c)
+	bo = env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateCompleted)
+	bo = env.sync("bo-2", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+
+	// the managers share the cluster limit whatever their caches have
+	setting, err = env.boc.ds.GetSetting()
+	c.Assert(err, IsNil)
+	setting.BackupConcurrentLimitPerNode = 0
+	setting.BackupConcurrentLimitPerCluster = 2
+	_, err = env.boc.ds.UpdateSetting(setting)
+	c.Assert(err, IsNil)
+
+	env2 := newBackupOperationTestEnvForManager(c, env.lhClient, env.kubeClient, TestOwnerID2, "other-volume")
+	env2.create(newBackupOperation("bo-other", "other-volume"), c)
+	bo = env2.sync("bo-other", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+	env.create(newBackupOperation("bo-3", TestVolumeName), c)
+	bo = env.sync("bo-3", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStatePending)
+
+	env.engine.result <- nil
+	env.waitForBackupDone("bo-2", c)
+	env.sync("bo-2", c)
+	bo = env.sync("bo-3", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+	bo = env2.sync("bo-other", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+
+	env2.engine.result <- nil
+	env2.waitForBackupDone("bo-other", c)
+	env.engine.result <- nil
+	env.waitForBackupDone("bo-3", c)
+	env.engine.lock.Lock()
+	c.Assert(env.engine.bandwidthLimits, DeepEquals, []int{100, 20, 20})
+	env.engine.lock.Unlock()
+}
+
+func (s *TestSuite) TestBackupOperationConcurrentLimitPerNode(c *C) {
+	env := newBackupOperationTestEnv(c)
+
+	setting, err := env.boc.ds.GetSetting()
+	c.Assert(err, IsNil)
+	setting.BackupConcurrentLimitPerNode = 1
+	_, err = env.boc.ds.UpdateSetting(setting)
+	c.Assert(err, IsNil)
+
+	// the volume owned by the other manager is attached to the same node,
+	// its backup counts on the node of the engine
+	env2 := newBackupOperationTestEnvForManager(c, env.lhClient, env.kubeClient, TestOwnerID2, "other-volume")
+	obj, exists, err := env2.eIndexer.GetByKey(TestNamespace + "/" + types.GetEngineNameForVolume("other-volume"))
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+	e := obj.(*longhorn.Engine)
+	e.Spec.NodeID = TestOwnerID1
+	err = env2.eIndexer.Update(e)
+	c.Assert(err, IsNil)
+
+	env.create(newBackupOperation("bo-1", TestVolumeName), c)
+	bo := env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+	env2.create(newBackupOperation("bo-other", "other-volume"), c)
+	bo = env2.sync("bo-other", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStatePending)
+
+	env.engine.result <- nil
+	env.waitForBackupDone("bo-1", c)
+	bo = env.sync("bo-1", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateCompleted)
+	bo = env2.sync("bo-other", c)
+	c.Assert(bo.Status.State, Equals, types.BackupOperationStateInProgress)
+
+	env2.engine.result <- nil
+	env2.waitForBackupDone("bo-other", c)
+}
@@ -0,0 +1,464 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhinformers "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions/longhorn/v1alpha1"
+)
+
+// BackupTargetController checks the availability of the backup targets by
+// listing the backup volumes in them every poll interval, and syncs the
+// BackupVolumes and Backups of the targets from the listed content. The
+// default target is created if missing, so the status of the target set by
+// the settings can be recorded.
+type BackupTargetController struct {
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the backup target
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds *datastore.DataStore
+
+	btStoreSynced cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+
+	// newBackupStore is replaced in the tests
+	newBackupStore func(url, engineImage string, credential map[string]string) backupStore
+}
+
+// backupStore is the content of the backup target
+type backupStore interface {
+	ListVolumes() ([]*engineapi.BackupVolume, error)
+	List(volumeName string) ([]*engineapi.Backup, error)
+}
+
+func NewBackupTargetController(
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	backupTargetInformer lhinformers.BackupTargetInformer,
+	kubeClient clientset.Interface,
+	namespace string, controllerID string) *BackupTargetController {
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	// TODO: remove the wrapper when every clients have moved to use the clientset.
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	btc := &BackupTargetController{
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, v1.EventSource{Component: "longhorn-backup-target-controller"}),
+
+		ds: ds,
+
+		btStoreSynced: backupTargetInformer.Informer().HasSynced,
+
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "longhorn-backup-target"),
+
+		newBackupStore: newBackupStore,
+	}
+
+	backupTargetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			bt := obj.(*longhorn.BackupTarget)
+			btc.enqueueBackupTarget(bt)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curBT := cur.(*longhorn.BackupTarget)
+			btc.enqueueBackupTarget(curBT)
+		},
+		DeleteFunc: func(obj interface{}) {
+			bt := obj.(*longhorn.BackupTarget)
+			btc.enqueueBackupTarget(bt)
+		},
+	})
+
+	return btc
+}
+
+func newBackupStore(url, engineImage string, credential map[string]string) backupStore {
+	return engineapi.NewBackupTarget(url, engineImage, credential)
+}
+
+func (btc *BackupTargetController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer btc.queue.ShutDown()
+
+	logrus.Infof("Start Longhorn Backup Target controller")
+	defer logrus.Infof("Shutting down Longhorn Backup Target controller")
+
+	if !controller.WaitForCacheSync("longhorn backup targets", stopCh, btc.btStoreSynced) {
+		return
+	}
+
+	// create the default target if missing
+	btc.queue.Add(btc.namespace + "/" + types.DefaultBackupTargetName)
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(btc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (btc *BackupTargetController) worker() {
+	for btc.processNextWorkItem() {
+	}
+}
+
+func (btc *BackupTargetController) processNextWorkItem() bool {
+	key, quit := btc.queue.Get()
+
+	if quit {
+		return false
+	}
+	defer btc.queue.Done(key)
+
+	err := btc.syncBackupTarget(key.(string))
+	btc.handleErr(err, key)
+
+	return true
+}
+
+func (btc *BackupTargetController) handleErr(err error, key interface{}) {
+	if err == nil {
+		btc.queue.Forget(key)
+		return
+	}
+
+	if btc.queue.NumRequeues(key) < maxRetries {
+		logrus.Warnf("Error syncing Longhorn backup target %v: %v", key, err)
+		btc.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	logrus.Warnf("Dropping Longhorn backup target %v out of the queue: %v", key, err)
+	btc.queue.Forget(key)
+}
+
+func (btc *BackupTargetController) syncBackupTarget(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to sync backup target for %v", key)
+	}()
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != btc.namespace {
+		// Not ours, don't do anything
+		return nil
+	}
+
+	bt, err := btc.ds.GetBackupTarget(name)
+	if err != nil {
+		return err
+	}
+	if bt == nil {
+		if name != types.DefaultBackupTargetName {
+			logrus.Infof("Longhorn backup target %v has been deleted", key)
+			return btc.cleanupBackups(name)
+		}
+		// the empty URL follows the settings
+		bt, err = btc.ds.CreateBackupTarget(&longhorn.BackupTarget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: types.DefaultBackupTargetName,
+			},
+			Spec: types.BackupTargetSpec{
+				OwnerID: btc.controllerID,
+			},
+		})
+		if err != nil {
+			// we don't mind others coming first
+			if apierrors.IsAlreadyExists(err) {
+				return nil
+			}
+			return err
+		}
+		logrus.Infof("Created the default backup target following the settings")
+	}
+
+	if bt.Spec.OwnerID == "" {
+		// Claim it
+		bt.Spec.OwnerID = btc.controllerID
+		bt, err = btc.ds.UpdateBackupTarget(bt)
+		if err != nil {
+			// we don't mind others coming first
+			if apierrors.IsConflict(errors.Cause(err)) {
+				return nil
+			}
+			return err
+		}
+		logrus.Debugf("Backup Target Controller %v picked up %v", btc.controllerID, bt.Name)
+	} else if bt.Spec.OwnerID != btc.controllerID {
+		// Not ours
+		return nil
+	}
+
+	if bt.DeletionTimestamp != nil {
+		return nil
+	}
+
+	spec, err := btc.ds.GetBackupTargetSpec(bt.Name)
+	if err != nil {
+		return err
+	}
+	interval := time.Duration(spec.PollInterval) * time.Second
+	if interval == 0 {
+		interval = types.DefaultBackupTargetPollInterval * time.Second
+	}
+	syncRequested := bt.Spec.SyncRequestedAt != bt.Status.LastSyncRequestedAt
+	if !syncRequested && spec.URL == bt.Status.CheckedURL && bt.Status.LastCheckTime != "" {
+		if lastCheck, err := util.ParseTime(bt.Status.LastCheckTime); err == nil {
+			if remaining := lastCheck.Add(interval).Sub(time.Now()); remaining > 0 {
+				btc.queue.AddAfter(key, remaining)
+				return nil
+			}
+		}
+	}
+
+	status, store, volumes := btc.checkBackupTarget(spec)
+	status.LastSyncRequestedAt = bt.Spec.SyncRequestedAt
+	if status.CheckedURL == "" || status.CheckedURL != bt.Status.CheckedURL {
+		// the content is of the previous URL
+		if err := btc.cleanupBackups(bt.Name); err != nil {
+			return err
+		}
+	}
+	if status.Available {
+		syncErr, err := btc.syncBackups(bt.Name, store, volumes)
+		if err != nil {
+			return err
+		}
+		status.LastSyncedAt = status.LastCheckTime
+		if syncErr != nil {
+			status.LastSyncError = syncErr.Error()
+		}
+	}
+	if status.Available {
+		status.LastAvailableTime = status.LastCheckTime
+		if bt.Status.CheckedURL == status.CheckedURL && !bt.Status.Available {
+			btc.eventRecorder.Eventf(bt, v1.EventTypeNormal, types.EventReasonBackupTargetAvailable,
+				"Backup target %v (%v) is available", bt.Name, status.CheckedURL)
+		}
+	} else {
+		if status.CheckedURL == bt.Status.CheckedURL {
+			status.LastAvailableTime = bt.Status.LastAvailableTime
+			status.LastSyncedAt = bt.Status.LastSyncedAt
+			status.LastSyncError = bt.Status.LastSyncError
+		}
+		if status.CheckedURL != "" && (bt.Status.Available || bt.Status.LastCheckTime == "" || status.CheckedURL != bt.Status.CheckedURL) {
+			btc.eventRecorder.Eventf(bt, v1.EventTypeWarning, types.EventReasonBackupTargetUnavailable,
+				"Backup target %v (%v) is unavailable: %v", bt.Name, status.CheckedURL, status.LastError)
+		}
+	}
+	bt.Status = *status
+	if _, err := btc.ds.UpdateBackupTarget(bt); err != nil {
+		return err
+	}
+	btc.queue.AddAfter(key, interval)
+	return nil
+}
+
+// checkBackupTarget lists the backup volumes in the target to see if it's
+// reachable with the credential. The backup store and the listed volumes
+// are returned if it's available.
+func (btc *BackupTargetController) checkBackupTarget(spec *types.BackupTargetSpec) (*types.BackupTargetStatus, backupStore, []*engineapi.BackupVolume) {
+	status := &types.BackupTargetStatus{
+		CheckedURL:    spec.URL,
+		LastCheckTime: util.Now(),
+	}
+	if spec.URL == "" {
+		status.LastError = "backup target is not set"
+		return status, nil, nil
+	}
+	setting, err := btc.ds.GetSetting()
+	if err != nil {
+		status.LastError = err.Error()
+		return status, nil, nil
+	}
+	if setting.DefaultEngineImage == "" {
+		status.LastError = "default engine image not set"
+		return status, nil, nil
+	}
+	var credential map[string]string
+	if spec.CredentialSecret != "" {
+		if credential, err = btc.ds.GetCredentialFromSecret(spec.CredentialSecret); err != nil {
+			status.LastError = fmt.Sprintf("cannot get credential: %v", err)
+			return status, nil, nil
+		}
+	}
+	store := btc.newBackupStore(spec.URL, setting.DefaultEngineImage, credential)
+	start := time.Now()
+	volumes, err := store.ListVolumes()
+	status.Latency = int64(time.Since(start) / time.Millisecond)
+	if err != nil {
+		status.LastError = err.Error()
+		return status, nil, nil
+	}
+	status.Available = true
+	return status, store, volumes
+}
+
+// syncBackups creates or updates the BackupVolumes and Backups of the backup
+// target by the content listed from the target, and deletes the ones no
+// longer in the target. The ones with the content unchanged are not
+// updated. The volumes whose backups cannot be listed are kept as they are
+// and returned in syncErr, err is the failure to update the content.
+func (btc *BackupTargetController) syncBackups(backupTargetName string, store backupStore, volumes []*engineapi.BackupVolume) (syncErr, err error) {
+	bvs, err := btc.ds.ListBackupVolumes(backupTargetName)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := btc.ds.ListBackups(backupTargetName, "")
+	if err != nil {
+		return nil, err
+	}
+	failures := []string{}
+	for _, volume := range volumes {
+		bvName := types.GetBackupVolumeName(backupTargetName, volume.Name)
+		backups, err := store.List(volume.Name)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("cannot list backups of volume %v: %v", volume.Name, err))
+			delete(bvs, bvName)
+			for name, b := range bs {
+				if b.Spec.VolumeName == volume.Name {
+					delete(bs, name)
+				}
+			}
+			continue
+		}
+		bvStatus := types.BackupVolumeStatus{
+			Size:    volume.Size,
+			Created: volume.Created,
+		}
+		for _, backup := range backups {
+			if backup.Created > bvStatus.LastBackupAt {
+				bvStatus.LastBackupName = backup.Name
+				bvStatus.LastBackupAt = backup.Created
+			}
+			bStatus := types.BackupStatus{
+				URL:             backup.URL,
+				SnapshotName:    backup.SnapshotName,
+				SnapshotCreated: backup.SnapshotCreated,
+				Created:         backup.Created,
+				Size:            backup.Size,
+				Labels:          backup.Labels,
+				VolumeSize:      backup.VolumeSize,
+				VolumeCreated:   backup.VolumeCreated,
+			}
+			name := types.GetBackupName(backupTargetName, volume.Name, backup.Name)
+			if b := bs[name]; b != nil {
+				delete(bs, name)
+				if reflect.DeepEqual(b.Status, bStatus) {
+					continue
+				}
+				b.Status = bStatus
+				if _, err := btc.ds.UpdateBackup(b); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if _, err := btc.ds.CreateBackup(&longhorn.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+				},
+				Spec: types.BackupSpec{
+					BackupTarget: backupTargetName,
+					VolumeName:   volume.Name,
+					BackupName:   backup.Name,
+				},
+				Status: bStatus,
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if bv := bvs[bvName]; bv != nil {
+			delete(bvs, bvName)
+			if reflect.DeepEqual(bv.Status, bvStatus) {
+				continue
+			}
+			bv.Status = bvStatus
+			if _, err := btc.ds.UpdateBackupVolume(bv); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, err := btc.ds.CreateBackupVolume(&longhorn.BackupVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: bvName,
+			},
+			Spec: types.BackupVolumeSpec{
+				BackupTarget: backupTargetName,
+				VolumeName:   volume.Name,
+			},
+			Status: bvStatus,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// gone from the target
+	for name := range bs {
+		if err := btc.ds.DeleteBackup(name); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	for name := range bvs {
+		if err := btc.ds.DeleteBackupVolume(name); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	if len(failures) != 0 {
+		return errors.New(strings.Join(failures, "; ")), nil
+	}
+	return nil, nil
+}
+
+// cleanupBackups deletes the BackupVolumes and Backups of the backup target
+func (btc *BackupTargetController) cleanupBackups(backupTargetName string) error {
+	_, err := btc.syncBackups(backupTargetName, nil, nil)
+	return err
+}
+
+func (btc *BackupTargetController) enqueueBackupTarget(bt *longhorn.BackupTarget) {
+	key, err := controller.KeyFunc(bt)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", bt, err))
+		return
+	}
+
+	btc.queue.AddRateLimited(key)
+}
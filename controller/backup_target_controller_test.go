@@ -0,0 +1,418 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+	lhinformerfactory "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestBackupTargetURL = "nfs://backup:/opt/backupstore"
+)
+
+type BackupTargetTestCase struct {
+	settingURL string
+	url        string
+	status     types.BackupTargetStatus
+	listErr    error
+
+	expectChecked   bool
+	expectAvailable bool
+	expectError     string
+	expectEvent     string
+}
+
+type fakeBackupStore struct {
+	volumes []*engineapi.BackupVolume
+	backups map[string][]*engineapi.Backup
+	listErr error
+	// backupListErrs fails listing the backups of the volumes
+	backupListErrs map[string]error
+}
+
+func (s *fakeBackupStore) ListVolumes() ([]*engineapi.BackupVolume, error) {
+	return s.volumes, s.listErr
+}
+
+func (s *fakeBackupStore) List(volumeName string) ([]*engineapi.Backup, error) {
+	return s.backups[volumeName], s.backupListErrs[volumeName]
+}
+
+func newTestBackupTargetController(lhInformerFactory lhinformerfactory.SharedInformerFactory, kubeInformerFactory informers.SharedInformerFactory,
+	lhClient *lhfake.Clientset, kubeClient *fake.Clientset) *BackupTargetController {
+	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
+	engineInformer := lhInformerFactory.Longhorn().V1alpha1().Engines()
+	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
+	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
+	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
+
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
+	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
+
+	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
+	initSettings(ds)
+
+	btc := NewBackupTargetController(ds, scheme.Scheme, backupTargetInformer, kubeClient, TestNamespace, TestOwnerID1)
+	fakeRecorder := record.NewFakeRecorder(100)
+	btc.eventRecorder = fakeRecorder
+
+	btc.btStoreSynced = alwaysReady
+
+	return btc
+}
+
+func (s *TestSuite) TestSyncBackupTarget(c *C) {
+	var tc *BackupTargetTestCase
+	testCases := map[string]*BackupTargetTestCase{}
+
+	tc = &BackupTargetTestCase{}
+	tc.url = TestBackupTargetURL
+	tc.expectChecked = true
+	tc.expectAvailable = true
+	testCases["first check available"] = tc
+
+	tc = &BackupTargetTestCase{}
+	tc.settingURL = TestBackupTargetURL
+	tc.expectChecked = true
+	tc.expectAvailable = true
+	testCases["default target follows settings"] = tc
+
+	tc = &BackupTargetTestCase{}
+	tc.expectError = "backup target is not set"
+	testCases["backup target not set"] = tc
+
+	tc = &BackupTargetTestCase{}
+	tc.url = TestBackupTargetURL
+	tc.status = types.BackupTargetStatus{
+		CheckedURL:        TestBackupTargetURL,
+		Available:         true,
+		LastCheckTime:     util.FormatTimeZ(time.Now().Add(-2 * types.DefaultBackupTargetPollInterval * time.Second)),
+		LastAvailableTime: util.FormatTimeZ(time.Now().Add(-2 * types.DefaultBackupTargetPollInterval * time.Second)),
+	}
+	tc.listErr = fmt.Errorf("access denied")
+	tc.expectChecked = true
+	tc.expectError = "access denied"
+	tc.expectEvent = types.EventReasonBackupTargetUnavailable
+	testCases["became unavailable"] = tc
+
+	tc = &BackupTargetTestCase{}
+	tc.url = TestBackupTargetURL
+	tc.status = types.BackupTargetStatus{
+		CheckedURL:    TestBackupTargetURL,
+		LastError:     "access denied",
+		LastCheckTime: util.FormatTimeZ(time.Now().Add(-2 * types.DefaultBackupTargetPollInterval * time.Second)),
+	}
+	tc.expectChecked = true
+	tc.expectAvailable = true
+	tc.expectEvent = types.EventReasonBackupTargetAvailable
+	testCases["became available"] = tc
+
+	tc = &BackupTargetTestCase{}
+	tc.url = TestBackupTargetURL
+	tc.status = types.BackupTargetStatus{
+		CheckedURL:        TestBackupTargetURL,
+		Available:         true,
+		LastCheckTime:     util.Now(),
+		LastAvailableTime: util.Now(),
+	}
+	tc.listErr = fmt.Errorf("access denied")
+	tc.expectAvailable = true
+	testCases["within poll interval"] = tc
+
+	tc = &BackupTargetTestCase{}
+	tc.url = TestBackupTargetURL
+	tc.status = types.BackupTargetStatus{
+		CheckedURL:    "nfs://old:/opt/backupstore",
+		Available:     true,
+		LastCheckTime: util.Now(),
+	}
+	tc.listErr = fmt.Errorf("no such host")
+	tc.expectChecked = true
+	tc.expectError = "no such host"
+	tc.expectEvent = types.EventReasonBackupTargetUnavailable
+	testCases["URL changed"] = tc
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+		lhClient := lhfake.NewSimpleClientset()
+		lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+		btIndexer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets().Informer().GetIndexer()
+
+		btc := newTestBackupTargetController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient)
+
+		setting, err := btc.ds.GetSetting()
+		c.Assert(err, IsNil)
+		setting.BackupTarget = tc.settingURL
+		_, err = btc.ds.UpdateSetting(setting)
+		c.Assert(err, IsNil)
+
+		checked := ""
+		btc.newBackupStore = func(url, engineImage string, credential map[string]string) backupStore {
+			checked = url
+			c.Assert(engineImage, Equals, TestEngineImage)
+			return &fakeBackupStore{listErr: tc.listErr}
+		}
+
+		bt := &longhorn.BackupTarget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      types.DefaultBackupTargetName,
+				Namespace: TestNamespace,
+			},
+			Spec: types.BackupTargetSpec{
+				URL:     tc.url,
+				OwnerID: TestOwnerID1,
+			},
+			Status: tc.status,
+		}
+		bt, err = lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Create(bt)
+		c.Assert(err, IsNil)
+		err = btIndexer.Add(bt)
+		c.Assert(err, IsNil)
+
+		err = btc.syncBackupTarget(getKey(bt, c))
+		c.Assert(err, IsNil)
+
+		bt, err = lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Get(types.DefaultBackupTargetName, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		if tc.expectChecked {
+			c.Assert(checked, Equals, TestBackupTargetURL)
+			c.Assert(bt.Status.CheckedURL, Equals, TestBackupTargetURL)
+		} else {
+			c.Assert(checked, Equals, "")
+		}
+		c.Assert(bt.Status.Available, Equals, tc.expectAvailable)
+		c.Assert(bt.Status.LastError, Equals, tc.expectError)
+		if tc.expectAvailable {
+			c.Assert(bt.Status.LastAvailableTime, Not(Equals), "")
+		}
+
+		events := btc.eventRecorder.(*record.FakeRecorder).Events
+		if tc.expectEvent == "" {
+			c.Assert(events, HasLen, 0)
+		} else {
+			c.Assert(events, HasLen, 1)
+			c.Assert(strings.Contains(<-events, tc.expectEvent), Equals, true)
+		}
+	}
+}
+
+func (s *TestSuite) TestSyncBackupTargetCreateDefault(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+	lhClient := lhfake.NewSimpleClientset()
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+	btc := newTestBackupTargetController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient)
+
+	err := btc.syncBackupTarget(TestNamespace + "/" + types.DefaultBackupTargetName)
+	c.Assert(err, IsNil)
+
+	bt, err := lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Get(types.DefaultBackupTargetName, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bt.Spec.URL, Equals, "")
+	c.Assert(bt.Spec.OwnerID, Equals, TestOwnerID1)
+
+	// the other targets are not recreated
+	err = btc.syncBackupTarget(TestNamespace + "/offsite")
+	c.Assert(err, IsNil)
+	bts, err := lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bts.Items, HasLen, 1)
+}
+
+func (s *TestSuite) TestSyncBackupTargetBackups(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+	lhClient := lhfake.NewSimpleClientset()
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+	btIndexer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets().Informer().GetIndexer()
+	bvIndexer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes().Informer().GetIndexer()
+	bIndexer := lhInformerFactory.Longhorn().V1alpha1().Backups().Informer().GetIndexer()
+
+	btc := newTestBackupTargetController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient)
+
+	store := &fakeBackupStore{
+		volumes: []*engineapi.BackupVolume{
+			{Name: TestVolumeName, Size: "1073741824", Created: "2018-01-01T00:00:00Z"},
+		},
+		backups: map[string][]*engineapi.Backup{
+			TestVolumeName: {
+				{Name: "backup-1", URL: TestBackupTargetURL + "?backup=backup-1", SnapshotName: "snap-1", Created: "2018-01-02T00:00:00Z"},
+				{Name: "backup-2", URL: TestBackupTargetURL + "?backup=backup-2", SnapshotName: "snap-2", Created: "2018-01-03T00:00:00Z"},
+			},
+		},
+	}
+	btc.newBackupStore = func(url, engineImage string, credential map[string]string) backupStore {
+		return store
+	}
+
+	// the stale backup of the target is deleted, the one of another
+	// target is kept
+	for _, target := range []string{types.DefaultBackupTargetName, "offsite"} {
+		b := &longhorn.Backup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      types.GetBackupName(target, TestVolumeName, "backup-0"),
+				Namespace: TestNamespace,
+			},
+			Spec: types.BackupSpec{
+				BackupTarget: target,
+				VolumeName:   TestVolumeName,
+				BackupName:   "backup-0",
+			},
+		}
+		b, err := lhClient.LonghornV1alpha1().Backups(TestNamespace).Create(b)
+		c.Assert(err, IsNil)
+		err = bIndexer.Add(b)
+		c.Assert(err, IsNil)
+	}
+
+	now := util.Now()
+	bt := &longhorn.BackupTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      types.DefaultBackupTargetName,
+			Namespace: TestNamespace,
+		},
+		Spec: types.BackupTargetSpec{
+			URL:             TestBackupTargetURL,
+			OwnerID:         TestOwnerID1,
+			SyncRequestedAt: now,
+		},
+		// checked recently, but the sync is requested
+		Status: types.BackupTargetStatus{
+			CheckedURL:        TestBackupTargetURL,
+			Available:         true,
+			LastCheckTime:     now,
+			LastAvailableTime: now,
+		},
+	}
+	bt, err := lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Create(bt)
+	c.Assert(err, IsNil)
+	err = btIndexer.Add(bt)
+	c.Assert(err, IsNil)
+
+	err = btc.syncBackupTarget(getKey(bt, c))
+	c.Assert(err, IsNil)
+
+	bt, err = lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Get(types.DefaultBackupTargetName, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bt.Status.Available, Equals, true)
+	c.Assert(bt.Status.LastSyncRequestedAt, Equals, now)
+	c.Assert(bt.Status.LastSyncedAt, Not(Equals), "")
+
+	bv, err := lhClient.LonghornV1alpha1().BackupVolumes(TestNamespace).Get(types.GetBackupVolumeName(types.DefaultBackupTargetName, TestVolumeName), metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bv.Spec.VolumeName, Equals, TestVolumeName)
+	c.Assert(bv.Status.LastBackupName, Equals, "backup-2")
+	c.Assert(bv.Status.LastBackupAt, Equals, "2018-01-03T00:00:00Z")
+	err = bvIndexer.Add(bv)
+	c.Assert(err, IsNil)
+
+	bs, err := lhClient.LonghornV1alpha1().Backups(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	snapshots := map[string]string{}
+	for _, b := range bs.Items {
+		snapshots[b.Spec.BackupTarget+"/"+b.Spec.BackupName] = b.Status.SnapshotName
+	}
+	c.Assert(snapshots, DeepEquals, map[string]string{
+		types.DefaultBackupTargetName + "/backup-1": "snap-1",
+		types.DefaultBackupTargetName + "/backup-2": "snap-2",
+		"offsite/backup-0":                          "",
+	})
+
+	// the unchanged content is not updated on the following polls
+	for _, obj := range bIndexer.List() {
+		err = bIndexer.Delete(obj)
+		c.Assert(err, IsNil)
+	}
+	for _, b := range bs.Items {
+		err = bIndexer.Add(b.DeepCopy())
+		c.Assert(err, IsNil)
+	}
+	lhClient.ClearActions()
+	bt.Spec.SyncRequestedAt = util.FormatTimeZ(time.Now().Add(time.Minute))
+	err = btIndexer.Update(bt)
+	c.Assert(err, IsNil)
+	err = btc.syncBackupTarget(getKey(bt, c))
+	c.Assert(err, IsNil)
+	c.Assert(lhClient.Actions(), Not(HasLen), 0)
+	for _, action := range lhClient.Actions() {
+		resource := action.GetResource().Resource
+		c.Assert(resource == "backups" || resource == "backupvolumes", Equals, false,
+			Commentf("unexpected %v of %v", action.GetVerb(), resource))
+	}
+
+	// the backups of the volume failed to be listed are kept
+	store.volumes = append(store.volumes, &engineapi.BackupVolume{Name: "other-volume"})
+	store.backupListErrs = map[string]error{TestVolumeName: fmt.Errorf("permission denied")}
+	bt, err = lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Get(types.DefaultBackupTargetName, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	bt.Spec.SyncRequestedAt = util.FormatTimeZ(time.Now().Add(2 * time.Minute))
+	err = btIndexer.Update(bt)
+	c.Assert(err, IsNil)
+	err = btc.syncBackupTarget(getKey(bt, c))
+	c.Assert(err, IsNil)
+	bt, err = lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Get(types.DefaultBackupTargetName, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bt.Status.Available, Equals, true)
+	c.Assert(bt.Status.LastSyncError, Equals, "cannot list backups of volume "+TestVolumeName+": permission denied")
+	bs, err = lhClient.LonghornV1alpha1().Backups(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bs.Items, HasLen, 3)
+	bvs, err := lhClient.LonghornV1alpha1().BackupVolumes(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bvs.Items, HasLen, 2)
+	for _, bv := range bvs.Items {
+		err = bvIndexer.Update(bv.DeepCopy())
+		c.Assert(err, IsNil)
+	}
+	err = btIndexer.Update(bt)
+	c.Assert(err, IsNil)
+
+	// the content is purged once the target is unset
+	bt.Spec.URL = ""
+	err = btIndexer.Update(bt)
+	c.Assert(err, IsNil)
+	err = btc.syncBackupTarget(getKey(bt, c))
+	c.Assert(err, IsNil)
+	bvs, err = lhClient.LonghornV1alpha1().BackupVolumes(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(bvs.Items, HasLen, 0)
+}
@@ -67,22 +67,40 @@ func StartControllers(stopCh chan struct{}, controllerID, serviceAccount, manage
 	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
 	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
 	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
 
 	podInformer := kubeInformerFactory.Core().V1().Pods()
 	jobInformer := kubeInformerFactory.Batch().V1().Jobs()
 	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
 	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
 
 	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
-		podInformer, cronJobInformer, daemonSetInformer, kubeClient, namespace, nodeInformer)
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, namespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
 	rc := NewReplicaController(ds, scheme, replicaInformer, podInformer, jobInformer, kubeClient,
 		namespace, controllerID)
 	ec := NewEngineController(ds, scheme, engineInformer, podInformer, kubeClient,
 		&engineapi.EngineCollection{}, namespace, controllerID)
-	vc := NewVolumeController(ds, scheme, volumeInformer, engineInformer, replicaInformer, kubeClient,
+	sec := NewSnapshotExportController(ds, scheme, snapshotExportInformer, kubeClient, &engineapi.EngineCollection{}, namespace, controllerID)
+	vc := NewVolumeController(ds, scheme, volumeInformer, engineInformer, replicaInformer, recurringJobInformer, kubeClient,
 		namespace, controllerID, serviceAccount, managerImage)
 	ic := NewEngineImageController(ds, scheme, engineImageInformer, volumeInformer, daemonSetInformer, kubeClient, namespace, controllerID)
-	nc := NewNodeController(ds, scheme, nodeInformer, podInformer, kubeClient, namespace)
+	nc := NewNodeController(ds, scheme, nodeInformer, podInformer, kubeClient, namespace, controllerID)
+	bic := NewBackingImageController(ds, scheme, backingImageInformer, volumeInformer, replicaInformer, daemonSetInformer, podInformer, kubeClient, namespace, controllerID)
+	oc := NewOrphanController(ds, scheme, orphanInformer, replicaInformer, kubeClient, namespace, controllerID)
+	notc := NewNotificationController(ds, eventInformer, kubeClient, namespace, controllerID)
+	rjc := NewRecurringJobController(ds, recurringJobInformer, volumeInformer, kubeClient, namespace, controllerID)
+	btc := NewBackupTargetController(ds, scheme, backupTargetInformer, kubeClient, namespace, controllerID)
+	boc := NewBackupOperationController(ds, scheme, backupOperationInformer, volumeInformer, nodeInformer, kubeClient,
+		&engineapi.EngineCollection{}, namespace, controllerID)
 
 	go kubeInformerFactory.Start(stopCh)
 	go lhInformerFactory.Start(stopCh)
@@ -94,6 +112,13 @@ func StartControllers(stopCh chan struct{}, controllerID, serviceAccount, manage
 	go vc.Run(Workers, stopCh)
 	go ic.Run(Workers, stopCh)
 	go nc.Run(Workers, stopCh)
+	go bic.Run(Workers, stopCh)
+	go oc.Run(Workers, stopCh)
+	go notc.Run(Workers, stopCh)
+	go rjc.Run(Workers, stopCh)
+	go btc.Run(Workers, stopCh)
+	go boc.Run(Workers, stopCh)
+	go sec.Run(Workers, stopCh)
 
 	return ds, nil
 }
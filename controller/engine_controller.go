@@ -29,7 +29,9 @@ import (
 
 	"github.com/rancher/longhorn-manager/datastore"
 	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/metrics"
 	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
 
 	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
 	lhinformers "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions/longhorn/v1alpha1"
@@ -47,6 +49,9 @@ var (
 
 	EnginePollInterval = 5 * time.Second
 	EnginePollTimeout  = 30 * time.Second
+
+	// listing snapshots is more expensive than listing replicas
+	EngineActualSizeUpdateInterval = time.Minute
 )
 
 type EngineController struct {
@@ -85,6 +90,8 @@ type EngineMonitor struct {
 	controllerID string
 	// used to notify the controller that monitoring has stopped
 	monitoringRemoveCh chan string
+
+	actualSizeUpdatedAt time.Time
 }
 
 func NewEngineController(
@@ -311,7 +318,19 @@ func (ec *EngineController) CreatePodSpec(obj interface{}) (*v1.Pod, error) {
 		return nil, err
 	}
 
-	if e.Spec.Frontend == types.VolumeFrontendBlockDev {
+	if e.Spec.DisableFrontend {
+		// no device to check, the controller is ready once it serves
+		port, err := strconv.Atoi(engineapi.ControllerDefaultPort)
+		if err != nil {
+			return nil, fmt.Errorf("BUG: Invalid controller default port %v", engineapi.ControllerDefaultPort)
+		}
+		readinessHandler = v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{
+				Path: "/v1/",
+				Port: intstr.FromInt(port),
+			},
+		}
+	} else if e.Spec.Frontend == types.VolumeFrontendBlockDev {
 		frontend = EngineFrontendBlockDev
 		readinessHandler = v1.Handler{
 			Exec: &v1.ExecAction{
@@ -341,9 +360,11 @@ func (ec *EngineController) CreatePodSpec(obj interface{}) (*v1.Pod, error) {
 		"--launcher-listen", "0.0.0.0:" + engineapi.EngineLauncherDefaultPort,
 		"--longhorn-binary", types.DefaultEngineBinaryPath,
 		"--listen", "0.0.0.0:" + engineapi.ControllerDefaultPort,
-		"--frontend", frontend,
 		"--size", strconv.FormatInt(e.Spec.VolumeSize, 10),
 	}
+	if frontend != "" {
+		cmd = append(cmd, "--frontend", frontend)
+	}
 	for _, ip := range e.Spec.ReplicaAddressMap {
 		url := engineapi.GetReplicaDefaultURL(ip)
 		cmd = append(cmd, "--replica", url)
@@ -572,6 +593,8 @@ func (m *EngineMonitor) stop(e *longhorn.Engine) {
 	if e != nil {
 		e.Status.Endpoint = ""
 		e.Status.ReplicaModeMap = nil
+		e.Status.RebuildStatus = nil
+		metrics.DeleteVolumeMetrics(e.Spec.VolumeName, e.Spec.NodeID, e.Status.CurrentImage)
 		if _, err := m.ds.UpdateEngine(e); err != nil {
 			utilruntime.HandleError(errors.Wrapf(err, "failed to update engine %v to stop monitoring", m.Name))
 			// better luck next time
@@ -614,25 +637,160 @@ func (m *EngineMonitor) refresh(engine *longhorn.Engine) error {
 			if r.Mode != engine.Status.ReplicaModeMap[replica] {
 				switch r.Mode {
 				case types.ReplicaModeERR:
-					m.eventRecorder.Eventf(engine, v1.EventTypeWarning, EventReasonFaulted, "Detected replica %v (%v) faulted", replica, ip)
+					m.eventRecorder.Eventf(engine, v1.EventTypeWarning, types.EventReasonFaulted, "Detected replica %v (%v) faulted", replica, ip)
 				case types.ReplicaModeWO:
-					m.eventRecorder.Eventf(engine, v1.EventTypeNormal, EventReasonRebuilding, "Start rebuilding replica %v (%v)", replica, ip)
+					m.eventRecorder.Eventf(engine, v1.EventTypeNormal, types.EventReasonRebuilding, "Start rebuilding replica %v (%v)", replica, ip)
+					metrics.ReplicaRebuildTotal.WithLabelValues(engine.Spec.VolumeName, engine.Spec.NodeID, engine.Status.CurrentImage).Inc()
 				case types.ReplicaModeRW:
-					m.eventRecorder.Eventf(engine, v1.EventTypeNormal, EventReasonRebuilded, "Replica %v (%v) has been rebuilded", replica, ip)
+					m.eventRecorder.Eventf(engine, v1.EventTypeNormal, types.EventReasonRebuilded, "Replica %v (%v) has been rebuilded", replica, ip)
 				default:
 					logrus.Errorf("Invalid engine replica mode %v", r.Mode)
 				}
 			}
 		}
 	}
-	if !reflect.DeepEqual(engine.Status.ReplicaModeMap, currentReplicaModeMap) {
+
+	currentRebuildStatus, err := m.getRebuildStatus(engine, client, addressReplicaMap, currentReplicaModeMap)
+	if err != nil {
+		return err
+	}
+
+	currentActualSize := engine.Status.ActualSize
+	if time.Since(m.actualSizeUpdatedAt) > EngineActualSizeUpdateInterval {
+		if actualSize, err := m.getActualSize(engine, client); err != nil {
+			logrus.Warnf("Failed to get actual size of engine %v: %v", engine.Name, err)
+		} else {
+			currentActualSize = actualSize
+			m.actualSizeUpdatedAt = time.Now()
+		}
+	}
+
+	if !reflect.DeepEqual(engine.Status.ReplicaModeMap, currentReplicaModeMap) ||
+		!reflect.DeepEqual(engine.Status.RebuildStatus, currentRebuildStatus) ||
+		engine.Status.ActualSize != currentActualSize {
 		engine.Status.ReplicaModeMap = currentReplicaModeMap
+		engine.Status.RebuildStatus = currentRebuildStatus
+		engine.Status.ActualSize = currentActualSize
 		_, err = m.ds.UpdateEngine(engine)
 		return err
 	}
 	return nil
 }
 
+func (m *EngineMonitor) getActualSize(engine *longhorn.Engine, client engineapi.EngineClient) (int64, error) {
+	snapshots, err := client.SnapshotList()
+	if err != nil {
+		return 0, err
+	}
+	actualSize, err := engineapi.GetSnapshotsActualSize(snapshots)
+	if err != nil {
+		return 0, err
+	}
+
+	labels := []string{engine.Spec.VolumeName, engine.Spec.NodeID, engine.Status.CurrentImage}
+	metrics.SnapshotCount.WithLabelValues(labels...).Set(float64(len(snapshots)))
+	metrics.SnapshotActualSizeBytes.WithLabelValues(labels...).Set(float64(actualSize))
+	return actualSize, nil
+}
+
+// getRebuildStatus returns the rebuild progress of the replicas in WO mode,
+// keyed by the replica name
+func (m *EngineMonitor) getRebuildStatus(engine *longhorn.Engine, client engineapi.EngineClient,
+	addressReplicaMap map[string]string, replicaModeMap map[string]types.ReplicaMode) (map[string]types.RebuildStatus, error) {
+
+	if !hasReplicaInMode(replicaModeMap, types.ReplicaModeWO) {
+		return nil, nil
+	}
+	rebuildStatus := map[string]types.RebuildStatus{}
+	for replica, mode := range replicaModeMap {
+		if mode != types.ReplicaModeWO {
+			continue
+		}
+		// keep the last known progress in case the engine cannot tell
+		if old, exists := engine.Status.RebuildStatus[replica]; exists {
+			rebuildStatus[replica] = old
+		}
+	}
+
+	urlStatusMap, err := client.ReplicaRebuildStatus()
+	if engineapi.IsUnsupportedByEngine(err) {
+		// the progress is unknown, the replica modes still tell the rebuild
+		return nil, nil
+	}
+	if err != nil {
+		logrus.Warnf("Failed to get replica rebuild status of engine %v: %v", engine.Name, err)
+		return rebuildStatus, nil
+	}
+	setting, err := m.ds.GetSetting()
+	if err != nil {
+		return nil, err
+	}
+	timeout := setting.ReplicaRebuildStallTimeout
+	if timeout <= 0 {
+		timeout = types.DefaultReplicaRebuildStallTimeout
+	}
+
+	now := time.Now()
+	for url, s := range urlStatusMap {
+		ip := engineapi.GetIPFromURL(url)
+		replica, exists := addressReplicaMap[ip]
+		if !exists || replicaModeMap[replica] != types.ReplicaModeWO {
+			continue
+		}
+		status := types.RebuildStatus{
+			IsRebuilding: s.IsRebuilding,
+			Progress:     s.Progress,
+			Error:        s.Error,
+		}
+		if s.FromReplicaAddress != "" {
+			status.FromReplica = addressReplicaMap[engineapi.GetIPFromURL(s.FromReplicaAddress)]
+		}
+		old := engine.Status.RebuildStatus[replica]
+		if updateRebuildStatus(&old, &status, now, time.Duration(timeout)*time.Minute) {
+			m.eventRecorder.Eventf(engine, v1.EventTypeWarning, types.EventReasonRebuildStalled,
+				"Rebuilding replica %v (%v) made no progress in %v minutes, stuck at %v%%", replica, ip, timeout, status.Progress)
+		}
+		rebuildStatus[replica] = status
+	}
+	return rebuildStatus, nil
+}
+
+// updateRebuildStatus carries over the time of the last progress change from
+// the old status, and flags the rebuild as stalled if the progress wasn't
+// changed within the timeout. It returns true if the rebuild just stalled
+func updateRebuildStatus(old, status *types.RebuildStatus, now time.Time, timeout time.Duration) bool {
+	if old.LastProgressAt == "" || old.Progress != status.Progress {
+		status.LastProgressAt = util.FormatTimeZ(now)
+		status.Stalled = false
+		return false
+	}
+	status.LastProgressAt = old.LastProgressAt
+	status.Stalled = old.Stalled
+	if status.Stalled {
+		return false
+	}
+	lastProgressAt, err := util.ParseTimeZ(old.LastProgressAt)
+	if err != nil {
+		logrus.Warnf("Invalid last rebuild progress time %v: %v", old.LastProgressAt, err)
+		status.LastProgressAt = util.FormatTimeZ(now)
+		return false
+	}
+	if now.Sub(lastProgressAt) > timeout {
+		status.Stalled = true
+		return true
+	}
+	return false
+}
+
+func hasReplicaInMode(replicaModeMap map[string]types.ReplicaMode, mode types.ReplicaMode) bool {
+	for _, m := range replicaModeMap {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
 func (ec *EngineController) ReconcileEngineState(e *longhorn.Engine) error {
 	if err := ec.removeUnknownReplica(e); err != nil {
 		return err
@@ -685,9 +843,9 @@ func (ec *EngineController) removeUnknownReplica(e *longhorn.Engine) error {
 		go func(ip string) {
 			url := engineapi.GetReplicaDefaultURL(ip)
 			if err := client.ReplicaRemove(url); err != nil {
-				ec.eventRecorder.Eventf(e, v1.EventTypeWarning, EventReasonFailedDeleting, "Failed to remove replica IP %v from engine: %v", ip, err)
+				ec.eventRecorder.Eventf(e, v1.EventTypeWarning, types.EventReasonFailedDeleting, "Failed to remove replica IP %v from engine: %v", ip, err)
 			} else {
-				ec.eventRecorder.Eventf(e, v1.EventTypeNormal, EventReasonDelete, "Removed replica IP %v from engine", ip)
+				ec.eventRecorder.Eventf(e, v1.EventTypeNormal, types.EventReasonDelete, "Removed replica IP %v from engine", ip)
 			}
 		}(ip)
 	}
@@ -731,7 +889,7 @@ func (ec *EngineController) startRebuilding(e *longhorn.Engine, replica, ip stri
 		// start rebuild
 		if err := client.ReplicaAdd(replicaURL); err != nil {
 			logrus.Errorf("Failed rebuilding %v of %v: %v", ip, e.Spec.VolumeName, err)
-			ec.eventRecorder.Eventf(e, v1.EventTypeWarning, EventReasonFailedRebuilding, "Failed rebuilding replica with IP %v: %v", ip, err)
+			ec.eventRecorder.Eventf(e, v1.EventTypeWarning, types.EventReasonFailedRebuilding, "Failed rebuilding replica with IP %v: %v", ip, err)
 			// we've sent out event to notify user. we don't want to
 			// automatically handle it because it may cause chain
 			// reaction to create numerous new replicas if we set
@@ -739,7 +897,7 @@ func (ec *EngineController) startRebuilding(e *longhorn.Engine, replica, ip stri
 			// user can decide to delete it then we will try again
 			if err := client.ReplicaRemove(replicaURL); err != nil {
 				logrus.Errorf("Failed to remove rebuilding replica %v of %v due to rebuilding failure: %v", ip, e.Spec.VolumeName, err)
-				ec.eventRecorder.Eventf(e, v1.EventTypeWarning, EventReasonFailedDeleting, "Failed to remove rebuilding replica %v due to rebuilding failure: %v", ip, err)
+				ec.eventRecorder.Eventf(e, v1.EventTypeWarning, types.EventReasonFailedDeleting, "Failed to remove rebuilding replica %v due to rebuilding failure: %v", ip, err)
 			} else {
 				logrus.Errorf("Removed failed rebuilding replica %v of %v", ip, e.Spec.VolumeName)
 			}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestUpdateRebuildStatus(c *C) {
+	now := time.Now()
+	timeout := 30 * time.Minute
+	lastProgressAt := util.FormatTimeZ(now.Add(-time.Hour))
+
+	testCases := map[string]struct {
+		old      types.RebuildStatus
+		progress int
+
+		expectLastProgressAt string
+		expectStalled        bool
+		expectJustStalled    bool
+	}{
+		"rebuild started": {
+			types.RebuildStatus{}, 0,
+			util.FormatTimeZ(now), false, false,
+		},
+		"rebuild in progress": {
+			types.RebuildStatus{Progress: 10, LastProgressAt: lastProgressAt}, 20,
+			util.FormatTimeZ(now), false, false,
+		},
+		"rebuild just stalled": {
+			types.RebuildStatus{Progress: 10, LastProgressAt: lastProgressAt}, 10,
+			lastProgressAt, true, true,
+		},
+		"rebuild still stalled": {
+			types.RebuildStatus{Progress: 10, LastProgressAt: lastProgressAt, Stalled: true}, 10,
+			lastProgressAt, true, false,
+		},
+		"stalled rebuild resumed": {
+			types.RebuildStatus{Progress: 10, LastProgressAt: lastProgressAt, Stalled: true}, 11,
+			util.FormatTimeZ(now), false, false,
+		},
+		"rebuild not stalled yet": {
+			types.RebuildStatus{Progress: 10, LastProgressAt: util.FormatTimeZ(now.Add(-time.Minute))}, 10,
+			util.FormatTimeZ(now.Add(-time.Minute)), false, false,
+		},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		status := &types.RebuildStatus{
+			IsRebuilding: true,
+			Progress:     tc.progress,
+		}
+		justStalled := updateRebuildStatus(&tc.old, status, now, timeout)
+		c.Assert(justStalled, Equals, tc.expectJustStalled)
+		c.Assert(status.Stalled, Equals, tc.expectStalled)
+		c.Assert(status.LastProgressAt, Equals, tc.expectLastProgressAt)
+		c.Assert(status.Progress, Equals, tc.progress)
+	}
+}
@@ -207,10 +207,10 @@ func (h *InstanceHandler) getPodLogs(podName string, taillines int) (string, err
 func (h *InstanceHandler) createPodForObject(obj runtime.Object, pod *v1.Pod) (*v1.Pod, error) {
 	p, err := h.kubeClient.CoreV1().Pods(h.namespace).Create(pod)
 	if err != nil {
-		h.eventRecorder.Eventf(obj, v1.EventTypeWarning, EventReasonFailedStarting, "Error starting %v: %v", pod.Name, err)
+		h.eventRecorder.Eventf(obj, v1.EventTypeWarning, types.EventReasonFailedStarting, "Error starting %v: %v", pod.Name, err)
 		return nil, err
 	}
-	h.eventRecorder.Eventf(obj, v1.EventTypeNormal, EventReasonStart, "Starts %v", pod.Name)
+	h.eventRecorder.Eventf(obj, v1.EventTypeNormal, types.EventReasonStart, "Starts %v", pod.Name)
 	return p, nil
 }
 
@@ -221,10 +221,10 @@ func (h *InstanceHandler) deletePodForObject(obj runtime.Object) error {
 	}
 
 	if err := h.kubeClient.CoreV1().Pods(h.namespace).Delete(podName, nil); err != nil {
-		h.eventRecorder.Eventf(obj, v1.EventTypeWarning, EventReasonFailedStopping, "Error stopping %v: %v", podName, err)
+		h.eventRecorder.Eventf(obj, v1.EventTypeWarning, types.EventReasonFailedStopping, "Error stopping %v: %v", podName, err)
 		return nil
 	}
-	h.eventRecorder.Eventf(obj, v1.EventTypeNormal, EventReasonStop, "Stops %v", podName)
+	h.eventRecorder.Eventf(obj, v1.EventTypeNormal, types.EventReasonStop, "Stops %v", podName)
 	return nil
 }
 
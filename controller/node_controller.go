@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -22,6 +23,7 @@ import (
 
 	"github.com/rancher/longhorn-manager/datastore"
 	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
 
 	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
 	lhinformers "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions/longhorn/v1alpha1"
@@ -29,11 +31,15 @@ import (
 
 var (
 	ownerKindNode = longhorn.SchemeGroupVersion.WithKind("Node").String()
+
+	NodeStorageUpdateInterval = time.Minute
 )
 
 type NodeController struct {
 	// which namespace controller is running with
 	namespace string
+	// the storage usage of this node is reported by the controller
+	controllerID string
 
 	kubeClient    clientset.Interface
 	eventRecorder record.EventRecorder
@@ -44,6 +50,8 @@ type NodeController struct {
 	pStoreSynced cache.InformerSynced
 
 	queue workqueue.RateLimitingInterface
+
+	replicaDirectory string
 }
 
 func NewNodeController(
@@ -52,7 +60,7 @@ func NewNodeController(
 	nodeInformer lhinformers.NodeInformer,
 	podInformer coreinformers.PodInformer,
 	kubeClient clientset.Interface,
-	namespace string) *NodeController {
+	namespace string, controllerID string) *NodeController {
 
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartLogging(logrus.Infof)
@@ -60,7 +68,8 @@ func NewNodeController(
 	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
 
 	nc := &NodeController{
-		namespace: namespace,
+		namespace:    namespace,
+		controllerID: controllerID,
 
 		kubeClient:    kubeClient,
 		eventRecorder: eventBroadcaster.NewRecorder(scheme, v1.EventSource{Component: "longhorn-node-controller"}),
@@ -71,6 +80,8 @@ func NewNodeController(
 		pStoreSynced: podInformer.Informer().HasSynced,
 
 		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "longhorn-node"),
+
+		replicaDirectory: types.ReplicaDirectoryOnHost,
 	}
 
 	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -105,6 +116,7 @@ func (nc *NodeController) Run(workers int, stopCh <-chan struct{}) {
 	for i := 0; i < workers; i++ {
 		go wait.Until(nc.worker, time.Second, stopCh)
 	}
+	go wait.Until(nc.updateStorageUsage, NodeStorageUpdateInterval, stopCh)
 
 	<-stopCh
 }
@@ -172,9 +184,18 @@ func (nc *NodeController) syncNode(key string) (err error) {
 		return nc.ds.RemoveFinalizerForNode(node)
 	}
 
+	oldState := node.Status.State
 	defer func() {
 		if err == nil {
 			_, err = nc.ds.UpdateNode(node)
+			// only the manager which updated the state reports it
+			if err == nil && oldState != "" && oldState != node.Status.State {
+				if node.Status.State == types.NodeStateDown {
+					nc.eventRecorder.Eventf(node, v1.EventTypeWarning, types.EventReasonNodeDown, "Node %v is down", node.Name)
+				} else {
+					nc.eventRecorder.Eventf(node, v1.EventTypeNormal, types.EventReasonNodeUp, "Node %v is up", node.Name)
+				}
+			}
 		}
 		// ignore if it's conflict
 		if apierrors.IsConflict(errors.Cause(err)) {
@@ -220,3 +241,62 @@ func (nc *NodeController) syncStatusWithPod(pod *v1.Pod, node *longhorn.Node) er
 
 	return nil
 }
+
+func (nc *NodeController) updateStorageUsage() {
+	if err := nc.syncNodeStorage(); err != nil {
+		logrus.Warnf("Failed to update storage usage of node %v: %v", nc.controllerID, err)
+	}
+}
+
+// syncNodeStorage reports the disk space used by the replicas on the current
+// node and the space of the filesystem holding the replica directory. The
+// replica sizes are the actual sizes collected by the engine monitors
+func (nc *NodeController) syncNodeStorage() error {
+	node, err := nc.ds.GetNode(nc.controllerID)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return nil
+	}
+
+	volumes, err := nc.ds.ListVolumes()
+	if err != nil {
+		return err
+	}
+	replicas, err := nc.ds.ListReplicasRO()
+	if err != nil {
+		return err
+	}
+	replicaSizeMap := map[string]int64{}
+	storageUsed := int64(0)
+	for _, r := range replicas {
+		if r.Spec.NodeID != nc.controllerID {
+			continue
+		}
+		v, exists := volumes[r.Spec.VolumeName]
+		// the actual size hasn't been collected yet
+		if !exists || v.Status.ActualSize == 0 {
+			continue
+		}
+		replicaSizeMap[r.Name] = v.Status.ActualSize
+		storageUsed += v.Status.ActualSize
+	}
+
+	status := node.Status
+	status.ReplicaSizeMap = replicaSizeMap
+	status.StorageUsed = storageUsed
+	if maximum, available, err := util.GetDiskStat(nc.replicaDirectory); err != nil {
+		logrus.Warnf("Failed to get disk stat of node %v: %v", nc.controllerID, err)
+	} else {
+		status.StorageMaximum = maximum
+		status.StorageAvailable = available
+	}
+
+	if reflect.DeepEqual(node.Status, status) {
+		return nil
+	}
+	node.Status = status
+	_, err = nc.ds.UpdateNode(node)
+	return err
+}
@@ -2,6 +2,8 @@ package controller
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,15 +37,25 @@ func newTestNodeController(lhInformerFactory lhinformerfactory.SharedInformerFac
 	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
 	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
 	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
 
 	podInformer := kubeInformerFactory.Core().V1().Pods()
 	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
 	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
 
 	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
-		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer)
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
 
-	nc := NewNodeController(ds, scheme.Scheme, nodeInformer, podInformer, kubeClient, TestNamespace)
+	nc := NewNodeController(ds, scheme.Scheme, nodeInformer, podInformer, kubeClient, TestNamespace, TestNode1)
 	fakeRecorder := record.NewFakeRecorder(100)
 	nc.eventRecorder = fakeRecorder
 
@@ -159,3 +171,81 @@ func (s *TestSuite) TestSyncNode(c *C) {
 
 	}
 }
+
+func (s *TestSuite) TestSyncNodeStorage(c *C) {
+	replicaDirectory, err := ioutil.TempDir("", "node-storage-test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(replicaDirectory)
+
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+	lhClient := lhfake.NewSimpleClientset()
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+	nIndexer := lhInformerFactory.Longhorn().V1alpha1().Nodes().Informer().GetIndexer()
+	rIndexer := lhInformerFactory.Longhorn().V1alpha1().Replicas().Informer().GetIndexer()
+	vIndexer := lhInformerFactory.Longhorn().V1alpha1().Volumes().Informer().GetIndexer()
+
+	nc := newTestNodeController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient)
+	nc.replicaDirectory = replicaDirectory
+
+	for _, name := range []string{TestNode1, TestNode2} {
+		n := newNode(name, TestNamespace, true, types.NodeStateUp)
+		if name == TestNode2 {
+			// reported by the other node
+			n.Status.StorageMaximum = 4096000
+			n.Status.StorageAvailable = 2048000
+			n.Status.StorageUsed = 1024000
+		}
+		n, err := lhClient.Longhorn().Nodes(TestNamespace).Create(n)
+		c.Assert(err, IsNil)
+		nIndexer.Add(n)
+	}
+
+	// volume with the actual size collected by the engine monitor
+	v1 := newVolume("vol1", 2)
+	v1.Status.ActualSize = 8192
+	// volume without the actual size collected yet
+	v2 := newVolume("vol2", 2)
+	for _, v := range []*longhorn.Volume{v1, v2} {
+		v.Namespace = TestNamespace
+		c.Assert(vIndexer.Add(v), IsNil)
+	}
+
+	// replica on the current node
+	r1 := newReplica(types.InstanceStateRunning, types.InstanceStateRunning, "")
+	r1.Name = "r1"
+	r1.Spec.NodeID = TestNode1
+	r1.Spec.VolumeName = v1.Name
+	// replica on the current node without the actual size
+	r2 := newReplica(types.InstanceStateRunning, types.InstanceStateRunning, "")
+	r2.Name = "r2"
+	r2.Spec.NodeID = TestNode1
+	r2.Spec.VolumeName = v2.Name
+	// replica on the other node
+	r3 := newReplica(types.InstanceStateRunning, types.InstanceStateRunning, "")
+	r3.Name = "r3"
+	r3.Spec.NodeID = TestNode2
+	r3.Spec.VolumeName = v1.Name
+	for _, r := range []*longhorn.Replica{r1, r2, r3} {
+		c.Assert(rIndexer.Add(r), IsNil)
+	}
+
+	err = nc.syncNodeStorage()
+	c.Assert(err, IsNil)
+
+	n1, err := lhClient.LonghornV1alpha1().Nodes(TestNamespace).Get(TestNode1, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(n1.Status.State, Equals, types.NodeStateUp)
+	c.Assert(n1.Status.ReplicaSizeMap, HasLen, 1)
+	c.Assert(n1.Status.ReplicaSizeMap["r1"], Equals, int64(8192))
+	c.Assert(n1.Status.StorageUsed, Equals, int64(8192))
+	c.Assert(n1.Status.StorageMaximum > 0, Equals, true)
+	c.Assert(n1.Status.StorageAvailable <= n1.Status.StorageMaximum, Equals, true)
+
+	n2, err := lhClient.LonghornV1alpha1().Nodes(TestNamespace).Get(TestNode2, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(n2.Status.ReplicaSizeMap, IsNil)
+	c.Assert(n2.Status.StorageUsed, Equals, int64(1024000))
+}
@@ -0,0 +1,463 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+)
+
+const (
+	NotificationSignatureHeader = "X-Longhorn-Signature"
+	NotificationDeliveryHeader  = "X-Longhorn-Delivery"
+)
+
+var (
+	// NotificationDedupWindow suppresses the repeated notifications for the
+	// same reason on the same object. Events older than it are ignored.
+	NotificationDedupWindow = 10 * time.Minute
+	// NotificationRetryInterval is the delay before the first retry of the
+	// failed delivery, doubled for every retry up to
+	// NotificationRetryMaxInterval
+	NotificationRetryCount       = 3
+	NotificationRetryInterval    = 5 * time.Second
+	NotificationRetryMaxInterval = time.Minute
+	NotificationTimeout          = 10 * time.Second
+	// NotificationClaimTimeout is how long the claim of an event lasts
+	// without the claiming manager trying to deliver it, another manager
+	// takes the event over then
+	NotificationClaimTimeout = 5 * time.Minute
+
+	// notifiedByAnnotation is the manager claimed the event count in
+	// claimedCountAnnotation at claimedAtAnnotation, notifiedCountAnnotation
+	// is the event count which has been sent. notifiedWebhooksAnnotation
+	// records the webhooks which got the claimed count, so the retry skips
+	// them.
+	notifiedByAnnotation       = longhornFinalizerKey + "/notified-by"
+	claimedCountAnnotation     = longhornFinalizerKey + "/claimed-count"
+	claimedAtAnnotation        = longhornFinalizerKey + "/claimed-at"
+	notifiedCountAnnotation    = longhornFinalizerKey + "/notified-count"
+	notifiedWebhooksAnnotation = longhornFinalizerKey + "/notified-webhooks"
+)
+
+// Notification is the payload posted to the webhooks
+type Notification struct {
+	Reason         string `json:"reason"`
+	Type           string `json:"type"`
+	Message        string `json:"message"`
+	Kind           string `json:"kind"`
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	Count          int32  `json:"count"`
+	FirstTimestamp string `json:"firstTimestamp"`
+	LastTimestamp  string `json:"lastTimestamp"`
+	Source         string `json:"source"`
+	ReportedBy     string `json:"reportedBy"`
+}
+
+// NotificationController posts the events emitted by Longhorn to the
+// webhooks configured in the settings. Every manager watches all the events,
+// the one claiming the event by annotating it sends the notification. The
+// notifications sent recently are recorded in a ConfigMap shared by the
+// managers to suppress the repeated ones. The failed deliveries are retried
+// through the queue with backoff, only to the webhooks which didn't get the
+// event yet.
+type NotificationController struct {
+	// which namespace controller is running with
+	namespace    string
+	controllerID string
+
+	kubeClient clientset.Interface
+
+	ds *datastore.DataStore
+
+	evStoreSynced cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+
+	httpClient *http.Client
+}
+
+func NewNotificationController(
+	ds *datastore.DataStore,
+	eventInformer coreinformers.EventInformer,
+	kubeClient clientset.Interface,
+	namespace string, controllerID string) *NotificationController {
+
+	nc := &NotificationController{
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		kubeClient: kubeClient,
+
+		ds: ds,
+
+		evStoreSynced: eventInformer.Informer().HasSynced,
+
+		queue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.NewItemExponentialFailureRateLimiter(NotificationRetryInterval, NotificationRetryMaxInterval), "longhorn-notification"),
+
+		httpClient: &http.Client{Timeout: NotificationTimeout},
+	}
+
+	eventInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			e := obj.(*v1.Event)
+			nc.enqueueEvent(e)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldE := old.(*v1.Event)
+			curE := cur.(*v1.Event)
+			// the annotations updated by the managers don't count, the
+			// failed delivery is retried after the backoff
+			if oldE.Count == curE.Count {
+				return
+			}
+			nc.enqueueEvent(curE)
+		},
+	})
+
+	return nc
+}
+
+func (nc *NotificationController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer nc.queue.ShutDown()
+
+	logrus.Infof("Start Longhorn Notification controller")
+	defer logrus.Infof("Shutting down Longhorn Notification controller")
+
+	if !controller.WaitForCacheSync("longhorn notifications", stopCh, nc.evStoreSynced) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(nc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (nc *NotificationController) worker() {
+	for nc.processNextWorkItem() {
+	}
+}
+
+func (nc *NotificationController) processNextWorkItem() bool {
+	key, quit := nc.queue.Get()
+
+	if quit {
+		return false
+	}
+	defer nc.queue.Done(key)
+
+	err := nc.syncEvent(key.(string))
+	nc.handleErr(err, key)
+
+	return true
+}
+
+func (nc *NotificationController) handleErr(err error, key interface{}) {
+	if err == nil {
+		nc.queue.Forget(key)
+		return
+	}
+
+	if nc.queue.NumRequeues(key) < NotificationRetryCount {
+		logrus.Warnf("Error syncing notification for event %v: %v", key, err)
+		nc.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	logrus.Warnf("Dropping notification for event %v out of the queue: %v", key, err)
+	nc.queue.Forget(key)
+}
+
+func (nc *NotificationController) syncEvent(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to sync notification for event %v", key)
+	}()
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != nc.namespace {
+		// Not ours, don't do anything
+		return nil
+	}
+
+	event, err := nc.ds.GetEvent(name)
+	if err != nil {
+		return err
+	}
+	if event == nil || !strings.HasPrefix(event.Source.Component, "longhorn") {
+		return nil
+	}
+	if notifiedCount, err := strconv.Atoi(event.Annotations[notifiedCountAnnotation]); err == nil && int32(notifiedCount) >= event.Count {
+		return nil
+	}
+	if time.Since(event.LastTimestamp.Time) > NotificationDedupWindow {
+		return nil
+	}
+
+	setting, err := nc.ds.GetSetting()
+	if err != nil {
+		return err
+	}
+	webhooks := util.SplitStringToMap(setting.NotificationWebhookURLs, ",")
+	if len(webhooks) == 0 {
+		return nil
+	}
+	if _, selected := util.SplitStringToMap(setting.NotificationEventReasons, ",")[event.Reason]; !selected {
+		return nil
+	}
+
+	// Claim it, unless another manager is sending it
+	if event.Annotations == nil {
+		event.Annotations = map[string]string{}
+	}
+	claimedCount, err := strconv.Atoi(event.Annotations[claimedCountAnnotation])
+	claimed := err == nil && int32(claimedCount) >= event.Count
+	if claimed && event.Annotations[notifiedByAnnotation] != nc.controllerID {
+		if remaining := nc.claimRemaining(event); remaining > 0 {
+			// check again in case the claiming manager is gone
+			nc.queue.AddAfter(key, remaining)
+			return nil
+		}
+		logrus.Infof("Taking over the notification for event %v from %v", key, event.Annotations[notifiedByAnnotation])
+	}
+	if !claimed {
+		// a new count is sent to all the webhooks
+		delete(event.Annotations, notifiedWebhooksAnnotation)
+	}
+	event.Annotations[notifiedByAnnotation] = nc.controllerID
+	event.Annotations[claimedCountAnnotation] = strconv.Itoa(int(event.Count))
+	event.Annotations[claimedAtAnnotation] = util.Now()
+	if event, err = nc.ds.UpdateEvent(event); err != nil {
+		// we don't mind others coming first
+		if apierrors.IsConflict(errors.Cause(err)) {
+			return nil
+		}
+		return err
+	}
+
+	delivery := fmt.Sprintf("%v-%v", event.UID, event.Count)
+	notify, err := nc.reserveNotification(event, delivery)
+	if err != nil {
+		return err
+	}
+	if notify {
+		notified := util.SplitStringToMap(event.Annotations[notifiedWebhooksAnnotation], ",")
+		failed, err := nc.sendNotification(event, delivery, webhooks, notified, setting.NotificationSecret)
+		if err != nil {
+			return err
+		}
+		if len(failed) != 0 {
+			// record the webhooks which got it, the event is retried
+			// for the failed ones only
+			event.Annotations[notifiedWebhooksAnnotation] = joinStringSet(notified)
+			if _, err := nc.ds.UpdateEvent(event); err != nil {
+				return err
+			}
+			return fmt.Errorf("failed to send notification to %v webhook(s)", len(failed))
+		}
+	} else {
+		logrus.Debugf("Skip the repeated notification for event %v", key)
+	}
+
+	delete(event.Annotations, notifiedWebhooksAnnotation)
+	event.Annotations[notifiedCountAnnotation] = strconv.Itoa(int(event.Count))
+	_, err = nc.ds.UpdateEvent(event)
+	return err
+}
+
+// claimRemaining returns how long the claim of the event by another manager
+// lasts, 0 if it has expired
+func (nc *NotificationController) claimRemaining(event *v1.Event) time.Duration {
+	claimedAt, err := util.ParseTime(event.Annotations[claimedAtAnnotation])
+	if err != nil {
+		return 0
+	}
+	if remaining := claimedAt.Add(NotificationClaimTimeout).Sub(time.Now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// sendNotification posts the event to the webhooks not in notified yet, the
+// webhooks got it are added to notified. The failed ones are returned after
+// trying the rest, the event will be retried for them then.
+func (nc *NotificationController) sendNotification(event *v1.Event, delivery string, webhooks, notified map[string]struct{}, secretName string) (failed []string, err error) {
+	var signingKey []byte
+	if secretName != "" {
+		if signingKey, err = nc.ds.GetNotificationKeyFromSecret(secretName); err != nil {
+			return nil, err
+		}
+	}
+	payload, err := json.Marshal(nc.newNotification(event))
+	if err != nil {
+		return nil, err
+	}
+	for webhook := range webhooks {
+		id := getWebhookID(webhook)
+		if _, ok := notified[id]; ok {
+			continue
+		}
+		if err := nc.post(webhook, delivery, payload, signingKey); err != nil {
+			logrus.Warnf("Failed to send notification for event %v to webhook %v: %v", event.Name, id, err)
+			failed = append(failed, webhook)
+			continue
+		}
+		notified[id] = struct{}{}
+	}
+	return failed, nil
+}
+
+// getWebhookID returns the checksum identifying the webhook in the
+// annotation of the event, the URL may contain the token of the receiver
+func getWebhookID(webhook string) string {
+	return util.GetStringChecksum(webhook)[:types.NotificationWebhookIDLength]
+}
+
+func joinStringSet(set map[string]struct{}) string {
+	list := []string{}
+	for s := range set {
+		list = append(list, s)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
+
+// reserveNotification records the delivery in the ConfigMap shared by the
+// managers. It returns false if a notification for the same reason on the
+// same object has been sent within NotificationDedupWindow, unless it's the
+// same delivery being retried.
+func (nc *NotificationController) reserveNotification(event *v1.Event, delivery string) (bool, error) {
+	cm, err := nc.ds.GetConfigMap(types.NotificationDedupConfigMapName)
+	if err != nil {
+		return false, err
+	}
+	exists := cm != nil
+	if !exists {
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: types.NotificationDedupConfigMapName,
+			},
+		}
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+
+	for key, entry := range cm.Data {
+		notifiedAt, _ := parseNotifiedEntry(entry)
+		if _, err := util.ParseTime(notifiedAt); err != nil || util.TimestampAfterTimeout(notifiedAt, NotificationDedupWindow) {
+			delete(cm.Data, key)
+		}
+	}
+	key := fmt.Sprintf("%v.%v.%v", event.InvolvedObject.Kind, event.Reason, event.InvolvedObject.Name)
+	if entry, ok := cm.Data[key]; ok {
+		_, notifiedDelivery := parseNotifiedEntry(entry)
+		return notifiedDelivery == delivery, nil
+	}
+
+	cm.Data[key] = util.Now() + "," + delivery
+	if !exists {
+		_, err = nc.ds.CreateConfigMap(cm)
+	} else {
+		_, err = nc.ds.UpdateConfigMap(cm)
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to record notification")
+	}
+	return true, nil
+}
+
+func parseNotifiedEntry(entry string) (notifiedAt, delivery string) {
+	parts := strings.SplitN(entry, ",", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+func (nc *NotificationController) newNotification(event *v1.Event) *Notification {
+	return &Notification{
+		Reason:         event.Reason,
+		Type:           event.Type,
+		Message:        event.Message,
+		Kind:           event.InvolvedObject.Kind,
+		Name:           event.InvolvedObject.Name,
+		Namespace:      event.Namespace,
+		Count:          event.Count,
+		FirstTimestamp: util.FormatTimeZ(event.FirstTimestamp.Time),
+		LastTimestamp:  util.FormatTimeZ(event.LastTimestamp.Time),
+		Source:         event.Source.Component,
+		ReportedBy:     nc.controllerID,
+	}
+}
+
+func (nc *NotificationController) post(webhook, delivery string, payload, signingKey []byte) error {
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(NotificationDeliveryHeader, delivery)
+	if len(signingKey) != 0 {
+		req.Header.Set(NotificationSignatureHeader, "sha256="+SignNotification(signingKey, payload))
+	}
+	resp, err := nc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %v", resp.Status)
+	}
+	return nil
+}
+
+// SignNotification returns the hex encoded HMAC-SHA256 of the payload, which
+// is sent in NotificationSignatureHeader
+func SignNotification(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (nc *NotificationController) enqueueEvent(e *v1.Event) {
+	key, err := controller.KeyFunc(e)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", e, err))
+		return
+	}
+
+	nc.queue.Add(key)
+}
@@ -0,0 +1,364 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+	lhinformerfactory "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestNotificationSecret = "notification-secret"
+	TestNotificationKey    = "test-hmac-key"
+)
+
+type NotificationTestCase struct {
+	event   *v1.Event
+	reasons string
+	// notified is recorded in the ConfigMap shared by the managers
+	notified map[string]string
+	// failedDeliveries is the number of syncs failing to deliver before
+	// the webhook accepts the notification
+	failedDeliveries int
+
+	expectNotified bool
+	expectClaimed  bool
+}
+
+type receivedNotification struct {
+	signature string
+	payload   []byte
+}
+
+func newTestNotificationController(lhInformerFactory lhinformerfactory.SharedInformerFactory, kubeInformerFactory informers.SharedInformerFactory,
+	lhClient *lhfake.Clientset, kubeClient *fake.Clientset) *NotificationController {
+	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
+	engineInformer := lhInformerFactory.Longhorn().V1alpha1().Engines()
+	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
+	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
+	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
+
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
+	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
+
+	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
+	initSettings(ds)
+
+	nc := NewNotificationController(ds, eventInformer, kubeClient, TestNamespace, TestNode1)
+	nc.evStoreSynced = alwaysReady
+
+	return nc
+}
+
+func newEvent(name, reason, component string, count int32) *v1.Event {
+	now := metav1.Now()
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: TestNamespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:      "Volume",
+			Namespace: TestNamespace,
+			Name:      TestVolumeName,
+		},
+		Reason:         reason,
+		Message:        "test message",
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: component},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          count,
+	}
+}
+
+func (s *TestSuite) TestSyncEventNotification(c *C) {
+	var tc *NotificationTestCase
+	testCases := map[string]*NotificationTestCase{}
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-faulted", types.EventReasonFaulted, "longhorn-volume-controller", 1)
+	tc.reasons = types.DefaultNotificationEventReasons
+	tc.expectNotified = true
+	tc.expectClaimed = true
+	testCases["notify selected event"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-attached", types.EventReasonAttached, "longhorn-volume-controller", 1)
+	tc.reasons = types.DefaultNotificationEventReasons
+	testCases["skip unselected event"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-other", types.EventReasonFaulted, "kubelet", 1)
+	tc.reasons = types.DefaultNotificationEventReasons
+	testCases["skip event not from longhorn"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-notified", types.EventReasonFaulted, "longhorn-volume-controller", 2)
+	tc.event.Annotations = map[string]string{
+		notifiedByAnnotation:    TestNode2,
+		notifiedCountAnnotation: "2",
+	}
+	tc.reasons = types.DefaultNotificationEventReasons
+	testCases["skip notified event"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-old", types.EventReasonFaulted, "longhorn-volume-controller", 1)
+	tc.event.LastTimestamp = metav1.NewTime(time.Now().Add(-2 * NotificationDedupWindow))
+	tc.reasons = types.DefaultNotificationEventReasons
+	testCases["skip old event"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-claimed", types.EventReasonFaulted, "longhorn-volume-controller", 2)
+	tc.event.Annotations = map[string]string{
+		notifiedByAnnotation:    TestNode2,
+		claimedCountAnnotation:  "2",
+		claimedAtAnnotation:     util.Now(),
+		notifiedCountAnnotation: "1",
+	}
+	tc.reasons = types.DefaultNotificationEventReasons
+	testCases["skip event claimed by another manager"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-claim-expired", types.EventReasonFaulted, "longhorn-volume-controller", 2)
+	tc.event.Annotations = map[string]string{
+		notifiedByAnnotation:    TestNode2,
+		claimedCountAnnotation:  "2",
+		claimedAtAnnotation:     util.FormatTimeZ(time.Now().Add(-2 * NotificationClaimTimeout)),
+		notifiedCountAnnotation: "1",
+	}
+	tc.reasons = types.DefaultNotificationEventReasons
+	tc.expectNotified = true
+	tc.expectClaimed = true
+	testCases["take over expired claim"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-repeated", types.EventReasonFaulted, "longhorn-volume-controller", 3)
+	tc.event.Annotations = map[string]string{
+		notifiedByAnnotation:    TestNode2,
+		claimedCountAnnotation:  "2",
+		notifiedCountAnnotation: "2",
+	}
+	tc.reasons = types.DefaultNotificationEventReasons
+	tc.notified = map[string]string{
+		"Volume." + types.EventReasonFaulted + "." + TestVolumeName: util.FormatTimeZ(time.Now().Add(-time.Minute)) + ",-2",
+	}
+	tc.expectClaimed = true
+	testCases["skip repeated event"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-expired", types.EventReasonFaulted, "longhorn-volume-controller", 1)
+	tc.reasons = types.DefaultNotificationEventReasons
+	tc.notified = map[string]string{
+		"Volume." + types.EventReasonFaulted + "." + TestVolumeName: util.FormatTimeZ(time.Now().Add(-2*NotificationDedupWindow)) + ",-1",
+	}
+	tc.expectNotified = true
+	tc.expectClaimed = true
+	testCases["notify after dedup window"] = tc
+
+	tc = &NotificationTestCase{}
+	tc.event = newEvent("event-retried", types.EventReasonFaulted, "longhorn-volume-controller", 1)
+	tc.reasons = types.DefaultNotificationEventReasons
+	tc.failedDeliveries = 1
+	tc.expectNotified = true
+	tc.expectClaimed = true
+	testCases["retry failed delivery"] = tc
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		received := []receivedNotification{}
+		failing := tc.failedDeliveries > 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			payload, err := ioutil.ReadAll(req.Body)
+			c.Assert(err, IsNil)
+			if failing {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			received = append(received, receivedNotification{
+				signature: req.Header.Get(NotificationSignatureHeader),
+				payload:   payload,
+			})
+		}))
+
+		kubeClient := fake.NewSimpleClientset()
+		kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+		lhClient := lhfake.NewSimpleClientset()
+		lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+		evIndexer := kubeInformerFactory.Core().V1().Events().Informer().GetIndexer()
+
+		nc := newTestNotificationController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient)
+		if tc.notified != nil {
+			_, err := kubeClient.CoreV1().ConfigMaps(TestNamespace).Create(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: types.NotificationDedupConfigMapName,
+				},
+				Data: tc.notified,
+			})
+			c.Assert(err, IsNil)
+		}
+
+		setting, err := nc.ds.GetSetting()
+		c.Assert(err, IsNil)
+		setting.NotificationWebhookURLs = server.URL
+		setting.NotificationEventReasons = tc.reasons
+		setting.NotificationSecret = TestNotificationSecret
+		_, err = nc.ds.UpdateSetting(setting)
+		c.Assert(err, IsNil)
+
+		_, err = kubeClient.CoreV1().Secrets(TestNamespace).Create(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: TestNotificationSecret,
+			},
+			Data: map[string][]byte{
+				types.NotificationHMACKey: []byte(TestNotificationKey),
+			},
+		})
+		c.Assert(err, IsNil)
+
+		event, err := kubeClient.CoreV1().Events(TestNamespace).Create(tc.event)
+		c.Assert(err, IsNil)
+		err = evIndexer.Add(event)
+		c.Assert(err, IsNil)
+
+		for i := 0; i < tc.failedDeliveries; i++ {
+			err = nc.syncEvent(getKey(event, c))
+			c.Assert(err, NotNil)
+			// the event is claimed but not marked as notified
+			event, err = kubeClient.CoreV1().Events(TestNamespace).Get(tc.event.Name, metav1.GetOptions{})
+			c.Assert(err, IsNil)
+			c.Assert(event.Annotations[notifiedByAnnotation], Equals, TestNode1)
+			c.Assert(event.Annotations[claimedCountAnnotation], Equals, fmt.Sprint(tc.event.Count))
+			c.Assert(event.Annotations[notifiedCountAnnotation], Equals, "")
+			err = evIndexer.Update(event)
+			c.Assert(err, IsNil)
+		}
+		failing = false
+
+		err = nc.syncEvent(getKey(event, c))
+		c.Assert(err, IsNil)
+		server.Close()
+
+		if tc.expectNotified {
+			c.Assert(received, HasLen, 1)
+			c.Assert(received[0].signature, Equals, "sha256="+SignNotification([]byte(TestNotificationKey), received[0].payload))
+			notification := &Notification{}
+			err = json.Unmarshal(received[0].payload, notification)
+			c.Assert(err, IsNil)
+			c.Assert(notification.Reason, Equals, tc.event.Reason)
+			c.Assert(notification.Name, Equals, TestVolumeName)
+			c.Assert(notification.ReportedBy, Equals, TestNode1)
+
+			// recorded for the other managers to suppress the repeated ones
+			cm, err := kubeClient.CoreV1().ConfigMaps(TestNamespace).Get(types.NotificationDedupConfigMapName, metav1.GetOptions{})
+			c.Assert(err, IsNil)
+			_, delivery := parseNotifiedEntry(cm.Data["Volume."+tc.event.Reason+"."+TestVolumeName])
+			c.Assert(delivery, Equals, fmt.Sprintf("-%v", tc.event.Count))
+		} else {
+			c.Assert(received, HasLen, 0)
+		}
+
+		event, err = kubeClient.CoreV1().Events(TestNamespace).Get(tc.event.Name, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		if tc.expectClaimed {
+			c.Assert(event.Annotations[notifiedByAnnotation], Equals, TestNode1)
+			c.Assert(event.Annotations[claimedCountAnnotation], Equals, fmt.Sprint(tc.event.Count))
+			c.Assert(event.Annotations[claimedAtAnnotation], Not(Equals), "")
+			c.Assert(event.Annotations[notifiedCountAnnotation], Equals, fmt.Sprint(tc.event.Count))
+			c.Assert(event.Annotations[notifiedWebhooksAnnotation], Equals, "")
+		} else {
+			c.Assert(event.Annotations, DeepEquals, tc.event.Annotations)
+		}
+	}
+}
+
+func (s *TestSuite) TestSyncEventNotificationPerWebhook(c *C) {
+	received := map[string]int{}
+	failing := map[string]bool{"failing": true}
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if failing[name] {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			received[name]++
+		}))
+	}
+	working := newServer("working")
+	defer working.Close()
+	failingServer := newServer("failing")
+	defer failingServer.Close()
+
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+	lhClient := lhfake.NewSimpleClientset()
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+	evIndexer := kubeInformerFactory.Core().V1().Events().Informer().GetIndexer()
+
+	nc := newTestNotificationController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient)
+	setting, err := nc.ds.GetSetting()
+	c.Assert(err, IsNil)
+	setting.NotificationWebhookURLs = working.URL + "," + failingServer.URL
+	setting.NotificationEventReasons = types.DefaultNotificationEventReasons
+	_, err = nc.ds.UpdateSetting(setting)
+	c.Assert(err, IsNil)
+
+	event, err := kubeClient.CoreV1().Events(TestNamespace).Create(
+		newEvent("event-faulted", types.EventReasonFaulted, "longhorn-volume-controller", 1))
+	c.Assert(err, IsNil)
+	err = evIndexer.Add(event)
+	c.Assert(err, IsNil)
+
+	// the delivered webhook is recorded
+	err = nc.syncEvent(getKey(event, c))
+	c.Assert(err, NotNil)
+	c.Assert(received, DeepEquals, map[string]int{"working": 1})
+	event, err = kubeClient.CoreV1().Events(TestNamespace).Get(event.Name, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(event.Annotations[notifiedWebhooksAnnotation], Equals, getWebhookID(working.URL))
+	c.Assert(event.Annotations[notifiedCountAnnotation], Equals, "")
+	err = evIndexer.Update(event)
+	c.Assert(err, IsNil)
+
+	// the retry only sends to the failed webhook
+	failing["failing"] = false
+	err = nc.syncEvent(getKey(event, c))
+	c.Assert(err, IsNil)
+	c.Assert(received, DeepEquals, map[string]int{"working": 1, "failing": 1})
+	event, err = kubeClient.CoreV1().Events(TestNamespace).Get(event.Name, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(event.Annotations[notifiedWebhooksAnnotation], Equals, "")
+	c.Assert(event.Annotations[notifiedCountAnnotation], Equals, "1")
+}
@@ -0,0 +1,371 @@
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhinformers "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions/longhorn/v1alpha1"
+)
+
+var (
+	OrphanScanInterval = 5 * time.Minute
+	// the orphaned data is auto deleted only if it hasn't been modified for
+	// a few scans, in case it's being written by a replica just created
+	OrphanAutoDeleteGracePeriod = 3 * OrphanScanInterval
+)
+
+// OrphanController finds the replica data directories on the current node
+// which don't belong to any replica, e.g. the replica was deleted while the
+// node was down so the cleanup job never ran, and records them as orphans
+type OrphanController struct {
+	// which namespace controller is running with
+	namespace string
+	// the orphans on this node are handled by the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds *datastore.DataStore
+
+	oStoreSynced cache.InformerSynced
+	rStoreSynced cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+
+	replicaDirectory string
+}
+
+func NewOrphanController(
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	orphanInformer lhinformers.OrphanInformer,
+	replicaInformer lhinformers.ReplicaInformer,
+	kubeClient clientset.Interface,
+	namespace string, controllerID string) *OrphanController {
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	// TODO: remove the wrapper when every clients have moved to use the clientset.
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	oc := &OrphanController{
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, v1.EventSource{Component: "longhorn-orphan-controller"}),
+
+		ds: ds,
+
+		oStoreSynced: orphanInformer.Informer().HasSynced,
+		rStoreSynced: replicaInformer.Informer().HasSynced,
+
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "longhorn-orphan"),
+
+		replicaDirectory: types.ReplicaDirectoryOnHost,
+	}
+
+	orphanInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			o := obj.(*longhorn.Orphan)
+			oc.enqueueOrphan(o)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curO := cur.(*longhorn.Orphan)
+			oc.enqueueOrphan(curO)
+		},
+		DeleteFunc: func(obj interface{}) {
+			o := obj.(*longhorn.Orphan)
+			oc.enqueueOrphan(o)
+		},
+	})
+
+	return oc
+}
+
+func (oc *OrphanController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer oc.queue.ShutDown()
+
+	logrus.Infof("Start Longhorn Orphan controller")
+	defer logrus.Infof("Shutting down Longhorn Orphan controller")
+
+	if !controller.WaitForCacheSync("longhorn orphans", stopCh, oc.oStoreSynced, oc.rStoreSynced) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(oc.worker, time.Second, stopCh)
+	}
+	go wait.Until(oc.scanOrphans, OrphanScanInterval, stopCh)
+
+	<-stopCh
+}
+
+func (oc *OrphanController) worker() {
+	for oc.processNextWorkItem() {
+	}
+}
+
+func (oc *OrphanController) processNextWorkItem() bool {
+	key, quit := oc.queue.Get()
+
+	if quit {
+		return false
+	}
+	defer oc.queue.Done(key)
+
+	err := oc.syncOrphan(key.(string))
+	oc.handleErr(err, key)
+
+	return true
+}
+
+func (oc *OrphanController) handleErr(err error, key interface{}) {
+	if err == nil {
+		oc.queue.Forget(key)
+		return
+	}
+
+	if oc.queue.NumRequeues(key) < maxRetries {
+		logrus.Warnf("Error syncing Longhorn orphan %v: %v", key, err)
+		oc.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	logrus.Warnf("Dropping Longhorn orphan %v out of the queue: %v", key, err)
+	oc.queue.Forget(key)
+}
+
+func (oc *OrphanController) syncOrphan(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to sync orphan for %v", key)
+	}()
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != oc.namespace {
+		// Not ours, don't do anything
+		return nil
+	}
+
+	orphan, err := oc.ds.GetOrphan(name)
+	if err != nil {
+		return err
+	}
+	if orphan == nil {
+		logrus.Infof("Longhorn orphan %v has been deleted", key)
+		return nil
+	}
+	// the data can only be reached from the node it's on
+	if orphan.Spec.NodeID != oc.controllerID {
+		return nil
+	}
+
+	if orphan.DeletionTimestamp != nil {
+		if err := oc.cleanupOrphanData(orphan); err != nil {
+			oc.eventRecorder.Eventf(orphan, v1.EventTypeWarning, types.EventReasonFailedDeleting, "Cannot delete orphaned data %v: %v", orphan.Spec.DataPath, err)
+			return err
+		}
+		return oc.ds.RemoveFinalizerForOrphan(orphan)
+	}
+	return nil
+}
+
+func (oc *OrphanController) cleanupOrphanData(o *longhorn.Orphan) error {
+	dataPath := filepath.Clean(o.Spec.DataPath)
+	// never touch anything outside of the replica directory
+	if filepath.Dir(dataPath) != filepath.Clean(oc.replicaDirectory) {
+		return fmt.Errorf("invalid orphan data path %v, not in %v", dataPath, oc.replicaDirectory)
+	}
+	if _, err := os.Stat(dataPath); os.IsNotExist(err) {
+		return nil
+	}
+	inUse, err := oc.getDataPathsInUseFromAPI()
+	if err != nil {
+		return err
+	}
+	// the orphan record is stale, keep the data
+	if inUse[dataPath] {
+		logrus.Infof("Orphaned data %v is used by a replica now, skip the cleanup", dataPath)
+		return nil
+	}
+	if err := os.RemoveAll(dataPath); err != nil {
+		return err
+	}
+	logrus.Infof("Cleaned up orphaned data %v on node %v", dataPath, oc.controllerID)
+	oc.eventRecorder.Eventf(o, v1.EventTypeNormal, types.EventReasonDelete, "Deleted orphaned data %v on node %v", dataPath, oc.controllerID)
+	return nil
+}
+
+func (oc *OrphanController) getDataPathsInUse() (map[string]bool, error) {
+	replicas, err := oc.ds.ListReplicasRO()
+	if err != nil {
+		return nil, err
+	}
+	return getReplicaDataPaths(replicas), nil
+}
+
+// getDataPathsInUseFromAPI bypasses the cache before the data is deleted
+func (oc *OrphanController) getDataPathsInUseFromAPI() (map[string]bool, error) {
+	replicas, err := oc.ds.ListReplicasFromAPI()
+	if err != nil {
+		return nil, err
+	}
+	return getReplicaDataPaths(replicas), nil
+}
+
+func getReplicaDataPaths(replicas []*longhorn.Replica) map[string]bool {
+	inUse := map[string]bool{}
+	for _, r := range replicas {
+		if r.Spec.DataPath != "" {
+			inUse[filepath.Clean(r.Spec.DataPath)] = true
+		}
+	}
+	return inUse
+}
+
+func (oc *OrphanController) scanOrphans() {
+	if err := oc.syncOrphansOnNode(); err != nil {
+		logrus.Warnf("Failed to scan orphaned data on node %v: %v", oc.controllerID, err)
+	}
+}
+
+// syncOrphansOnNode records the replica data directories without replica as
+// orphans, and removes the records of the directories which are gone or in
+// use again
+func (oc *OrphanController) syncOrphansOnNode() error {
+	dataPaths := map[string]bool{}
+	files, err := ioutil.ReadDir(oc.replicaDirectory)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "cannot read replica directory %v", oc.replicaDirectory)
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			dataPaths[filepath.Join(oc.replicaDirectory, f.Name())] = true
+		}
+	}
+
+	inUse, err := oc.getDataPathsInUse()
+	if err != nil {
+		return err
+	}
+	orphans, err := oc.ds.ListOrphans()
+	if err != nil {
+		return err
+	}
+	setting, err := oc.ds.GetSetting()
+	if err != nil {
+		return err
+	}
+
+	recorded := map[string]*longhorn.Orphan{}
+	for _, o := range orphans {
+		if o.Spec.NodeID != oc.controllerID || o.DeletionTimestamp != nil {
+			continue
+		}
+		dataPath := filepath.Clean(o.Spec.DataPath)
+		if !dataPaths[dataPath] || inUse[dataPath] {
+			// the cleanup won't touch the data in this case
+			if err := oc.ds.DeleteOrphan(o.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		recorded[dataPath] = o
+	}
+
+	// the replicas listed from the API server, only if there is any data to
+	// auto delete
+	var freshInUse map[string]bool
+	for dataPath := range dataPaths {
+		if inUse[dataPath] {
+			continue
+		}
+		size, lastModified, err := util.GetDirectoryUsage(dataPath)
+		if err != nil {
+			logrus.Warnf("Failed to get usage of orphaned data %v: %v", dataPath, err)
+			continue
+		}
+		status := types.OrphanStatus{
+			Size:         size,
+			LastModified: lastModified.UTC().Format(time.RFC3339),
+		}
+
+		o := recorded[dataPath]
+		if o == nil {
+			o = &longhorn.Orphan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: types.GetOrphanChecksumName(oc.controllerID, dataPath),
+				},
+				Spec: types.OrphanSpec{
+					NodeID:   oc.controllerID,
+					DataPath: dataPath,
+				},
+				Status: status,
+			}
+			if o, err = oc.ds.CreateOrphan(o); err != nil {
+				return errors.Wrapf(err, "cannot record orphaned data %v", dataPath)
+			}
+			logrus.Infof("Found orphaned data %v on node %v, size %v", dataPath, oc.controllerID, size)
+		} else if o.Status != status {
+			o.Status = status
+			if o, err = oc.ds.UpdateOrphan(o); err != nil {
+				return err
+			}
+		}
+
+		if setting.AutoDeleteOrphans && !o.Spec.Keep &&
+			util.TimestampAfterTimeout(o.Status.LastModified, OrphanAutoDeleteGracePeriod) {
+			if freshInUse == nil {
+				if freshInUse, err = oc.getDataPathsInUseFromAPI(); err != nil {
+					return err
+				}
+			}
+			if freshInUse[dataPath] {
+				continue
+			}
+			logrus.Infof("Auto deleting orphaned data %v on node %v", dataPath, oc.controllerID)
+			if err := oc.ds.DeleteOrphan(o.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (oc *OrphanController) enqueueOrphan(o *longhorn.Orphan) {
+	key, err := controller.KeyFunc(o)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", o, err))
+		return
+	}
+
+	oc.queue.AddRateLimited(key)
+}
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+	lhinformerfactory "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions"
+
+	. "gopkg.in/check.v1"
+)
+
+type OrphanTestCase struct {
+	autoDelete bool
+	// directories under the replica directory
+	dataDirs []string
+	// data directories not modified for longer than the grace period
+	staleDirs []string
+	// data directories used by replicas
+	replicaDirs []string
+	// data directories used by replicas not in the cache yet
+	apiReplicaDirs []string
+	// data directories already recorded as orphans
+	orphanDirs []string
+	keptDirs   []string
+
+	expectOrphanDirs []string
+}
+
+func newTestOrphanController(lhInformerFactory lhinformerfactory.SharedInformerFactory, kubeInformerFactory informers.SharedInformerFactory,
+	lhClient *lhfake.Clientset, kubeClient *fake.Clientset, replicaDirectory string) *OrphanController {
+	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
+	engineInformer := lhInformerFactory.Longhorn().V1alpha1().Engines()
+	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
+	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
+	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
+
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
+	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
+
+	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
+	initSettings(ds)
+
+	oc := NewOrphanController(ds, scheme.Scheme, orphanInformer, replicaInformer, kubeClient, TestNamespace, TestNode1)
+	fakeRecorder := record.NewFakeRecorder(100)
+	oc.eventRecorder = fakeRecorder
+	oc.replicaDirectory = replicaDirectory
+
+	oc.oStoreSynced = alwaysReady
+	oc.rStoreSynced = alwaysReady
+
+	return oc
+}
+
+func newOrphan(nodeID, dataPath string, keep bool) *longhorn.Orphan {
+	return &longhorn.Orphan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: types.GetOrphanChecksumName(nodeID, dataPath),
+		},
+		Spec: types.OrphanSpec{
+			NodeID:   nodeID,
+			DataPath: dataPath,
+			Keep:     keep,
+		},
+	}
+}
+
+func (s *TestSuite) TestSyncOrphansOnNode(c *C) {
+	testCases := map[string]*OrphanTestCase{}
+
+	tc := &OrphanTestCase{}
+	tc.dataDirs = []string{"vol1-a", "vol2-b"}
+	tc.replicaDirs = []string{"vol1-a"}
+	tc.expectOrphanDirs = []string{"vol2-b"}
+	testCases["record orphan"] = tc
+
+	tc = &OrphanTestCase{}
+	tc.dataDirs = []string{"vol1-a"}
+	tc.replicaDirs = []string{"vol1-a"}
+	tc.orphanDirs = []string{"vol1-a", "vol2-b"}
+	tc.expectOrphanDirs = []string{}
+	testCases["remove stale orphan records"] = tc
+
+	tc = &OrphanTestCase{}
+	tc.autoDelete = true
+	tc.dataDirs = []string{"vol1-a", "vol2-b", "vol3-c", "vol4-d"}
+	tc.staleDirs = []string{"vol1-a", "vol3-c", "vol4-d"}
+	tc.keptDirs = []string{"vol3-c"}
+	tc.apiReplicaDirs = []string{"vol4-d"}
+	tc.expectOrphanDirs = []string{"vol2-b", "vol3-c", "vol4-d"}
+	testCases["auto delete orphans"] = tc
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		replicaDirectory, err := ioutil.TempDir("", "orphan-test")
+		c.Assert(err, IsNil)
+		defer os.RemoveAll(replicaDirectory)
+
+		kubeClient := fake.NewSimpleClientset()
+		kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+		lhClient := lhfake.NewSimpleClientset()
+		lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+		rIndexer := lhInformerFactory.Longhorn().V1alpha1().Replicas().Informer().GetIndexer()
+		oIndexer := lhInformerFactory.Longhorn().V1alpha1().Orphans().Informer().GetIndexer()
+
+		oc := newTestOrphanController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient, replicaDirectory)
+
+		setting, err := oc.ds.GetSetting()
+		c.Assert(err, IsNil)
+		setting.AutoDeleteOrphans = tc.autoDelete
+		_, err = oc.ds.UpdateSetting(setting)
+		c.Assert(err, IsNil)
+
+		for _, dir := range tc.dataDirs {
+			err := os.MkdirAll(filepath.Join(replicaDirectory, dir), 0700)
+			c.Assert(err, IsNil)
+			err = ioutil.WriteFile(filepath.Join(replicaDirectory, dir, "volume-head-000.img"), []byte("data"), 0600)
+			c.Assert(err, IsNil)
+		}
+		staleTime := time.Now().Add(-2 * OrphanAutoDeleteGracePeriod)
+		for _, dir := range tc.staleDirs {
+			for _, p := range []string{filepath.Join(replicaDirectory, dir, "volume-head-000.img"), filepath.Join(replicaDirectory, dir)} {
+				err := os.Chtimes(p, staleTime, staleTime)
+				c.Assert(err, IsNil)
+			}
+		}
+		for _, dir := range tc.replicaDirs {
+			r := newReplica(types.InstanceStateRunning, types.InstanceStateRunning, "")
+			r.Name = dir
+			r.Spec.DataPath = replicaDirectory + "/" + dir + "/"
+			err := rIndexer.Add(r)
+			c.Assert(err, IsNil)
+		}
+		for _, dir := range tc.apiReplicaDirs {
+			r := newReplica(types.InstanceStateStopped, types.InstanceStateStopped, "")
+			r.Name = dir
+			r.Spec.DataPath = filepath.Join(replicaDirectory, dir)
+			_, err := lhClient.LonghornV1alpha1().Replicas(TestNamespace).Create(r)
+			c.Assert(err, IsNil)
+		}
+		for _, dir := range tc.orphanDirs {
+			o, err := lhClient.LonghornV1alpha1().Orphans(TestNamespace).Create(newOrphan(TestNode1, filepath.Join(replicaDirectory, dir), false))
+			c.Assert(err, IsNil)
+			oIndexer.Add(o)
+		}
+		for _, dir := range tc.keptDirs {
+			o, err := lhClient.LonghornV1alpha1().Orphans(TestNamespace).Create(newOrphan(TestNode1, filepath.Join(replicaDirectory, dir), true))
+			c.Assert(err, IsNil)
+			oIndexer.Add(o)
+		}
+		// orphans on other nodes won't be touched
+		otherOrphan, err := lhClient.LonghornV1alpha1().Orphans(TestNamespace).Create(newOrphan(TestNode2, filepath.Join(replicaDirectory, "vol9-z"), false))
+		c.Assert(err, IsNil)
+		oIndexer.Add(otherOrphan)
+
+		err = oc.syncOrphansOnNode()
+		c.Assert(err, IsNil)
+
+		orphans, err := lhClient.LonghornV1alpha1().Orphans(TestNamespace).List(metav1.ListOptions{})
+		c.Assert(err, IsNil)
+		orphanDirs := map[string]*longhorn.Orphan{}
+		for i := range orphans.Items {
+			o := &orphans.Items[i]
+			if o.Spec.NodeID != TestNode1 {
+				c.Assert(o.Name, Equals, otherOrphan.Name)
+				continue
+			}
+			orphanDirs[filepath.Base(o.Spec.DataPath)] = o
+		}
+		c.Assert(orphanDirs, HasLen, len(tc.expectOrphanDirs))
+		for _, dir := range tc.expectOrphanDirs {
+			o := orphanDirs[dir]
+			c.Assert(o, NotNil)
+			c.Assert(o.Status.Size > 0, Equals, true)
+			c.Assert(o.Status.LastModified, Not(Equals), "")
+		}
+	}
+}
@@ -18,7 +18,7 @@ import (
 const (
 	LonghornProvisionerName = "rancher.io/longhorn"
 	LonghornStorageClass    = "longhorn"
-	LonghornDriver          = "rancher.io/longhorn"
+	LonghornDriver          = types.LonghornFlexVolumeDriver
 )
 
 type Provisioner struct {
@@ -67,7 +67,7 @@ func (p *Provisioner) Provision(opts pvController.VolumeOptions) (*v1.Persistent
 		NumberOfReplicas:    numberOfReplicas,
 		StaleReplicaTimeout: staleReplicaTimeout,
 	}
-	v, err := p.m.Create(opts.PVName, spec)
+	v, err := p.m.Create(opts.PVName, spec, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhinformers "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions/longhorn/v1alpha1"
+)
+
+// RecurringJobController reports the volumes selected by each recurring job.
+// The cron jobs for the selected volumes are created by the volume
+// controller.
+type RecurringJobController struct {
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the recurring job
+	controllerID string
+
+	kubeClient clientset.Interface
+
+	ds *datastore.DataStore
+
+	rjStoreSynced cache.InformerSynced
+	vStoreSynced  cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+}
+
+func NewRecurringJobController(
+	ds *datastore.DataStore,
+	recurringJobInformer lhinformers.RecurringJobInformer,
+	volumeInformer lhinformers.VolumeInformer,
+	kubeClient clientset.Interface,
+	namespace string, controllerID string) *RecurringJobController {
+
+	rjc := &RecurringJobController{
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		kubeClient: kubeClient,
+
+		ds: ds,
+
+		rjStoreSynced: recurringJobInformer.Informer().HasSynced,
+		vStoreSynced:  volumeInformer.Informer().HasSynced,
+
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "longhorn-recurring-job"),
+	}
+
+	recurringJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			rj := obj.(*longhorn.RecurringJob)
+			rjc.enqueueRecurringJob(rj)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curRJ := cur.(*longhorn.RecurringJob)
+			rjc.enqueueRecurringJob(curRJ)
+		},
+		DeleteFunc: func(obj interface{}) {
+			rj := obj.(*longhorn.RecurringJob)
+			rjc.enqueueRecurringJob(rj)
+		},
+	})
+
+	volumeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			rjc.enqueueRecurringJobs()
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldV := old.(*longhorn.Volume)
+			curV := cur.(*longhorn.Volume)
+			// only the labels affect the selection
+			if !reflect.DeepEqual(oldV.Labels, curV.Labels) {
+				rjc.enqueueRecurringJobs()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			rjc.enqueueRecurringJobs()
+		},
+	})
+
+	return rjc
+}
+
+func (rjc *RecurringJobController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer rjc.queue.ShutDown()
+
+	logrus.Infof("Start Longhorn Recurring Job controller")
+	defer logrus.Infof("Shutting down Longhorn Recurring Job controller")
+
+	if !controller.WaitForCacheSync("longhorn recurring jobs", stopCh, rjc.rjStoreSynced, rjc.vStoreSynced) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(rjc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (rjc *RecurringJobController) worker() {
+	for rjc.processNextWorkItem() {
+	}
+}
+
+func (rjc *RecurringJobController) processNextWorkItem() bool {
+	key, quit := rjc.queue.Get()
+
+	if quit {
+		return false
+	}
+	defer rjc.queue.Done(key)
+
+	err := rjc.syncRecurringJob(key.(string))
+	rjc.handleErr(err, key)
+
+	return true
+}
+
+func (rjc *RecurringJobController) handleErr(err error, key interface{}) {
+	if err == nil {
+		rjc.queue.Forget(key)
+		return
+	}
+
+	if rjc.queue.NumRequeues(key) < maxRetries {
+		logrus.Warnf("Error syncing Longhorn recurring job %v: %v", key, err)
+		rjc.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	logrus.Warnf("Dropping Longhorn recurring job %v out of the queue: %v", key, err)
+	rjc.queue.Forget(key)
+}
+
+func (rjc *RecurringJobController) syncRecurringJob(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to sync recurring job for %v", key)
+	}()
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != rjc.namespace {
+		// Not ours, don't do anything
+		return nil
+	}
+
+	rj, err := rjc.ds.GetRecurringJob(name)
+	if err != nil {
+		return err
+	}
+	if rj == nil {
+		logrus.Infof("Longhorn recurring job %v has been deleted", key)
+		return nil
+	}
+
+	if rj.Spec.OwnerID == "" {
+		// Claim it
+		rj.Spec.OwnerID = rjc.controllerID
+		rj, err = rjc.ds.UpdateRecurringJob(rj)
+		if err != nil {
+			// we don't mind others coming first
+			if apierrors.IsConflict(errors.Cause(err)) {
+				return nil
+			}
+			return err
+		}
+		logrus.Debugf("Recurring Job Controller %v picked up %v", rjc.controllerID, rj.Name)
+	} else if rj.Spec.OwnerID != rjc.controllerID {
+		// Not ours
+		return nil
+	}
+
+	if rj.DeletionTimestamp != nil {
+		return nil
+	}
+
+	volumes, err := rjc.ds.ListVolumes()
+	if err != nil {
+		return err
+	}
+	selected := []string{}
+	for _, v := range volumes {
+		if types.IsVolumeSelectedByRecurringJob(&rj.Spec, v.Labels) {
+			selected = append(selected, v.Name)
+		}
+	}
+	sort.Strings(selected)
+
+	// release the runs of the deleted volumes and the ones which never
+	// finished
+	running := map[string]string{}
+	for volumeName, startedAt := range rj.Status.Running {
+		if volumes[volumeName] == nil || util.TimestampAfterTimeout(startedAt, types.RecurringJobRunTimeout) {
+			logrus.Warnf("Recurring job %v released the stale run on volume %v started at %v", rj.Name, volumeName, startedAt)
+			continue
+		}
+		running[volumeName] = startedAt
+	}
+
+	if reflect.DeepEqual(rj.Status.Volumes, selected) && len(running) == len(rj.Status.Running) {
+		return nil
+	}
+	rj.Status.Volumes = selected
+	rj.Status.Running = running
+	if _, err := rjc.ds.UpdateRecurringJob(rj); err != nil {
+		return err
+	}
+	logrus.Debugf("Recurring job %v selects volumes %v", rj.Name, selected)
+	return nil
+}
+
+func (rjc *RecurringJobController) enqueueRecurringJob(rj *longhorn.RecurringJob) {
+	key, err := controller.KeyFunc(rj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", rj, err))
+		return
+	}
+
+	rjc.queue.AddRateLimited(key)
+}
+
+// enqueueRecurringJobs enqueues the recurring jobs owned by the controller,
+// since any of them may select the changed volume
+func (rjc *RecurringJobController) enqueueRecurringJobs() {
+	rjs, err := rjc.ds.ListRecurringJobs()
+	if err != nil {
+		logrus.Warnf("Failed to list recurring jobs: %v", err)
+		return
+	}
+	for _, rj := range rjs {
+		// Not ours
+		if rj.Spec.OwnerID != rjc.controllerID {
+			continue
+		}
+		rjc.enqueueRecurringJob(rj)
+	}
+}
@@ -0,0 +1,390 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+	lhinformerfactory "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestRecurringJobName = "backup"
+	TestRecurringJobCron = "0 1 * * *"
+	TestRecurringGroup   = "daily"
+)
+
+type RecurringJobTestCase struct {
+	selector map[string]string
+	groups   []string
+	// volume labels keyed by the volume name
+	volumeLabels map[string]map[string]string
+	running      map[string]string
+
+	expectVolumes []string
+	expectRunning map[string]string
+}
+
+func newTestRecurringJobController(lhInformerFactory lhinformerfactory.SharedInformerFactory, kubeInformerFactory informers.SharedInformerFactory,
+	lhClient *lhfake.Clientset, kubeClient *fake.Clientset) *RecurringJobController {
+	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
+	engineInformer := lhInformerFactory.Longhorn().V1alpha1().Engines()
+	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
+	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
+	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
+
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
+	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
+
+	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
+	initSettings(ds)
+
+	rjc := NewRecurringJobController(ds, recurringJobInformer, volumeInformer, kubeClient, TestNamespace, TestOwnerID1)
+	rjc.rjStoreSynced = alwaysReady
+	rjc.vStoreSynced = alwaysReady
+
+	return rjc
+}
+
+func newRecurringJob(name string, selector map[string]string, groups []string) *longhorn.RecurringJob {
+	return &longhorn.RecurringJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: TestNamespace,
+		},
+		Spec: types.RecurringJobSpec{
+			OwnerID:     TestOwnerID1,
+			Task:        types.RecurringJobTypeBackup,
+			Cron:        TestRecurringJobCron,
+			Retain:      3,
+			Concurrency: 2,
+			Selector:    selector,
+			Groups:      groups,
+		},
+	}
+}
+
+func (s *TestSuite) TestSyncRecurringJob(c *C) {
+	var tc *RecurringJobTestCase
+	testCases := map[string]*RecurringJobTestCase{}
+
+	tc = &RecurringJobTestCase{}
+	tc.selector = map[string]string{"app": "db"}
+	tc.volumeLabels = map[string]map[string]string{
+		"vol-1": {"app": "db"},
+		"vol-2": {"app": "db", "tier": "prod"},
+		"vol-3": {"app": "web"},
+	}
+	tc.expectVolumes = []string{"vol-1", "vol-2"}
+	testCases["select by labels"] = tc
+
+	tc = &RecurringJobTestCase{}
+	tc.groups = []string{TestRecurringGroup}
+	tc.volumeLabels = map[string]map[string]string{
+		"vol-1": {types.GetRecurringJobGroupLabelKey(TestRecurringGroup): types.RecurringJobGroupLabelValue},
+		"vol-2": {types.GetRecurringJobGroupLabelKey("weekly"): types.RecurringJobGroupLabelValue},
+		"vol-3": {},
+	}
+	tc.expectVolumes = []string{"vol-1"}
+	testCases["select by groups"] = tc
+
+	tc = &RecurringJobTestCase{}
+	tc.selector = map[string]string{"app": "db"}
+	tc.groups = []string{TestRecurringGroup}
+	tc.volumeLabels = map[string]map[string]string{
+		"vol-1": {"app": "db"},
+		"vol-2": {types.GetRecurringJobGroupLabelKey(TestRecurringGroup): types.RecurringJobGroupLabelValue},
+		"vol-3": {"app": "web"},
+	}
+	tc.expectVolumes = []string{"vol-1", "vol-2"}
+	testCases["select by labels or groups"] = tc
+
+	tc = &RecurringJobTestCase{}
+	tc.selector = map[string]string{"app": "db"}
+	tc.volumeLabels = map[string]map[string]string{
+		"vol-1": {"app": "db"},
+	}
+	now := util.Now()
+	tc.running = map[string]string{
+		"vol-1":       now,
+		"vol-deleted": now,
+		"vol-stale":   util.FormatTimeZ(time.Now().Add(-2 * types.RecurringJobRunTimeout)),
+	}
+	tc.expectVolumes = []string{"vol-1"}
+	tc.expectRunning = map[string]string{
+		"vol-1": now,
+	}
+	testCases["release stale runs"] = tc
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+		lhClient := lhfake.NewSimpleClientset()
+		lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+		vIndexer := lhInformerFactory.Longhorn().V1alpha1().Volumes().Informer().GetIndexer()
+		rjIndexer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs().Informer().GetIndexer()
+
+		rjc := newTestRecurringJobController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient)
+
+		for volumeName, labels := range tc.volumeLabels {
+			v := newVolume(volumeName, 2)
+			v.Labels = labels
+			v, err := lhClient.LonghornV1alpha1().Volumes(TestNamespace).Create(v)
+			c.Assert(err, IsNil)
+			err = vIndexer.Add(v)
+			c.Assert(err, IsNil)
+		}
+
+		rj := newRecurringJob(TestRecurringJobName, tc.selector, tc.groups)
+		rj.Status.Running = tc.running
+		rj, err := lhClient.LonghornV1alpha1().RecurringJobs(TestNamespace).Create(rj)
+		c.Assert(err, IsNil)
+		err = rjIndexer.Add(rj)
+		c.Assert(err, IsNil)
+
+		err = rjc.syncRecurringJob(getKey(rj, c))
+		c.Assert(err, IsNil)
+
+		rj, err = lhClient.LonghornV1alpha1().RecurringJobs(TestNamespace).Get(TestRecurringJobName, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(rj.Status.Volumes, DeepEquals, tc.expectVolumes)
+		if tc.running != nil {
+			c.Assert(rj.Status.Running, DeepEquals, tc.expectRunning)
+		}
+	}
+}
+
+func (s *TestSuite) TestVolumeRecurringJobCronJobs(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+	lhClient := lhfake.NewSimpleClientset()
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+	rjIndexer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs().Informer().GetIndexer()
+
+	vc := newTestVolumeController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient, TestOwnerID1)
+
+	setting, err := vc.ds.GetSetting()
+	c.Assert(err, IsNil)
+	setting.BackupTarget = "vfs:///var/backup"
+	_, err = vc.ds.UpdateSetting(setting)
+	c.Assert(err, IsNil)
+
+	for _, rj := range []*longhorn.RecurringJob{
+		newRecurringJob(TestRecurringJobName, map[string]string{"app": "db"}, nil),
+		newRecurringJob("other", map[string]string{"app": "web"}, nil),
+	} {
+		err = rjIndexer.Add(rj)
+		c.Assert(err, IsNil)
+	}
+
+	v := newVolume(TestVolumeName, 2)
+	v.Labels = map[string]string{"app": "db"}
+	v.Status.State = types.VolumeStateAttached
+	v.Spec.RecurringJobs = []types.RecurringJob{
+		{
+			Name:   "snap",
+			Type:   types.RecurringJobTypeSnapshot,
+			Cron:   "0 * * * *",
+			Retain: 5,
+			Retention: &types.RetentionPolicy{
+				Daily:  7,
+				Weekly: 4,
+			},
+			Freeze: true,
+		},
+	}
+
+	err = vc.updateRecurringJobs(v)
+	c.Assert(err, IsNil)
+
+	cronJobs, err := kubeClient.BatchV1beta1().CronJobs(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(cronJobs.Items, HasLen, 2)
+
+	cronJobMap := map[string][]string{}
+	cronJobVolumes := map[string]int{}
+	for _, cronJob := range cronJobs.Items {
+		cronJobMap[cronJob.Name] = cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command
+		cronJobVolumes[cronJob.Name] = len(cronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes)
+	}
+	snapshotCmd, exists := cronJobMap[types.GetCronJobNameForVolumeAndJob(TestVolumeName, "snap")]
+	c.Assert(exists, Equals, true)
+	c.Assert(strings.Join(snapshotCmd, " "), Not(Matches), ".*--recurring-job.*")
+	c.Assert(strings.Join(snapshotCmd, " "), Matches, ".*--retain 5 --retain-hourly 0 --retain-daily 7 --retain-weekly 4 --retain-monthly 0 --freeze")
+	// fsfreeze needs the host proc and dev
+	c.Assert(cronJobVolumes[types.GetCronJobNameForVolumeAndJob(TestVolumeName, "snap")], Equals, 3)
+
+	backupCmd, exists := cronJobMap[types.GetCronJobNameForVolumeAndJob(TestVolumeName, TestRecurringJobName)]
+	c.Assert(exists, Equals, true)
+	c.Assert(strings.Join(backupCmd, " "), Not(Matches), ".*--retain-daily.*")
+	c.Assert(strings.Join(backupCmd, " "), Not(Matches), ".*--freeze.*")
+	c.Assert(cronJobVolumes[types.GetCronJobNameForVolumeAndJob(TestVolumeName, TestRecurringJobName)], Equals, 1)
+	c.Assert(strings.Join(backupCmd, " "), Matches, ".*--backuptarget vfs:///var/backup --recurring-job "+TestRecurringJobName)
+}
+
+type RecurringJobStatusTestCase struct {
+	status    map[string]types.RecurringJobRunStatus
+	condition *types.Condition
+
+	expectJobs      []string
+	expectCondition types.ConditionStatus
+	expectReason    string
+	expectMessage   string
+}
+
+func newRecurringJobRunStatus(consecutiveFailures int, lastError string) types.RecurringJobRunStatus {
+	status := types.RecurringJobRunStatus{
+		ConsecutiveFailures: consecutiveFailures,
+	}
+	result := types.RecurringJobRunResultSucceeded
+	if consecutiveFailures != 0 {
+		result = types.RecurringJobRunResultFailed
+	}
+	status.Runs = []types.RecurringJobRun{
+		{
+			StartTime: util.Now(),
+			EndTime:   util.Now(),
+			Result:    result,
+			Error:     lastError,
+		},
+	}
+	return status
+}
+
+func (s *TestSuite) TestReconcileRecurringJobStatus(c *C) {
+	var tc *RecurringJobStatusTestCase
+	testCases := map[string]*RecurringJobStatusTestCase{}
+
+	tc = &RecurringJobStatusTestCase{}
+	tc.status = map[string]types.RecurringJobRunStatus{
+		"snap": newRecurringJobRunStatus(2, "timeout"),
+	}
+	tc.expectJobs = []string{"snap"}
+	testCases["failures below threshold"] = tc
+
+	tc = &RecurringJobStatusTestCase{}
+	tc.status = map[string]types.RecurringJobRunStatus{
+		"snap":               newRecurringJobRunStatus(3, "timeout"),
+		TestRecurringJobName: newRecurringJobRunStatus(0, ""),
+	}
+	tc.expectJobs = []string{TestRecurringJobName, "snap"}
+	tc.expectCondition = types.ConditionStatusTrue
+	tc.expectReason = types.VolumeConditionReasonRecurringJobFailed
+	tc.expectMessage = "job snap failed 3 times in a row: timeout"
+	testCases["failing job raises condition"] = tc
+
+	tc = &RecurringJobStatusTestCase{}
+	tc.status = map[string]types.RecurringJobRunStatus{
+		"snap": newRecurringJobRunStatus(0, ""),
+	}
+	tc.condition = &types.Condition{
+		Type:   types.VolumeConditionTypeRecurringJobFailing,
+		Status: types.ConditionStatusTrue,
+		Reason: types.VolumeConditionReasonRecurringJobFailed,
+	}
+	tc.expectJobs = []string{"snap"}
+	tc.expectCondition = types.ConditionStatusFalse
+	tc.expectReason = types.VolumeConditionReasonRecurringJobRecovered
+	testCases["recovered job clears condition"] = tc
+
+	tc = &RecurringJobStatusTestCase{}
+	tc.status = map[string]types.RecurringJobRunStatus{
+		"snap":    newRecurringJobRunStatus(0, ""),
+		"removed": newRecurringJobRunStatus(5, "timeout"),
+	}
+	tc.expectJobs = []string{"snap"}
+	testCases["history of removed job dropped"] = tc
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+		lhClient := lhfake.NewSimpleClientset()
+		lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+		rjIndexer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs().Informer().GetIndexer()
+
+		vc := newTestVolumeController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient, TestOwnerID1)
+
+		setting, err := vc.ds.GetSetting()
+		c.Assert(err, IsNil)
+		setting.BackupTarget = "vfs:///var/backup"
+		setting.RecurringJobFailureThreshold = 3
+		_, err = vc.ds.UpdateSetting(setting)
+		c.Assert(err, IsNil)
+
+		err = rjIndexer.Add(newRecurringJob(TestRecurringJobName, map[string]string{"app": "db"}, nil))
+		c.Assert(err, IsNil)
+
+		v := newVolume(TestVolumeName, 2)
+		v.Labels = map[string]string{"app": "db"}
+		v.Spec.RecurringJobs = []types.RecurringJob{
+			{
+				Name:   "snap",
+				Type:   types.RecurringJobTypeSnapshot,
+				Cron:   "0 * * * *",
+				Retain: 5,
+			},
+		}
+		v.Status.RecurringJobStatus = tc.status
+		if tc.condition != nil {
+			v.Status.Conditions = map[string]types.Condition{
+				tc.condition.Type: *tc.condition,
+			}
+		}
+
+		err = vc.updateRecurringJobs(v)
+		c.Assert(err, IsNil)
+
+		jobs := []string{}
+		for job := range v.Status.RecurringJobStatus {
+			jobs = append(jobs, job)
+		}
+		sort.Strings(jobs)
+		c.Assert(jobs, DeepEquals, tc.expectJobs)
+
+		cond, exists := v.Status.Conditions[types.VolumeConditionTypeRecurringJobFailing]
+		if tc.expectCondition == "" {
+			c.Assert(exists, Equals, false)
+			continue
+		}
+		c.Assert(exists, Equals, true)
+		c.Assert(cond.Status, Equals, tc.expectCondition)
+		c.Assert(cond.Reason, Equals, tc.expectReason)
+		c.Assert(cond.Message, Equals, tc.expectMessage)
+	}
+}
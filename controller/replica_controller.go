@@ -27,6 +27,7 @@ import (
 	"k8s.io/kubernetes/pkg/controller"
 
 	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
 	"github.com/rancher/longhorn-manager/types"
 	"github.com/rancher/longhorn-manager/util"
 
@@ -60,6 +61,12 @@ const (
 	replicaReadinessProbeFailureThresholdDefault = 3
 )
 
+var (
+	// RestoreStatusPollInterval is how often the restore progress is polled
+	// from the replica which is restoring
+	RestoreStatusPollInterval = 10 * time.Second
+)
+
 type ReplicaController struct {
 	// which namespace controller is running with
 	namespace string
@@ -271,7 +278,69 @@ func (rc *ReplicaController) syncReplica(key string) (err error) {
 		}
 	}
 
-	return rc.instanceHandler.ReconcileInstanceState(replica, &replica.Spec.InstanceSpec, &replica.Status.InstanceStatus)
+	if err := rc.instanceHandler.ReconcileInstanceState(replica, &replica.Spec.InstanceSpec, &replica.Status.InstanceStatus); err != nil {
+		return err
+	}
+
+	return rc.syncRestoreStatus(replica)
+}
+
+// syncRestoreStatus polls the restore progress from the replica while it's
+// restoring. The replica won't pass the readiness probe before the restore is
+// done, so the pod IP is used instead of the instance IP
+func (rc *ReplicaController) syncRestoreStatus(r *longhorn.Replica) error {
+	if r.Spec.RestoreFrom == "" || r.Spec.RestoreName == "" {
+		return nil
+	}
+
+	status := &r.Status.RestoreStatus
+	switch r.Status.CurrentState {
+	case types.InstanceStateRunning:
+		if status.IsRestoring {
+			status.IsRestoring = false
+			status.Progress = 100
+			status.Error = ""
+		}
+		return nil
+	case types.InstanceStateStarting:
+		pod, err := rc.instanceHandler.getPod(r.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+			return nil
+		}
+		rs, err := engineapi.GetReplicaRestoreStatus(r.Spec.EngineImage, engineapi.GetReplicaDefaultURL(pod.Status.PodIP))
+		if engineapi.IsUnsupportedByEngine(err) {
+			// the replica is still restoring, only the progress is unknown
+			status.IsRestoring = true
+			status.CurrentBackup = r.Spec.RestoreName
+			return nil
+		}
+		// keep polling until the replica is running or gone
+		rc.enqueueReplicaAfter(r, RestoreStatusPollInterval)
+		if err != nil {
+			logrus.Warnf("Failed to get restore status of replica %v: %v", r.Name, err)
+			return nil
+		}
+		if rs.CurrentBackup == "" {
+			rs.CurrentBackup = r.Spec.RestoreName
+		}
+		*status = *rs
+	case types.InstanceStateError:
+		if status.IsRestoring {
+			status.IsRestoring = false
+			if status.Error == "" {
+				status.Error = "replica failed during restore"
+			}
+		}
+	default:
+		status.IsRestoring = false
+	}
+	return nil
 }
 
 func (rc *ReplicaController) enqueueReplica(replica *longhorn.Replica) {
@@ -284,6 +353,16 @@ func (rc *ReplicaController) enqueueReplica(replica *longhorn.Replica) {
 	rc.queue.AddRateLimited(key)
 }
 
+func (rc *ReplicaController) enqueueReplicaAfter(replica *longhorn.Replica, duration time.Duration) {
+	key, err := controller.KeyFunc(replica)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", replica, err))
+		return
+	}
+
+	rc.queue.AddAfter(key, duration)
+}
+
 func (rc *ReplicaController) getReadinessProbeFailureThreshold(r *longhorn.Replica) int32 {
 	if r.Spec.RestoreFrom == "" {
 		// default value if
@@ -306,6 +385,9 @@ func (rc *ReplicaController) CreatePodSpec(obj interface{}) (*v1.Pod, error) {
 	if r.Spec.RestoreFrom != "" && r.Spec.RestoreName != "" {
 		cmd = append(cmd, "--restore-from", r.Spec.RestoreFrom, "--restore-name", r.Spec.RestoreName)
 	}
+	if r.Spec.BackingImage != "" {
+		cmd = append(cmd, "--backing-file", types.GetBackingImageFileInContainer())
+	}
 	cmd = append(cmd, "/volume")
 
 	privilege := true
@@ -375,13 +457,29 @@ func (rc *ReplicaController) CreatePodSpec(obj interface{}) (*v1.Pod, error) {
 	// set pod to node that replica scheduled on
 	pod.Spec.NodeName = r.Spec.NodeID
 
+	if r.Spec.BackingImage != "" {
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, v1.VolumeMount{
+			Name:      "backing-image",
+			MountPath: types.BackingImageDirectoryInContainer,
+			ReadOnly:  true,
+		})
+		pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+			Name: "backing-image",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: types.GetBackingImageDirectoryOnHost(r.Spec.BackingImage),
+				},
+			},
+		})
+	}
+
 	if r.Spec.RestoreName != "" && r.Spec.RestoreFrom != "" {
-		settings, err := rc.ds.GetSetting()
+		backupTarget, err := rc.ds.GetBackupTargetSpecForURL(r.Spec.RestoreFrom)
 		if err != nil && !apierrors.IsNotFound(err) {
 			return nil, err
 		}
-		if settings != nil && settings.BackupTargetCredentialSecret != "" {
-			err := util.ConfigEnvWithCredential(r.Spec.RestoreFrom, settings.BackupTargetCredentialSecret, &pod.Spec.Containers[0])
+		if backupTarget != nil && backupTarget.CredentialSecret != "" {
+			err := util.ConfigEnvWithCredential(r.Spec.RestoreFrom, backupTarget.CredentialSecret, &pod.Spec.Containers[0])
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,395 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhinformers "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions/longhorn/v1alpha1"
+)
+
+// SnapshotExportController exports the snapshots into image files on the
+// node, in the background. The image is served by the manager on the node
+// and removed with the SnapshotExport, which is deleted with the snapshot or
+// SnapshotExportRetention after the export finished.
+type SnapshotExportController struct {
+	// which namespace controller is running with
+	namespace string
+	// the node keeping the exported images
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds *datastore.DataStore
+
+	engines engineapi.EngineClientCollection
+
+	seStoreSynced cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+
+	exportDirectory string
+
+	lock sync.Mutex
+	// the exports running in the manager, keyed by the SnapshotExport
+	running map[string]*runningExport
+}
+
+type runningExport struct {
+	done     bool
+	size     int64
+	checksum string
+	err      error
+}
+
+func NewSnapshotExportController(
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	snapshotExportInformer lhinformers.SnapshotExportInformer,
+	kubeClient clientset.Interface,
+	engines engineapi.EngineClientCollection,
+	namespace string, controllerID string) *SnapshotExportController {
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	// TODO: remove the wrapper when every clients have moved to use the clientset.
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	sec := &SnapshotExportController{
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, v1.EventSource{Component: "longhorn-snapshot-export-controller"}),
+
+		ds: ds,
+
+		engines: engines,
+
+		seStoreSynced: snapshotExportInformer.Informer().HasSynced,
+
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "longhorn-snapshot-export"),
+
+		exportDirectory: types.SnapshotExportDirectory,
+
+		running: map[string]*runningExport{},
+	}
+
+	snapshotExportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			se := obj.(*longhorn.SnapshotExport)
+			sec.enqueueSnapshotExport(se)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curSE := cur.(*longhorn.SnapshotExport)
+			sec.enqueueSnapshotExport(curSE)
+		},
+		DeleteFunc: func(obj interface{}) {
+			se, ok := obj.(*longhorn.SnapshotExport)
+			if ok {
+				sec.enqueueSnapshotExport(se)
+			}
+		},
+	})
+
+	return sec
+}
+
+func (sec *SnapshotExportController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer sec.queue.ShutDown()
+
+	logrus.Infof("Start Longhorn Snapshot Export controller")
+	defer logrus.Infof("Shutting down Longhorn Snapshot Export controller")
+
+	if !controller.WaitForCacheSync("longhorn snapshot exports", stopCh, sec.seStoreSynced) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(sec.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (sec *SnapshotExportController) worker() {
+	for sec.processNextWorkItem() {
+	}
+}
+
+func (sec *SnapshotExportController) processNextWorkItem() bool {
+	key, quit := sec.queue.Get()
+
+	if quit {
+		return false
+	}
+	defer sec.queue.Done(key)
+
+	err := sec.syncSnapshotExport(key.(string))
+	sec.handleErr(err, key)
+
+	return true
+}
+
+func (sec *SnapshotExportController) handleErr(err error, key interface{}) {
+	if err == nil {
+		sec.queue.Forget(key)
+		return
+	}
+
+	if sec.queue.NumRequeues(key) < maxRetries {
+		logrus.Warnf("Error syncing Longhorn snapshot export %v: %v", key, err)
+		sec.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	logrus.Warnf("Dropping Longhorn snapshot export %v out of the queue: %v", key, err)
+	sec.queue.Forget(key)
+}
+
+func (sec *SnapshotExportController) syncSnapshotExport(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to sync snapshot export for %v", key)
+	}()
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != sec.namespace {
+		// Not ours, don't do anything
+		return nil
+	}
+
+	se, err := sec.ds.GetSnapshotExport(name)
+	if err != nil {
+		return err
+	}
+	if se == nil {
+		logrus.Infof("Longhorn snapshot export %v has been deleted", key)
+		return nil
+	}
+	// the image can only be reached from the node it's on
+	if se.Spec.NodeID != sec.controllerID {
+		return nil
+	}
+
+	if se.DeletionTimestamp != nil {
+		return sec.deleteSnapshotExport(se)
+	}
+	if se.Status.IsFinished() {
+		return sec.cleanupSnapshotExport(se)
+	}
+	if se.Status.State == types.SnapshotExportStateInProgress {
+		return sec.syncRunningExport(se)
+	}
+	return sec.startExport(se)
+}
+
+func (sec *SnapshotExportController) getExportFile(se *longhorn.SnapshotExport) string {
+	return types.GetSnapshotExportFile(sec.exportDirectory, se.Spec.VolumeName, se.Spec.SnapshotName)
+}
+
+// startExport runs the export in the background, the failure before the
+// engine starts the export is recorded in the SnapshotExport
+func (sec *SnapshotExportController) startExport(se *longhorn.SnapshotExport) error {
+	e, err := sec.ds.GetVolumeEngine(se.Spec.VolumeName)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return sec.finishSnapshotExport(se, types.SnapshotExportStateError,
+			fmt.Sprintf("cannot get engine for %v", se.Spec.VolumeName))
+	}
+	engine, err := GetClientForEngine(e, sec.engines, e.Status.CurrentImage)
+	if err != nil {
+		return sec.finishSnapshotExport(se, types.SnapshotExportStateError, err.Error())
+	}
+
+	se.Status.State = types.SnapshotExportStateInProgress
+	se.Status.StartTime = util.Now()
+	se, err = sec.ds.UpdateSnapshotExport(se)
+	if err != nil {
+		return err
+	}
+
+	r := &runningExport{}
+	sec.lock.Lock()
+	sec.running[se.Name] = r
+	sec.lock.Unlock()
+
+	file := sec.getExportFile(se)
+	go func() {
+		size, checksum, err := exportSnapshot(engine, se.Spec.SnapshotName, file)
+
+		sec.lock.Lock()
+		r.done = true
+		r.size = size
+		r.checksum = checksum
+		r.err = err
+		sec.lock.Unlock()
+		sec.enqueueSnapshotExport(se)
+	}()
+
+	logrus.Debugf("Started export %v of volume %v snapshot %v", se.Name, se.Spec.VolumeName, se.Spec.SnapshotName)
+	return nil
+}
+
+// exportSnapshot exports the snapshot into a temporary file first, so the
+// partial export is never served
+func exportSnapshot(engine engineapi.EngineClient, snapshotName, file string) (int64, string, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return 0, "", errors.Wrapf(err, "cannot create export directory %v", filepath.Dir(file))
+	}
+	tmpFile := file + ".tmp"
+	defer os.Remove(tmpFile)
+
+	if err := engine.SnapshotExport(snapshotName, tmpFile); err != nil {
+		return 0, "", err
+	}
+	checksum, err := util.GetFileChecksumSHA512(tmpFile)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "cannot calculate checksum of snapshot %v export", snapshotName)
+	}
+	st, err := os.Stat(tmpFile)
+	if err != nil {
+		return 0, "", err
+	}
+	if err := os.Rename(tmpFile, file); err != nil {
+		return 0, "", errors.Wrapf(err, "cannot save snapshot %v export", snapshotName)
+	}
+	return st.Size(), checksum, nil
+}
+
+// syncRunningExport records the result once the export finished, the
+// SnapshotExport is enqueued by then
+func (sec *SnapshotExportController) syncRunningExport(se *longhorn.SnapshotExport) error {
+	sec.lock.Lock()
+	r := sec.running[se.Name]
+	var result runningExport
+	if r != nil {
+		result = *r
+	}
+	sec.lock.Unlock()
+
+	if r == nil {
+		// the manager restarted while the export was running
+		return sec.finishSnapshotExport(se, types.SnapshotExportStateError, "export was interrupted")
+	}
+	if !result.done {
+		return nil
+	}
+
+	var err error
+	if result.err == nil {
+		se.Status.Size = result.size
+		se.Status.Checksum = result.checksum
+		err = sec.finishSnapshotExport(se, types.SnapshotExportStateCompleted, "")
+	} else {
+		err = sec.finishSnapshotExport(se, types.SnapshotExportStateError, result.err.Error())
+		if err == nil {
+			sec.eventRecorder.Eventf(se, v1.EventTypeWarning, types.EventReasonFailedExport,
+				"Failed to export snapshot %v of volume %v: %v", se.Spec.SnapshotName, se.Spec.VolumeName, result.err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	sec.lock.Lock()
+	delete(sec.running, se.Name)
+	sec.lock.Unlock()
+	return nil
+}
+
+func (sec *SnapshotExportController) finishSnapshotExport(se *longhorn.SnapshotExport, state types.SnapshotExportState, errMsg string) error {
+	se.Status.State = state
+	se.Status.Error = errMsg
+	se.Status.EndTime = util.Now()
+	if _, err := sec.ds.UpdateSnapshotExport(se); err != nil {
+		return err
+	}
+	logrus.Debugf("Export %v of volume %v snapshot %v finished as %v", se.Name, se.Spec.VolumeName, se.Spec.SnapshotName, state)
+	return nil
+}
+
+// cleanupSnapshotExport deletes the SnapshotExport finished longer than
+// SnapshotExportRetention ago
+func (sec *SnapshotExportController) cleanupSnapshotExport(se *longhorn.SnapshotExport) error {
+	if endTime, err := util.ParseTime(se.Status.EndTime); err == nil {
+		if remaining := endTime.Add(types.SnapshotExportRetention).Sub(time.Now()); remaining > 0 {
+			sec.enqueueSnapshotExportAfter(se, remaining)
+			return nil
+		}
+	}
+	if err := sec.ds.DeleteSnapshotExport(se.Name); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// deleteSnapshotExport removes the exported image once the running export
+// finished, then lets the SnapshotExport go
+func (sec *SnapshotExportController) deleteSnapshotExport(se *longhorn.SnapshotExport) error {
+	sec.lock.Lock()
+	r := sec.running[se.Name]
+	if r != nil && !r.done {
+		sec.lock.Unlock()
+		// enqueued once the export finished
+		return nil
+	}
+	delete(sec.running, se.Name)
+	sec.lock.Unlock()
+
+	file := sec.getExportFile(se)
+	for _, f := range []string{file, file + ".tmp"} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "cannot remove snapshot export %v", f)
+		}
+	}
+	return sec.ds.RemoveFinalizerForSnapshotExport(se)
+}
+
+func (sec *SnapshotExportController) enqueueSnapshotExport(se *longhorn.SnapshotExport) {
+	key, err := controller.KeyFunc(se)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", se, err))
+		return
+	}
+
+	sec.queue.AddRateLimited(key)
+}
+
+func (sec *SnapshotExportController) enqueueSnapshotExportAfter(se *longhorn.SnapshotExport, delay time.Duration) {
+	key, err := controller.KeyFunc(se)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", se, err))
+		return
+	}
+
+	sec.queue.AddAfter(key, delay)
+}
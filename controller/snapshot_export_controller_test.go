@@ -0,0 +1,326 @@
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+	lhinformerfactory "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestSnapshotExportContent = "snapshot-content"
+)
+
+// fakeExportEngine writes the export once the result is sent
+type fakeExportEngine struct {
+	engineapi.EngineClient
+
+	result chan error
+}
+
+func (e *fakeExportEngine) SnapshotExport(snapName, fileName string) error {
+	if err := <-e.result; err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, []byte(TestSnapshotExportContent), 0600)
+}
+
+type fakeExportEngineCollection struct {
+	engine *fakeExportEngine
+}
+
+func (c *fakeExportEngineCollection) NewEngineClient(request *engineapi.EngineClientRequest) (engineapi.EngineClient, error) {
+	return c.engine, nil
+}
+
+func newTestSnapshotExportController(lhInformerFactory lhinformerfactory.SharedInformerFactory, kubeInformerFactory informers.SharedInformerFactory,
+	lhClient *lhfake.Clientset, kubeClient *fake.Clientset, engines engineapi.EngineClientCollection, exportDirectory string) *SnapshotExportController {
+	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
+	engineInformer := lhInformerFactory.Longhorn().V1alpha1().Engines()
+	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
+	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
+	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
+
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
+	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
+
+	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
+	initSettings(ds)
+
+	sec := NewSnapshotExportController(ds, scheme.Scheme, snapshotExportInformer, kubeClient, engines, TestNamespace, TestNode1)
+	fakeRecorder := record.NewFakeRecorder(100)
+	sec.eventRecorder = fakeRecorder
+	sec.exportDirectory = exportDirectory
+
+	sec.seStoreSynced = alwaysReady
+
+	return sec
+}
+
+func newSnapshotExport(snapshotName, nodeID string) *longhorn.SnapshotExport {
+	return &longhorn.SnapshotExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       types.GetSnapshotExportName(TestVolumeName, snapshotName),
+			Namespace:  TestNamespace,
+			Finalizers: []string{longhorn.SchemeGroupVersion.Group},
+		},
+		Spec: types.SnapshotExportSpec{
+			VolumeName:   TestVolumeName,
+			SnapshotName: snapshotName,
+			NodeID:       nodeID,
+		},
+		Status: types.SnapshotExportStatus{
+			State: types.SnapshotExportStatePending,
+		},
+	}
+}
+
+type snapshotExportTestEnv struct {
+	sec       *SnapshotExportController
+	engine    *fakeExportEngine
+	lhClient  *lhfake.Clientset
+	seIndexer cache.Indexer
+	eIndexer  cache.Indexer
+}
+
+func newSnapshotExportTestEnv(c *C, exportDirectory string) *snapshotExportTestEnv {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+	lhClient := lhfake.NewSimpleClientset()
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+	vIndexer := lhInformerFactory.Longhorn().V1alpha1().Volumes().Informer().GetIndexer()
+	eIndexer := lhInformerFactory.Longhorn().V1alpha1().Engines().Informer().GetIndexer()
+
+	engine := &fakeExportEngine{
+		result: make(chan error, 1),
+	}
+	env := &snapshotExportTestEnv{
+		sec:       newTestSnapshotExportController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient, &fakeExportEngineCollection{engine}, exportDirectory),
+		engine:    engine,
+		lhClient:  lhClient,
+		seIndexer: lhInformerFactory.Longhorn().V1alpha1().SnapshotExports().Informer().GetIndexer(),
+		eIndexer:  eIndexer,
+	}
+
+	v := newVolume(TestVolumeName, 2)
+	v.Namespace = TestNamespace
+	v.Spec.OwnerID = TestNode1
+	err := vIndexer.Add(v)
+	c.Assert(err, IsNil)
+	e := newEngineForVolume(v)
+	e.Namespace = TestNamespace
+	e.Status.CurrentState = types.InstanceStateRunning
+	e.Status.CurrentImage = TestEngineImage
+	e.Status.IP = randomIP()
+	err = eIndexer.Add(e)
+	c.Assert(err, IsNil)
+
+	return env
+}
+
+func (env *snapshotExportTestEnv) create(se *longhorn.SnapshotExport, c *C) {
+	se, err := env.lhClient.LonghornV1alpha1().SnapshotExports(TestNamespace).Create(se)
+	c.Assert(err, IsNil)
+	err = env.seIndexer.Add(se)
+	c.Assert(err, IsNil)
+}
+
+// sync syncs the SnapshotExport and returns the updated one, or nil if it's
+// gone
+func (env *snapshotExportTestEnv) sync(name string, c *C) *longhorn.SnapshotExport {
+	err := env.sec.syncSnapshotExport(TestNamespace + "/" + name)
+	c.Assert(err, IsNil)
+	ses, err := env.lhClient.LonghornV1alpha1().SnapshotExports(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	for i := range ses.Items {
+		if ses.Items[i].Name == name {
+			se := &ses.Items[i]
+			err = env.seIndexer.Update(se)
+			c.Assert(err, IsNil)
+			return se
+		}
+	}
+	return nil
+}
+
+func (env *snapshotExportTestEnv) waitForExportDone(name string, c *C) {
+	for i := 0; i < 100; i++ {
+		env.sec.lock.Lock()
+		done := env.sec.running[name] != nil && env.sec.running[name].done
+		env.sec.lock.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatalf("export %v is not done", name)
+}
+
+func (s *TestSuite) TestSnapshotExportCompleted(c *C) {
+	exportDirectory, err := ioutil.TempDir("", "snapshot-export-test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(exportDirectory)
+	env := newSnapshotExportTestEnv(c, exportDirectory)
+
+	se := newSnapshotExport("snapshot-1", TestNode1)
+	env.create(se, c)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Status.State, Equals, types.SnapshotExportStateInProgress)
+	c.Assert(se.Status.StartTime, Not(Equals), "")
+
+	env.engine.result <- nil
+	env.waitForExportDone(se.Name, c)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Status.State, Equals, types.SnapshotExportStateCompleted)
+	c.Assert(se.Status.Size, Equals, int64(len(TestSnapshotExportContent)))
+	c.Assert(se.Status.Checksum, Equals, util.GetStringChecksum(TestSnapshotExportContent))
+	c.Assert(se.Status.EndTime, Not(Equals), "")
+	c.Assert(env.sec.running, HasLen, 0)
+
+	file := types.GetSnapshotExportFile(exportDirectory, TestVolumeName, "snapshot-1")
+	content, err := ioutil.ReadFile(file)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, TestSnapshotExportContent)
+	_, err = os.Stat(file + ".tmp")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	// the image is removed with the SnapshotExport
+	now := metav1.Now()
+	se.DeletionTimestamp = &now
+	se, err = env.lhClient.LonghornV1alpha1().SnapshotExports(TestNamespace).Update(se)
+	c.Assert(err, IsNil)
+	err = env.seIndexer.Update(se)
+	c.Assert(err, IsNil)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Finalizers, HasLen, 0)
+	_, err = os.Stat(file)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *TestSuite) TestSnapshotExportFailed(c *C) {
+	exportDirectory, err := ioutil.TempDir("", "snapshot-export-test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(exportDirectory)
+	env := newSnapshotExportTestEnv(c, exportDirectory)
+
+	se := newSnapshotExport("snapshot-1", TestNode1)
+	env.create(se, c)
+	env.sync(se.Name, c)
+	env.engine.result <- fmt.Errorf("cannot find snapshot")
+	env.waitForExportDone(se.Name, c)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Status.State, Equals, types.SnapshotExportStateError)
+	c.Assert(se.Status.Error, Equals, "cannot find snapshot")
+	c.Assert(env.sec.eventRecorder.(*record.FakeRecorder).Events, HasLen, 1)
+	_, err = os.Stat(types.GetSnapshotExportFile(exportDirectory, TestVolumeName, "snapshot-1") + ".tmp")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	// the manager restarted while the export was running
+	se = newSnapshotExport("snapshot-2", TestNode1)
+	se.Status.State = types.SnapshotExportStateInProgress
+	env.create(se, c)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Status.State, Equals, types.SnapshotExportStateError)
+	c.Assert(se.Status.Error, Equals, "export was interrupted")
+
+	// the volume is detached
+	e, err := env.sec.ds.GetVolumeEngine(TestVolumeName)
+	c.Assert(err, IsNil)
+	e.Status.CurrentState = types.InstanceStateStopped
+	err = env.eIndexer.Update(e)
+	c.Assert(err, IsNil)
+	se = newSnapshotExport("snapshot-3", TestNode1)
+	env.create(se, c)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Status.State, Equals, types.SnapshotExportStateError)
+	c.Assert(env.sec.running, HasLen, 0)
+
+	// the exports on the other nodes are not touched
+	se = newSnapshotExport("snapshot-4", TestNode2)
+	env.create(se, c)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Status.State, Equals, types.SnapshotExportStatePending)
+}
+
+func (s *TestSuite) TestSnapshotExportDeletedWhileRunning(c *C) {
+	exportDirectory, err := ioutil.TempDir("", "snapshot-export-test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(exportDirectory)
+	env := newSnapshotExportTestEnv(c, exportDirectory)
+
+	se := newSnapshotExport("snapshot-1", TestNode1)
+	env.create(se, c)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Status.State, Equals, types.SnapshotExportStateInProgress)
+
+	// the image is removed once the export finished
+	now := metav1.Now()
+	se.DeletionTimestamp = &now
+	se, err = env.lhClient.LonghornV1alpha1().SnapshotExports(TestNamespace).Update(se)
+	c.Assert(err, IsNil)
+	err = env.seIndexer.Update(se)
+	c.Assert(err, IsNil)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Finalizers, HasLen, 1)
+
+	env.engine.result <- nil
+	env.waitForExportDone(se.Name, c)
+	se = env.sync(se.Name, c)
+	c.Assert(se.Finalizers, HasLen, 0)
+	c.Assert(env.sec.running, HasLen, 0)
+	_, err = os.Stat(types.GetSnapshotExportFile(exportDirectory, TestVolumeName, "snapshot-1"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func (s *TestSuite) TestSnapshotExportCleanup(c *C) {
+	env := newSnapshotExportTestEnv(c, "")
+
+	for snapshotName, endTime := range map[string]time.Time{
+		"snapshot-expired": time.Now().Add(-types.SnapshotExportRetention - time.Hour),
+		"snapshot-recent":  time.Now().Add(-time.Hour),
+	} {
+		se := newSnapshotExport(snapshotName, TestNode1)
+		se.Status.State = types.SnapshotExportStateCompleted
+		se.Status.EndTime = util.FormatTimeZ(endTime)
+		env.create(se, c)
+		err := env.sec.syncSnapshotExport(TestNamespace + "/" + se.Name)
+		c.Assert(err, IsNil)
+	}
+
+	ses, err := env.lhClient.LonghornV1alpha1().SnapshotExports(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(ses.Items, HasLen, 1)
+	c.Assert(ses.Items[0].Spec.SnapshotName, Equals, "snapshot-recent")
+}
@@ -2,8 +2,11 @@ package controller
 
 import (
 	"fmt"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -42,6 +45,10 @@ const (
 	LabelRecurringJob = "RecurringJob"
 
 	CronJobBackoffLimit = 3
+
+	importHostDevDirectory = "/host/dev/"
+	importSourceDirectory  = "/source/"
+	importScratchDirectory = "/scratch/"
 )
 
 type VolumeController struct {
@@ -57,9 +64,10 @@ type VolumeController struct {
 
 	ds *datastore.DataStore
 
-	vStoreSynced cache.InformerSynced
-	eStoreSynced cache.InformerSynced
-	rStoreSynced cache.InformerSynced
+	vStoreSynced  cache.InformerSynced
+	eStoreSynced  cache.InformerSynced
+	rStoreSynced  cache.InformerSynced
+	rjStoreSynced cache.InformerSynced
 
 	queue workqueue.RateLimitingInterface
 
@@ -75,6 +83,7 @@ func NewVolumeController(
 	volumeInformer lhinformers.VolumeInformer,
 	engineInformer lhinformers.EngineInformer,
 	replicaInformer lhinformers.ReplicaInformer,
+	recurringJobInformer lhinformers.RecurringJobInformer,
 	kubeClient clientset.Interface,
 	namespace, controllerID, serviceAccount string,
 	managerImage string) *VolumeController {
@@ -94,9 +103,10 @@ func NewVolumeController(
 		kubeClient:    kubeClient,
 		eventRecorder: eventBroadcaster.NewRecorder(scheme, v1.EventSource{Component: "longhorn-volume-controller"}),
 
-		vStoreSynced: volumeInformer.Informer().HasSynced,
-		eStoreSynced: engineInformer.Informer().HasSynced,
-		rStoreSynced: replicaInformer.Informer().HasSynced,
+		vStoreSynced:  volumeInformer.Informer().HasSynced,
+		eStoreSynced:  engineInformer.Informer().HasSynced,
+		rStoreSynced:  replicaInformer.Informer().HasSynced,
+		rjStoreSynced: recurringJobInformer.Informer().HasSynced,
 
 		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "longhorn-volume"),
 
@@ -141,6 +151,23 @@ func NewVolumeController(
 			vc.enqueueControlleeChange(obj)
 		},
 	})
+	recurringJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			rj := obj.(*longhorn.RecurringJob)
+			vc.enqueueRecurringJobVolumes(rj.Status.Volumes)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldRJ := old.(*longhorn.RecurringJob)
+			curRJ := cur.(*longhorn.RecurringJob)
+			// the volumes no longer selected need to remove the job
+			vc.enqueueRecurringJobVolumes(oldRJ.Status.Volumes)
+			vc.enqueueRecurringJobVolumes(curRJ.Status.Volumes)
+		},
+		DeleteFunc: func(obj interface{}) {
+			rj := obj.(*longhorn.RecurringJob)
+			vc.enqueueRecurringJobVolumes(rj.Status.Volumes)
+		},
+	})
 	return vc
 }
 
@@ -151,7 +178,7 @@ func (vc *VolumeController) Run(workers int, stopCh <-chan struct{}) {
 	logrus.Infof("Start Longhorn volume controller")
 	defer logrus.Infof("Shutting down Longhorn volume controller")
 
-	if !controller.WaitForCacheSync("longhorn engines", stopCh, vc.vStoreSynced, vc.eStoreSynced, vc.rStoreSynced) {
+	if !controller.WaitForCacheSync("longhorn engines", stopCh, vc.vStoreSynced, vc.eStoreSynced, vc.rStoreSynced, vc.rjStoreSynced) {
 		return
 	}
 
@@ -246,6 +273,17 @@ func (vc *VolumeController) syncVolume(key string) (err error) {
 		return err
 	}
 
+	if volume.DeletionTimestamp == nil && volume.Spec.RecycledAt != "" {
+		expired, err := vc.isRecycleExpired(volume)
+		if err != nil {
+			return err
+		}
+		if expired {
+			logrus.Infof("Removing volume %v from the recycle bin", volume.Name)
+			return vc.ds.DeleteVolume(volume.Name)
+		}
+	}
+
 	if volume.DeletionTimestamp != nil {
 		if volume.Status.State != types.VolumeStateDeleting {
 			volume.Status.State = types.VolumeStateDeleting
@@ -253,10 +291,14 @@ func (vc *VolumeController) syncVolume(key string) (err error) {
 			if err != nil {
 				return err
 			}
-			vc.eventRecorder.Eventf(volume, v1.EventTypeNormal, EventReasonDelete, "Deleting volume %v", volume.Name)
+			vc.eventRecorder.Eventf(volume, v1.EventTypeNormal, types.EventReasonDelete, "Deleting volume %v", volume.Name)
+		}
+		cronJobROs, err := vc.ds.ListVolumeCronJobROs(volume.Name)
+		if err != nil {
+			return err
 		}
-		for _, job := range volume.Spec.RecurringJobs {
-			if err := vc.ds.DeleteCronJob(types.GetCronJobNameForVolumeAndJob(volume.Name, job.Name)); err != nil {
+		for name := range cronJobROs {
+			if err := vc.ds.DeleteCronJob(name); err != nil {
 				return err
 			}
 		}
@@ -297,6 +339,16 @@ func (vc *VolumeController) syncVolume(key string) (err error) {
 		return err
 	}
 
+	if err := vc.reconcileVolumeImport(volume); err != nil {
+		return err
+	}
+
+	if err := vc.reconcileVolumeRestore(volume, replicas); err != nil {
+		return err
+	}
+
+	vc.expireAutoAttachedJobs(volume)
+
 	if err := vc.ReconcileVolumeState(volume, engine, replicas); err != nil {
 		return err
 	}
@@ -334,6 +386,11 @@ func (vc *VolumeController) ReconcileEngineReplicaState(v *longhorn.Volume, e *l
 		return nil
 	}
 
+	// keep the last known value after the volume detached
+	if e.Status.ActualSize != 0 {
+		v.Status.ActualSize = e.Status.ActualSize
+	}
+
 	// 1. remove ERR replicas
 	// 2. count RW replicas
 	healthyCount := 0
@@ -370,19 +427,19 @@ func (vc *VolumeController) ReconcileEngineReplicaState(v *longhorn.Volume, e *l
 	if healthyCount == 0 { // no healthy replica exists, going to faulted
 		v.Status.Robustness = types.VolumeRobustnessFaulted
 		if oldRobustness != types.VolumeRobustnessFaulted {
-			vc.eventRecorder.Eventf(v, v1.EventTypeWarning, EventReasonFaulted, "volume %v became faulted", v.Name)
+			vc.eventRecorder.Eventf(v, v1.EventTypeWarning, types.EventReasonFaulted, "volume %v became faulted", v.Name)
 		}
 		// detach the volume
 		v.Spec.NodeID = ""
 	} else if healthyCount >= v.Spec.NumberOfReplicas {
 		v.Status.Robustness = types.VolumeRobustnessHealthy
 		if oldRobustness == types.VolumeRobustnessDegraded {
-			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, EventReasonHealthy, "volume %v became healthy", v.Name)
+			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonHealthy, "volume %v became healthy", v.Name)
 		}
 	} else { // healthyCount < v.Spec.NumberOfReplicas
 		v.Status.Robustness = types.VolumeRobustnessDegraded
 		if oldRobustness != types.VolumeRobustnessDegraded {
-			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, EventReasonDegraded, "volume %v became degraded", v.Name)
+			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonDegraded, "volume %v became degraded", v.Name)
 		}
 		// start rebuilding if necessary
 		if err = vc.replenishReplicas(v, rs); err != nil {
@@ -473,7 +530,7 @@ func (vc *VolumeController) ReconcileVolumeState(v *longhorn.Volume, e *longhorn
 	if e.Status.CurrentState == types.InstanceStateError {
 		// Engine dead unexpected, force detaching the volume
 		logrus.Errorf("Engine of volume %v dead unexpectedly, detach the volume", v.Name)
-		vc.eventRecorder.Eventf(v, v1.EventTypeWarning, EventReasonFaulted, "Engine of volume %v dead unexpectedly, detach the volume", v.Name)
+		vc.eventRecorder.Eventf(v, v1.EventTypeWarning, types.EventReasonFaulted, "Engine of volume %v dead unexpectedly, detach the volume", v.Name)
 		v.Spec.NodeID = ""
 	}
 
@@ -536,10 +593,30 @@ func (vc *VolumeController) ReconcileVolumeState(v *longhorn.Volume, e *longhorn
 
 		v.Status.State = types.VolumeStateDetached
 		if oldState != v.Status.State {
-			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, EventReasonDetached, "volume %v has been detached", v.Name)
+			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonDetached, "volume %v has been detached", v.Name)
 		}
 
 	} else {
+		if e.Spec.DesireState == types.InstanceStateRunning &&
+			(e.Spec.DisableFrontend != v.Spec.DisableFrontend || e.Spec.NodeID != v.Spec.NodeID) {
+			// the workload took over the volume attached for the
+			// recurring jobs, restart the engine with the frontend
+			logrus.Infof("Restarting engine of volume %v on %v with frontend disabled %v",
+				v.Name, v.Spec.NodeID, v.Spec.DisableFrontend)
+			v.Status.State = types.VolumeStateAttaching
+			v.Status.Endpoint = ""
+			e.Spec.NodeID = ""
+			e.Spec.DesireState = types.InstanceStateStopped
+			e, err = vc.ds.UpdateEngine(e)
+			return err
+		}
+		// wait for the engine to stop before starting it again
+		if e.Spec.DesireState != types.InstanceStateRunning &&
+			(e.Status.CurrentState == types.InstanceStateRunning || e.Status.CurrentState == types.InstanceStateStopping) {
+			v.Status.State = types.VolumeStateAttaching
+			return nil
+		}
+
 		// if engine was running, then we are attached already
 		// (but we may still need to start rebuilding replicas)
 		if e.Status.CurrentState != types.InstanceStateRunning {
@@ -547,6 +624,15 @@ func (vc *VolumeController) ReconcileVolumeState(v *longhorn.Volume, e *longhorn
 			v.Status.State = types.VolumeStateAttaching
 		}
 
+		// replicas cannot start before the backing image is available on the node
+		ready, err := vc.isBackingImageReadyForReplicas(v, rs)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			return nil
+		}
+
 		replicaUpdated := false
 		for _, r := range rs {
 			if r.Spec.FailedAt == "" &&
@@ -592,6 +678,7 @@ func (vc *VolumeController) ReconcileVolumeState(v *longhorn.Volume, e *longhorn
 					e.Spec.NodeID, v.Spec.NodeID)
 			}
 			e.Spec.NodeID = v.Spec.NodeID
+			e.Spec.DisableFrontend = v.Spec.DisableFrontend
 			e.Spec.ReplicaAddressMap = replicaAddressMap
 			e.Spec.DesireState = types.InstanceStateRunning
 			engineUpdated = true
@@ -616,7 +703,7 @@ func (vc *VolumeController) ReconcileVolumeState(v *longhorn.Volume, e *longhorn
 		v.Status.Endpoint = e.Status.Endpoint
 		v.Status.State = types.VolumeStateAttached
 		if oldState != v.Status.State {
-			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, EventReasonAttached, "volume %v has been attached to %v", v.Name, v.Spec.NodeID)
+			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonAttached, "volume %v has been attached to %v", v.Name, v.Spec.NodeID)
 		}
 	}
 	return nil
@@ -854,6 +941,7 @@ func (vc *VolumeController) createReplica(v *longhorn.Volume) (*longhorn.Replica
 				DesireState: types.InstanceStateStopped,
 				OwnerID:     vc.controllerID,
 			},
+			BackingImage: v.Spec.BackingImage,
 		},
 	}
 	if v.Spec.FromBackup != "" {
@@ -895,6 +983,22 @@ func (vc *VolumeController) enqueueVolume(v *longhorn.Volume) {
 	vc.queue.AddRateLimited(key)
 }
 
+// enqueueRecurringJobVolumes enqueues the volumes selected by a recurring job
+// so their cron jobs can be updated
+func (vc *VolumeController) enqueueRecurringJobVolumes(volumeNames []string) {
+	for _, name := range volumeNames {
+		volume, err := vc.ds.GetVolume(name)
+		if err != nil || volume == nil {
+			continue
+		}
+		// Not ours
+		if volume.Spec.OwnerID != vc.controllerID {
+			continue
+		}
+		vc.enqueueVolume(volume)
+	}
+}
+
 func (vc *VolumeController) enqueueControlleeChange(obj interface{}) {
 	metaObj, err := meta.Accessor(obj)
 	if err != nil {
@@ -943,7 +1047,9 @@ func (vc *VolumeController) getOwnerReferencesForVolume(v *longhorn.Volume) []me
 	}
 }
 
-func (vc *VolumeController) createCronJob(v *longhorn.Volume, job *types.RecurringJob, suspend bool, backupTarget string, credentialSecret string) *batchv1beta1.CronJob {
+// createCronJob builds the cron job for the job set on the volume, or the
+// job from RecurringJob recurringJobName which limits the concurrency
+func (vc *VolumeController) createCronJob(v *longhorn.Volume, job *types.RecurringJob, suspend bool, backupTarget string, credentialSecret string, recurringJobName string) *batchv1beta1.CronJob {
 	backoffLimit := int32(CronJobBackoffLimit)
 	cmd := []string{
 		"longhorn-manager", "-d",
@@ -952,8 +1058,31 @@ func (vc *VolumeController) createCronJob(v *longhorn.Volume, job *types.Recurri
 		"--labels", LabelRecurringJob + "=" + job.Name,
 		"--retain", strconv.Itoa(job.Retain),
 	}
+	if !job.Retention.IsEmpty() {
+		cmd = append(cmd,
+			"--retain-hourly", strconv.Itoa(job.Retention.Hourly),
+			"--retain-daily", strconv.Itoa(job.Retention.Daily),
+			"--retain-weekly", strconv.Itoa(job.Retention.Weekly),
+			"--retain-monthly", strconv.Itoa(job.Retention.Monthly),
+		)
+	}
 	if job.Type == types.RecurringJobTypeBackup {
 		cmd = append(cmd, "--backuptarget", backupTarget)
+		if job.BackupTarget != "" {
+			cmd = append(cmd, "--backuptarget-name", job.BackupTarget)
+		}
+	}
+	if job.Freeze {
+		cmd = append(cmd, "--freeze")
+	}
+	if job.Type == types.RecurringJobTypeTrim {
+		cmd = []string{
+			"longhorn-manager", "-d",
+			"trim", v.Name,
+		}
+	}
+	if recurringJobName != "" {
+		cmd = append(cmd, "--recurring-job", recurringJobName)
 	}
 	// for mounting inside container
 	privilege := true
@@ -1023,27 +1152,445 @@ func (vc *VolumeController) createCronJob(v *longhorn.Volume, job *types.Recurri
 	if job.Type == types.RecurringJobTypeBackup {
 		util.ConfigEnvWithCredential(backupTarget, credentialSecret, &cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0])
 	}
+	if job.Type == types.RecurringJobTypeTrim || job.Freeze {
+		// fstrim and fsfreeze run in the host mount namespace against the
+		// volume device
+		podSpec := &cronJob.Spec.JobTemplate.Spec.Template.Spec
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts,
+			v1.VolumeMount{
+				Name:      "proc",
+				MountPath: util.HostProcPath,
+			},
+			v1.VolumeMount{
+				Name:      "dev",
+				MountPath: util.HostDevPath,
+			})
+		podSpec.Volumes = append(podSpec.Volumes,
+			v1.Volume{
+				Name: "proc",
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: "/proc/",
+					},
+				},
+			},
+			v1.Volume{
+				Name: "dev",
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: "/dev/",
+					},
+				},
+			})
+	}
 	return cronJob
 }
 
-func (vc *VolumeController) updateRecurringJobs(v *longhorn.Volume) (err error) {
+// reconcileVolumeImport attaches the volume to the owner node before the
+// first attach, fills it with the image by a job, then detaches it. A failed
+// import is kept until the user retries it by the action importRetry
+func (vc *VolumeController) reconcileVolumeImport(v *longhorn.Volume) (err error) {
 	defer func() {
-		err = errors.Wrapf(err, "fail to update recurring jobs for %v", v.Name)
+		err = errors.Wrapf(err, "fail to reconcile image import for %v", v.Name)
+	}()
+
+	// the recycled volume is kept detached
+	if v.Spec.FromImage == "" || v.Spec.RecycledAt != "" {
+		return nil
+	}
+	if v.Status.ImportState == types.VolumeImportStateCompleted ||
+		v.Status.ImportState == types.VolumeImportStateFailed {
+		return nil
+	}
+	if v.Status.ImportState == "" {
+		v.Status.ImportState = types.VolumeImportStatePending
+	}
+	if v.Spec.NodeID == "" {
+		v.Spec.NodeID = vc.controllerID
+		return nil
+	}
+	if v.Status.State != types.VolumeStateAttached {
+		return nil
+	}
+
+	jobName := types.GetImportJobNameForVolume(v.Name)
+	job, err := vc.kubeClient.BatchV1().Jobs(vc.namespace).Get(jobName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	// wait for the job of the failed import to be gone before retrying
+	if err == nil && job.DeletionTimestamp != nil {
+		return nil
+	}
+	if apierrors.IsNotFound(err) {
+		jobSpec, err := vc.createImportJobSpec(v)
+		if err != nil {
+			return err
+		}
+		if _, err := vc.kubeClient.BatchV1().Jobs(vc.namespace).Create(jobSpec); err != nil {
+			return errors.Wrap(err, "failed to create import job")
+		}
+		v.Status.ImportState = types.VolumeImportStateImporting
+		vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonImporting, "Importing volume %v from %v", v.Name, v.Spec.FromImage)
+		return nil
+	}
+
+	if job.Status.Succeeded > 0 {
+		v.Status.ImportState = types.VolumeImportStateCompleted
+		v.Status.ImportProgress = 100
+		v.Status.ImportError = ""
+		vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonImported, "Imported volume %v from %v", v.Name, v.Spec.FromImage)
+	} else if isJobFailed(job) {
+		v.Status.ImportState = types.VolumeImportStateFailed
+		if v.Status.ImportError == "" {
+			v.Status.ImportError = "import job failed"
+		}
+		vc.eventRecorder.Eventf(v, v1.EventTypeWarning, types.EventReasonFailedImporting, "Failed to import volume %v from %v: %v", v.Name, v.Spec.FromImage, v.Status.ImportError)
+	} else {
+		return nil
+	}
+
+	propagation := metav1.DeletePropagationForeground
+	if err := vc.kubeClient.BatchV1().Jobs(vc.namespace).Delete(jobName, &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete import job")
+	}
+	// detach the volume now
+	v.Spec.NodeID = ""
+	return nil
+}
+
+// expireAutoAttachedJobs drops the recurring jobs which attached the volume
+// longer than RecurringJobRunTimeout ago, e.g. the job pod was killed before
+// detaching it. The volume is detached once no job is left.
+func (vc *VolumeController) expireAutoAttachedJobs(v *longhorn.Volume) {
+	if len(v.Spec.AutoAttachedForJobs) == 0 {
+		return
+	}
+	for job, attachedAt := range v.Spec.AutoAttachedForJobs {
+		t, err := util.ParseTime(attachedAt)
+		if err == nil && time.Since(t) < types.RecurringJobRunTimeout {
+			continue
+		}
+		logrus.Warnf("Recurring job %v attached volume %v at %v and didn't detach it, releasing it", job, v.Name, attachedAt)
+		delete(v.Spec.AutoAttachedForJobs, job)
+	}
+	if len(v.Spec.AutoAttachedForJobs) == 0 {
+		v.Spec.AutoAttachedForJobs = nil
+		v.Spec.NodeID = ""
+		v.Spec.DisableFrontend = false
+		logrus.Infof("Detaching volume %v attached for the recurring jobs", v.Name)
+	}
+}
+
+// isRecycleExpired returns true if the volume has been in the recycle bin for
+// longer than SettingsInfo.RecycleBinRetention
+func (vc *VolumeController) isRecycleExpired(v *longhorn.Volume) (bool, error) {
+	setting, err := vc.ds.GetSetting()
+	if err != nil {
+		return false, err
+	}
+	if setting.RecycleBinRetention <= 0 {
+		return false, nil
+	}
+	return util.TimestampAfterTimeout(v.Spec.RecycledAt, time.Duration(setting.RecycleBinRetention)*time.Hour), nil
+}
+
+// reconcileVolumeRestore attaches the volume to the owner node, so the
+// replicas can restore from the backup during launch, reports the progress
+// collected by the replicas, then detaches the volume once the restore is
+// done. A failed restore is kept until the user retries it by the action
+// restoreRetry
+func (vc *VolumeController) reconcileVolumeRestore(v *longhorn.Volume, rs map[string]*longhorn.Replica) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to reconcile restore for %v", v.Name)
 	}()
 
-	suspended := false
+	// the recycled volume is kept detached
+	if v.Spec.FromBackup == "" || v.Spec.RecycledAt != "" {
+		return nil
+	}
+	cond, exists := v.Status.Conditions[types.VolumeConditionTypeRestoring]
+	if exists && cond.Status != types.ConditionStatusTrue {
+		return nil
+	}
+	if !exists {
+		// the volume was restored before the progress was tracked
+		for _, r := range rs {
+			if r.Spec.HealthyAt != "" {
+				vc.setVolumeCondition(v, types.VolumeConditionTypeRestoring, types.ConditionStatusFalse,
+					types.VolumeConditionReasonRestoreCompleted, "")
+				return nil
+			}
+		}
+		v.Status.RestoreRequired = true
+		vc.setVolumeCondition(v, types.VolumeConditionTypeRestoring, types.ConditionStatusTrue,
+			types.VolumeConditionReasonRestoreInProgress, fmt.Sprintf("Restoring from %v", v.Spec.FromBackup))
+		vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonRestoring, "Restoring volume %v from %v", v.Name, v.Spec.FromBackup)
+	}
+	if v.Spec.NodeID == "" {
+		// replace the replicas dropped by the failed restore before retrying
+		if err := vc.replenishReplicas(v, rs); err != nil {
+			return err
+		}
+		v.Spec.NodeID = vc.controllerID
+		return nil
+	}
+
+	status := types.RestoreStatus{}
+	failedReplicas := []*longhorn.Replica{}
+	first := true
+	for _, r := range rs {
+		if r.Spec.FailedAt != "" {
+			continue
+		}
+		rStatus := r.Status.RestoreStatus
+		if rStatus.Error != "" {
+			failedReplicas = append(failedReplicas, r)
+		}
+		// the slowest replica decides the progress of the volume
+		if first || rStatus.Progress < status.Progress {
+			status.Progress = rStatus.Progress
+			status.RestoredBytes = rStatus.RestoredBytes
+		}
+		first = false
+		if rStatus.IsRestoring {
+			status.IsRestoring = true
+		}
+		if status.CurrentBackup == "" {
+			status.CurrentBackup = rStatus.CurrentBackup
+		}
+		if status.Error == "" && rStatus.Error != "" {
+			status.Error = fmt.Sprintf("replica %v: %v", r.Name, rStatus.Error)
+		}
+	}
+
+	if status.Error != "" {
+		// the partially restored replicas will be replaced on retry
+		for _, r := range failedReplicas {
+			r.Spec.FailedAt = vc.nowHandler()
+			r, err = vc.ds.UpdateReplica(r)
+			if err != nil {
+				return err
+			}
+			rs[r.Name] = r
+		}
+		status.IsRestoring = false
+		v.Status.RestoreStatus = status
+		vc.setVolumeCondition(v, types.VolumeConditionTypeRestoring, types.ConditionStatusFalse,
+			types.VolumeConditionReasonRestoreFailure, status.Error)
+		vc.eventRecorder.Eventf(v, v1.EventTypeWarning, types.EventReasonFailedRestoring, "Failed to restore volume %v from %v: %v", v.Name, v.Spec.FromBackup, status.Error)
+		// detach the volume now, it cannot be attached until the user
+		// retries the restore
+		v.Spec.NodeID = ""
+		return nil
+	}
+
+	// replicas only become running after the restore completed
 	if v.Status.State != types.VolumeStateAttached {
-		suspended = true
+		v.Status.RestoreStatus = status
+		return nil
+	}
+
+	status.IsRestoring = false
+	status.Progress = 100
+	v.Status.RestoreStatus = status
+	v.Status.RestoreRequired = false
+	vc.setVolumeCondition(v, types.VolumeConditionTypeRestoring, types.ConditionStatusFalse,
+		types.VolumeConditionReasonRestoreCompleted, "")
+	vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonRestored, "Restored volume %v from %v", v.Name, v.Spec.FromBackup)
+	// detach the volume now
+	v.Spec.NodeID = ""
+	return nil
+}
+
+// reconcileRecurringJobStatus drops the run history of the jobs no longer
+// applied to the volume, and raises the condition RecurringJobFailing once a
+// job failed failureThreshold times in a row
+func (vc *VolumeController) reconcileRecurringJobStatus(v *longhorn.Volume, jobNames map[string]struct{}, failureThreshold int) {
+	names := []string{}
+	for name := range v.Status.RecurringJobStatus {
+		if _, exists := jobNames[name]; !exists {
+			delete(v.Status.RecurringJobStatus, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	failures := []string{}
+	for _, name := range names {
+		status := v.Status.RecurringJobStatus[name]
+		if failureThreshold <= 0 || status.ConsecutiveFailures < failureThreshold {
+			continue
+		}
+		failure := fmt.Sprintf("job %v failed %v times in a row", name, status.ConsecutiveFailures)
+		if len(status.Runs) != 0 && status.Runs[0].Error != "" {
+			failure += ": " + status.Runs[0].Error
+		}
+		failures = append(failures, failure)
+	}
+
+	cond, exists := v.Status.Conditions[types.VolumeConditionTypeRecurringJobFailing]
+	failing := exists && cond.Status == types.ConditionStatusTrue
+	if len(failures) == 0 {
+		if failing {
+			vc.setVolumeCondition(v, types.VolumeConditionTypeRecurringJobFailing, types.ConditionStatusFalse,
+				types.VolumeConditionReasonRecurringJobRecovered, "")
+			vc.eventRecorder.Eventf(v, v1.EventTypeNormal, types.EventReasonRecurringJobRecovered, "Recurring jobs of volume %v recovered", v.Name)
+		}
+		return
+	}
+	message := strings.Join(failures, "; ")
+	vc.setVolumeCondition(v, types.VolumeConditionTypeRecurringJobFailing, types.ConditionStatusTrue,
+		types.VolumeConditionReasonRecurringJobFailed, message)
+	if !failing {
+		vc.eventRecorder.Eventf(v, v1.EventTypeWarning, types.EventReasonRecurringJobFailed, "Recurring jobs of volume %v are failing: %v", v.Name, message)
+	}
+}
+
+func (vc *VolumeController) setVolumeCondition(v *longhorn.Volume, conditionType string, status types.ConditionStatus, reason, message string) {
+	if v.Status.Conditions == nil {
+		v.Status.Conditions = map[string]types.Condition{}
+	}
+	cond, exists := v.Status.Conditions[conditionType]
+	if !exists || cond.Status != status {
+		cond.LastTransitionTime = vc.nowHandler()
+	}
+	cond.Type = conditionType
+	cond.Status = status
+	cond.Reason = reason
+	cond.Message = message
+	v.Status.Conditions[conditionType] = cond
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (vc *VolumeController) createImportJobSpec(v *longhorn.Volume) (*batchv1.Job, error) {
+	source, err := types.ParseImageURL(v.Spec.FromImage)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status.Endpoint == "" {
+		return nil, fmt.Errorf("cannot find block device for volume %v", v.Name)
+	}
+
+	jobName := types.GetImportJobNameForVolume(v.Name)
+	from := v.Spec.FromImage
+	volumes := []v1.Volume{
+		{
+			Name: "dev",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: "/dev",
+				},
+			},
+		},
+		{
+			Name: "scratch",
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	volumeMounts := []v1.VolumeMount{
+		{
+			Name:      "dev",
+			MountPath: importHostDevDirectory,
+		},
+		{
+			Name:      "scratch",
+			MountPath: importScratchDirectory,
+		},
+	}
+	if source.IsLocalFile {
+		volumes = append(volumes, v1.Volume{
+			Name: "source",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: filepath.Dir(source.Path),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      "source",
+			MountPath: importSourceDirectory,
+			ReadOnly:  true,
+		})
+		from = filepath.Join(importSourceDirectory, filepath.Base(source.Path))
+	}
+
+	cmd := []string{
+		"longhorn-manager", "-d",
+		"import", v.Name,
+		"--from", from,
+		"--format", string(v.Spec.FromImageFormat),
+		"--device", filepath.Join(importHostDevDirectory, strings.TrimPrefix(v.Status.Endpoint, "/dev/")),
+		"--scratch-dir", importScratchDirectory,
+	}
+
+	backoffLimit := int32(0)
+	privilege := true
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            jobName,
+			Namespace:       vc.namespace,
+			OwnerReferences: vc.getOwnerReferencesForVolume(v),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: jobName,
+				},
+				Spec: v1.PodSpec{
+					NodeName: v.Spec.NodeID,
+					Containers: []v1.Container{
+						{
+							Name:    jobName,
+							Image:   vc.ManagerImage,
+							Command: cmd,
+							SecurityContext: &v1.SecurityContext{
+								Privileged: &privilege,
+							},
+							Env: []v1.EnvVar{
+								{
+									Name: "POD_NAMESPACE",
+									ValueFrom: &v1.EnvVarSource{
+										FieldRef: &v1.ObjectFieldSelector{
+											FieldPath: "metadata.namespace",
+										},
+									},
+								},
+							},
+							VolumeMounts: volumeMounts,
+						},
+					},
+					Volumes:            volumes,
+					ServiceAccountName: vc.ServiceAccount,
+					RestartPolicy:      v1.RestartPolicyNever,
+				},
+			},
+		},
 	}
+	return job, nil
+}
+
+func (vc *VolumeController) updateRecurringJobs(v *longhorn.Volume) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "fail to update recurring jobs for %v", v.Name)
+	}()
 
-	backupTarget := ""
-	backupCredentialSecret := ""
 	setting, err := vc.ds.GetSetting()
 	if err != nil {
 		return err
 	}
-	backupTarget = setting.BackupTarget
-	backupCredentialSecret = setting.BackupTargetCredentialSecret
 
 	// the cronjobs are RO in the map, but not the map itself
 	appliedCronJobROs, err := vc.ds.ListVolumeCronJobROs(v.Name)
@@ -1051,15 +1598,47 @@ func (vc *VolumeController) updateRecurringJobs(v *longhorn.Volume) (err error)
 		return err
 	}
 
+	jobs, err := vc.ds.ListVolumeAppliedRecurringJobs(v)
+	if err != nil {
+		return err
+	}
 	currentCronJobs := make(map[string]*batchv1beta1.CronJob)
-	for _, job := range v.Spec.RecurringJobs {
-		if backupTarget == "" && job.Type == types.RecurringJobTypeBackup {
-			return fmt.Errorf("cannot backup with empty backup target")
+	jobNames := map[string]struct{}{}
+	for name, job := range jobs {
+		backupTarget := &types.BackupTargetSpec{}
+		if job.Type == types.RecurringJobTypeBackup {
+			if backupTarget, err = vc.ds.GetBackupTargetSpec(job.BackupTarget); err != nil {
+				if !job.FromRecurringJob {
+					return err
+				}
+				// don't block the other jobs of the volume
+				logrus.Warnf("Cannot apply recurring job %v to volume %v: %v", name, v.Name, err)
+				continue
+			}
+		}
+		if backupTarget.URL == "" && job.Type == types.RecurringJobTypeBackup {
+			if !job.FromRecurringJob {
+				return fmt.Errorf("cannot backup with empty backup target")
+			}
+			// don't block the other jobs of the volume
+			logrus.Warnf("Cannot apply recurring job %v to volume %v with empty backup target", name, v.Name)
+			continue
 		}
+		jobNames[name] = struct{}{}
 
-		cronJob := vc.createCronJob(v, &job, suspended, backupTarget, backupCredentialSecret)
+		// the owner manager schedules the job, see app.RecurringJobScheduler
+		if types.IsRecurringJobRunByManager(setting.RecurringJobMode, job.Type) {
+			continue
+		}
+		recurringJobName := ""
+		if job.FromRecurringJob {
+			recurringJobName = name
+		}
+		suspended := !IsRecurringJobRunnable(v, job.Type)
+		cronJob := vc.createCronJob(v, &job.RecurringJob, suspended, backupTarget.URL, backupTarget.CredentialSecret, recurringJobName)
 		currentCronJobs[cronJob.Name] = cronJob
 	}
+	vc.reconcileRecurringJobStatus(v, jobNames, setting.RecurringJobFailureThreshold)
 
 	for name, cronJob := range currentCronJobs {
 		if appliedCronJobROs[name] == nil {
@@ -1085,6 +1664,21 @@ func (vc *VolumeController) updateRecurringJobs(v *longhorn.Volume) (err error)
 	return nil
 }
 
+// IsRecurringJobRunnable checks if the job can run on the volume now. The
+// snapshot and backup jobs attach the detached volume temporarily unless
+// it's disabled, the trim jobs need the filesystem of the attached workload.
+func IsRecurringJobRunnable(v *longhorn.Volume, jobType types.RecurringJobType) bool {
+	if jobType == types.RecurringJobTypeTrim {
+		return v.Status.State == types.VolumeStateAttached && !v.Spec.DisableFrontend
+	}
+	if v.Status.State == types.VolumeStateAttached {
+		return true
+	}
+	return v.Status.State == types.VolumeStateDetached && !v.Spec.DisableAutoAttach &&
+		!v.Status.RestoreRequired && v.Spec.RecycledAt == "" &&
+		(v.Spec.FromImage == "" || v.Status.ImportState == types.VolumeImportStateCompleted)
+}
+
 func (vc *VolumeController) isVolumeUpgrading(v *longhorn.Volume) bool {
 	return v.Status.CurrentImage != v.Spec.EngineImage
 }
@@ -1100,3 +1694,27 @@ func (vc *VolumeController) getEngineImage(image string) (*longhorn.EngineImage,
 	}
 	return img, nil
 }
+
+func (vc *VolumeController) isBackingImageReadyForReplicas(v *longhorn.Volume, rs map[string]*longhorn.Replica) (bool, error) {
+	if v.Spec.BackingImage == "" {
+		return true, nil
+	}
+	bi, err := vc.ds.GetBackingImage(v.Spec.BackingImage)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to get backing image %v", v.Spec.BackingImage)
+	}
+	if bi == nil {
+		return false, fmt.Errorf("cannot find backing image %v", v.Spec.BackingImage)
+	}
+	for _, r := range rs {
+		if r.Spec.FailedAt != "" || r.Spec.DesireState == types.InstanceStateRunning {
+			continue
+		}
+		if bi.Status.DiskStatusMap[r.Spec.NodeID].State != types.BackingImageDiskStateReady {
+			logrus.Debugf("Waiting for backing image %v to be ready on node %v for replica %v",
+				bi.Name, r.Spec.NodeID, r.Name)
+			return false, nil
+		}
+	}
+	return true, nil
+}
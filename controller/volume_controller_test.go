@@ -44,16 +44,26 @@ func newTestVolumeController(lhInformerFactory lhinformerfactory.SharedInformerF
 	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
 	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
 	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
 
 	podInformer := kubeInformerFactory.Core().V1().Pods()
 	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
 	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
 
 	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
-		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer)
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
 	initSettings(ds)
 
-	vc := NewVolumeController(ds, scheme.Scheme, volumeInformer, engineInformer, replicaInformer, kubeClient, TestNamespace, controllerID, TestServiceAccount, TestManagerImage)
+	vc := NewVolumeController(ds, scheme.Scheme, volumeInformer, engineInformer, replicaInformer, recurringJobInformer, kubeClient, TestNamespace, controllerID, TestServiceAccount, TestManagerImage)
 
 	fakeRecorder := record.NewFakeRecorder(100)
 	vc.eventRecorder = fakeRecorder
@@ -61,6 +71,7 @@ func newTestVolumeController(lhInformerFactory lhinformerfactory.SharedInformerF
 	vc.vStoreSynced = alwaysReady
 	vc.rStoreSynced = alwaysReady
 	vc.eStoreSynced = alwaysReady
+	vc.rjStoreSynced = alwaysReady
 	vc.nowHandler = getTestNow
 
 	return vc
@@ -159,6 +170,55 @@ func (s *TestSuite) TestVolumeLifeCycle(c *C) {
 	}
 	testCases["volume attached"] = tc
 
+	// volume attaching for the recurring jobs, start engine without frontend
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.NodeID = TestNode1
+	tc.volume.Spec.DisableFrontend = true
+	tc.volume.Spec.AutoAttachedForJobs = map[string]string{"backup": util.Now()}
+	for _, r := range tc.replicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+		r.Spec.NodeID = util.RandomID()
+		r.Status.CurrentState = types.InstanceStateRunning
+		r.Status.IP = randomIP()
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Status.State = types.VolumeStateAttaching
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	tc.expectEngine.Spec.NodeID = tc.volume.Spec.NodeID
+	tc.expectEngine.Spec.DisableFrontend = true
+	tc.expectEngine.Spec.DesireState = types.InstanceStateRunning
+	for name, r := range tc.expectReplicas {
+		tc.expectEngine.Spec.ReplicaAddressMap[name] = r.Status.IP
+	}
+	testCases["volume attaching without frontend - start controller"] = tc
+
+	// volume attached for the recurring jobs taken over by the workload
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.NodeID = TestNode2
+	tc.volume.Status.State = types.VolumeStateAttached
+	tc.volume.Status.Robustness = types.VolumeRobustnessHealthy
+	tc.engine.Spec.NodeID = TestNode1
+	tc.engine.Spec.DisableFrontend = true
+	tc.engine.Spec.DesireState = types.InstanceStateRunning
+	tc.engine.Status.CurrentState = types.InstanceStateRunning
+	tc.engine.Status.IP = randomIP()
+	tc.engine.Status.ReplicaModeMap = map[string]types.ReplicaMode{}
+	for name, r := range tc.replicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+		r.Spec.NodeID = util.RandomID()
+		r.Spec.HealthyAt = getTestNow()
+		r.Status.CurrentState = types.InstanceStateRunning
+		r.Status.IP = randomIP()
+		tc.engine.Spec.ReplicaAddressMap[name] = r.Status.IP
+		tc.engine.Status.ReplicaModeMap[name] = types.ReplicaModeRW
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Status.State = types.VolumeStateAttaching
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	tc.expectEngine.Spec.NodeID = ""
+	tc.expectEngine.Spec.DesireState = types.InstanceStateStopped
+	testCases["volume taken over - restart controller with frontend"] = tc
+
 	// volume detaching - stop engine
 	tc = generateVolumeTestCaseTemplate()
 	tc.volume.Spec.NodeID = ""
@@ -187,6 +247,40 @@ func (s *TestSuite) TestVolumeLifeCycle(c *C) {
 	tc.expectEngine.Spec.DesireState = types.InstanceStateStopped
 	testCases["volume detaching - stop engine"] = tc
 
+	// volume attached for the recurring job whose pod died without
+	// detaching it
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.NodeID = TestNode1
+	tc.volume.Spec.DisableFrontend = true
+	tc.volume.Spec.AutoAttachedForJobs = map[string]string{
+		"backup": util.FormatTimeZ(time.Now().Add(-2 * types.RecurringJobRunTimeout)),
+	}
+	tc.volume.Status.Robustness = types.VolumeRobustnessHealthy
+	tc.engine.Spec.NodeID = TestNode1
+	tc.engine.Spec.DisableFrontend = true
+	tc.engine.Spec.DesireState = types.InstanceStateRunning
+	tc.engine.Status.CurrentState = types.InstanceStateRunning
+	tc.engine.Status.IP = randomIP()
+	tc.engine.Status.ReplicaModeMap = map[string]types.ReplicaMode{}
+	for name, r := range tc.replicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+		r.Spec.NodeID = util.RandomID()
+		r.Spec.HealthyAt = getTestNow()
+		r.Status.CurrentState = types.InstanceStateRunning
+		r.Status.IP = randomIP()
+		tc.engine.Spec.ReplicaAddressMap[name] = r.Status.IP
+		tc.engine.Status.ReplicaModeMap[name] = types.ReplicaModeRW
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Spec.NodeID = ""
+	tc.expectVolume.Spec.DisableFrontend = false
+	tc.expectVolume.Spec.AutoAttachedForJobs = nil
+	tc.expectVolume.Status.State = types.VolumeStateDetaching
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	tc.expectEngine.Spec.NodeID = ""
+	tc.expectEngine.Spec.DesireState = types.InstanceStateStopped
+	testCases["volume attached for expired recurring job - stop engine"] = tc
+
 	// volume detaching - stop replicas
 	tc = generateVolumeTestCaseTemplate()
 	tc.volume.Spec.NodeID = ""
@@ -221,6 +315,301 @@ func (s *TestSuite) TestVolumeLifeCycle(c *C) {
 	s.runTestCases(c, testCases)
 }
 
+func newRestoringCondition(status types.ConditionStatus, reason, message string) map[string]types.Condition {
+	return map[string]types.Condition{
+		types.VolumeConditionTypeRestoring: {
+			Type:               types.VolumeConditionTypeRestoring,
+			Status:             status,
+			LastTransitionTime: getTestNow(),
+			Reason:             reason,
+			Message:            message,
+		},
+	}
+}
+
+func (s *TestSuite) TestVolumeRestore(c *C) {
+	var tc *VolumeTestCase
+	testCases := map[string]*VolumeTestCase{}
+
+	// restore starts, attach to the owner node and start replicas
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.FromBackup = TestRestoreFrom
+	tc.engine.Status.CurrentState = types.InstanceStateStopped
+	for _, r := range tc.replicas {
+		r.Status.CurrentState = types.InstanceStateStopped
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Spec.NodeID = TestOwnerID1
+	tc.expectVolume.Status.State = types.VolumeStateAttaching
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	tc.expectVolume.Status.RestoreRequired = true
+	tc.expectVolume.Status.Conditions = newRestoringCondition(types.ConditionStatusTrue,
+		types.VolumeConditionReasonRestoreInProgress, "Restoring from "+TestRestoreFrom)
+	for _, r := range tc.expectReplicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+	}
+	testCases["restore starts"] = tc
+
+	// restore in progress, the slowest replica decides the progress
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.FromBackup = TestRestoreFrom
+	tc.volume.Spec.NodeID = TestNode1
+	tc.volume.Status.RestoreRequired = true
+	tc.volume.Status.Conditions = newRestoringCondition(types.ConditionStatusTrue,
+		types.VolumeConditionReasonRestoreInProgress, "Restoring from "+TestRestoreFrom)
+	progress := 30
+	for _, r := range tc.replicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+		r.Spec.NodeID = TestNode1
+		r.Status.CurrentState = types.InstanceStateStarting
+		r.Status.RestoreStatus = types.RestoreStatus{
+			IsRestoring:   true,
+			Progress:      progress,
+			RestoredBytes: int64(progress) * TestVolumeSize / 100,
+			CurrentBackup: TestRestoreName,
+		}
+		progress += 30
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Status.State = types.VolumeStateAttaching
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	tc.expectVolume.Status.RestoreStatus = types.RestoreStatus{
+		IsRestoring:   true,
+		Progress:      30,
+		RestoredBytes: 30 * TestVolumeSize / 100,
+		CurrentBackup: TestRestoreName,
+	}
+	testCases["restore in progress"] = tc
+
+	// restore completed after the volume attached, detach it
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.FromBackup = TestRestoreFrom
+	tc.volume.Spec.NodeID = TestNode1
+	tc.volume.Status.State = types.VolumeStateAttached
+	tc.volume.Status.Endpoint = "/dev/" + tc.volume.Name
+	tc.volume.Status.Robustness = types.VolumeRobustnessHealthy
+	tc.volume.Status.RestoreRequired = true
+	tc.volume.Status.Conditions = newRestoringCondition(types.ConditionStatusTrue,
+		types.VolumeConditionReasonRestoreInProgress, "Restoring from "+TestRestoreFrom)
+	tc.engine.Spec.NodeID = TestNode1
+	tc.engine.Spec.DesireState = types.InstanceStateRunning
+	tc.engine.Status.CurrentState = types.InstanceStateRunning
+	tc.engine.Status.IP = randomIP()
+	tc.engine.Status.Endpoint = "/dev/" + tc.volume.Name
+	tc.engine.Status.ReplicaModeMap = map[string]types.ReplicaMode{}
+	for name, r := range tc.replicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+		r.Spec.NodeID = TestNode1
+		r.Spec.HealthyAt = getTestNow()
+		r.Status.CurrentState = types.InstanceStateRunning
+		r.Status.IP = randomIP()
+		r.Status.RestoreStatus = types.RestoreStatus{
+			Progress:      100,
+			RestoredBytes: TestVolumeSize,
+			CurrentBackup: TestRestoreName,
+		}
+		tc.engine.Spec.ReplicaAddressMap[name] = r.Status.IP
+		tc.engine.Status.ReplicaModeMap[name] = types.ReplicaModeRW
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Spec.NodeID = ""
+	tc.expectVolume.Status.State = types.VolumeStateDetaching
+	tc.expectVolume.Status.Endpoint = ""
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	tc.expectVolume.Status.RestoreRequired = false
+	tc.expectVolume.Status.RestoreStatus = types.RestoreStatus{
+		Progress:      100,
+		RestoredBytes: TestVolumeSize,
+		CurrentBackup: TestRestoreName,
+	}
+	tc.expectVolume.Status.Conditions = newRestoringCondition(types.ConditionStatusFalse,
+		types.VolumeConditionReasonRestoreCompleted, "")
+	tc.expectEngine.Spec.NodeID = ""
+	tc.expectEngine.Spec.DesireState = types.InstanceStateStopped
+	testCases["restore completed"] = tc
+
+	// restore failed on a replica, drop it and detach the volume until the
+	// user retries the restore
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.FromBackup = TestRestoreFrom
+	tc.volume.Spec.NodeID = TestNode1
+	tc.volume.Status.RestoreRequired = true
+	tc.volume.Status.Conditions = newRestoringCondition(types.ConditionStatusTrue,
+		types.VolumeConditionReasonRestoreInProgress, "Restoring from "+TestRestoreFrom)
+	tc.engine.Status.CurrentState = types.InstanceStateStopped
+	var failedReplica *longhorn.Replica
+	for _, r := range tc.replicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+		r.Spec.NodeID = TestNode1
+		r.Status.CurrentState = types.InstanceStateStarting
+		r.Status.RestoreStatus = types.RestoreStatus{
+			IsRestoring:   true,
+			Progress:      50,
+			RestoredBytes: TestVolumeSize / 2,
+			CurrentBackup: TestRestoreName,
+		}
+		failedReplica = r
+	}
+	failedReplica.Status.CurrentState = types.InstanceStateError
+	failedReplica.Status.RestoreStatus.IsRestoring = false
+	failedReplica.Status.RestoreStatus.Error = "backup not found"
+	tc.copyCurrentToExpect()
+	restoreError := fmt.Sprintf("replica %v: backup not found", failedReplica.Name)
+	tc.expectVolume.Spec.NodeID = ""
+	tc.expectVolume.Status.State = types.VolumeStateDetaching
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	tc.expectVolume.Status.RestoreStatus = types.RestoreStatus{
+		Progress:      50,
+		RestoredBytes: TestVolumeSize / 2,
+		CurrentBackup: TestRestoreName,
+		Error:         restoreError,
+	}
+	tc.expectVolume.Status.Conditions = newRestoringCondition(types.ConditionStatusFalse,
+		types.VolumeConditionReasonRestoreFailure, restoreError)
+	for _, r := range tc.expectReplicas {
+		r.Spec.DesireState = types.InstanceStateStopped
+	}
+	// the failed replica never became healthy, it's cleaned up
+	delete(tc.expectReplicas, failedReplica.Name)
+	testCases["restore failed"] = tc
+
+	// the user retried the failed restore, replace the failed replica and
+	// attach to the owner node again
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.FromBackup = TestRestoreFrom
+	tc.volume.Status.RestoreRequired = true
+	tc.volume.Status.Conditions = newRestoringCondition(types.ConditionStatusTrue,
+		types.VolumeConditionReasonRestoreInProgress, "Restoring from "+TestRestoreFrom)
+	tc.engine.Status.CurrentState = types.InstanceStateStopped
+	for _, r := range tc.replicas {
+		r.Status.CurrentState = types.InstanceStateStopped
+		failedReplica = r
+	}
+	failedReplica.Spec.FailedAt = getTestNow()
+	failedReplica.Status.RestoreStatus.Error = "backup not found"
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Spec.NodeID = TestOwnerID1
+	tc.expectVolume.Status.State = types.VolumeStateAttaching
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	// the failed replica never became healthy, it's cleaned up
+	delete(tc.expectReplicas, failedReplica.Name)
+	for _, r := range tc.expectReplicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+	}
+	testCases["restore retried"] = tc
+
+	s.runTestCases(c, testCases)
+}
+
+func (s *TestSuite) TestVolumeImport(c *C) {
+	var tc *VolumeTestCase
+	testCases := map[string]*VolumeTestCase{}
+
+	// the failed import was reset by the user, attach to the owner node
+	// to run the import job again
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.FromImage = "http://example.com/image.qcow2"
+	tc.volume.Spec.FromImageFormat = types.VolumeImageFormatQcow2
+	tc.volume.Status.ImportState = types.VolumeImportStatePending
+	tc.engine.Status.CurrentState = types.InstanceStateStopped
+	for _, r := range tc.replicas {
+		r.Status.CurrentState = types.InstanceStateStopped
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Spec.NodeID = TestOwnerID1
+	tc.expectVolume.Status.State = types.VolumeStateAttaching
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	for _, r := range tc.expectReplicas {
+		r.Spec.DesireState = types.InstanceStateRunning
+	}
+	testCases["import retried"] = tc
+
+	// the failed import is kept detached until the user retries it
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.FromImage = "http://example.com/image.qcow2"
+	tc.volume.Spec.FromImageFormat = types.VolumeImageFormatQcow2
+	tc.volume.Status.ImportState = types.VolumeImportStateFailed
+	tc.volume.Status.ImportError = "import job failed"
+	tc.engine.Status.CurrentState = types.InstanceStateStopped
+	for _, r := range tc.replicas {
+		r.Status.CurrentState = types.InstanceStateStopped
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Status.State = types.VolumeStateDetached
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	testCases["import failed"] = tc
+
+	s.runTestCases(c, testCases)
+}
+
+func (s *TestSuite) TestVolumeRecycle(c *C) {
+	var tc *VolumeTestCase
+	testCases := map[string]*VolumeTestCase{}
+
+	// the recycled volume won't be attached for restoring
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.FromBackup = TestRestoreFrom
+	tc.volume.Spec.RecycledAt = util.Now()
+	tc.engine.Status.CurrentState = types.InstanceStateStopped
+	for _, r := range tc.replicas {
+		r.Status.CurrentState = types.InstanceStateStopped
+	}
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Status.State = types.VolumeStateDetached
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.EngineImage
+	testCases["recycled volume kept detached"] = tc
+
+	s.runTestCases(c, testCases)
+
+	recycleTestCases := map[string]struct {
+		retention  int
+		recycledAt time.Time
+
+		expectRemoved bool
+	}{
+		"recycled volume within retention": {24, time.Now().Add(-time.Hour), false},
+		"recycled volume expired":          {24, time.Now().Add(-25 * time.Hour), true},
+		"recycle bin disabled":             {0, time.Now().Add(-25 * time.Hour), false},
+	}
+	for name, rtc := range recycleTestCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+
+		lhClient := lhfake.NewSimpleClientset()
+		lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+		vIndexer := lhInformerFactory.Longhorn().V1alpha1().Volumes().Informer().GetIndexer()
+
+		vc := newTestVolumeController(lhInformerFactory, kubeInformerFactory, lhClient, kubeClient, TestOwnerID1)
+
+		setting, err := vc.ds.GetSetting()
+		c.Assert(err, IsNil)
+		setting.RecycleBinRetention = rtc.retention
+		_, err = vc.ds.UpdateSetting(setting)
+		c.Assert(err, IsNil)
+
+		volume := newVolume(TestVolumeName, 2)
+		volume.Spec.RecycledAt = util.FormatTimeZ(rtc.recycledAt)
+		v, err := lhClient.LonghornV1alpha1().Volumes(TestNamespace).Create(volume)
+		c.Assert(err, IsNil)
+		err = vIndexer.Add(v)
+		c.Assert(err, IsNil)
+
+		expired, err := vc.isRecycleExpired(v)
+		c.Assert(err, IsNil)
+		c.Assert(expired, Equals, rtc.expectRemoved)
+		if !rtc.expectRemoved {
+			continue
+		}
+
+		err = vc.syncVolume(getKey(v, c))
+		c.Assert(err, IsNil)
+		_, err = lhClient.LonghornV1alpha1().Volumes(TestNamespace).Get(v.Name, metav1.GetOptions{})
+		c.Assert(apierrors.IsNotFound(err), Equals, true)
+	}
+}
+
 func newVolume(name string, replicaCount int) *longhorn.Volume {
 	return &longhorn.Volume{
 		ObjectMeta: metav1.ObjectMeta{
@@ -157,6 +157,10 @@ func (cs *ControllerServer) ControllerPublishVolume(ctx context.Context, req *cs
 	if existVol.State == string(types.VolumeStateDetached) {
 		needToAttach = true
 	}
+	// the volume was attached temporarily for the recurring jobs
+	if len(existVol.AutoAttachedForJobs) != 0 {
+		needToAttach = true
+	}
 
 	logrus.Debugf("ControllerPublishVolume: current nodeID %s", req.GetNodeId())
 	if needToAttach {
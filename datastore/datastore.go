@@ -37,8 +37,28 @@ type DataStore struct {
 	dsLister      appslisters_v1beta2.DaemonSetLister
 	dsStoreSynced cache.InformerSynced
 
-	nLister      lhlisters.NodeLister
-	nStoreSynced cache.InformerSynced
+	nLister       lhlisters.NodeLister
+	nStoreSynced  cache.InformerSynced
+	biLister      lhlisters.BackingImageLister
+	biStoreSynced cache.InformerSynced
+	oLister       lhlisters.OrphanLister
+	oStoreSynced  cache.InformerSynced
+	evLister      corelisters.EventLister
+	evStoreSynced cache.InformerSynced
+	rjLister      lhlisters.RecurringJobLister
+	rjStoreSynced cache.InformerSynced
+	sgLister      lhlisters.SnapshotGroupLister
+	sgStoreSynced cache.InformerSynced
+	btLister      lhlisters.BackupTargetLister
+	btStoreSynced cache.InformerSynced
+	bvLister      lhlisters.BackupVolumeLister
+	bvStoreSynced cache.InformerSynced
+	bLister       lhlisters.BackupLister
+	bStoreSynced  cache.InformerSynced
+	boLister      lhlisters.BackupOperationLister
+	boStoreSynced cache.InformerSynced
+	seLister      lhlisters.SnapshotExportLister
+	seStoreSynced cache.InformerSynced
 }
 
 func NewDataStore(
@@ -52,7 +72,17 @@ func NewDataStore(
 	cronJobInformer batchinformers_v1beta1.CronJobInformer,
 	daemonSetInformer appsinformers_v1beta2.DaemonSetInformer,
 	kubeClient clientset.Interface,
-	namespace string, nodeInformer lhinformers.NodeInformer) *DataStore {
+	namespace string, nodeInformer lhinformers.NodeInformer,
+	backingImageInformer lhinformers.BackingImageInformer,
+	orphanInformer lhinformers.OrphanInformer,
+	eventInformer coreinformers.EventInformer,
+	recurringJobInformer lhinformers.RecurringJobInformer,
+	snapshotGroupInformer lhinformers.SnapshotGroupInformer,
+	backupTargetInformer lhinformers.BackupTargetInformer,
+	backupVolumeInformer lhinformers.BackupVolumeInformer,
+	backupInformer lhinformers.BackupInformer,
+	backupOperationInformer lhinformers.BackupOperationInformer,
+	snapshotExportInformer lhinformers.SnapshotExportInformer) *DataStore {
 
 	return &DataStore{
 		namespace: namespace,
@@ -75,13 +105,34 @@ func NewDataStore(
 		dsLister:      daemonSetInformer.Lister(),
 		dsStoreSynced: daemonSetInformer.Informer().HasSynced,
 
-		nLister:      nodeInformer.Lister(),
-		nStoreSynced: nodeInformer.Informer().HasSynced,
+		nLister:       nodeInformer.Lister(),
+		nStoreSynced:  nodeInformer.Informer().HasSynced,
+		biLister:      backingImageInformer.Lister(),
+		biStoreSynced: backingImageInformer.Informer().HasSynced,
+		oLister:       orphanInformer.Lister(),
+		oStoreSynced:  orphanInformer.Informer().HasSynced,
+		evLister:      eventInformer.Lister(),
+		evStoreSynced: eventInformer.Informer().HasSynced,
+		rjLister:      recurringJobInformer.Lister(),
+		rjStoreSynced: recurringJobInformer.Informer().HasSynced,
+		sgLister:      snapshotGroupInformer.Lister(),
+		sgStoreSynced: snapshotGroupInformer.Informer().HasSynced,
+		btLister:      backupTargetInformer.Lister(),
+		btStoreSynced: backupTargetInformer.Informer().HasSynced,
+		bvLister:      backupVolumeInformer.Lister(),
+		bvStoreSynced: backupVolumeInformer.Informer().HasSynced,
+		bLister:       backupInformer.Lister(),
+		bStoreSynced:  backupInformer.Informer().HasSynced,
+		boLister:      backupOperationInformer.Lister(),
+		boStoreSynced: backupOperationInformer.Informer().HasSynced,
+		seLister:      snapshotExportInformer.Lister(),
+		seStoreSynced: snapshotExportInformer.Informer().HasSynced,
 	}
 }
 
 func (s *DataStore) Sync(stopCh <-chan struct{}) bool {
 	return controller.WaitForCacheSync("longhorn datastore", stopCh,
 		s.vStoreSynced, s.eStoreSynced, s.rStoreSynced, s.iStoreSynced,
-		s.pStoreSynced, s.cjStoreSynced, s.dsStoreSynced)
+		s.pStoreSynced, s.cjStoreSynced, s.dsStoreSynced, s.biStoreSynced,
+		s.oStoreSynced, s.evStoreSynced, s.rjStoreSynced, s.sgStoreSynced, s.btStoreSynced, s.bvStoreSynced, s.bStoreSynced, s.boStoreSynced, s.seStoreSynced)
 }
@@ -148,6 +148,46 @@ func (s *DataStore) DeleteEngineImageDaemonSet(name string) error {
 	return nil
 }
 
+func (s *DataStore) CreateBackingImageDaemonSet(name string, ds *appsv1beta2.DaemonSet) error {
+	if ds.ObjectMeta.Labels == nil {
+		ds.ObjectMeta.Labels = map[string]string{}
+	}
+	for k, v := range types.GetBackingImageLabel(name) {
+		ds.ObjectMeta.Labels[k] = v
+	}
+	if _, err := s.kubeClient.AppsV1beta2().DaemonSets(s.namespace).Create(ds); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *DataStore) GetBackingImageDaemonSet(name string) (*appsv1beta2.DaemonSet, error) {
+	return s.GetEngineImageDaemonSet(name)
+}
+
+func (s *DataStore) UpdateBackingImageDaemonSet(ds *appsv1beta2.DaemonSet) error {
+	if _, err := s.kubeClient.AppsV1beta2().DaemonSets(s.namespace).Update(ds); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *DataStore) DeleteBackingImageDaemonSet(name string) error {
+	return s.DeleteEngineImageDaemonSet(name)
+}
+
+// ListBackingImagePods returns read-only pods which download the backing
+// image to the nodes
+func (s *DataStore) ListBackingImagePods(name string) ([]*corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels: types.GetBackingImageLabel(name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.pLister.Pods(s.namespace).List(selector)
+}
+
 func (s *DataStore) ListManagerPods() ([]*corev1.Pod, error) {
 	selector, err := s.getManagerSelector()
 	if err != nil {
@@ -159,3 +199,59 @@ func (s *DataStore) ListManagerPods() ([]*corev1.Pod, error) {
 	}
 	return podList, nil
 }
+
+func (s *DataStore) CreateEvent(event *corev1.Event) (*corev1.Event, error) {
+	return s.kubeClient.CoreV1().Events(s.namespace).Create(event)
+}
+
+func (s *DataStore) GetEvent(name string) (*corev1.Event, error) {
+	resultRO, err := s.evLister.Events(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+func (s *DataStore) UpdateEvent(event *corev1.Event) (*corev1.Event, error) {
+	return s.kubeClient.CoreV1().Events(s.namespace).Update(event)
+}
+
+// GetConfigMap reads the ConfigMap from the API server instead of the cache,
+// so the update based on it fails on conflict if it has changed meanwhile.
+// It returns nil if the ConfigMap doesn't exist.
+func (s *DataStore) GetConfigMap(name string) (*corev1.ConfigMap, error) {
+	cm, err := s.kubeClient.CoreV1().ConfigMaps(s.namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cm, nil
+}
+
+func (s *DataStore) CreateConfigMap(cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	return s.kubeClient.CoreV1().ConfigMaps(s.namespace).Create(cm)
+}
+
+func (s *DataStore) UpdateConfigMap(cm *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	return s.kubeClient.CoreV1().ConfigMaps(s.namespace).Update(cm)
+}
+
+// GetNotificationKeyFromSecret returns the HMAC key used to sign the
+// notifications
+func (s *DataStore) GetNotificationKeyFromSecret(secretName string) ([]byte, error) {
+	secret, err := s.kubeClient.CoreV1().Secrets(s.namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	key := secret.Data[types.NotificationHMACKey]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("cannot find %v in secret %v", types.NotificationHMACKey, secretName)
+	}
+	return key, nil
+}
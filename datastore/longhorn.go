@@ -2,15 +2,18 @@ package datastore
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/rancher/longhorn-manager/types"
 	"github.com/rancher/longhorn-manager/util"
@@ -86,9 +89,37 @@ func checkVolume(v *longhorn.Volume) error {
 		return fmt.Errorf("Volume name is too long %v, must be less than %v characters",
 			v.Name, NameMaximumLength)
 	}
+	if err := CheckVolumeLabels(v.Name, v.Labels); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CheckVolumeLabels validates the labels set by the user on the volume.
+// The label used by Longhorn to track the volume cannot be changed.
+func CheckVolumeLabels(volumeName string, labels map[string]string) error {
+	if errs := metavalidation.ValidateLabels(labels, field.NewPath("labels")); len(errs) != 0 {
+		return fmt.Errorf("Invalid volume labels: %v", errs.ToAggregate())
+	}
+	if value, exists := labels[longhornVolumeKey]; exists && value != volumeName {
+		return fmt.Errorf("Invalid volume labels: label %v is reserved", longhornVolumeKey)
+	}
 	return nil
 }
 
+// GetVolumeUserLabels returns the labels on the volume except the ones
+// maintained by Longhorn
+func GetVolumeUserLabels(v *longhorn.Volume) map[string]string {
+	result := map[string]string{}
+	for key, value := range v.Labels {
+		if key == longhornVolumeKey {
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
 func tagVolumeLabel(volumeName string, obj runtime.Object) error {
 	metadata, err := meta.Accessor(obj)
 	if err != nil {
@@ -180,9 +211,14 @@ func (s *DataStore) GetVolume(name string) (*longhorn.Volume, error) {
 }
 
 func (s *DataStore) ListVolumes() (map[string]*longhorn.Volume, error) {
+	return s.ListVolumesBySelector(labels.Everything())
+}
+
+// ListVolumesBySelector returns the volumes with the labels matching the selector
+func (s *DataStore) ListVolumesBySelector(selector labels.Selector) (map[string]*longhorn.Volume, error) {
 	itemMap := make(map[string]*longhorn.Volume)
 
-	list, err := s.vLister.Volumes(s.namespace).List(labels.Everything())
+	list, err := s.vLister.Volumes(s.namespace).List(selector)
 	if err != nil {
 		return nil, err
 	}
@@ -369,6 +405,26 @@ func (s *DataStore) GetVolumeReplicas(volumeName string) (map[string]*longhorn.R
 	return replicas, nil
 }
 
+// ListReplicasRO returns all the replicas, the objects are from the cache
+// and must not be modified
+func (s *DataStore) ListReplicasRO() ([]*longhorn.Replica, error) {
+	return s.rLister.Replicas(s.namespace).List(labels.Everything())
+}
+
+// ListReplicasFromAPI returns all the replicas from the API server, including
+// the ones created recently which may not be in the cache yet
+func (s *DataStore) ListReplicasFromAPI() ([]*longhorn.Replica, error) {
+	list, err := s.lhClient.LonghornV1alpha1().Replicas(s.namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	replicas := []*longhorn.Replica{}
+	for i := range list.Items {
+		replicas = append(replicas, &list.Items[i])
+	}
+	return replicas, nil
+}
+
 func (s *DataStore) CreateEngineImage(img *longhorn.EngineImage) (*longhorn.EngineImage, error) {
 	if err := util.AddFinalizer(longhornFinalizerKey, img); err != nil {
 		return nil, err
@@ -504,3 +560,570 @@ func (s *DataStore) RemoveFinalizerForNode(obj *longhorn.Node) error {
 	}
 	return nil
 }
+
+func (s *DataStore) CreateBackingImage(bi *longhorn.BackingImage) (*longhorn.BackingImage, error) {
+	if err := util.AddFinalizer(longhornFinalizerKey, bi); err != nil {
+		return nil, err
+	}
+	return s.lhClient.LonghornV1alpha1().BackingImages(s.namespace).Create(bi)
+}
+
+func (s *DataStore) UpdateBackingImage(bi *longhorn.BackingImage) (*longhorn.BackingImage, error) {
+	if err := util.AddFinalizer(longhornFinalizerKey, bi); err != nil {
+		return nil, err
+	}
+	return s.lhClient.LonghornV1alpha1().BackingImages(s.namespace).Update(bi)
+}
+
+// DeleteBackingImage won't result in immediately deletion since finalizer was set by default
+func (s *DataStore) DeleteBackingImage(name string) error {
+	return s.lhClient.LonghornV1alpha1().BackingImages(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+// RemoveFinalizerForBackingImage will result in deletion if DeletionTimestamp was set
+func (s *DataStore) RemoveFinalizerForBackingImage(obj *longhorn.BackingImage) error {
+	if !util.FinalizerExists(longhornFinalizerKey, obj) {
+		// finalizer already removed
+		return nil
+	}
+	if err := util.RemoveFinalizer(longhornFinalizerKey, obj); err != nil {
+		return err
+	}
+	_, err := s.lhClient.LonghornV1alpha1().BackingImages(s.namespace).Update(obj)
+	if err != nil {
+		// workaround `StorageError: invalid object, Code: 4` due to empty object
+		if obj.DeletionTimestamp != nil {
+			return nil
+		}
+		return errors.Wrapf(err, "unable to remove finalizer for backing image %v", obj.Name)
+	}
+	return nil
+}
+
+func (s *DataStore) GetBackingImage(name string) (*longhorn.BackingImage, error) {
+	resultRO, err := s.biLister.BackingImages(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+func (s *DataStore) ListBackingImages() (map[string]*longhorn.BackingImage, error) {
+	itemMap := map[string]*longhorn.BackingImage{}
+
+	list, err := s.biLister.BackingImages(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
+
+func (s *DataStore) CreateOrphan(o *longhorn.Orphan) (*longhorn.Orphan, error) {
+	if err := util.AddFinalizer(longhornFinalizerKey, o); err != nil {
+		return nil, err
+	}
+	return s.lhClient.LonghornV1alpha1().Orphans(s.namespace).Create(o)
+}
+
+func (s *DataStore) UpdateOrphan(o *longhorn.Orphan) (*longhorn.Orphan, error) {
+	if err := util.AddFinalizer(longhornFinalizerKey, o); err != nil {
+		return nil, err
+	}
+	return s.lhClient.LonghornV1alpha1().Orphans(s.namespace).Update(o)
+}
+
+// DeleteOrphan won't result in immediately deletion since finalizer was set by default
+func (s *DataStore) DeleteOrphan(name string) error {
+	return s.lhClient.LonghornV1alpha1().Orphans(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+// RemoveFinalizerForOrphan will result in deletion if DeletionTimestamp was set
+func (s *DataStore) RemoveFinalizerForOrphan(obj *longhorn.Orphan) error {
+	if !util.FinalizerExists(longhornFinalizerKey, obj) {
+		// finalizer already removed
+		return nil
+	}
+	if err := util.RemoveFinalizer(longhornFinalizerKey, obj); err != nil {
+		return err
+	}
+	_, err := s.lhClient.LonghornV1alpha1().Orphans(s.namespace).Update(obj)
+	if err != nil {
+		// workaround `StorageError: invalid object, Code: 4` due to empty object
+		if obj.DeletionTimestamp != nil {
+			return nil
+		}
+		return errors.Wrapf(err, "unable to remove finalizer for orphan %v", obj.Name)
+	}
+	return nil
+}
+
+func (s *DataStore) GetOrphan(name string) (*longhorn.Orphan, error) {
+	resultRO, err := s.oLister.Orphans(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+func (s *DataStore) ListOrphans() (map[string]*longhorn.Orphan, error) {
+	itemMap := map[string]*longhorn.Orphan{}
+
+	list, err := s.oLister.Orphans(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
+
+func (s *DataStore) CreateRecurringJob(rj *longhorn.RecurringJob) (*longhorn.RecurringJob, error) {
+	return s.lhClient.LonghornV1alpha1().RecurringJobs(s.namespace).Create(rj)
+}
+
+func (s *DataStore) UpdateRecurringJob(rj *longhorn.RecurringJob) (*longhorn.RecurringJob, error) {
+	return s.lhClient.LonghornV1alpha1().RecurringJobs(s.namespace).Update(rj)
+}
+
+func (s *DataStore) DeleteRecurringJob(name string) error {
+	return s.lhClient.LonghornV1alpha1().RecurringJobs(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+func (s *DataStore) GetRecurringJob(name string) (*longhorn.RecurringJob, error) {
+	resultRO, err := s.rjLister.RecurringJobs(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+func (s *DataStore) ListRecurringJobs() (map[string]*longhorn.RecurringJob, error) {
+	itemMap := map[string]*longhorn.RecurringJob{}
+
+	list, err := s.rjLister.RecurringJobs(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
+
+// ListVolumeAppliedRecurringJobs returns the jobs set on the volume and the
+// jobs of the recurring jobs selecting the volume, keyed by the job name. The
+// job set on the volume takes precedence if the names collide.
+func (s *DataStore) ListVolumeAppliedRecurringJobs(v *longhorn.Volume) (map[string]*types.AppliedRecurringJob, error) {
+	jobs := map[string]*types.AppliedRecurringJob{}
+	for _, job := range v.Spec.RecurringJobs {
+		jobs[job.Name] = &types.AppliedRecurringJob{
+			RecurringJob: job,
+		}
+	}
+	rjs, err := s.ListVolumeRecurringJobs(v)
+	if err != nil {
+		return nil, err
+	}
+	for _, rj := range rjs {
+		if jobs[rj.Name] != nil {
+			continue
+		}
+		jobs[rj.Name] = &types.AppliedRecurringJob{
+			RecurringJob: types.RecurringJob{
+				Name:      rj.Name,
+				Type:      rj.Spec.Task,
+				Cron:      rj.Spec.Cron,
+				Retain:    rj.Spec.Retain,
+				Retention: rj.Spec.Retention,
+				Freeze:    rj.Spec.Freeze,
+				Hooks:     rj.Spec.Hooks,
+
+				BackupTarget: rj.Spec.BackupTarget,
+			},
+			FromRecurringJob: true,
+		}
+	}
+	return jobs, nil
+}
+
+// ListVolumeRecurringJobs returns the recurring jobs selecting the volume
+func (s *DataStore) ListVolumeRecurringJobs(v *longhorn.Volume) (map[string]*longhorn.RecurringJob, error) {
+	rjs, err := s.ListRecurringJobs()
+	if err != nil {
+		return nil, err
+	}
+	for name, rj := range rjs {
+		if rj.DeletionTimestamp != nil || !types.IsVolumeSelectedByRecurringJob(&rj.Spec, v.Labels) {
+			delete(rjs, name)
+		}
+	}
+	return rjs, nil
+}
+
+func (s *DataStore) CreateSnapshotGroup(sg *longhorn.SnapshotGroup) (*longhorn.SnapshotGroup, error) {
+	return s.lhClient.LonghornV1alpha1().SnapshotGroups(s.namespace).Create(sg)
+}
+
+func (s *DataStore) UpdateSnapshotGroup(sg *longhorn.SnapshotGroup) (*longhorn.SnapshotGroup, error) {
+	return s.lhClient.LonghornV1alpha1().SnapshotGroups(s.namespace).Update(sg)
+}
+
+func (s *DataStore) DeleteSnapshotGroup(name string) error {
+	return s.lhClient.LonghornV1alpha1().SnapshotGroups(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+func (s *DataStore) GetSnapshotGroup(name string) (*longhorn.SnapshotGroup, error) {
+	resultRO, err := s.sgLister.SnapshotGroups(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+func (s *DataStore) ListSnapshotGroups() (map[string]*longhorn.SnapshotGroup, error) {
+	itemMap := map[string]*longhorn.SnapshotGroup{}
+
+	list, err := s.sgLister.SnapshotGroups(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
+
+func (s *DataStore) CreateBackupTarget(bt *longhorn.BackupTarget) (*longhorn.BackupTarget, error) {
+	return s.lhClient.LonghornV1alpha1().BackupTargets(s.namespace).Create(bt)
+}
+
+func (s *DataStore) UpdateBackupTarget(bt *longhorn.BackupTarget) (*longhorn.BackupTarget, error) {
+	return s.lhClient.LonghornV1alpha1().BackupTargets(s.namespace).Update(bt)
+}
+
+func (s *DataStore) DeleteBackupTarget(name string) error {
+	return s.lhClient.LonghornV1alpha1().BackupTargets(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+func (s *DataStore) GetBackupTarget(name string) (*longhorn.BackupTarget, error) {
+	resultRO, err := s.btLister.BackupTargets(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+func (s *DataStore) ListBackupTargets() (map[string]*longhorn.BackupTarget, error) {
+	itemMap := map[string]*longhorn.BackupTarget{}
+
+	list, err := s.btLister.BackupTargets(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
+
+// GetBackupTargetSpec returns the spec of the backup target named name, or
+// types.DefaultBackupTargetName if name is empty. The default target falls
+// back to the backupTarget settings if there is no BackupTarget named so or
+// its URL is empty. The URL of the returned spec is empty if the target is
+// not set.
+func (s *DataStore) GetBackupTargetSpec(name string) (*types.BackupTargetSpec, error) {
+	if name == "" {
+		name = types.DefaultBackupTargetName
+	}
+	bt, err := s.GetBackupTarget(name)
+	if err != nil {
+		return nil, err
+	}
+	if bt != nil && (bt.Spec.URL != "" || name != types.DefaultBackupTargetName) {
+		return &bt.Spec, nil
+	}
+	if name != types.DefaultBackupTargetName {
+		return nil, fmt.Errorf("cannot find backup target %v", name)
+	}
+	setting, err := s.GetSetting()
+	if err != nil {
+		return nil, err
+	}
+	spec := &types.BackupTargetSpec{}
+	if bt != nil {
+		*spec = bt.Spec
+	}
+	spec.URL = setting.BackupTarget
+	spec.CredentialSecret = setting.BackupTargetCredentialSecret
+	return spec, nil
+}
+
+// GetBackupTargetSpecForURL returns the spec of the backup target the backup
+// or backup volume URL belongs to, the default target if none matches
+func (s *DataStore) GetBackupTargetSpecForURL(backupURL string) (*types.BackupTargetSpec, error) {
+	bts, err := s.ListBackupTargets()
+	if err != nil {
+		return nil, err
+	}
+	// the URL is the target URL with the backup and volume in the query
+	targetURL := strings.TrimSuffix(strings.SplitN(backupURL, "?", 2)[0], "/")
+	for _, bt := range bts {
+		if bt.Spec.URL != "" && strings.TrimSuffix(bt.Spec.URL, "/") == targetURL {
+			return &bt.Spec, nil
+		}
+	}
+	return s.GetBackupTargetSpec("")
+}
+
+func (s *DataStore) CreateBackupVolume(bv *longhorn.BackupVolume) (*longhorn.BackupVolume, error) {
+	return s.lhClient.LonghornV1alpha1().BackupVolumes(s.namespace).Create(bv)
+}
+
+func (s *DataStore) UpdateBackupVolume(bv *longhorn.BackupVolume) (*longhorn.BackupVolume, error) {
+	return s.lhClient.LonghornV1alpha1().BackupVolumes(s.namespace).Update(bv)
+}
+
+func (s *DataStore) DeleteBackupVolume(name string) error {
+	return s.lhClient.LonghornV1alpha1().BackupVolumes(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+func (s *DataStore) GetBackupVolume(name string) (*longhorn.BackupVolume, error) {
+	resultRO, err := s.bvLister.BackupVolumes(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+// ListBackupVolumes returns the BackupVolumes of the backup target, or the
+// default target if backupTargetName is empty
+func (s *DataStore) ListBackupVolumes(backupTargetName string) (map[string]*longhorn.BackupVolume, error) {
+	if backupTargetName == "" {
+		backupTargetName = types.DefaultBackupTargetName
+	}
+	itemMap := map[string]*longhorn.BackupVolume{}
+
+	list, err := s.bvLister.BackupVolumes(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		if itemRO.Spec.BackupTarget != backupTargetName {
+			continue
+		}
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
+
+func (s *DataStore) CreateBackup(b *longhorn.Backup) (*longhorn.Backup, error) {
+	return s.lhClient.LonghornV1alpha1().Backups(s.namespace).Create(b)
+}
+
+func (s *DataStore) UpdateBackup(b *longhorn.Backup) (*longhorn.Backup, error) {
+	return s.lhClient.LonghornV1alpha1().Backups(s.namespace).Update(b)
+}
+
+func (s *DataStore) DeleteBackup(name string) error {
+	return s.lhClient.LonghornV1alpha1().Backups(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+func (s *DataStore) GetBackup(name string) (*longhorn.Backup, error) {
+	resultRO, err := s.bLister.Backups(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+// ListBackups returns the Backups of the backup target, or the default
+// target if backupTargetName is empty. All the backups in the target are
+// returned if volumeName is empty.
+func (s *DataStore) ListBackups(backupTargetName, volumeName string) (map[string]*longhorn.Backup, error) {
+	if backupTargetName == "" {
+		backupTargetName = types.DefaultBackupTargetName
+	}
+	itemMap := map[string]*longhorn.Backup{}
+
+	list, err := s.bLister.Backups(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		if itemRO.Spec.BackupTarget != backupTargetName {
+			continue
+		}
+		if volumeName != "" && itemRO.Spec.VolumeName != volumeName {
+			continue
+		}
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
+
+// GetOwnerReferencesForVolume makes the object garbage collected with the
+// volume
+func GetOwnerReferencesForVolume(v *longhorn.Volume) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion: longhorn.SchemeGroupVersion.String(),
+			Kind:       "Volume",
+			UID:        v.UID,
+			Name:       v.Name,
+		},
+	}
+}
+
+func (s *DataStore) CreateBackupOperation(bo *longhorn.BackupOperation) (*longhorn.BackupOperation, error) {
+	return s.lhClient.LonghornV1alpha1().BackupOperations(s.namespace).Create(bo)
+}
+
+func (s *DataStore) UpdateBackupOperation(bo *longhorn.BackupOperation) (*longhorn.BackupOperation, error) {
+	return s.lhClient.LonghornV1alpha1().BackupOperations(s.namespace).Update(bo)
+}
+
+func (s *DataStore) DeleteBackupOperation(name string) error {
+	return s.lhClient.LonghornV1alpha1().BackupOperations(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+func (s *DataStore) GetBackupOperation(name string) (*longhorn.BackupOperation, error) {
+	resultRO, err := s.boLister.BackupOperations(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+// ListBackupOperations returns the BackupOperations of the volume, or all of
+// them if volumeName is empty
+func (s *DataStore) ListBackupOperations(volumeName string) (map[string]*longhorn.BackupOperation, error) {
+	itemMap := map[string]*longhorn.BackupOperation{}
+
+	list, err := s.boLister.BackupOperations(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		if volumeName != "" && itemRO.Spec.VolumeName != volumeName {
+			continue
+		}
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
+
+func (s *DataStore) CreateSnapshotExport(se *longhorn.SnapshotExport) (*longhorn.SnapshotExport, error) {
+	if err := util.AddFinalizer(longhornFinalizerKey, se); err != nil {
+		return nil, err
+	}
+	return s.lhClient.LonghornV1alpha1().SnapshotExports(s.namespace).Create(se)
+}
+
+func (s *DataStore) UpdateSnapshotExport(se *longhorn.SnapshotExport) (*longhorn.SnapshotExport, error) {
+	if err := util.AddFinalizer(longhornFinalizerKey, se); err != nil {
+		return nil, err
+	}
+	return s.lhClient.LonghornV1alpha1().SnapshotExports(s.namespace).Update(se)
+}
+
+// DeleteSnapshotExport won't result in immediately deletion since finalizer
+// was set by default, the exported image is removed by the node keeping it
+func (s *DataStore) DeleteSnapshotExport(name string) error {
+	return s.lhClient.LonghornV1alpha1().SnapshotExports(s.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+// RemoveFinalizerForSnapshotExport will result in deletion if
+// DeletionTimestamp was set
+func (s *DataStore) RemoveFinalizerForSnapshotExport(obj *longhorn.SnapshotExport) error {
+	if !util.FinalizerExists(longhornFinalizerKey, obj) {
+		// finalizer already removed
+		return nil
+	}
+	if err := util.RemoveFinalizer(longhornFinalizerKey, obj); err != nil {
+		return err
+	}
+	_, err := s.lhClient.LonghornV1alpha1().SnapshotExports(s.namespace).Update(obj)
+	if err != nil {
+		// workaround `StorageError: invalid object, Code: 4` due to empty object
+		if obj.DeletionTimestamp != nil {
+			return nil
+		}
+		return errors.Wrapf(err, "unable to remove finalizer for snapshot export %v", obj.Name)
+	}
+	return nil
+}
+
+func (s *DataStore) GetSnapshotExport(name string) (*longhorn.SnapshotExport, error) {
+	resultRO, err := s.seLister.SnapshotExports(s.namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Cannot use cached object from lister
+	return resultRO.DeepCopy(), nil
+}
+
+// ListSnapshotExports returns the SnapshotExports of the volume, or all of
+// them if volumeName is empty
+func (s *DataStore) ListSnapshotExports(volumeName string) (map[string]*longhorn.SnapshotExport, error) {
+	itemMap := map[string]*longhorn.SnapshotExport{}
+
+	list, err := s.seLister.SnapshotExports(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, itemRO := range list {
+		if volumeName != "" && itemRO.Spec.VolumeName != volumeName {
+			continue
+		}
+		// Cannot use cached object from lister
+		itemMap[itemRO.Name] = itemRO.DeepCopy()
+	}
+	return itemMap, nil
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -16,6 +18,13 @@ import (
 
 type EngineCollection struct{}
 
+var (
+	// cliAPIVersions caches the CLI API version of the engine binaries,
+	// keyed by the engine image
+	cliAPIVersions     = map[string]int{}
+	cliAPIVersionsLock sync.Mutex
+)
+
 type Engine struct {
 	name  string
 	image string
@@ -25,6 +34,7 @@ type Engine struct {
 
 const (
 	rebuildTimeout = 180 * time.Minute
+	reclaimTimeout = 30 * time.Minute
 )
 
 func (c *EngineCollection) NewEngineClient(request *EngineClientRequest) (EngineClient, error) {
@@ -125,6 +135,22 @@ func (e *Engine) ReplicaRemove(url string) error {
 	return nil
 }
 
+// ReplicaRebuildStatus returns the rebuild progress keyed by the replica URL
+func (e *Engine) ReplicaRebuildStatus() (map[string]*RebuildStatus, error) {
+	if err := CheckEngineFeature(e.image, "replica-rebuild-status"); err != nil {
+		return nil, err
+	}
+	output, err := e.ExecuteEngineBinary("replica-rebuild-status")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get replica rebuild status from controller '%s'", e.name)
+	}
+	status := map[string]*RebuildStatus{}
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode replica rebuild status: %v", output)
+	}
+	return status, nil
+}
+
 func (e *Engine) Endpoint() string {
 	info, err := e.launcherInfo()
 	if err != nil {
@@ -135,6 +161,23 @@ func (e *Engine) Endpoint() string {
 	return info.Endpoint
 }
 
+// ReclaimSpace punches holes in the volume head for the blocks unmapped by
+// the filesystem, returns the number of bytes reclaimed
+func (e *Engine) ReclaimSpace() (int64, error) {
+	if err := CheckEngineFeature(e.image, "reclaim-space"); err != nil {
+		return 0, err
+	}
+	output, err := e.ExecuteEngineBinaryWithTimeout(reclaimTimeout, "reclaim-space")
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to reclaim space for volume %v", e.name)
+	}
+	reclaimed, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot parse reclaimed size for volume %v", e.name)
+	}
+	return reclaimed, nil
+}
+
 func (e *Engine) launcherInfo() (*LauncherVolumeInfo, error) {
 	output, err := e.ExecuteEngineLauncherBinary("info")
 	if err != nil {
@@ -169,6 +212,28 @@ func (e *Engine) BackupRestore(backup string) error {
 	return nil
 }
 
+// GetReplicaRestoreStatus asks the replica which is restoring from a backup
+// during launch about the progress, using the engine binary of the image
+func GetReplicaRestoreStatus(engineImage, replicaURL string) (*types.RestoreStatus, error) {
+	if err := ValidateReplicaURL(replicaURL); err != nil {
+		return nil, err
+	}
+	if err := CheckEngineFeature(engineImage, "backup restore-status"); err != nil {
+		return nil, err
+	}
+	binary := filepath.Join(types.GetEngineBinaryDirectoryOnHostForImage(engineImage), "longhorn")
+	output, err := util.Execute(binary, "--url", replicaURL, "backup", "restore-status")
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get restore status of replica %v", replicaURL)
+	}
+
+	status := &types.RestoreStatus{}
+	if err := json.Unmarshal([]byte(output), status); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode restore status: %v", output)
+	}
+	return status, nil
+}
+
 func (e *Engine) Upgrade(binary string, replicaURLs []string) error {
 	args := []string{
 		"upgrade", "--longhorn-binary", binary,
@@ -204,3 +269,35 @@ func (e *Engine) Version(clientOnly bool) (*EngineVersion, error) {
 	}
 	return version, nil
 }
+
+// getCLIAPIVersion returns the CLI API version of the engine binary of the
+// image. The binary of an image never changes, so the version is cached.
+func getCLIAPIVersion(image string) (int, error) {
+	cliAPIVersionsLock.Lock()
+	defer cliAPIVersionsLock.Unlock()
+	if version, exists := cliAPIVersions[image]; exists {
+		return version, nil
+	}
+	e := &Engine{image: image}
+	version, err := e.Version(true)
+	if err != nil {
+		return 0, err
+	}
+	cliAPIVersions[image] = version.ClientVersion.CLIAPIVersion
+	return version.ClientVersion.CLIAPIVersion, nil
+}
+
+// CheckEngineFeature returns error caused by ErrUnsupportedByEngine if the
+// engine of the image is older than ExtendedCLIMinVersion, feature names the
+// command for the error message
+func CheckEngineFeature(image, feature string) error {
+	version, err := getCLIAPIVersion(image)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get CLI API version of engine image %v", image)
+	}
+	if version < ExtendedCLIMinVersion {
+		return errors.Wrapf(ErrUnsupportedByEngine, "%v requires engine CLI API version %v, engine image %v has %v",
+			feature, ExtendedCLIMinVersion, image, version)
+	}
+	return nil
+}
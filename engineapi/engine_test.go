@@ -0,0 +1,39 @@
+package engineapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckEngineFeature(t *testing.T) {
+	assert := require.New(t)
+
+	cliAPIVersionsLock.Lock()
+	cliAPIVersions["longhorn-engine:old"] = ExtendedCLIMinVersion - 1
+	cliAPIVersions["longhorn-engine:new"] = ExtendedCLIMinVersion
+	cliAPIVersionsLock.Unlock()
+
+	err := CheckEngineFeature("longhorn-engine:old", "snapshot export")
+	assert.Error(err)
+	assert.True(IsUnsupportedByEngine(err))
+	assert.Contains(err.Error(), "snapshot export requires engine CLI API version")
+	assert.Nil(CheckEngineFeature("longhorn-engine:new", "snapshot export"))
+
+	// the command isn't run by the old engine
+	e := &Engine{name: "vol", image: "longhorn-engine:old"}
+	err = e.SnapshotExport("snap", "/tmp/snap.img")
+	assert.True(IsUnsupportedByEngine(err))
+	_, err = e.ReclaimSpace()
+	assert.True(IsUnsupportedByEngine(err))
+	_, err = e.SnapshotBackup("snap", "s3://backupbucket@us-east-1/", nil, nil, 100)
+	assert.True(IsUnsupportedByEngine(err))
+	_, err = e.SnapshotBackupStatus("snap")
+	assert.True(IsUnsupportedByEngine(err))
+	err = e.SnapshotBackupCancel("snap")
+	assert.True(IsUnsupportedByEngine(err))
+	_, err = e.ReplicaRebuildStatus()
+	assert.True(IsUnsupportedByEngine(err))
+	_, err = GetReplicaRestoreStatus("longhorn-engine:old", "tcp://10.0.0.1:9502")
+	assert.True(IsUnsupportedByEngine(err))
+}
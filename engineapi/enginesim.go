@@ -181,10 +181,41 @@ func (e *EngineSimulator) SnapshotPurge() error {
 	return fmt.Errorf("Not implemented")
 }
 
-func (e *EngineSimulator) SnapshotBackup(snapName, backupTarget string, labels map[string]string, credential map[string]string) error {
+func (e *EngineSimulator) SnapshotBackup(snapName, backupTarget string, labels map[string]string, credential map[string]string, bandwidthLimit int) (string, error) {
+	return "", fmt.Errorf("Not implemented")
+}
+
+func (e *EngineSimulator) SnapshotBackupStatus(snapName string) (*BackupProgress, error) {
+	return nil, fmt.Errorf("Not implemented")
+}
+
+func (e *EngineSimulator) SnapshotBackupCancel(snapName string) error {
 	return fmt.Errorf("Not implemented")
 }
 
+func (e *EngineSimulator) SnapshotExport(snapName, fileName string) error {
+	return fmt.Errorf("Not implemented")
+}
+
+func (e *EngineSimulator) ReplicaRebuildStatus() (map[string]*RebuildStatus, error) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	ret := map[string]*RebuildStatus{}
+	for _, replica := range e.replicas {
+		if replica.Mode == types.ReplicaModeWO {
+			ret[replica.URL] = &RebuildStatus{
+				IsRebuilding: true,
+			}
+		}
+	}
+	return ret, nil
+}
+
+func (e *EngineSimulator) ReclaimSpace() (int64, error) {
+	return 0, fmt.Errorf("Not implemented")
+}
+
 func (e *EngineSimulator) Upgrade(binary string, replicaURLs []string) error {
 	return fmt.Errorf("Not implemented")
 }
@@ -2,12 +2,14 @@ package engineapi
 
 import (
 	"encoding/json"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/pkg/errors"
 
+	"github.com/rancher/longhorn-manager/types"
 	"github.com/rancher/longhorn-manager/util"
 )
 
@@ -15,6 +17,7 @@ const (
 	VolumeHeadName = "volume-head"
 	purgeTimeout   = 15 * time.Minute
 	backupTimeout  = 360 * time.Minute
+	exportTimeout  = 360 * time.Minute
 )
 
 func (e *Engine) SnapshotCreate(name string, labels map[string]string) (string, error) {
@@ -31,6 +34,55 @@ func (e *Engine) SnapshotCreate(name string, labels map[string]string) (string,
 	return strings.TrimSpace(output), nil
 }
 
+// SnapshotCreateWithFreeze creates the snapshot with the filesystem on the
+// volume device frozen, so the snapshot is filesystem consistent. It must run
+// on the node the volume is attached to. The result of the freeze is recorded
+// in the snapshot label types.SnapshotLabelFreeze, the snapshot is only crash
+// consistent if the filesystem is not mounted or cannot be frozen. device is
+// empty if the volume has no block device frontend.
+func SnapshotCreateWithFreeze(engine EngineClient, device, name string, labels map[string]string) (string, error) {
+	snapshotLabels := map[string]string{}
+	for k, v := range labels {
+		snapshotLabels[k] = v
+	}
+
+	mountPoint := ""
+	if device != "" {
+		var err error
+		if mountPoint, err = util.GetDeviceMountPoint(util.GetHostDevicePath(device)); err != nil {
+			logrus.Warnf("Cannot find the filesystem of volume %v to freeze: %v", engine.Name(), err)
+		}
+	}
+	if mountPoint == "" {
+		snapshotLabels[types.SnapshotLabelFreeze] = types.SnapshotFreezeResultSkipped
+		return engine.SnapshotCreate(name, snapshotLabels)
+	}
+
+	snapshotLabels[types.SnapshotLabelFreeze] = types.SnapshotFreezeResultFrozen
+	created := ""
+	frozen := false
+	err := util.RunWithFilesystemFrozen(mountPoint, types.SnapshotFreezeTimeout, func() (err error) {
+		frozen = true
+		created, err = engine.SnapshotCreate(name, snapshotLabels)
+		return err
+	})
+	if !frozen {
+		logrus.Warnf("Creating snapshot %v of volume %v without freeze: %v", name, engine.Name(), err)
+		snapshotLabels[types.SnapshotLabelFreeze] = types.SnapshotFreezeResultFailed
+		return engine.SnapshotCreate(name, snapshotLabels)
+	}
+	if err == util.ErrFreezeTimeout && created != "" {
+		// the snapshot completed after the filesystem was unfrozen
+		if err := engine.SnapshotDelete(created); err != nil {
+			logrus.Warnf("Failed to delete snapshot %v of volume %v exceeding the freeze timeout: %v", created, engine.Name(), err)
+		}
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "error creating snapshot '%s' with filesystem frozen", name)
+	}
+	return created, nil
+}
+
 func (e *Engine) SnapshotList() (map[string]*Snapshot, error) {
 	output, err := e.ExecuteEngineBinary("snapshot", "info")
 	if err != nil {
@@ -74,28 +126,96 @@ func (e *Engine) SnapshotPurge() error {
 	return nil
 }
 
-func (e *Engine) SnapshotBackup(snapName, backupTarget string, labels map[string]string, credential map[string]string) error {
+// SnapshotBackup blocks until the backup completed, returns the URL of the
+// backup. The progress can be polled by SnapshotBackupStatus meanwhile.
+// bandwidthLimit is in MiB per second, 0 means unlimited.
+func (e *Engine) SnapshotBackup(snapName, backupTarget string, labels map[string]string, credential map[string]string, bandwidthLimit int) (string, error) {
+	if bandwidthLimit > 0 {
+		if err := CheckEngineFeature(e.image, "backup create --bandwidth-limit"); err != nil {
+			return "", err
+		}
+	}
 	snap, err := e.SnapshotGet(snapName)
 	if err != nil {
-		return errors.Wrapf(err, "error getting snapshot '%s', volume '%s'", snapName, e.name)
+		return "", errors.Wrapf(err, "error getting snapshot '%s', volume '%s'", snapName, e.name)
 	}
 	if snap == nil {
-		return errors.Errorf("could not find snapshot '%s' to backup, volume '%s'", snapName, e.name)
+		return "", errors.Errorf("could not find snapshot '%s' to backup, volume '%s'", snapName, e.name)
 	}
 	args := []string{"backup", "create", "--dest", backupTarget}
 	for k, v := range labels {
 		args = append(args, "--label", k+"="+v)
 	}
+	if bandwidthLimit > 0 {
+		args = append(args, "--bandwidth-limit", strconv.Itoa(bandwidthLimit)+"M")
+	}
 	args = append(args, snapName)
 	// set credential if backup for s3
 	err = util.ConfigBackupCredential(backupTarget, credential)
 	if err != nil {
-		return err
+		return "", err
 	}
-	backup, err := e.ExecuteEngineBinaryWithTimeout(backupTimeout, args...)
+	output, err := e.ExecuteEngineBinaryWithTimeout(backupTimeout, args...)
 	if err != nil {
-		return err
+		return "", err
 	}
+	backup := strings.TrimSpace(output)
 	logrus.Debugf("Backup %v created for volume %v snapshot %v", backup, e.Name(), snapName)
+	return backup, nil
+}
+
+func (e *Engine) SnapshotBackupStatus(snapName string) (*BackupProgress, error) {
+	if err := CheckEngineFeature(e.image, "backup status"); err != nil {
+		return nil, err
+	}
+	output, err := e.ExecuteEngineBinary("backup", "status", snapName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting backup status of snapshot '%s'", snapName)
+	}
+	progress := &BackupProgress{}
+	if err := json.Unmarshal([]byte(output), progress); err != nil {
+		return nil, errors.Wrapf(err, "error parsing backup status: %v", output)
+	}
+	return progress, nil
+}
+
+// SnapshotBackupCancel stops the running backup of the snapshot, the
+// SnapshotBackup call returns with an error
+func (e *Engine) SnapshotBackupCancel(snapName string) error {
+	if err := CheckEngineFeature(e.image, "backup cancel"); err != nil {
+		return err
+	}
+	if _, err := e.ExecuteEngineBinary("backup", "cancel", snapName); err != nil {
+		return errors.Wrapf(err, "error cancelling backup of snapshot '%s'", snapName)
+	}
+	logrus.Debugf("Backup of volume %v snapshot %v cancelled", e.Name(), snapName)
 	return nil
 }
+
+func (e *Engine) SnapshotExport(snapName, fileName string) error {
+	if err := CheckEngineFeature(e.image, "snapshot export"); err != nil {
+		return err
+	}
+	if _, err := e.ExecuteEngineBinaryWithTimeout(exportTimeout, "snapshot", "export", "--output", fileName, snapName); err != nil {
+		return errors.Wrapf(err, "error exporting snapshot '%s' to %v", snapName, fileName)
+	}
+	logrus.Debugf("Volume %v snapshot %v exported to %v", e.Name(), snapName, fileName)
+	return nil
+}
+
+// GetSnapshotsActualSize adds up the disk space used by the snapshots and the
+// volume head
+func GetSnapshotsActualSize(snapshots map[string]*Snapshot) (int64, error) {
+	actualSize := int64(0)
+	for _, s := range snapshots {
+		if s.Size == "" {
+			continue
+		}
+		size, err := strconv.ParseInt(s.Size, 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid size %v of snapshot %v", s.Size, s.Name)
+		}
+		actualSize += size
+	}
+	return actualSize, nil
+}
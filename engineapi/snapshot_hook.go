@@ -0,0 +1,156 @@
+package engineapi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+)
+
+// SnapshotHookRunner executes the snapshot hooks in the workload pods using
+// the volume, found through the persistent volume and the claim bound to it
+type SnapshotHookRunner struct {
+	volumeName string
+	kubeClient clientset.Interface
+
+	// for unit test
+	execHandler func(pod *v1.Pod, container string, command []string, timeout time.Duration) error
+}
+
+// NewSnapshotHookRunner only supports in-cluster config
+func NewSnapshotHookRunner(volumeName string) (*SnapshotHookRunner, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get client config")
+	}
+	kubeClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get k8s client")
+	}
+	r := &SnapshotHookRunner{
+		volumeName: volumeName,
+		kubeClient: kubeClient,
+	}
+	r.execHandler = func(pod *v1.Pod, container string, command []string, timeout time.Duration) error {
+		output, err := util.ExecInPod(config, kubeClient, pod.Namespace, pod.Name, container, command, timeout)
+		if output != "" {
+			logrus.Debugf("Snapshot hook %v of volume %v in pod %v/%v: %v", command, volumeName, pod.Namespace, pod.Name, output)
+		}
+		return err
+	}
+	return r, nil
+}
+
+// Run executes the pre hooks, creates the snapshot by calling create, then
+// executes the post hooks. The post hooks run even if the snapshot was
+// aborted or failed, so they undo whatever the pre hooks did. It returns the
+// hook failures, which don't fail the call if the policy is
+// types.SnapshotHookFailurePolicyContinue.
+func (r *SnapshotHookRunner) Run(hooks *types.SnapshotHooks, create func() error) (failures []string, err error) {
+	if hooks.IsEmpty() {
+		return nil, create()
+	}
+	abort := hooks.FailurePolicy != types.SnapshotHookFailurePolicyContinue
+
+	pods, err := r.listWorkloadPods()
+	if err != nil {
+		failure := fmt.Sprintf("cannot find the pods using volume %v: %v", r.volumeName, err)
+		if abort {
+			return []string{failure}, fmt.Errorf("aborted snapshot of volume %v: %v", r.volumeName, failure)
+		}
+		return []string{failure}, create()
+	}
+
+	preFailures := r.execHooks(pods, hooks.Pre, "pre", abort)
+	failures = append(failures, preFailures...)
+	if len(preFailures) != 0 && abort {
+		err = fmt.Errorf("aborted snapshot of volume %v: %v", r.volumeName, preFailures[0])
+	} else {
+		err = create()
+	}
+
+	postFailures := r.execHooks(pods, hooks.Post, "post", false)
+	failures = append(failures, postFailures...)
+	if err == nil && len(postFailures) != 0 && abort {
+		err = fmt.Errorf("snapshot of volume %v was taken but %v", r.volumeName, postFailures[0])
+	}
+	return failures, err
+}
+
+// execHooks stops at the first failure if abort
+func (r *SnapshotHookRunner) execHooks(pods []*v1.Pod, hooks []types.SnapshotHook, stage string, abort bool) []string {
+	failures := []string{}
+	for _, pod := range pods {
+		for _, hook := range hooks {
+			container := hook.Container
+			if container == "" {
+				container = pod.Spec.Containers[0].Name
+			}
+			timeout := types.SnapshotHookDefaultTimeout
+			if hook.Timeout > 0 {
+				timeout = time.Duration(hook.Timeout) * time.Second
+			}
+			if err := r.execHandler(pod, container, hook.Command, timeout); err != nil {
+				failures = append(failures, fmt.Sprintf("%v hook %v failed in container %v of pod %v/%v: %v",
+					stage, strings.Join(hook.Command, " "), container, pod.Namespace, pod.Name, err))
+				if abort {
+					return failures
+				}
+			}
+		}
+	}
+	return failures
+}
+
+// listWorkloadPods returns the running pods using the claim bound to the
+// persistent volume of the volume
+func (r *SnapshotHookRunner) listWorkloadPods() ([]*v1.Pod, error) {
+	pvList, err := r.kubeClient.CoreV1().PersistentVolumes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods := []*v1.Pod{}
+	for _, pv := range pvList.Items {
+		if !isPersistentVolumeForVolume(&pv, r.volumeName) || pv.Spec.ClaimRef == nil {
+			continue
+		}
+		claim := pv.Spec.ClaimRef
+		podList, err := r.kubeClient.CoreV1().Pods(claim.Namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+				continue
+			}
+			for _, volume := range pod.Spec.Volumes {
+				if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claim.Name {
+					pods = append(pods, pod)
+					break
+				}
+			}
+		}
+	}
+	return pods, nil
+}
+
+func isPersistentVolumeForVolume(pv *v1.PersistentVolume, volumeName string) bool {
+	if pv.Spec.FlexVolume != nil {
+		return pv.Spec.FlexVolume.Driver == types.LonghornFlexVolumeDriver && pv.Name == volumeName
+	}
+	if pv.Spec.CSI != nil {
+		return pv.Spec.CSI.Driver == types.DefaultCSIDriverName && pv.Spec.CSI.VolumeHandle == volumeName
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package engineapi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rancher/longhorn-manager/types"
+
+	. "gopkg.in/check.v1"
+)
+
+func newHookTestPod(name, claimName string, phase v1.PodPhase) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "db"}, {Name: "sidecar"}},
+			Volumes: []v1.Volume{
+				{
+					Name: "data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+							ClaimName: claimName,
+						},
+					},
+				},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+// newTestSnapshotHookRunner records the executed hooks as
+// "pod/container:command", the commands containing "fail" fail
+func newTestSnapshotHookRunner(executed *[]string) *SnapshotHookRunner {
+	kubeClient := fake.NewSimpleClientset(
+		&v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: VolumeName},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeSource: v1.PersistentVolumeSource{
+					FlexVolume: &v1.FlexPersistentVolumeSource{Driver: types.LonghornFlexVolumeDriver},
+				},
+				ClaimRef: &v1.ObjectReference{Namespace: "default", Name: "db-data"},
+			},
+		},
+		&v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "other"},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeSource: v1.PersistentVolumeSource{
+					CSI: &v1.CSIPersistentVolumeSource{Driver: types.DefaultCSIDriverName, VolumeHandle: "other"},
+				},
+				ClaimRef: &v1.ObjectReference{Namespace: "default", Name: "other-data"},
+			},
+		},
+		newHookTestPod("db-0", "db-data", v1.PodRunning),
+		newHookTestPod("db-1", "db-data", v1.PodPending),
+		newHookTestPod("other-0", "other-data", v1.PodRunning),
+	)
+	return &SnapshotHookRunner{
+		volumeName: VolumeName,
+		kubeClient: kubeClient,
+		execHandler: func(pod *v1.Pod, container string, command []string, timeout time.Duration) error {
+			cmd := strings.Join(command, " ")
+			*executed = append(*executed, pod.Name+"/"+container+":"+cmd)
+			if strings.Contains(cmd, "fail") {
+				return fmt.Errorf("exit code 1")
+			}
+			return nil
+		},
+	}
+}
+
+func (s *TestSuite) TestSnapshotHooks(c *C) {
+	var executed []string
+	r := newTestSnapshotHookRunner(&executed)
+	created := false
+	create := func() error {
+		executed = append(executed, "snapshot")
+		created = true
+		return nil
+	}
+
+	hooks := &types.SnapshotHooks{
+		Pre:  []types.SnapshotHook{{Command: []string{"flush"}}},
+		Post: []types.SnapshotHook{{Container: "sidecar", Command: []string{"resume"}}},
+	}
+	failures, err := r.Run(hooks, create)
+	c.Assert(err, IsNil)
+	c.Assert(failures, HasLen, 0)
+	c.Assert(created, Equals, true)
+	c.Assert(executed, DeepEquals, []string{"db-0/db:flush", "snapshot", "db-0/sidecar:resume"})
+
+	// the failed pre hook aborts the snapshot, the post hooks still run
+	executed, created = nil, false
+	hooks.Pre = []types.SnapshotHook{{Command: []string{"fail"}}, {Command: []string{"flush"}}}
+	failures, err = r.Run(hooks, create)
+	c.Assert(err, NotNil)
+	c.Assert(failures, HasLen, 1)
+	c.Assert(created, Equals, false)
+	c.Assert(executed, DeepEquals, []string{"db-0/db:fail", "db-0/sidecar:resume"})
+
+	// the failures are recorded only with the continue policy
+	executed, created = nil, false
+	hooks.FailurePolicy = types.SnapshotHookFailurePolicyContinue
+	hooks.Post = []types.SnapshotHook{{Command: []string{"fail"}}}
+	failures, err = r.Run(hooks, create)
+	c.Assert(err, IsNil)
+	c.Assert(failures, HasLen, 2)
+	c.Assert(created, Equals, true)
+	c.Assert(executed, DeepEquals, []string{"db-0/db:fail", "db-0/db:flush", "snapshot", "db-0/db:fail"})
+
+	// the failed post hook fails the snapshot with the abort policy
+	executed, created = nil, false
+	hooks.FailurePolicy = types.SnapshotHookFailurePolicyAbort
+	hooks.Pre = nil
+	failures, err = r.Run(hooks, create)
+	c.Assert(err, NotNil)
+	c.Assert(failures, HasLen, 1)
+	c.Assert(created, Equals, true)
+}
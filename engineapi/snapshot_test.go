@@ -0,0 +1,25 @@
+package engineapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSnapshotsActualSize(t *testing.T) {
+	assert := require.New(t)
+
+	snapshots := map[string]*Snapshot{
+		"snap1":        {Name: "snap1", Size: "4096"},
+		"snap2":        {Name: "snap2", Size: "1048576", Removed: true},
+		VolumeHeadName: {Name: VolumeHeadName, Size: "0"},
+		"snap3":        {Name: "snap3"},
+	}
+	size, err := GetSnapshotsActualSize(snapshots)
+	assert.Nil(err)
+	assert.Equal(int64(1052672), size)
+
+	snapshots["snap4"] = &Snapshot{Name: "snap4", Size: "1G"}
+	_, err = GetSnapshotsActualSize(snapshots)
+	assert.NotNil(err)
+}
@@ -0,0 +1,58 @@
+package engineapi
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+// TrimVolume discards the unused blocks of the filesystem on the volume, then
+// reclaims the unmapped blocks in the volume head through engine. The result
+// is reported by recordEvent
+func TrimVolume(engine EngineClient, v *longhorn.Volume, recordEvent func(eventType, reason, message string)) (err error) {
+	if v.Spec.Frontend != types.VolumeFrontendBlockDev {
+		return fmt.Errorf("cannot trim volume %v with frontend %v, only %v is supported", v.Name, v.Spec.Frontend, types.VolumeFrontendBlockDev)
+	}
+
+	defer func() {
+		if err != nil {
+			recordEvent(v1.EventTypeWarning, types.EventReasonFailedTrimming, fmt.Sprintf("Failed to trim volume %v: %v", v.Name, err))
+		}
+	}()
+	mountPoint, err := util.GetDeviceMountPoint(util.GetHostDevicePath(v.Status.Endpoint))
+	if err != nil {
+		return err
+	}
+	if mountPoint == "" {
+		return fmt.Errorf("cannot find the filesystem of volume %v, it's not mounted", v.Name)
+	}
+	trimmed, err := util.TrimFilesystem(mountPoint)
+	if err != nil {
+		return err
+	}
+	reclaimed, err := engine.ReclaimSpace()
+	if err != nil {
+		return err
+	}
+	recordEvent(v1.EventTypeNormal, types.EventReasonTrimmed,
+		fmt.Sprintf("Trimmed %v bytes of the filesystem on volume %v, reclaimed %v bytes from the volume head", trimmed, v.Name, reclaimed))
+	return nil
+}
+
+// NewVolumeEvent builds an event of the volume, see util.NewEvent
+func NewVolumeEvent(v *longhorn.Volume, eventType, reason, message, component string) *v1.Event {
+	ref := &v1.ObjectReference{
+		APIVersion:      longhorn.SchemeGroupVersion.String(),
+		Kind:            "Volume",
+		Namespace:       v.Namespace,
+		Name:            v.Name,
+		UID:             v.UID,
+		ResourceVersion: v.ResourceVersion,
+	}
+	return util.NewEvent(ref, eventType, reason, message, component)
+}
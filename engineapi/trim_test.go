@@ -0,0 +1,73 @@
+package engineapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+type recordedEvent struct {
+	eventType, reason, message string
+}
+
+func TestTrimVolume(t *testing.T) {
+	assert := require.New(t)
+
+	events := []recordedEvent{}
+	recordEvent := func(eventType, reason, message string) {
+		events = append(events, recordedEvent{eventType, reason, message})
+	}
+	v := &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{Name: "vol"},
+		Spec: types.VolumeSpec{
+			Frontend: types.VolumeFrontendBlockDev,
+		},
+		Status: types.VolumeStatus{
+			Endpoint: "/dev/longhorn/not-exist",
+		},
+	}
+	engine := &EngineSimulator{}
+
+	// the unsupported frontend is rejected without an event
+	v.Spec.Frontend = ""
+	err := TrimVolume(engine, v, recordEvent)
+	assert.Error(err)
+	assert.Contains(err.Error(), "only blockdev is supported")
+	assert.Len(events, 0)
+
+	// the failure is recorded
+	v.Spec.Frontend = types.VolumeFrontendBlockDev
+	err = TrimVolume(engine, v, recordEvent)
+	assert.Error(err)
+	assert.Len(events, 1)
+	assert.Equal(v1.EventTypeWarning, events[0].eventType)
+	assert.Equal(types.EventReasonFailedTrimming, events[0].reason)
+	assert.Contains(events[0].message, "Failed to trim volume vol")
+}
+
+func TestNewVolumeEvent(t *testing.T) {
+	assert := require.New(t)
+
+	v := &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vol",
+			Namespace: "longhorn-system",
+			UID:       "uid-1",
+		},
+	}
+	event := NewVolumeEvent(v, v1.EventTypeNormal, types.EventReasonTrimmed, "trimmed", "longhorn-manager")
+	assert.Equal("longhorn-system", event.Namespace)
+	assert.Equal("Volume", event.InvolvedObject.Kind)
+	assert.Equal("vol", event.InvolvedObject.Name)
+	assert.Equal(v.UID, event.InvolvedObject.UID)
+	assert.Equal(longhorn.SchemeGroupVersion.String(), event.InvolvedObject.APIVersion)
+	assert.Equal(types.EventReasonTrimmed, event.Reason)
+	assert.Equal("longhorn-manager", event.Source.Component)
+}
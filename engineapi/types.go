@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/pkg/errors"
+
 	"github.com/rancher/longhorn-manager/types"
 )
 
@@ -11,6 +13,12 @@ const (
 	// CurrentCLIVersion indicates the API version manager used to talk with the
 	// engine, including `longhorn-engine` and `longhorn-engine-launcher`
 	CurrentCLIVersion = 1
+	// ExtendedCLIMinVersion is the CLI API version of the engine adding
+	// `snapshot export`, `reclaim-space`, `replica-rebuild-status`, `backup
+	// status`, `backup cancel`, `backup restore-status` and the
+	// `--bandwidth-limit` option of `backup create`. The older engines
+	// report ErrUnsupportedByEngine for these features.
+	ExtendedCLIMinVersion = 2
 
 	ControllerDefaultPort     = "9501"
 	EngineLauncherDefaultPort = "9510"
@@ -25,6 +33,13 @@ type Replica struct {
 	Mode types.ReplicaMode
 }
 
+type RebuildStatus struct {
+	IsRebuilding       bool   `json:"isRebuilding"`
+	Progress           int    `json:"progress"`
+	Error              string `json:"error"`
+	FromReplicaAddress string `json:"fromReplicaAddress"`
+}
+
 type Controller struct {
 	URL    string
 	NodeID string
@@ -39,6 +54,7 @@ type EngineClient interface {
 	ReplicaList() (map[string]*Replica, error)
 	ReplicaAdd(url string) error
 	ReplicaRemove(url string) error
+	ReplicaRebuildStatus() (map[string]*RebuildStatus, error)
 
 	SnapshotCreate(name string, labels map[string]string) (string, error)
 	SnapshotList() (map[string]*Snapshot, error)
@@ -46,7 +62,12 @@ type EngineClient interface {
 	SnapshotDelete(name string) error
 	SnapshotRevert(name string) error
 	SnapshotPurge() error
-	SnapshotBackup(snapName, backupTarget string, labels map[string]string, credential map[string]string) error
+	SnapshotBackup(snapName, backupTarget string, labels map[string]string, credential map[string]string, bandwidthLimit int) (string, error)
+	SnapshotBackupStatus(snapName string) (*BackupProgress, error)
+	SnapshotBackupCancel(snapName string) error
+	SnapshotExport(snapName, fileName string) error
+
+	ReclaimSpace() (int64, error)
 }
 
 type EngineClientRequest struct {
@@ -99,6 +120,13 @@ type Backup struct {
 	VolumeCreated   string            `json:"volumeCreated"`
 }
 
+// BackupProgress is of the backup of the snapshot running in the engine
+type BackupProgress struct {
+	Progress         int    `json:"progress"`
+	BytesTransferred int64  `json:"bytesTransferred"`
+	Error            string `json:"error"`
+}
+
 type LauncherVolumeInfo struct {
 	Volume   string `json:"volume,omitempty"`
 	Frontend string `json:"frontend,omitempty"`
@@ -134,6 +162,16 @@ func ValidateReplicaURL(url string) error {
 	return nil
 }
 
+// ErrUnsupportedByEngine is the cause of the error returned if the engine
+// image is too old to provide the feature, see CheckEngineFeature
+var ErrUnsupportedByEngine = errors.New("unsupported by engine")
+
+// IsUnsupportedByEngine returns true if err is caused by the engine image too
+// old to provide the feature
+func IsUnsupportedByEngine(err error) bool {
+	return errors.Cause(err) == ErrUnsupportedByEngine
+}
+
 func CheckCLICompatibilty(cliVersion, cliMinVersion int) error {
 	if CurrentCLIVersion > cliVersion || CurrentCLIVersion < cliMinVersion {
 		return fmt.Errorf("Current CLI version %v is not compatible with CLIVersion %v and CLIMinVersion %v", CurrentCLIVersion, cliVersion, cliMinVersion)
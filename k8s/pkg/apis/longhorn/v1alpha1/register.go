@@ -33,6 +33,24 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&EngineImageList{},
 		&Node{},
 		&NodeList{},
+		&BackingImage{},
+		&BackingImageList{},
+		&Orphan{},
+		&OrphanList{},
+		&RecurringJob{},
+		&RecurringJobList{},
+		&SnapshotGroup{},
+		&SnapshotGroupList{},
+		&BackupTarget{},
+		&BackupTargetList{},
+		&Backup{},
+		&BackupList{},
+		&BackupVolume{},
+		&BackupVolumeList{},
+		&BackupOperation{},
+		&BackupOperationList{},
+		&SnapshotExport{},
+		&SnapshotExportList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	scheme "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// BackupOperationsGetter has a method to return a BackupOperationInterface.
+// A group's client should implement this interface.
+type BackupOperationsGetter interface {
+	BackupOperations(namespace string) BackupOperationInterface
+}
+
+// BackupOperationInterface has methods to work with BackupOperation resources.
+type BackupOperationInterface interface {
+	Create(*v1alpha1.BackupOperation) (*v1alpha1.BackupOperation, error)
+	Update(*v1alpha1.BackupOperation) (*v1alpha1.BackupOperation, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.BackupOperation, error)
+	List(opts v1.ListOptions) (*v1alpha1.BackupOperationList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupOperation, err error)
+	BackupOperationExpansion
+}
+
+// backupOperations implements BackupOperationInterface
+type backupOperations struct {
+	client rest.Interface
+	ns     string
+}
+
+// newBackupOperations returns a BackupOperations
+func newBackupOperations(c *LonghornV1alpha1Client, namespace string) *backupOperations {
+	return &backupOperations{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the backupOperation, and returns the corresponding backupOperation object, and an error if there is any.
+func (c *backupOperations) Get(name string, options v1.GetOptions) (result *v1alpha1.BackupOperation, err error) {
+	result = &v1alpha1.BackupOperation{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("backupoperations").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of BackupOperations that match those selectors.
+func (c *backupOperations) List(opts v1.ListOptions) (result *v1alpha1.BackupOperationList, err error) {
+	result = &v1alpha1.BackupOperationList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("backupoperations").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested backupOperations.
+func (c *backupOperations) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("backupoperations").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a backupOperation and creates it.  Returns the server's representation of the backupOperation, and an error, if there is any.
+func (c *backupOperations) Create(backupOperation *v1alpha1.BackupOperation) (result *v1alpha1.BackupOperation, err error) {
+	result = &v1alpha1.BackupOperation{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("backupoperations").
+		Body(backupOperation).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a backupOperation and updates it. Returns the server's representation of the backupOperation, and an error, if there is any.
+func (c *backupOperations) Update(backupOperation *v1alpha1.BackupOperation) (result *v1alpha1.BackupOperation, err error) {
+	result = &v1alpha1.BackupOperation{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("backupoperations").
+		Name(backupOperation.Name).
+		Body(backupOperation).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the backupOperation and deletes it. Returns an error if one occurs.
+func (c *backupOperations) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("backupoperations").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *backupOperations) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("backupoperations").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched backupOperation.
+func (c *backupOperations) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupOperation, err error) {
+	result = &v1alpha1.BackupOperation{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("backupoperations").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	scheme "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// BackupTargetsGetter has a method to return a BackupTargetInterface.
+// A group's client should implement this interface.
+type BackupTargetsGetter interface {
+	BackupTargets(namespace string) BackupTargetInterface
+}
+
+// BackupTargetInterface has methods to work with BackupTarget resources.
+type BackupTargetInterface interface {
+	Create(*v1alpha1.BackupTarget) (*v1alpha1.BackupTarget, error)
+	Update(*v1alpha1.BackupTarget) (*v1alpha1.BackupTarget, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.BackupTarget, error)
+	List(opts v1.ListOptions) (*v1alpha1.BackupTargetList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupTarget, err error)
+	BackupTargetExpansion
+}
+
+// backupTargets implements BackupTargetInterface
+type backupTargets struct {
+	client rest.Interface
+	ns     string
+}
+
+// newBackupTargets returns a BackupTargets
+func newBackupTargets(c *LonghornV1alpha1Client, namespace string) *backupTargets {
+	return &backupTargets{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the backupTarget, and returns the corresponding backupTarget object, and an error if there is any.
+func (c *backupTargets) Get(name string, options v1.GetOptions) (result *v1alpha1.BackupTarget, err error) {
+	result = &v1alpha1.BackupTarget{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("backuptargets").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of BackupTargets that match those selectors.
+func (c *backupTargets) List(opts v1.ListOptions) (result *v1alpha1.BackupTargetList, err error) {
+	result = &v1alpha1.BackupTargetList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("backuptargets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested backupTargets.
+func (c *backupTargets) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("backuptargets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a backupTarget and creates it.  Returns the server's representation of the backupTarget, and an error, if there is any.
+func (c *backupTargets) Create(backupTarget *v1alpha1.BackupTarget) (result *v1alpha1.BackupTarget, err error) {
+	result = &v1alpha1.BackupTarget{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("backuptargets").
+		Body(backupTarget).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a backupTarget and updates it. Returns the server's representation of the backupTarget, and an error, if there is any.
+func (c *backupTargets) Update(backupTarget *v1alpha1.BackupTarget) (result *v1alpha1.BackupTarget, err error) {
+	result = &v1alpha1.BackupTarget{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("backuptargets").
+		Name(backupTarget.Name).
+		Body(backupTarget).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the backupTarget and deletes it. Returns an error if one occurs.
+func (c *backupTargets) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("backuptargets").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *backupTargets) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("backuptargets").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched backupTarget.
+func (c *backupTargets) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupTarget, err error) {
+	result = &v1alpha1.BackupTarget{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("backuptargets").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
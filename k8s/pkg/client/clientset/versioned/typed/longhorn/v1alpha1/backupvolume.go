@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	scheme "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// BackupVolumesGetter has a method to return a BackupVolumeInterface.
+// A group's client should implement this interface.
+type BackupVolumesGetter interface {
+	BackupVolumes(namespace string) BackupVolumeInterface
+}
+
+// BackupVolumeInterface has methods to work with BackupVolume resources.
+type BackupVolumeInterface interface {
+	Create(*v1alpha1.BackupVolume) (*v1alpha1.BackupVolume, error)
+	Update(*v1alpha1.BackupVolume) (*v1alpha1.BackupVolume, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.BackupVolume, error)
+	List(opts v1.ListOptions) (*v1alpha1.BackupVolumeList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupVolume, err error)
+	BackupVolumeExpansion
+}
+
+// backupVolumes implements BackupVolumeInterface
+type backupVolumes struct {
+	client rest.Interface
+	ns     string
+}
+
+// newBackupVolumes returns a BackupVolumes
+func newBackupVolumes(c *LonghornV1alpha1Client, namespace string) *backupVolumes {
+	return &backupVolumes{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the backupVolume, and returns the corresponding backupVolume object, and an error if there is any.
+func (c *backupVolumes) Get(name string, options v1.GetOptions) (result *v1alpha1.BackupVolume, err error) {
+	result = &v1alpha1.BackupVolume{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("backupvolumes").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of BackupVolumes that match those selectors.
+func (c *backupVolumes) List(opts v1.ListOptions) (result *v1alpha1.BackupVolumeList, err error) {
+	result = &v1alpha1.BackupVolumeList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("backupvolumes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested backupVolumes.
+func (c *backupVolumes) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("backupvolumes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a backupVolume and creates it.  Returns the server's representation of the backupVolume, and an error, if there is any.
+func (c *backupVolumes) Create(backupVolume *v1alpha1.BackupVolume) (result *v1alpha1.BackupVolume, err error) {
+	result = &v1alpha1.BackupVolume{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("backupvolumes").
+		Body(backupVolume).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a backupVolume and updates it. Returns the server's representation of the backupVolume, and an error, if there is any.
+func (c *backupVolumes) Update(backupVolume *v1alpha1.BackupVolume) (result *v1alpha1.BackupVolume, err error) {
+	result = &v1alpha1.BackupVolume{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("backupvolumes").
+		Name(backupVolume.Name).
+		Body(backupVolume).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the backupVolume and deletes it. Returns an error if one occurs.
+func (c *backupVolumes) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("backupvolumes").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *backupVolumes) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("backupvolumes").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched backupVolume.
+func (c *backupVolumes) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupVolume, err error) {
+	result = &v1alpha1.BackupVolume{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("backupvolumes").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeBackupOperations implements BackupOperationInterface
+type FakeBackupOperations struct {
+	Fake *FakeLonghornV1alpha1
+	ns   string
+}
+
+var backupoperationsResource = schema.GroupVersionResource{Group: "longhorn.rancher.io", Version: "v1alpha1", Resource: "backupoperations"}
+
+var backupoperationsKind = schema.GroupVersionKind{Group: "longhorn.rancher.io", Version: "v1alpha1", Kind: "BackupOperation"}
+
+// Get takes name of the backupOperation, and returns the corresponding backupOperation object, and an error if there is any.
+func (c *FakeBackupOperations) Get(name string, options v1.GetOptions) (result *v1alpha1.BackupOperation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(backupoperationsResource, c.ns, name), &v1alpha1.BackupOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupOperation), err
+}
+
+// List takes label and field selectors, and returns the list of BackupOperations that match those selectors.
+func (c *FakeBackupOperations) List(opts v1.ListOptions) (result *v1alpha1.BackupOperationList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(backupoperationsResource, backupoperationsKind, c.ns, opts), &v1alpha1.BackupOperationList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.BackupOperationList{}
+	for _, item := range obj.(*v1alpha1.BackupOperationList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested backupOperations.
+func (c *FakeBackupOperations) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(backupoperationsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a backupOperation and creates it.  Returns the server's representation of the backupOperation, and an error, if there is any.
+func (c *FakeBackupOperations) Create(backupOperation *v1alpha1.BackupOperation) (result *v1alpha1.BackupOperation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(backupoperationsResource, c.ns, backupOperation), &v1alpha1.BackupOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupOperation), err
+}
+
+// Update takes the representation of a backupOperation and updates it. Returns the server's representation of the backupOperation, and an error, if there is any.
+func (c *FakeBackupOperations) Update(backupOperation *v1alpha1.BackupOperation) (result *v1alpha1.BackupOperation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(backupoperationsResource, c.ns, backupOperation), &v1alpha1.BackupOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupOperation), err
+}
+
+// Delete takes name of the backupOperation and deletes it. Returns an error if one occurs.
+func (c *FakeBackupOperations) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(backupoperationsResource, c.ns, name), &v1alpha1.BackupOperation{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeBackupOperations) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(backupoperationsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.BackupOperationList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched backupOperation.
+func (c *FakeBackupOperations) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupOperation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(backupoperationsResource, c.ns, name, data, subresources...), &v1alpha1.BackupOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupOperation), err
+}
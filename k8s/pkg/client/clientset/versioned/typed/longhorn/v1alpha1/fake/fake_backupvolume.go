@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeBackupVolumes implements BackupVolumeInterface
+type FakeBackupVolumes struct {
+	Fake *FakeLonghornV1alpha1
+	ns   string
+}
+
+var backupvolumesResource = schema.GroupVersionResource{Group: "longhorn.rancher.io", Version: "v1alpha1", Resource: "backupvolumes"}
+
+var backupvolumesKind = schema.GroupVersionKind{Group: "longhorn.rancher.io", Version: "v1alpha1", Kind: "BackupVolume"}
+
+// Get takes name of the backupVolume, and returns the corresponding backupVolume object, and an error if there is any.
+func (c *FakeBackupVolumes) Get(name string, options v1.GetOptions) (result *v1alpha1.BackupVolume, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(backupvolumesResource, c.ns, name), &v1alpha1.BackupVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVolume), err
+}
+
+// List takes label and field selectors, and returns the list of BackupVolumes that match those selectors.
+func (c *FakeBackupVolumes) List(opts v1.ListOptions) (result *v1alpha1.BackupVolumeList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(backupvolumesResource, backupvolumesKind, c.ns, opts), &v1alpha1.BackupVolumeList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.BackupVolumeList{}
+	for _, item := range obj.(*v1alpha1.BackupVolumeList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested backupVolumes.
+func (c *FakeBackupVolumes) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(backupvolumesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a backupVolume and creates it.  Returns the server's representation of the backupVolume, and an error, if there is any.
+func (c *FakeBackupVolumes) Create(backupVolume *v1alpha1.BackupVolume) (result *v1alpha1.BackupVolume, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(backupvolumesResource, c.ns, backupVolume), &v1alpha1.BackupVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVolume), err
+}
+
+// Update takes the representation of a backupVolume and updates it. Returns the server's representation of the backupVolume, and an error, if there is any.
+func (c *FakeBackupVolumes) Update(backupVolume *v1alpha1.BackupVolume) (result *v1alpha1.BackupVolume, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(backupvolumesResource, c.ns, backupVolume), &v1alpha1.BackupVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVolume), err
+}
+
+// Delete takes name of the backupVolume and deletes it. Returns an error if one occurs.
+func (c *FakeBackupVolumes) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(backupvolumesResource, c.ns, name), &v1alpha1.BackupVolume{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeBackupVolumes) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(backupvolumesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.BackupVolumeList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched backupVolume.
+func (c *FakeBackupVolumes) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.BackupVolume, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(backupvolumesResource, c.ns, name, data, subresources...), &v1alpha1.BackupVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BackupVolume), err
+}
@@ -28,6 +28,26 @@ type FakeLonghornV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeLonghornV1alpha1) BackingImages(namespace string) v1alpha1.BackingImageInterface {
+	return &FakeBackingImages{c, namespace}
+}
+
+func (c *FakeLonghornV1alpha1) Backups(namespace string) v1alpha1.BackupInterface {
+	return &FakeBackups{c, namespace}
+}
+
+func (c *FakeLonghornV1alpha1) BackupOperations(namespace string) v1alpha1.BackupOperationInterface {
+	return &FakeBackupOperations{c, namespace}
+}
+
+func (c *FakeLonghornV1alpha1) BackupTargets(namespace string) v1alpha1.BackupTargetInterface {
+	return &FakeBackupTargets{c, namespace}
+}
+
+func (c *FakeLonghornV1alpha1) BackupVolumes(namespace string) v1alpha1.BackupVolumeInterface {
+	return &FakeBackupVolumes{c, namespace}
+}
+
 func (c *FakeLonghornV1alpha1) Engines(namespace string) v1alpha1.EngineInterface {
 	return &FakeEngines{c, namespace}
 }
@@ -40,6 +60,14 @@ func (c *FakeLonghornV1alpha1) Nodes(namespace string) v1alpha1.NodeInterface {
 	return &FakeNodes{c, namespace}
 }
 
+func (c *FakeLonghornV1alpha1) Orphans(namespace string) v1alpha1.OrphanInterface {
+	return &FakeOrphans{c, namespace}
+}
+
+func (c *FakeLonghornV1alpha1) RecurringJobs(namespace string) v1alpha1.RecurringJobInterface {
+	return &FakeRecurringJobs{c, namespace}
+}
+
 func (c *FakeLonghornV1alpha1) Replicas(namespace string) v1alpha1.ReplicaInterface {
 	return &FakeReplicas{c, namespace}
 }
@@ -48,6 +76,14 @@ func (c *FakeLonghornV1alpha1) Settings(namespace string) v1alpha1.SettingInterf
 	return &FakeSettings{c, namespace}
 }
 
+func (c *FakeLonghornV1alpha1) SnapshotExports(namespace string) v1alpha1.SnapshotExportInterface {
+	return &FakeSnapshotExports{c, namespace}
+}
+
+func (c *FakeLonghornV1alpha1) SnapshotGroups(namespace string) v1alpha1.SnapshotGroupInterface {
+	return &FakeSnapshotGroups{c, namespace}
+}
+
 func (c *FakeLonghornV1alpha1) Volumes(namespace string) v1alpha1.VolumeInterface {
 	return &FakeVolumes{c, namespace}
 }
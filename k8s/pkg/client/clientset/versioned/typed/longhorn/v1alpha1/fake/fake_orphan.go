@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeOrphans implements OrphanInterface
+type FakeOrphans struct {
+	Fake *FakeLonghornV1alpha1
+	ns   string
+}
+
+var orphansResource = schema.GroupVersionResource{Group: "longhorn.rancher.io", Version: "v1alpha1", Resource: "orphans"}
+
+var orphansKind = schema.GroupVersionKind{Group: "longhorn.rancher.io", Version: "v1alpha1", Kind: "Orphan"}
+
+// Get takes name of the orphan, and returns the corresponding orphan object, and an error if there is any.
+func (c *FakeOrphans) Get(name string, options v1.GetOptions) (result *v1alpha1.Orphan, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(orphansResource, c.ns, name), &v1alpha1.Orphan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Orphan), err
+}
+
+// List takes label and field selectors, and returns the list of Orphans that match those selectors.
+func (c *FakeOrphans) List(opts v1.ListOptions) (result *v1alpha1.OrphanList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(orphansResource, orphansKind, c.ns, opts), &v1alpha1.OrphanList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.OrphanList{}
+	for _, item := range obj.(*v1alpha1.OrphanList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested orphans.
+func (c *FakeOrphans) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(orphansResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a orphan and creates it.  Returns the server's representation of the orphan, and an error, if there is any.
+func (c *FakeOrphans) Create(orphan *v1alpha1.Orphan) (result *v1alpha1.Orphan, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(orphansResource, c.ns, orphan), &v1alpha1.Orphan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Orphan), err
+}
+
+// Update takes the representation of a orphan and updates it. Returns the server's representation of the orphan, and an error, if there is any.
+func (c *FakeOrphans) Update(orphan *v1alpha1.Orphan) (result *v1alpha1.Orphan, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(orphansResource, c.ns, orphan), &v1alpha1.Orphan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Orphan), err
+}
+
+// Delete takes name of the orphan and deletes it. Returns an error if one occurs.
+func (c *FakeOrphans) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(orphansResource, c.ns, name), &v1alpha1.Orphan{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeOrphans) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(orphansResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.OrphanList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched orphan.
+func (c *FakeOrphans) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.Orphan, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(orphansResource, c.ns, name, data, subresources...), &v1alpha1.Orphan{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Orphan), err
+}
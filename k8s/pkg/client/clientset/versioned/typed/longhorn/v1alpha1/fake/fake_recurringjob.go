@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeRecurringJobs implements RecurringJobInterface
+type FakeRecurringJobs struct {
+	Fake *FakeLonghornV1alpha1
+	ns   string
+}
+
+var recurringjobsResource = schema.GroupVersionResource{Group: "longhorn.rancher.io", Version: "v1alpha1", Resource: "recurringjobs"}
+
+var recurringjobsKind = schema.GroupVersionKind{Group: "longhorn.rancher.io", Version: "v1alpha1", Kind: "RecurringJob"}
+
+// Get takes name of the recurringJob, and returns the corresponding recurringJob object, and an error if there is any.
+func (c *FakeRecurringJobs) Get(name string, options v1.GetOptions) (result *v1alpha1.RecurringJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(recurringjobsResource, c.ns, name), &v1alpha1.RecurringJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.RecurringJob), err
+}
+
+// List takes label and field selectors, and returns the list of RecurringJobs that match those selectors.
+func (c *FakeRecurringJobs) List(opts v1.ListOptions) (result *v1alpha1.RecurringJobList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(recurringjobsResource, recurringjobsKind, c.ns, opts), &v1alpha1.RecurringJobList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.RecurringJobList{}
+	for _, item := range obj.(*v1alpha1.RecurringJobList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested recurringJobs.
+func (c *FakeRecurringJobs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(recurringjobsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a recurringJob and creates it.  Returns the server's representation of the recurringJob, and an error, if there is any.
+func (c *FakeRecurringJobs) Create(recurringJob *v1alpha1.RecurringJob) (result *v1alpha1.RecurringJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(recurringjobsResource, c.ns, recurringJob), &v1alpha1.RecurringJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.RecurringJob), err
+}
+
+// Update takes the representation of a recurringJob and updates it. Returns the server's representation of the recurringJob, and an error, if there is any.
+func (c *FakeRecurringJobs) Update(recurringJob *v1alpha1.RecurringJob) (result *v1alpha1.RecurringJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(recurringjobsResource, c.ns, recurringJob), &v1alpha1.RecurringJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.RecurringJob), err
+}
+
+// Delete takes name of the recurringJob and deletes it. Returns an error if one occurs.
+func (c *FakeRecurringJobs) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(recurringjobsResource, c.ns, name), &v1alpha1.RecurringJob{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeRecurringJobs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(recurringjobsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.RecurringJobList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched recurringJob.
+func (c *FakeRecurringJobs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.RecurringJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(recurringjobsResource, c.ns, name, data, subresources...), &v1alpha1.RecurringJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.RecurringJob), err
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeSnapshotExports implements SnapshotExportInterface
+type FakeSnapshotExports struct {
+	Fake *FakeLonghornV1alpha1
+	ns   string
+}
+
+var snapshotexportsResource = schema.GroupVersionResource{Group: "longhorn.rancher.io", Version: "v1alpha1", Resource: "snapshotexports"}
+
+var snapshotexportsKind = schema.GroupVersionKind{Group: "longhorn.rancher.io", Version: "v1alpha1", Kind: "SnapshotExport"}
+
+// Get takes name of the snapshotExport, and returns the corresponding snapshotExport object, and an error if there is any.
+func (c *FakeSnapshotExports) Get(name string, options v1.GetOptions) (result *v1alpha1.SnapshotExport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(snapshotexportsResource, c.ns, name), &v1alpha1.SnapshotExport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SnapshotExport), err
+}
+
+// List takes label and field selectors, and returns the list of SnapshotExports that match those selectors.
+func (c *FakeSnapshotExports) List(opts v1.ListOptions) (result *v1alpha1.SnapshotExportList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(snapshotexportsResource, snapshotexportsKind, c.ns, opts), &v1alpha1.SnapshotExportList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.SnapshotExportList{}
+	for _, item := range obj.(*v1alpha1.SnapshotExportList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested snapshotExports.
+func (c *FakeSnapshotExports) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(snapshotexportsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a snapshotExport and creates it.  Returns the server's representation of the snapshotExport, and an error, if there is any.
+func (c *FakeSnapshotExports) Create(snapshotExport *v1alpha1.SnapshotExport) (result *v1alpha1.SnapshotExport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(snapshotexportsResource, c.ns, snapshotExport), &v1alpha1.SnapshotExport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SnapshotExport), err
+}
+
+// Update takes the representation of a snapshotExport and updates it. Returns the server's representation of the snapshotExport, and an error, if there is any.
+func (c *FakeSnapshotExports) Update(snapshotExport *v1alpha1.SnapshotExport) (result *v1alpha1.SnapshotExport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(snapshotexportsResource, c.ns, snapshotExport), &v1alpha1.SnapshotExport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SnapshotExport), err
+}
+
+// Delete takes name of the snapshotExport and deletes it. Returns an error if one occurs.
+func (c *FakeSnapshotExports) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(snapshotexportsResource, c.ns, name), &v1alpha1.SnapshotExport{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeSnapshotExports) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(snapshotexportsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.SnapshotExportList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched snapshotExport.
+func (c *FakeSnapshotExports) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SnapshotExport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(snapshotexportsResource, c.ns, name, data, subresources...), &v1alpha1.SnapshotExport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SnapshotExport), err
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeSnapshotGroups implements SnapshotGroupInterface
+type FakeSnapshotGroups struct {
+	Fake *FakeLonghornV1alpha1
+	ns   string
+}
+
+var snapshotgroupsResource = schema.GroupVersionResource{Group: "longhorn.rancher.io", Version: "v1alpha1", Resource: "snapshotgroups"}
+
+var snapshotgroupsKind = schema.GroupVersionKind{Group: "longhorn.rancher.io", Version: "v1alpha1", Kind: "SnapshotGroup"}
+
+// Get takes name of the snapshotGroup, and returns the corresponding snapshotGroup object, and an error if there is any.
+func (c *FakeSnapshotGroups) Get(name string, options v1.GetOptions) (result *v1alpha1.SnapshotGroup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(snapshotgroupsResource, c.ns, name), &v1alpha1.SnapshotGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SnapshotGroup), err
+}
+
+// List takes label and field selectors, and returns the list of SnapshotGroups that match those selectors.
+func (c *FakeSnapshotGroups) List(opts v1.ListOptions) (result *v1alpha1.SnapshotGroupList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(snapshotgroupsResource, snapshotgroupsKind, c.ns, opts), &v1alpha1.SnapshotGroupList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.SnapshotGroupList{}
+	for _, item := range obj.(*v1alpha1.SnapshotGroupList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested snapshotGroups.
+func (c *FakeSnapshotGroups) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(snapshotgroupsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a snapshotGroup and creates it.  Returns the server's representation of the snapshotGroup, and an error, if there is any.
+func (c *FakeSnapshotGroups) Create(snapshotGroup *v1alpha1.SnapshotGroup) (result *v1alpha1.SnapshotGroup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(snapshotgroupsResource, c.ns, snapshotGroup), &v1alpha1.SnapshotGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SnapshotGroup), err
+}
+
+// Update takes the representation of a snapshotGroup and updates it. Returns the server's representation of the snapshotGroup, and an error, if there is any.
+func (c *FakeSnapshotGroups) Update(snapshotGroup *v1alpha1.SnapshotGroup) (result *v1alpha1.SnapshotGroup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(snapshotgroupsResource, c.ns, snapshotGroup), &v1alpha1.SnapshotGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SnapshotGroup), err
+}
+
+// Delete takes name of the snapshotGroup and deletes it. Returns an error if one occurs.
+func (c *FakeSnapshotGroups) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(snapshotgroupsResource, c.ns, name), &v1alpha1.SnapshotGroup{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeSnapshotGroups) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(snapshotgroupsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.SnapshotGroupList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched snapshotGroup.
+func (c *FakeSnapshotGroups) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SnapshotGroup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(snapshotgroupsResource, c.ns, name, data, subresources...), &v1alpha1.SnapshotGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.SnapshotGroup), err
+}
@@ -18,14 +18,32 @@ limitations under the License.
 
 package v1alpha1
 
+type BackingImageExpansion interface{}
+
+type BackupExpansion interface{}
+
+type BackupOperationExpansion interface{}
+
+type BackupTargetExpansion interface{}
+
+type BackupVolumeExpansion interface{}
+
 type EngineExpansion interface{}
 
 type EngineImageExpansion interface{}
 
 type NodeExpansion interface{}
 
+type OrphanExpansion interface{}
+
+type RecurringJobExpansion interface{}
+
 type ReplicaExpansion interface{}
 
 type SettingExpansion interface{}
 
+type SnapshotExportExpansion interface{}
+
+type SnapshotGroupExpansion interface{}
+
 type VolumeExpansion interface{}
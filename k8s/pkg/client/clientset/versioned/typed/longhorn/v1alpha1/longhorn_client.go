@@ -27,11 +27,20 @@ import (
 
 type LonghornV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	BackingImagesGetter
+	BackupsGetter
+	BackupOperationsGetter
+	BackupTargetsGetter
+	BackupVolumesGetter
 	EnginesGetter
 	EngineImagesGetter
 	NodesGetter
+	OrphansGetter
+	RecurringJobsGetter
 	ReplicasGetter
 	SettingsGetter
+	SnapshotExportsGetter
+	SnapshotGroupsGetter
 	VolumesGetter
 }
 
@@ -40,6 +49,26 @@ type LonghornV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *LonghornV1alpha1Client) BackingImages(namespace string) BackingImageInterface {
+	return newBackingImages(c, namespace)
+}
+
+func (c *LonghornV1alpha1Client) Backups(namespace string) BackupInterface {
+	return newBackups(c, namespace)
+}
+
+func (c *LonghornV1alpha1Client) BackupOperations(namespace string) BackupOperationInterface {
+	return newBackupOperations(c, namespace)
+}
+
+func (c *LonghornV1alpha1Client) BackupTargets(namespace string) BackupTargetInterface {
+	return newBackupTargets(c, namespace)
+}
+
+func (c *LonghornV1alpha1Client) BackupVolumes(namespace string) BackupVolumeInterface {
+	return newBackupVolumes(c, namespace)
+}
+
 func (c *LonghornV1alpha1Client) Engines(namespace string) EngineInterface {
 	return newEngines(c, namespace)
 }
@@ -52,6 +81,14 @@ func (c *LonghornV1alpha1Client) Nodes(namespace string) NodeInterface {
 	return newNodes(c, namespace)
 }
 
+func (c *LonghornV1alpha1Client) Orphans(namespace string) OrphanInterface {
+	return newOrphans(c, namespace)
+}
+
+func (c *LonghornV1alpha1Client) RecurringJobs(namespace string) RecurringJobInterface {
+	return newRecurringJobs(c, namespace)
+}
+
 func (c *LonghornV1alpha1Client) Replicas(namespace string) ReplicaInterface {
 	return newReplicas(c, namespace)
 }
@@ -60,6 +97,14 @@ func (c *LonghornV1alpha1Client) Settings(namespace string) SettingInterface {
 	return newSettings(c, namespace)
 }
 
+func (c *LonghornV1alpha1Client) SnapshotExports(namespace string) SnapshotExportInterface {
+	return newSnapshotExports(c, namespace)
+}
+
+func (c *LonghornV1alpha1Client) SnapshotGroups(namespace string) SnapshotGroupInterface {
+	return newSnapshotGroups(c, namespace)
+}
+
 func (c *LonghornV1alpha1Client) Volumes(namespace string) VolumeInterface {
 	return newVolumes(c, namespace)
 }
@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	scheme "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// OrphansGetter has a method to return a OrphanInterface.
+// A group's client should implement this interface.
+type OrphansGetter interface {
+	Orphans(namespace string) OrphanInterface
+}
+
+// OrphanInterface has methods to work with Orphan resources.
+type OrphanInterface interface {
+	Create(*v1alpha1.Orphan) (*v1alpha1.Orphan, error)
+	Update(*v1alpha1.Orphan) (*v1alpha1.Orphan, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.Orphan, error)
+	List(opts v1.ListOptions) (*v1alpha1.OrphanList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.Orphan, err error)
+	OrphanExpansion
+}
+
+// orphans implements OrphanInterface
+type orphans struct {
+	client rest.Interface
+	ns     string
+}
+
+// newOrphans returns a Orphans
+func newOrphans(c *LonghornV1alpha1Client, namespace string) *orphans {
+	return &orphans{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the orphan, and returns the corresponding orphan object, and an error if there is any.
+func (c *orphans) Get(name string, options v1.GetOptions) (result *v1alpha1.Orphan, err error) {
+	result = &v1alpha1.Orphan{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("orphans").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Orphans that match those selectors.
+func (c *orphans) List(opts v1.ListOptions) (result *v1alpha1.OrphanList, err error) {
+	result = &v1alpha1.OrphanList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("orphans").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested orphans.
+func (c *orphans) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("orphans").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a orphan and creates it.  Returns the server's representation of the orphan, and an error, if there is any.
+func (c *orphans) Create(orphan *v1alpha1.Orphan) (result *v1alpha1.Orphan, err error) {
+	result = &v1alpha1.Orphan{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("orphans").
+		Body(orphan).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a orphan and updates it. Returns the server's representation of the orphan, and an error, if there is any.
+func (c *orphans) Update(orphan *v1alpha1.Orphan) (result *v1alpha1.Orphan, err error) {
+	result = &v1alpha1.Orphan{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("orphans").
+		Name(orphan.Name).
+		Body(orphan).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the orphan and deletes it. Returns an error if one occurs.
+func (c *orphans) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("orphans").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *orphans) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("orphans").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched orphan.
+func (c *orphans) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.Orphan, err error) {
+	result = &v1alpha1.Orphan{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("orphans").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	scheme "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// RecurringJobsGetter has a method to return a RecurringJobInterface.
+// A group's client should implement this interface.
+type RecurringJobsGetter interface {
+	RecurringJobs(namespace string) RecurringJobInterface
+}
+
+// RecurringJobInterface has methods to work with RecurringJob resources.
+type RecurringJobInterface interface {
+	Create(*v1alpha1.RecurringJob) (*v1alpha1.RecurringJob, error)
+	Update(*v1alpha1.RecurringJob) (*v1alpha1.RecurringJob, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.RecurringJob, error)
+	List(opts v1.ListOptions) (*v1alpha1.RecurringJobList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.RecurringJob, err error)
+	RecurringJobExpansion
+}
+
+// recurringJobs implements RecurringJobInterface
+type recurringJobs struct {
+	client rest.Interface
+	ns     string
+}
+
+// newRecurringJobs returns a RecurringJobs
+func newRecurringJobs(c *LonghornV1alpha1Client, namespace string) *recurringJobs {
+	return &recurringJobs{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the recurringJob, and returns the corresponding recurringJob object, and an error if there is any.
+func (c *recurringJobs) Get(name string, options v1.GetOptions) (result *v1alpha1.RecurringJob, err error) {
+	result = &v1alpha1.RecurringJob{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("recurringjobs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of RecurringJobs that match those selectors.
+func (c *recurringJobs) List(opts v1.ListOptions) (result *v1alpha1.RecurringJobList, err error) {
+	result = &v1alpha1.RecurringJobList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("recurringjobs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested recurringJobs.
+func (c *recurringJobs) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("recurringjobs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a recurringJob and creates it.  Returns the server's representation of the recurringJob, and an error, if there is any.
+func (c *recurringJobs) Create(recurringJob *v1alpha1.RecurringJob) (result *v1alpha1.RecurringJob, err error) {
+	result = &v1alpha1.RecurringJob{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("recurringjobs").
+		Body(recurringJob).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a recurringJob and updates it. Returns the server's representation of the recurringJob, and an error, if there is any.
+func (c *recurringJobs) Update(recurringJob *v1alpha1.RecurringJob) (result *v1alpha1.RecurringJob, err error) {
+	result = &v1alpha1.RecurringJob{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("recurringjobs").
+		Name(recurringJob.Name).
+		Body(recurringJob).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the recurringJob and deletes it. Returns an error if one occurs.
+func (c *recurringJobs) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("recurringjobs").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *recurringJobs) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("recurringjobs").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched recurringJob.
+func (c *recurringJobs) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.RecurringJob, err error) {
+	result = &v1alpha1.RecurringJob{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("recurringjobs").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
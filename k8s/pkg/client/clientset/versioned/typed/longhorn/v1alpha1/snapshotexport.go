@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	scheme "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// SnapshotExportsGetter has a method to return a SnapshotExportInterface.
+// A group's client should implement this interface.
+type SnapshotExportsGetter interface {
+	SnapshotExports(namespace string) SnapshotExportInterface
+}
+
+// SnapshotExportInterface has methods to work with SnapshotExport resources.
+type SnapshotExportInterface interface {
+	Create(*v1alpha1.SnapshotExport) (*v1alpha1.SnapshotExport, error)
+	Update(*v1alpha1.SnapshotExport) (*v1alpha1.SnapshotExport, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.SnapshotExport, error)
+	List(opts v1.ListOptions) (*v1alpha1.SnapshotExportList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SnapshotExport, err error)
+	SnapshotExportExpansion
+}
+
+// snapshotExports implements SnapshotExportInterface
+type snapshotExports struct {
+	client rest.Interface
+	ns     string
+}
+
+// newSnapshotExports returns a SnapshotExports
+func newSnapshotExports(c *LonghornV1alpha1Client, namespace string) *snapshotExports {
+	return &snapshotExports{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the snapshotExport, and returns the corresponding snapshotExport object, and an error if there is any.
+func (c *snapshotExports) Get(name string, options v1.GetOptions) (result *v1alpha1.SnapshotExport, err error) {
+	result = &v1alpha1.SnapshotExport{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("snapshotexports").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of SnapshotExports that match those selectors.
+func (c *snapshotExports) List(opts v1.ListOptions) (result *v1alpha1.SnapshotExportList, err error) {
+	result = &v1alpha1.SnapshotExportList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("snapshotexports").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested snapshotExports.
+func (c *snapshotExports) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("snapshotexports").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a snapshotExport and creates it.  Returns the server's representation of the snapshotExport, and an error, if there is any.
+func (c *snapshotExports) Create(snapshotExport *v1alpha1.SnapshotExport) (result *v1alpha1.SnapshotExport, err error) {
+	result = &v1alpha1.SnapshotExport{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("snapshotexports").
+		Body(snapshotExport).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a snapshotExport and updates it. Returns the server's representation of the snapshotExport, and an error, if there is any.
+func (c *snapshotExports) Update(snapshotExport *v1alpha1.SnapshotExport) (result *v1alpha1.SnapshotExport, err error) {
+	result = &v1alpha1.SnapshotExport{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("snapshotexports").
+		Name(snapshotExport.Name).
+		Body(snapshotExport).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the snapshotExport and deletes it. Returns an error if one occurs.
+func (c *snapshotExports) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("snapshotexports").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *snapshotExports) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("snapshotexports").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched snapshotExport.
+func (c *snapshotExports) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SnapshotExport, err error) {
+	result = &v1alpha1.SnapshotExport{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("snapshotexports").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
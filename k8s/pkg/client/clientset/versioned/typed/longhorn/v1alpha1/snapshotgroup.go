@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	scheme "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// SnapshotGroupsGetter has a method to return a SnapshotGroupInterface.
+// A group's client should implement this interface.
+type SnapshotGroupsGetter interface {
+	SnapshotGroups(namespace string) SnapshotGroupInterface
+}
+
+// SnapshotGroupInterface has methods to work with SnapshotGroup resources.
+type SnapshotGroupInterface interface {
+	Create(*v1alpha1.SnapshotGroup) (*v1alpha1.SnapshotGroup, error)
+	Update(*v1alpha1.SnapshotGroup) (*v1alpha1.SnapshotGroup, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.SnapshotGroup, error)
+	List(opts v1.ListOptions) (*v1alpha1.SnapshotGroupList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SnapshotGroup, err error)
+	SnapshotGroupExpansion
+}
+
+// snapshotGroups implements SnapshotGroupInterface
+type snapshotGroups struct {
+	client rest.Interface
+	ns     string
+}
+
+// newSnapshotGroups returns a SnapshotGroups
+func newSnapshotGroups(c *LonghornV1alpha1Client, namespace string) *snapshotGroups {
+	return &snapshotGroups{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the snapshotGroup, and returns the corresponding snapshotGroup object, and an error if there is any.
+func (c *snapshotGroups) Get(name string, options v1.GetOptions) (result *v1alpha1.SnapshotGroup, err error) {
+	result = &v1alpha1.SnapshotGroup{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("snapshotgroups").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of SnapshotGroups that match those selectors.
+func (c *snapshotGroups) List(opts v1.ListOptions) (result *v1alpha1.SnapshotGroupList, err error) {
+	result = &v1alpha1.SnapshotGroupList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("snapshotgroups").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested snapshotGroups.
+func (c *snapshotGroups) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("snapshotgroups").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a snapshotGroup and creates it.  Returns the server's representation of the snapshotGroup, and an error, if there is any.
+func (c *snapshotGroups) Create(snapshotGroup *v1alpha1.SnapshotGroup) (result *v1alpha1.SnapshotGroup, err error) {
+	result = &v1alpha1.SnapshotGroup{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("snapshotgroups").
+		Body(snapshotGroup).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a snapshotGroup and updates it. Returns the server's representation of the snapshotGroup, and an error, if there is any.
+func (c *snapshotGroups) Update(snapshotGroup *v1alpha1.SnapshotGroup) (result *v1alpha1.SnapshotGroup, err error) {
+	result = &v1alpha1.SnapshotGroup{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("snapshotgroups").
+		Name(snapshotGroup.Name).
+		Body(snapshotGroup).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the snapshotGroup and deletes it. Returns an error if one occurs.
+func (c *snapshotGroups) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("snapshotgroups").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *snapshotGroups) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("snapshotgroups").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched snapshotGroup.
+func (c *snapshotGroups) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.SnapshotGroup, err error) {
+	result = &v1alpha1.SnapshotGroup{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("snapshotgroups").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
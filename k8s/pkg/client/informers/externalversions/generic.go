@@ -53,16 +53,34 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=longhorn.rancher.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("backingimages"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().BackingImages().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("backups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().Backups().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("backupoperations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().BackupOperations().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("backuptargets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().BackupTargets().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("backupvolumes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().BackupVolumes().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("engines"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().Engines().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("engineimages"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().EngineImages().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("nodes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().Nodes().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("orphans"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().Orphans().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("recurringjobs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().RecurringJobs().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("replicas"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().Replicas().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("settings"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().Settings().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("snapshotexports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().SnapshotExports().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("snapshotgroups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().SnapshotGroups().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("volumes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1alpha1().Volumes().Informer()}, nil
 
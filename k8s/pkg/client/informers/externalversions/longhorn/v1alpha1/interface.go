@@ -24,16 +24,34 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// BackingImages returns a BackingImageInformer.
+	BackingImages() BackingImageInformer
+	// Backups returns a BackupInformer.
+	Backups() BackupInformer
+	// BackupOperations returns a BackupOperationInformer.
+	BackupOperations() BackupOperationInformer
+	// BackupTargets returns a BackupTargetInformer.
+	BackupTargets() BackupTargetInformer
+	// BackupVolumes returns a BackupVolumeInformer.
+	BackupVolumes() BackupVolumeInformer
 	// Engines returns a EngineInformer.
 	Engines() EngineInformer
 	// EngineImages returns a EngineImageInformer.
 	EngineImages() EngineImageInformer
 	// Nodes returns a NodeInformer.
 	Nodes() NodeInformer
+	// Orphans returns a OrphanInformer.
+	Orphans() OrphanInformer
+	// RecurringJobs returns a RecurringJobInformer.
+	RecurringJobs() RecurringJobInformer
 	// Replicas returns a ReplicaInformer.
 	Replicas() ReplicaInformer
 	// Settings returns a SettingInformer.
 	Settings() SettingInformer
+	// SnapshotExports returns a SnapshotExportInformer.
+	SnapshotExports() SnapshotExportInformer
+	// SnapshotGroups returns a SnapshotGroupInformer.
+	SnapshotGroups() SnapshotGroupInformer
 	// Volumes returns a VolumeInformer.
 	Volumes() VolumeInformer
 }
@@ -49,6 +67,31 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// BackingImages returns a BackingImageInformer.
+func (v *version) BackingImages() BackingImageInformer {
+	return &backingImageInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// Backups returns a BackupInformer.
+func (v *version) Backups() BackupInformer {
+	return &backupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// BackupOperations returns a BackupOperationInformer.
+func (v *version) BackupOperations() BackupOperationInformer {
+	return &backupOperationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// BackupTargets returns a BackupTargetInformer.
+func (v *version) BackupTargets() BackupTargetInformer {
+	return &backupTargetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// BackupVolumes returns a BackupVolumeInformer.
+func (v *version) BackupVolumes() BackupVolumeInformer {
+	return &backupVolumeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Engines returns a EngineInformer.
 func (v *version) Engines() EngineInformer {
 	return &engineInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -64,6 +107,16 @@ func (v *version) Nodes() NodeInformer {
 	return &nodeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// Orphans returns a OrphanInformer.
+func (v *version) Orphans() OrphanInformer {
+	return &orphanInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// RecurringJobs returns a RecurringJobInformer.
+func (v *version) RecurringJobs() RecurringJobInformer {
+	return &recurringJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Replicas returns a ReplicaInformer.
 func (v *version) Replicas() ReplicaInformer {
 	return &replicaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -74,6 +127,16 @@ func (v *version) Settings() SettingInformer {
 	return &settingInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// SnapshotExports returns a SnapshotExportInformer.
+func (v *version) SnapshotExports() SnapshotExportInformer {
+	return &snapshotExportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// SnapshotGroups returns a SnapshotGroupInformer.
+func (v *version) SnapshotGroups() SnapshotGroupInformer {
+	return &snapshotGroupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Volumes returns a VolumeInformer.
 func (v *version) Volumes() VolumeInformer {
 	return &volumeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
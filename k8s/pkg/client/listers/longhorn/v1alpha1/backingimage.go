@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// BackingImageLister helps list BackingImages.
+type BackingImageLister interface {
+	// List lists all BackingImages in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.BackingImage, err error)
+	// BackingImages returns an object that can list and get BackingImages.
+	BackingImages(namespace string) BackingImageNamespaceLister
+	BackingImageListerExpansion
+}
+
+// backingImageLister implements the BackingImageLister interface.
+type backingImageLister struct {
+	indexer cache.Indexer
+}
+
+// NewBackingImageLister returns a new BackingImageLister.
+func NewBackingImageLister(indexer cache.Indexer) BackingImageLister {
+	return &backingImageLister{indexer: indexer}
+}
+
+// List lists all BackingImages in the indexer.
+func (s *backingImageLister) List(selector labels.Selector) (ret []*v1alpha1.BackingImage, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.BackingImage))
+	})
+	return ret, err
+}
+
+// BackingImages returns an object that can list and get BackingImages.
+func (s *backingImageLister) BackingImages(namespace string) BackingImageNamespaceLister {
+	return backingImageNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// BackingImageNamespaceLister helps list and get BackingImages.
+type BackingImageNamespaceLister interface {
+	// List lists all BackingImages in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.BackingImage, err error)
+	// Get retrieves the BackingImage from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.BackingImage, error)
+	BackingImageNamespaceListerExpansion
+}
+
+// backingImageNamespaceLister implements the BackingImageNamespaceLister
+// interface.
+type backingImageNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all BackingImages in the indexer for a given namespace.
+func (s backingImageNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.BackingImage, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.BackingImage))
+	})
+	return ret, err
+}
+
+// Get retrieves the BackingImage from the indexer for a given namespace and name.
+func (s backingImageNamespaceLister) Get(name string) (*v1alpha1.BackingImage, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("backingimage"), name)
+	}
+	return obj.(*v1alpha1.BackingImage), nil
+}
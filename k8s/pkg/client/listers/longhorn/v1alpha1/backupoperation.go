@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// BackupOperationLister helps list BackupOperations.
+type BackupOperationLister interface {
+	// List lists all BackupOperations in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.BackupOperation, err error)
+	// BackupOperations returns an object that can list and get BackupOperations.
+	BackupOperations(namespace string) BackupOperationNamespaceLister
+	BackupOperationListerExpansion
+}
+
+// backupOperationLister implements the BackupOperationLister interface.
+type backupOperationLister struct {
+	indexer cache.Indexer
+}
+
+// NewBackupOperationLister returns a new BackupOperationLister.
+func NewBackupOperationLister(indexer cache.Indexer) BackupOperationLister {
+	return &backupOperationLister{indexer: indexer}
+}
+
+// List lists all BackupOperations in the indexer.
+func (s *backupOperationLister) List(selector labels.Selector) (ret []*v1alpha1.BackupOperation, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.BackupOperation))
+	})
+	return ret, err
+}
+
+// BackupOperations returns an object that can list and get BackupOperations.
+func (s *backupOperationLister) BackupOperations(namespace string) BackupOperationNamespaceLister {
+	return backupOperationNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// BackupOperationNamespaceLister helps list and get BackupOperations.
+type BackupOperationNamespaceLister interface {
+	// List lists all BackupOperations in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.BackupOperation, err error)
+	// Get retrieves the BackupOperation from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.BackupOperation, error)
+	BackupOperationNamespaceListerExpansion
+}
+
+// backupOperationNamespaceLister implements the BackupOperationNamespaceLister
+// interface.
+type backupOperationNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all BackupOperations in the indexer for a given namespace.
+func (s backupOperationNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.BackupOperation, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.BackupOperation))
+	})
+	return ret, err
+}
+
+// Get retrieves the BackupOperation from the indexer for a given namespace and name.
+func (s backupOperationNamespaceLister) Get(name string) (*v1alpha1.BackupOperation, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("backupoperation"), name)
+	}
+	return obj.(*v1alpha1.BackupOperation), nil
+}
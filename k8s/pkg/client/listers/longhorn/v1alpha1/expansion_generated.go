@@ -18,6 +18,46 @@ limitations under the License.
 
 package v1alpha1
 
+// BackingImageListerExpansion allows custom methods to be added to
+// BackingImageLister.
+type BackingImageListerExpansion interface{}
+
+// BackingImageNamespaceListerExpansion allows custom methods to be added to
+// BackingImageNamespaceLister.
+type BackingImageNamespaceListerExpansion interface{}
+
+// BackupListerExpansion allows custom methods to be added to
+// BackupLister.
+type BackupListerExpansion interface{}
+
+// BackupNamespaceListerExpansion allows custom methods to be added to
+// BackupNamespaceLister.
+type BackupNamespaceListerExpansion interface{}
+
+// BackupOperationListerExpansion allows custom methods to be added to
+// BackupOperationLister.
+type BackupOperationListerExpansion interface{}
+
+// BackupOperationNamespaceListerExpansion allows custom methods to be added to
+// BackupOperationNamespaceLister.
+type BackupOperationNamespaceListerExpansion interface{}
+
+// BackupTargetListerExpansion allows custom methods to be added to
+// BackupTargetLister.
+type BackupTargetListerExpansion interface{}
+
+// BackupTargetNamespaceListerExpansion allows custom methods to be added to
+// BackupTargetNamespaceLister.
+type BackupTargetNamespaceListerExpansion interface{}
+
+// BackupVolumeListerExpansion allows custom methods to be added to
+// BackupVolumeLister.
+type BackupVolumeListerExpansion interface{}
+
+// BackupVolumeNamespaceListerExpansion allows custom methods to be added to
+// BackupVolumeNamespaceLister.
+type BackupVolumeNamespaceListerExpansion interface{}
+
 // EngineListerExpansion allows custom methods to be added to
 // EngineLister.
 type EngineListerExpansion interface{}
@@ -42,6 +82,22 @@ type NodeListerExpansion interface{}
 // NodeNamespaceLister.
 type NodeNamespaceListerExpansion interface{}
 
+// OrphanListerExpansion allows custom methods to be added to
+// OrphanLister.
+type OrphanListerExpansion interface{}
+
+// OrphanNamespaceListerExpansion allows custom methods to be added to
+// OrphanNamespaceLister.
+type OrphanNamespaceListerExpansion interface{}
+
+// RecurringJobListerExpansion allows custom methods to be added to
+// RecurringJobLister.
+type RecurringJobListerExpansion interface{}
+
+// RecurringJobNamespaceListerExpansion allows custom methods to be added to
+// RecurringJobNamespaceLister.
+type RecurringJobNamespaceListerExpansion interface{}
+
 // ReplicaListerExpansion allows custom methods to be added to
 // ReplicaLister.
 type ReplicaListerExpansion interface{}
@@ -58,6 +114,22 @@ type SettingListerExpansion interface{}
 // SettingNamespaceLister.
 type SettingNamespaceListerExpansion interface{}
 
+// SnapshotExportListerExpansion allows custom methods to be added to
+// SnapshotExportLister.
+type SnapshotExportListerExpansion interface{}
+
+// SnapshotExportNamespaceListerExpansion allows custom methods to be added to
+// SnapshotExportNamespaceLister.
+type SnapshotExportNamespaceListerExpansion interface{}
+
+// SnapshotGroupListerExpansion allows custom methods to be added to
+// SnapshotGroupLister.
+type SnapshotGroupListerExpansion interface{}
+
+// SnapshotGroupNamespaceListerExpansion allows custom methods to be added to
+// SnapshotGroupNamespaceLister.
+type SnapshotGroupNamespaceListerExpansion interface{}
+
 // VolumeListerExpansion allows custom methods to be added to
 // VolumeLister.
 type VolumeListerExpansion interface{}
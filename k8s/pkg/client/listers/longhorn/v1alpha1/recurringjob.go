@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// RecurringJobLister helps list RecurringJobs.
+type RecurringJobLister interface {
+	// List lists all RecurringJobs in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.RecurringJob, err error)
+	// RecurringJobs returns an object that can list and get RecurringJobs.
+	RecurringJobs(namespace string) RecurringJobNamespaceLister
+	RecurringJobListerExpansion
+}
+
+// recurringJobLister implements the RecurringJobLister interface.
+type recurringJobLister struct {
+	indexer cache.Indexer
+}
+
+// NewRecurringJobLister returns a new RecurringJobLister.
+func NewRecurringJobLister(indexer cache.Indexer) RecurringJobLister {
+	return &recurringJobLister{indexer: indexer}
+}
+
+// List lists all RecurringJobs in the indexer.
+func (s *recurringJobLister) List(selector labels.Selector) (ret []*v1alpha1.RecurringJob, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.RecurringJob))
+	})
+	return ret, err
+}
+
+// RecurringJobs returns an object that can list and get RecurringJobs.
+func (s *recurringJobLister) RecurringJobs(namespace string) RecurringJobNamespaceLister {
+	return recurringJobNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// RecurringJobNamespaceLister helps list and get RecurringJobs.
+type RecurringJobNamespaceLister interface {
+	// List lists all RecurringJobs in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.RecurringJob, err error)
+	// Get retrieves the RecurringJob from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.RecurringJob, error)
+	RecurringJobNamespaceListerExpansion
+}
+
+// recurringJobNamespaceLister implements the RecurringJobNamespaceLister
+// interface.
+type recurringJobNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all RecurringJobs in the indexer for a given namespace.
+func (s recurringJobNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.RecurringJob, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.RecurringJob))
+	})
+	return ret, err
+}
+
+// Get retrieves the RecurringJob from the indexer for a given namespace and name.
+func (s recurringJobNamespaceLister) Get(name string) (*v1alpha1.RecurringJob, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("recurringjob"), name)
+	}
+	return obj.(*v1alpha1.RecurringJob), nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SnapshotExportLister helps list SnapshotExports.
+type SnapshotExportLister interface {
+	// List lists all SnapshotExports in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.SnapshotExport, err error)
+	// SnapshotExports returns an object that can list and get SnapshotExports.
+	SnapshotExports(namespace string) SnapshotExportNamespaceLister
+	SnapshotExportListerExpansion
+}
+
+// snapshotExportLister implements the SnapshotExportLister interface.
+type snapshotExportLister struct {
+	indexer cache.Indexer
+}
+
+// NewSnapshotExportLister returns a new SnapshotExportLister.
+func NewSnapshotExportLister(indexer cache.Indexer) SnapshotExportLister {
+	return &snapshotExportLister{indexer: indexer}
+}
+
+// List lists all SnapshotExports in the indexer.
+func (s *snapshotExportLister) List(selector labels.Selector) (ret []*v1alpha1.SnapshotExport, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SnapshotExport))
+	})
+	return ret, err
+}
+
+// SnapshotExports returns an object that can list and get SnapshotExports.
+func (s *snapshotExportLister) SnapshotExports(namespace string) SnapshotExportNamespaceLister {
+	return snapshotExportNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// SnapshotExportNamespaceLister helps list and get SnapshotExports.
+type SnapshotExportNamespaceLister interface {
+	// List lists all SnapshotExports in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.SnapshotExport, err error)
+	// Get retrieves the SnapshotExport from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.SnapshotExport, error)
+	SnapshotExportNamespaceListerExpansion
+}
+
+// snapshotExportNamespaceLister implements the SnapshotExportNamespaceLister
+// interface.
+type snapshotExportNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all SnapshotExports in the indexer for a given namespace.
+func (s snapshotExportNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.SnapshotExport, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.SnapshotExport))
+	})
+	return ret, err
+}
+
+// Get retrieves the SnapshotExport from the indexer for a given namespace and name.
+func (s snapshotExportNamespaceLister) Get(name string) (*v1alpha1.SnapshotExport, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("snapshotexport"), name)
+	}
+	return obj.(*v1alpha1.SnapshotExport), nil
+}
@@ -44,6 +44,8 @@ func main() {
 	a.Commands = []cli.Command{
 		app.DaemonCmd(),
 		app.SnapshotCmd(),
+		app.TrimCmd(),
+		app.ImportCmd(),
 		app.DeployFlexvolumeDriverCmd(),
 		app.CSICommand(),
 	}
@@ -0,0 +1,72 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+func (m *VolumeManager) ListBackingImages() (map[string]*longhorn.BackingImage, error) {
+	return m.ds.ListBackingImages()
+}
+
+func (m *VolumeManager) GetBackingImage(name string) (*longhorn.BackingImage, error) {
+	return m.ds.GetBackingImage(name)
+}
+
+func (m *VolumeManager) CreateBackingImage(name, imageURL string) (*longhorn.BackingImage, error) {
+	name = strings.TrimSpace(name)
+	if !util.ValidateName(name) {
+		return nil, fmt.Errorf("invalid name %v", name)
+	}
+	imageURL = strings.TrimSpace(imageURL)
+	if _, err := types.ParseImageURL(imageURL); err != nil {
+		return nil, err
+	}
+
+	bi := &longhorn.BackingImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: types.BackingImageSpec{
+			OwnerID:  "", // the first controller who see it will pick it up
+			ImageURL: imageURL,
+		},
+	}
+	bi, err := m.ds.CreateBackingImage(bi)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Created backing image %v (%v)", bi.Name, bi.Spec.ImageURL)
+	return bi, nil
+}
+
+func (m *VolumeManager) DeleteBackingImage(name string) error {
+	bi, err := m.GetBackingImage(name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get backing image '%s'", name)
+	}
+	if bi == nil {
+		return nil
+	}
+	// the reference count in the status may lag behind the volumes
+	volumes, err := m.ds.ListVolumes()
+	if err != nil {
+		return errors.Wrapf(err, "unable to list volumes to delete backing image '%s'", name)
+	}
+	for _, v := range volumes {
+		if v.Spec.BackingImage == name {
+			return fmt.Errorf("unable to delete the backing image while being used by volume %v", v.Name)
+		}
+	}
+	return m.ds.DeleteBackingImage(name)
+}
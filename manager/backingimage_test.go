@@ -0,0 +1,49 @@
+package manager
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestBackingImageName = "parrot"
+	TestBackingImageURL  = "https://longhorn-backing-image.s3.amazonaws.com/parrot.qcow2"
+)
+
+func (s *TestSuite) TestDeleteBackingImage(c *C) {
+	env := newManagerTestEnv(c)
+
+	// the reference count hasn't been updated for the new volume yet
+	bi := &longhorn.BackingImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TestBackingImageName,
+			Namespace: TestNamespace,
+		},
+		Spec: types.BackingImageSpec{
+			ImageURL: TestBackingImageURL,
+		},
+	}
+	bi, err := env.lhClient.LonghornV1alpha1().BackingImages(TestNamespace).Create(bi)
+	c.Assert(err, IsNil)
+	err = env.biIndexer.Add(bi)
+	c.Assert(err, IsNil)
+	v := newVolume(TestVolumeName)
+	v.Spec.BackingImage = TestBackingImageName
+	env.addVolume(v, c)
+
+	err = env.m.DeleteBackingImage(TestBackingImageName)
+	c.Assert(err, ErrorMatches, ".*being used by volume "+TestVolumeName)
+
+	// deleted once the volume is gone
+	err = env.vIndexer.Delete(v)
+	c.Assert(err, IsNil)
+	err = env.m.DeleteBackingImage(TestBackingImageName)
+	c.Assert(err, IsNil)
+	_, err = env.lhClient.LonghornV1alpha1().BackingImages(TestNamespace).Get(TestBackingImageName, metav1.GetOptions{})
+	c.Assert(err, NotNil)
+}
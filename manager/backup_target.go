@@ -0,0 +1,220 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+func (m *VolumeManager) ListBackupTargets() (map[string]*longhorn.BackupTarget, error) {
+	return m.ds.ListBackupTargets()
+}
+
+func (m *VolumeManager) GetBackupTarget(name string) (*longhorn.BackupTarget, error) {
+	return m.ds.GetBackupTarget(name)
+}
+
+func (m *VolumeManager) CreateBackupTarget(name string, spec *types.BackupTargetSpec) (bt *longhorn.BackupTarget, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to create backup target %v", name)
+	}()
+
+	if err := checkBackupTargetSpec(name, spec); err != nil {
+		return nil, err
+	}
+	bt = &longhorn.BackupTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: types.BackupTargetSpec{
+			URL:              spec.URL,
+			CredentialSecret: spec.CredentialSecret,
+			PollInterval:     spec.PollInterval,
+			BandwidthLimit:   spec.BandwidthLimit,
+		},
+	}
+	bt, err = m.ds.CreateBackupTarget(bt)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Created backup target %v", bt.Name)
+	return bt, nil
+}
+
+func (m *VolumeManager) UpdateBackupTarget(name string, spec *types.BackupTargetSpec) (bt *longhorn.BackupTarget, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update backup target %v", name)
+	}()
+
+	if err := checkBackupTargetSpec(name, spec); err != nil {
+		return nil, err
+	}
+	bt, err = m.ds.GetBackupTarget(name)
+	if err != nil {
+		return nil, err
+	}
+	if bt == nil {
+		return nil, fmt.Errorf("cannot find backup target %v", name)
+	}
+	bt.Spec.URL = spec.URL
+	bt.Spec.CredentialSecret = spec.CredentialSecret
+	bt.Spec.PollInterval = spec.PollInterval
+	bt.Spec.BandwidthLimit = spec.BandwidthLimit
+	bt, err = m.ds.UpdateBackupTarget(bt)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Updated backup target %v to %+v", name, bt.Spec)
+	return bt, nil
+}
+
+// DeleteBackupTarget deletes the backup target unless a recurring job backs
+// up to it. The backups in the target are kept. The default target is
+// recreated to follow the backupTarget settings.
+func (m *VolumeManager) DeleteBackupTarget(name string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to delete backup target %v", name)
+	}()
+
+	rjs, err := m.ds.ListRecurringJobs()
+	if err != nil {
+		return err
+	}
+	for _, rj := range rjs {
+		if rj.Spec.BackupTarget == name {
+			return fmt.Errorf("backup target is used by recurring job %v", rj.Name)
+		}
+	}
+	volumes, err := m.ds.ListVolumes()
+	if err != nil {
+		return err
+	}
+	for _, v := range volumes {
+		for _, job := range v.Spec.RecurringJobs {
+			if job.BackupTarget == name {
+				return fmt.Errorf("backup target is used by job %v of volume %v", job.Name, v.Name)
+			}
+		}
+	}
+	if err := m.ds.DeleteBackupTarget(name); err != nil {
+		return err
+	}
+	logrus.Debugf("Deleted backup target %v", name)
+	return nil
+}
+
+func checkBackupTargetSpec(name string, spec *types.BackupTargetSpec) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) != 0 {
+		return fmt.Errorf("invalid backup target name %v: %v", name, errs)
+	}
+	if spec.URL == "" {
+		// follow the backupTarget settings
+		if name == types.DefaultBackupTargetName {
+			return nil
+		}
+		return fmt.Errorf("backup target URL is required")
+	}
+	// same as the backupTarget setting
+	if strings.ContainsAny(spec.URL, "$,") {
+		return fmt.Errorf("invalid backup target URL %v, contains $ or ,", spec.URL)
+	}
+	backupType, err := util.CheckBackupType(spec.URL)
+	if err != nil {
+		return err
+	}
+	if backupType == "" {
+		return fmt.Errorf("invalid backup target URL %v, the scheme is missing", spec.URL)
+	}
+	if backupType == util.BackupStoreTypeS3 && spec.CredentialSecret == "" {
+		return fmt.Errorf("credential secret is required by s3 backup target %v", spec.URL)
+	}
+	if spec.PollInterval < 0 {
+		return fmt.Errorf("invalid poll interval %v", spec.PollInterval)
+	}
+	if spec.BandwidthLimit < 0 {
+		return fmt.Errorf("invalid bandwidth limit %v", spec.BandwidthLimit)
+	}
+	return nil
+}
+
+// checkBackupTargetExists returns error if the backup target named name
+// doesn't exist, the empty name is the default target
+func (m *VolumeManager) checkBackupTargetExists(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := m.ds.GetBackupTargetSpec(name); err != nil {
+		return err
+	}
+	return nil
+}
+
+func getBackupTargetName(backupTargetName string) string {
+	if backupTargetName == "" {
+		return types.DefaultBackupTargetName
+	}
+	return backupTargetName
+}
+
+// SyncBackupTarget requests the backup target controller to refresh the
+// BackupVolumes and Backups of the target without waiting for the poll
+// interval
+func (m *VolumeManager) SyncBackupTarget(name string) (bt *longhorn.BackupTarget, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to sync backup target %v", name)
+	}()
+
+	bt, err = m.ds.GetBackupTarget(getBackupTargetName(name))
+	if err != nil {
+		return nil, err
+	}
+	if bt == nil {
+		return nil, fmt.Errorf("cannot find backup target %v", name)
+	}
+	bt.Spec.SyncRequestedAt = util.Now()
+	bt, err = m.ds.UpdateBackupTarget(bt)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Requested syncing backup target %v", bt.Name)
+	return bt, nil
+}
+
+// requestBackupTargetSync refreshes the BackupVolumes and Backups after the
+// content of the target changed
+func (m *VolumeManager) requestBackupTargetSync(backupTargetName string) {
+	if _, err := m.SyncBackupTarget(backupTargetName); err != nil {
+		logrus.Warnf("Failed to request sync: %v", err)
+	}
+}
+
+// ListBackupVolumes returns the BackupVolumes of the backup target synced by
+// the backup target controller
+func (m *VolumeManager) ListBackupVolumes(backupTargetName string) (map[string]*longhorn.BackupVolume, error) {
+	return m.ds.ListBackupVolumes(getBackupTargetName(backupTargetName))
+}
+
+func (m *VolumeManager) GetBackupVolume(backupTargetName, volumeName string) (*longhorn.BackupVolume, error) {
+	return m.ds.GetBackupVolume(types.GetBackupVolumeName(getBackupTargetName(backupTargetName), volumeName))
+}
+
+func (m *VolumeManager) ListBackupsForVolume(backupTargetName, volumeName string) (map[string]*longhorn.Backup, error) {
+	if volumeName == "" {
+		return nil, fmt.Errorf("volume name required")
+	}
+	return m.ds.ListBackups(getBackupTargetName(backupTargetName), volumeName)
+}
+
+func (m *VolumeManager) GetBackup(backupTargetName, backupName, volumeName string) (*longhorn.Backup, error) {
+	return m.ds.GetBackup(types.GetBackupName(getBackupTargetName(backupTargetName), volumeName, backupName))
+}
@@ -0,0 +1,229 @@
+package manager
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestBackupTargetName  = "offsite"
+	TestBackupTargetURL2  = "nfs://longhorn-test-nfs-svc.default:/opt/backupstore"
+	TestCredentialSecret  = "aws-secret"
+	TestSettingTargetURL  = "s3://settingbucket@us-east-1/"
+	TestSettingCredential = "setting-secret"
+)
+
+func (env *managerTestEnv) addBackupTarget(name string, spec types.BackupTargetSpec, c *C) {
+	bt := &longhorn.BackupTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: TestNamespace,
+		},
+		Spec: spec,
+	}
+	bt, err := env.lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Create(bt)
+	c.Assert(err, IsNil)
+	err = env.btIndexer.Add(bt)
+	c.Assert(err, IsNil)
+}
+
+func (env *managerTestEnv) setBackupTargetSetting(url, credentialSecret string, c *C) {
+	setting, err := env.m.ds.GetSetting()
+	c.Assert(err, IsNil)
+	setting.BackupTarget = url
+	setting.BackupTargetCredentialSecret = credentialSecret
+	_, err = env.m.ds.UpdateSetting(setting)
+	c.Assert(err, IsNil)
+}
+
+func (s *TestSuite) TestGetBackupTargetSpec(c *C) {
+	env := newManagerTestEnv(c)
+
+	// the default target follows the settings if there is no BackupTarget
+	spec, err := env.m.ds.GetBackupTargetSpec("")
+	c.Assert(err, IsNil)
+	c.Assert(spec.URL, Equals, "")
+	env.setBackupTargetSetting(TestSettingTargetURL, TestSettingCredential, c)
+	spec, err = env.m.ds.GetBackupTargetSpec("")
+	c.Assert(err, IsNil)
+	c.Assert(spec.URL, Equals, TestSettingTargetURL)
+	c.Assert(spec.CredentialSecret, Equals, TestSettingCredential)
+
+	// or the default BackupTarget without URL, keeping its poll interval
+	env.addBackupTarget(types.DefaultBackupTargetName, types.BackupTargetSpec{PollInterval: 60}, c)
+	spec, err = env.m.ds.GetBackupTargetSpec(types.DefaultBackupTargetName)
+	c.Assert(err, IsNil)
+	c.Assert(spec.URL, Equals, TestSettingTargetURL)
+	c.Assert(spec.CredentialSecret, Equals, TestSettingCredential)
+	c.Assert(spec.PollInterval, Equals, 60)
+
+	// the other targets don't fall back to the settings
+	_, err = env.m.ds.GetBackupTargetSpec(TestBackupTargetName)
+	c.Assert(err, ErrorMatches, ".*cannot find backup target "+TestBackupTargetName+".*")
+	env.addBackupTarget(TestBackupTargetName, types.BackupTargetSpec{URL: TestBackupTargetURL2}, c)
+	spec, err = env.m.ds.GetBackupTargetSpec(TestBackupTargetName)
+	c.Assert(err, IsNil)
+	c.Assert(spec.URL, Equals, TestBackupTargetURL2)
+	c.Assert(spec.CredentialSecret, Equals, "")
+}
+
+func (s *TestSuite) TestGetBackupTargetSpecForURL(c *C) {
+	env := newManagerTestEnv(c)
+	env.setBackupTargetSetting(TestSettingTargetURL, TestSettingCredential, c)
+	env.addBackupTarget(TestBackupTargetName, types.BackupTargetSpec{
+		URL:              TestBackupTargetURL + "/",
+		CredentialSecret: TestCredentialSecret,
+	}, c)
+
+	testCases := map[string]struct {
+		url              string
+		expectURL        string
+		expectCredential string
+	}{
+		"backup": {
+			TestBackupTargetURL + "?backup=backup-1&volume=vol-a",
+			TestBackupTargetURL + "/",
+			TestCredentialSecret,
+		},
+		"backup volume": {
+			TestBackupTargetURL + "/?volume=vol-a",
+			TestBackupTargetURL + "/",
+			TestCredentialSecret,
+		},
+		"unknown target": {
+			"s3://otherbucket@us-east-1/?volume=vol-a",
+			TestSettingTargetURL,
+			TestSettingCredential,
+		},
+	}
+	for name, tc := range testCases {
+		c.Logf("testing %v", name)
+		spec, err := env.m.ds.GetBackupTargetSpecForURL(tc.url)
+		c.Assert(err, IsNil)
+		c.Assert(spec.URL, Equals, tc.expectURL)
+		c.Assert(spec.CredentialSecret, Equals, tc.expectCredential)
+	}
+}
+
+func (s *TestSuite) TestDeleteBackupTargetInUse(c *C) {
+	env := newManagerTestEnv(c)
+	env.addBackupTarget(TestBackupTargetName, types.BackupTargetSpec{URL: TestBackupTargetURL2}, c)
+
+	rj := &longhorn.RecurringJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-daily",
+			Namespace: TestNamespace,
+		},
+		Spec: types.RecurringJobSpec{
+			Task:         types.RecurringJobTypeBackup,
+			Cron:         "0 0 * * *",
+			Retain:       7,
+			BackupTarget: TestBackupTargetName,
+		},
+	}
+	err := env.rjIndexer.Add(rj)
+	c.Assert(err, IsNil)
+	err = env.m.DeleteBackupTarget(TestBackupTargetName)
+	c.Assert(err, ErrorMatches, ".*backup target is used by recurring job backup-daily.*")
+	err = env.rjIndexer.Delete(rj)
+	c.Assert(err, IsNil)
+
+	v := newVolume(TestVolumeName)
+	v.Spec.RecurringJobs = []types.RecurringJob{
+		{
+			Name:         "backup-hourly",
+			Type:         types.RecurringJobTypeBackup,
+			Cron:         "0 * * * *",
+			Retain:       24,
+			BackupTarget: TestBackupTargetName,
+		},
+	}
+	env.addVolume(v, c)
+	err = env.m.DeleteBackupTarget(TestBackupTargetName)
+	c.Assert(err, ErrorMatches, ".*backup target is used by job backup-hourly of volume "+TestVolumeName+".*")
+
+	// the target is kept
+	_, err = env.lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Get(TestBackupTargetName, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+
+	v.Spec.RecurringJobs = nil
+	err = env.vIndexer.Update(v)
+	c.Assert(err, IsNil)
+	err = env.m.DeleteBackupTarget(TestBackupTargetName)
+	c.Assert(err, IsNil)
+	_, err = env.lhClient.LonghornV1alpha1().BackupTargets(TestNamespace).Get(TestBackupTargetName, metav1.GetOptions{})
+	c.Assert(err, NotNil)
+}
+
+func (s *TestSuite) TestCheckBackupTargetSpec(c *C) {
+	testCases := map[string]struct {
+		name      string
+		spec      types.BackupTargetSpec
+		expectErr string
+	}{
+		"nfs": {
+			TestBackupTargetName,
+			types.BackupTargetSpec{URL: TestBackupTargetURL2, PollInterval: 300},
+			"",
+		},
+		"s3": {
+			TestBackupTargetName,
+			types.BackupTargetSpec{URL: TestBackupTargetURL, CredentialSecret: TestCredentialSecret},
+			"",
+		},
+		"default following the settings": {
+			types.DefaultBackupTargetName,
+			types.BackupTargetSpec{},
+			"",
+		},
+		"invalid name": {
+			"Offsite_1",
+			types.BackupTargetSpec{URL: TestBackupTargetURL2},
+			"invalid backup target name.*",
+		},
+		"missing URL": {
+			TestBackupTargetName,
+			types.BackupTargetSpec{},
+			"backup target URL is required",
+		},
+		"multiple URLs": {
+			TestBackupTargetName,
+			types.BackupTargetSpec{URL: TestBackupTargetURL2 + "," + TestBackupTargetURL},
+			"invalid backup target URL .*, contains \\$ or ,",
+		},
+		"missing scheme": {
+			TestBackupTargetName,
+			types.BackupTargetSpec{URL: "/opt/backupstore"},
+			"invalid backup target URL .*, the scheme is missing",
+		},
+		"s3 without credential": {
+			TestBackupTargetName,
+			types.BackupTargetSpec{URL: TestBackupTargetURL},
+			"credential secret is required .*",
+		},
+		"negative poll interval": {
+			TestBackupTargetName,
+			types.BackupTargetSpec{URL: TestBackupTargetURL2, PollInterval: -1},
+			"invalid poll interval -1",
+		},
+		"negative bandwidth limit": {
+			TestBackupTargetName,
+			types.BackupTargetSpec{URL: TestBackupTargetURL2, BandwidthLimit: -1},
+			"invalid bandwidth limit -1",
+		},
+	}
+	for name, tc := range testCases {
+		c.Logf("testing %v", name)
+		err := checkBackupTargetSpec(tc.name, &tc.spec)
+		if tc.expectErr == "" {
+			c.Assert(err, IsNil)
+		} else {
+			c.Assert(err, ErrorMatches, tc.expectErr)
+		}
+	}
+}
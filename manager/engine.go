@@ -3,12 +3,25 @@ package manager
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/pkg/errors"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rancher/longhorn-manager/datastore"
 	"github.com/rancher/longhorn-manager/engineapi"
 	"github.com/rancher/longhorn-manager/types"
 	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+var (
+	backupOperationWaitInterval = 2 * time.Second
 )
 
 func (m *VolumeManager) ListSnapshots(volumeName string) (map[string]*engineapi.Snapshot, error) {
@@ -40,7 +53,10 @@ func (m *VolumeManager) GetSnapshot(snapshotName, volumeName string) (*engineapi
 	return snapshot, nil
 }
 
-func (m *VolumeManager) CreateSnapshot(snapshotName string, labels map[string]string, volumeName string) (*engineapi.Snapshot, error) {
+// CreateSnapshot creates the snapshot of the volume. If freeze is set, the
+// filesystem on the volume is frozen while taking the snapshot, which must
+// run on the node the volume is attached to.
+func (m *VolumeManager) CreateSnapshot(snapshotName string, labels map[string]string, freeze bool, volumeName string) (*engineapi.Snapshot, error) {
 	if volumeName == "" {
 		return nil, fmt.Errorf("volume name required")
 	}
@@ -55,7 +71,41 @@ func (m *VolumeManager) CreateSnapshot(snapshotName string, labels map[string]st
 	if err != nil {
 		return nil, err
 	}
-	snapshotName, err = engine.SnapshotCreate(snapshotName, labels)
+	v, err := m.ds.GetVolume(volumeName)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", volumeName)
+	}
+	if freeze && v.Spec.NodeID != m.currentNodeID {
+		return nil, fmt.Errorf("cannot freeze filesystem of volume %v attached to %v on %v", volumeName, v.Spec.NodeID, m.currentNodeID)
+	}
+	create := func() (err error) {
+		if freeze {
+			device := ""
+			if v.Spec.Frontend == types.VolumeFrontendBlockDev && !v.Spec.DisableFrontend {
+				device = v.Status.Endpoint
+			}
+			snapshotName, err = engineapi.SnapshotCreateWithFreeze(engine, device, snapshotName, labels)
+		} else {
+			snapshotName, err = engine.SnapshotCreate(snapshotName, labels)
+		}
+		return err
+	}
+	if v.Spec.SnapshotHooks.IsEmpty() {
+		err = create()
+	} else {
+		var hookRunner *engineapi.SnapshotHookRunner
+		if hookRunner, err = engineapi.NewSnapshotHookRunner(volumeName); err != nil {
+			return nil, err
+		}
+		var failures []string
+		failures, err = hookRunner.Run(v.Spec.SnapshotHooks, create)
+		if len(failures) != 0 {
+			m.recordVolumeEvent(v, corev1.EventTypeWarning, types.EventReasonFailedSnapshotHook, strings.Join(failures, "; "))
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +128,11 @@ func (m *VolumeManager) DeleteSnapshot(snapshotName, volumeName string) error {
 	if err != nil {
 		return err
 	}
-	return engine.SnapshotDelete(snapshotName)
+	if err := engine.SnapshotDelete(snapshotName); err != nil {
+		return err
+	}
+	m.deleteSnapshotExports(volumeName, []string{snapshotName})
+	return nil
 }
 
 func (m *VolumeManager) RevertSnapshot(snapshotName, volumeName string) error {
@@ -112,132 +166,359 @@ func (m *VolumeManager) PurgeSnapshot(volumeName string) error {
 	if err != nil {
 		return err
 	}
+	snapshots, err := engine.SnapshotList()
+	if err != nil {
+		return err
+	}
+	removed := []string{}
+	for _, snap := range snapshots {
+		if snap.Removed {
+			removed = append(removed, snap.Name)
+		}
+	}
 	//TODO time consuming operation, move it out of API server path
-	return engine.SnapshotPurge()
+	if err := engine.SnapshotPurge(); err != nil {
+		return err
+	}
+	m.deleteSnapshotExports(volumeName, removed)
+	return nil
 }
 
-func (m *VolumeManager) BackupSnapshot(snapshotName string, labels map[string]string, volumeName string) error {
-	if volumeName == "" || snapshotName == "" {
-		return fmt.Errorf("volume and snapshot name required")
+// TrimVolume discards the unused blocks of the filesystem on the volume, then
+// reclaims the unmapped blocks in the volume head. The result is recorded as
+// an event of the volume
+func (m *VolumeManager) TrimVolume(volumeName string) (err error) {
+	if volumeName == "" {
+		return fmt.Errorf("volume name required")
 	}
 
-	backupTarget, err := m.getBackupTargetURL()
+	v, err := m.ds.GetVolume(volumeName)
 	if err != nil {
 		return err
 	}
-	credential, err := m.getBackupCredentialConfig()
-	if err != nil {
-		return err
+	if v == nil {
+		return fmt.Errorf("cannot find volume %v", volumeName)
+	}
+	if v.Status.State != types.VolumeStateAttached {
+		return fmt.Errorf("invalid state to trim %v: %v", volumeName, v.Status.State)
 	}
 	engine, err := m.GetEngineClient(volumeName)
 	if err != nil {
 		return err
 	}
 	//TODO time consuming operation, move it out of API server path
-	return engine.SnapshotBackup(snapshotName, backupTarget, nil, credential)
+	return engineapi.TrimVolume(engine, v, func(eventType, reason, message string) {
+		m.recordVolumeEvent(v, eventType, reason, message)
+	})
 }
 
-func (m *VolumeManager) GetEngineClient(volumeName string) (client engineapi.EngineClient, err error) {
+func (m *VolumeManager) recordVolumeEvent(v *longhorn.Volume, eventType, reason, message string) {
+	if _, err := m.ds.CreateEvent(engineapi.NewVolumeEvent(v, eventType, reason, message, "longhorn-manager")); err != nil {
+		logrus.Warnf("Failed to record event %v for volume %v: %v", reason, v.Name, err)
+	}
+}
+
+// CreateBackupOperation requests the manager owning the volume to back up
+// the snapshot to the backup target named backupTargetName, or the default
+// target if it's empty. The progress is reported in the BackupOperation.
+func (m *VolumeManager) CreateBackupOperation(snapshotName string, labels map[string]string, volumeName, backupTargetName string) (bo *longhorn.BackupOperation, err error) {
 	defer func() {
-		err = errors.Wrapf(err, "cannot get client for volume %v", volumeName)
+		err = errors.Wrapf(err, "unable to back up snapshot %v of volume %v", snapshotName, volumeName)
 	}()
-	e, err := m.ds.GetVolumeEngine(volumeName)
+
+	if volumeName == "" || snapshotName == "" {
+		return nil, fmt.Errorf("volume and snapshot name required")
+	}
+	v, err := m.ds.GetVolume(volumeName)
 	if err != nil {
 		return nil, err
 	}
-	if e == nil {
-		return nil, fmt.Errorf("cannot get engine for %v", volumeName)
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", volumeName)
 	}
-	if e.Status.CurrentState != types.InstanceStateRunning {
-		return nil, fmt.Errorf("engine is not running")
+	backupTargetName = getBackupTargetName(backupTargetName)
+	if _, err := m.getBackupTargetSpec(backupTargetName); err != nil {
+		return nil, err
 	}
-	engineCollection := &engineapi.EngineCollection{}
-	return engineCollection.NewEngineClient(&engineapi.EngineClientRequest{
-		VolumeName:        e.Spec.VolumeName,
-		EngineImage:       e.Status.CurrentImage,
-		ControllerURL:     engineapi.GetControllerDefaultURL(e.Status.IP),
-		EngineLauncherURL: engineapi.GetEngineLauncherDefaultURL(e.Status.IP),
+
+	bo, err = m.ds.CreateBackupOperation(&longhorn.BackupOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            types.GetBackupOperationName(volumeName),
+			OwnerReferences: datastore.GetOwnerReferencesForVolume(v),
+		},
+		Spec: types.BackupOperationSpec{
+			VolumeName:   volumeName,
+			SnapshotName: snapshotName,
+			BackupTarget: backupTargetName,
+			Labels:       labels,
+		},
+		Status: types.BackupOperationStatus{
+			State: types.BackupOperationStatePending,
+		},
 	})
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Created backup operation %v for volume %v snapshot %v", bo.Name, volumeName, snapshotName)
+	return bo, nil
 }
 
-func (m *VolumeManager) getBackupTarget() (*engineapi.BackupTarget, error) {
-	targetURL, err := m.getBackupTargetURL()
+// BackupSnapshot backs up the snapshot and waits for the backup to finish
+func (m *VolumeManager) BackupSnapshot(snapshotName string, labels map[string]string, volumeName, backupTargetName string) error {
+	bo, err := m.CreateBackupOperation(snapshotName, labels, volumeName, backupTargetName)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	engineImage, err := m.GetDefaultEngineImage()
+	return m.waitForBackupOperation(bo.Name)
+}
+
+func (m *VolumeManager) waitForBackupOperation(name string) error {
+	seen := false
+	deadline := time.Now().Add(types.BackupOperationWaitTimeout)
+	for {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for backup operation %v", name)
+		}
+		bo, err := m.ds.GetBackupOperation(name)
+		if err != nil {
+			return err
+		}
+		if bo == nil {
+			if seen {
+				return fmt.Errorf("backup operation %v has been deleted", name)
+			}
+			// not in the cache yet
+		} else {
+			seen = true
+			switch bo.Status.State {
+			case types.BackupOperationStateCompleted:
+				return nil
+			case types.BackupOperationStateError:
+				return fmt.Errorf("backup operation %v failed: %v", name, bo.Status.Error)
+			case types.BackupOperationStateCancelled:
+				return fmt.Errorf("backup operation %v was cancelled", name)
+			}
+		}
+		time.Sleep(backupOperationWaitInterval)
+	}
+}
+
+func (m *VolumeManager) GetBackupOperation(name string) (*longhorn.BackupOperation, error) {
+	return m.ds.GetBackupOperation(name)
+}
+
+// ListBackupOperations returns the BackupOperations of the volume, or all of
+// them if volumeName is empty
+func (m *VolumeManager) ListBackupOperations(volumeName string) (map[string]*longhorn.BackupOperation, error) {
+	return m.ds.ListBackupOperations(volumeName)
+}
+
+// CancelBackupOperation requests the manager running the backup to stop it
+func (m *VolumeManager) CancelBackupOperation(name string) (bo *longhorn.BackupOperation, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to cancel backup operation %v", name)
+	}()
+
+	bo, err = m.ds.GetBackupOperation(name)
 	if err != nil {
 		return nil, err
 	}
-	credential, err := m.getBackupCredentialConfig()
+	if bo == nil {
+		return nil, fmt.Errorf("cannot find backup operation %v", name)
+	}
+	if bo.Status.IsFinished() {
+		return nil, fmt.Errorf("backup operation is already %v", bo.Status.State)
+	}
+	if bo.Spec.Cancel {
+		return bo, nil
+	}
+	if bo.Status.State == types.BackupOperationStateInProgress {
+		v, err := m.ds.GetVolume(bo.Spec.VolumeName)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, fmt.Errorf("cannot find volume %v", bo.Spec.VolumeName)
+		}
+		// the backup running in the older engine cannot be stopped
+		if err := engineapi.CheckEngineFeature(v.Status.CurrentImage, "backup cancel"); err != nil {
+			return nil, err
+		}
+	}
+	bo.Spec.Cancel = true
+	bo, err = m.ds.UpdateBackupOperation(bo)
 	if err != nil {
 		return nil, err
 	}
-	return engineapi.NewBackupTarget(targetURL, engineImage, credential), nil
+	logrus.Debugf("Requested cancelling backup operation %v", name)
+	return bo, nil
 }
 
-func (m *VolumeManager) getBackupCredentialConfig() (map[string]string, error) {
-	settings, err := m.ds.GetSetting()
-	if err != nil || settings == nil {
-		return nil, errors.New("cannot backup: unable to read settings")
+// CreateSnapshotExport requests the manager on the current node to export
+// the snapshot into an image file, which is served by the same manager. The
+// existing export of the snapshot is returned instead, so an interrupted
+// download can be resumed without exporting the snapshot again.
+func (m *VolumeManager) CreateSnapshotExport(snapshotName, volumeName string) (se *longhorn.SnapshotExport, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to export snapshot %v of volume %v", snapshotName, volumeName)
+	}()
+
+	if volumeName == "" || snapshotName == "" {
+		return nil, fmt.Errorf("volume and snapshot name required")
 	}
-	backupType, err := util.CheckBackupType(settings.BackupTarget)
+	v, err := m.ds.GetVolume(volumeName)
 	if err != nil {
 		return nil, err
 	}
-	if backupType == util.BackupStoreTypeS3 {
-		secretName := settings.BackupTargetCredentialSecret
-		if secretName == "" {
-			return nil, errors.New("Could not backup for s3 without credential secret")
-		}
-		return m.ds.GetCredentialFromSecret(secretName)
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", volumeName)
 	}
-	return nil, nil
-}
 
-func (m *VolumeManager) ListBackupVolumes() ([]*engineapi.BackupVolume, error) {
-	backupTarget, err := m.getBackupTarget()
+	name := types.GetSnapshotExportName(volumeName, snapshotName)
+	se, err = m.ds.GetSnapshotExport(name)
 	if err != nil {
 		return nil, err
 	}
+	if se != nil && se.DeletionTimestamp == nil {
+		return se, nil
+	}
+	if se != nil {
+		return nil, fmt.Errorf("previous export %v is being deleted", name)
+	}
 
-	return backupTarget.ListVolumes()
-}
+	engine, err := m.GetEngineClient(volumeName)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := engine.SnapshotGet(snapshotName)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil || snap.Removed {
+		return nil, fmt.Errorf("cannot find snapshot '%s' for volume '%s'", snapshotName, volumeName)
+	}
 
-func (m *VolumeManager) GetBackupVolume(volumeName string) (*engineapi.BackupVolume, error) {
-	backupTarget, err := m.getBackupTarget()
+	se, err = m.ds.CreateSnapshotExport(&longhorn.SnapshotExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			OwnerReferences: datastore.GetOwnerReferencesForVolume(v),
+		},
+		Spec: types.SnapshotExportSpec{
+			VolumeName:   volumeName,
+			SnapshotName: snapshotName,
+			NodeID:       m.currentNodeID,
+		},
+		Status: types.SnapshotExportStatus{
+			State: types.SnapshotExportStatePending,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
+	logrus.Debugf("Created snapshot export %v for volume %v snapshot %v on %v", se.Name, volumeName, snapshotName, m.currentNodeID)
+	return se, nil
+}
+
+func (m *VolumeManager) GetSnapshotExport(name string) (*longhorn.SnapshotExport, error) {
+	return m.ds.GetSnapshotExport(name)
+}
+
+// ListSnapshotExports returns the SnapshotExports of the volume, or all of
+// them if volumeName is empty
+func (m *VolumeManager) ListSnapshotExports(volumeName string) (map[string]*longhorn.SnapshotExport, error) {
+	return m.ds.ListSnapshotExports(volumeName)
+}
+
+func (m *VolumeManager) DeleteSnapshotExport(name string) error {
+	if err := m.ds.DeleteSnapshotExport(name); err != nil {
+		return err
+	}
+	logrus.Debugf("Deleted snapshot export %v", name)
+	return nil
+}
 
-	return backupTarget.GetVolume(volumeName)
+// deleteSnapshotExports deletes the exports of the snapshots, the exported
+// images are no longer needed once the snapshots are gone
+func (m *VolumeManager) deleteSnapshotExports(volumeName string, snapshotNames []string) {
+	for _, snapshotName := range snapshotNames {
+		name := types.GetSnapshotExportName(volumeName, snapshotName)
+		if err := m.ds.DeleteSnapshotExport(name); err != nil && !apierrors.IsNotFound(err) {
+			logrus.Warnf("Failed to delete snapshot export %v of volume %v snapshot %v: %v", name, volumeName, snapshotName, err)
+		}
+	}
 }
 
-func (m *VolumeManager) ListBackupsForVolume(volumeName string) ([]*engineapi.Backup, error) {
-	backupTarget, err := m.getBackupTarget()
+func (m *VolumeManager) GetEngineClient(volumeName string) (client engineapi.EngineClient, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "cannot get client for volume %v", volumeName)
+	}()
+	e, err := m.ds.GetVolumeEngine(volumeName)
 	if err != nil {
 		return nil, err
 	}
+	if e == nil {
+		return nil, fmt.Errorf("cannot get engine for %v", volumeName)
+	}
+	if e.Status.CurrentState != types.InstanceStateRunning {
+		return nil, fmt.Errorf("engine is not running")
+	}
+	return m.engines.NewEngineClient(&engineapi.EngineClientRequest{
+		VolumeName:        e.Spec.VolumeName,
+		EngineImage:       e.Status.CurrentImage,
+		ControllerURL:     engineapi.GetControllerDefaultURL(e.Status.IP),
+		EngineLauncherURL: engineapi.GetEngineLauncherDefaultURL(e.Status.IP),
+	})
+}
 
-	return backupTarget.List(volumeName)
+func (m *VolumeManager) getBackupTarget(backupTargetName string) (*engineapi.BackupTarget, error) {
+	spec, err := m.getBackupTargetSpec(backupTargetName)
+	if err != nil {
+		return nil, err
+	}
+	return m.newBackupTarget(spec)
 }
 
-func (m *VolumeManager) GetBackup(backupName, volumeName string) (*engineapi.Backup, error) {
-	backupTarget, err := m.getBackupTarget()
+func (m *VolumeManager) newBackupTarget(spec *types.BackupTargetSpec) (*engineapi.BackupTarget, error) {
+	engineImage, err := m.GetDefaultEngineImage()
 	if err != nil {
 		return nil, err
 	}
+	credential, err := m.getBackupCredentialConfig(spec)
+	if err != nil {
+		return nil, err
+	}
+	return engineapi.NewBackupTarget(spec.URL, engineImage, credential), nil
+}
 
-	url := engineapi.GetBackupURL(backupTarget.URL, backupName, volumeName)
-	return backupTarget.GetBackup(url)
+func (m *VolumeManager) getBackupCredentialConfig(spec *types.BackupTargetSpec) (map[string]string, error) {
+	backupType, err := util.CheckBackupType(spec.URL)
+	if err != nil {
+		return nil, err
+	}
+	if backupType == util.BackupStoreTypeS3 {
+		secretName := spec.CredentialSecret
+		if secretName == "" {
+			return nil, errors.New("Could not backup for s3 without credential secret")
+		}
+		return m.ds.GetCredentialFromSecret(secretName)
+	}
+	return nil, nil
 }
 
-func (m *VolumeManager) DeleteBackup(backupName, volumeName string) error {
-	backupTarget, err := m.getBackupTarget()
+func (m *VolumeManager) DeleteBackup(backupTargetName, backupName, volumeName string) error {
+	backupTarget, err := m.getBackupTarget(backupTargetName)
 	if err != nil {
 		return err
 	}
 
 	url := engineapi.GetBackupURL(backupTarget.URL, backupName, volumeName)
-	return backupTarget.DeleteBackup(url)
+	if err := backupTarget.DeleteBackup(url); err != nil {
+		return err
+	}
+	name := types.GetBackupName(getBackupTargetName(backupTargetName), volumeName, backupName)
+	if err := m.ds.DeleteBackup(name); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	m.requestBackupTargetSync(backupTargetName)
+	return nil
 }
@@ -0,0 +1,212 @@
+package manager
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+	lhfake "github.com/rancher/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+	lhinformerfactory "github.com/rancher/longhorn-manager/k8s/pkg/client/informers/externalversions"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	TestNamespace       = "default"
+	TestNode1           = "test-node-name-1"
+	TestNode2           = "test-node-name-2"
+	TestEngineImage     = "longhorn-engine:latest"
+	TestBackupTargetURL = "s3://backupbucket@us-east-1/backupstore"
+
+	TestVolumeName = "test-volume"
+	TestVolumeSize = 1073741824
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type TestSuite struct {
+}
+
+var _ = Suite(&TestSuite{})
+
+func (s *TestSuite) SetUpTest(c *C) {
+}
+
+// fakeEngine keeps the snapshots of the volume in memory
+type fakeEngine struct {
+	engineapi.EngineClient
+
+	snapshots map[string]*engineapi.Snapshot
+	createErr error
+}
+
+func (e *fakeEngine) SnapshotCreate(name string, labels map[string]string) (string, error) {
+	if e.createErr != nil {
+		return "", e.createErr
+	}
+	e.snapshots[name] = &engineapi.Snapshot{Name: name, Labels: labels}
+	return name, nil
+}
+
+func (e *fakeEngine) SnapshotGet(name string) (*engineapi.Snapshot, error) {
+	return e.snapshots[name], nil
+}
+
+func (e *fakeEngine) SnapshotDelete(name string) error {
+	if e.snapshots[name] == nil {
+		return fmt.Errorf("cannot find snapshot %v", name)
+	}
+	delete(e.snapshots, name)
+	return nil
+}
+
+// fakeEngineCollection returns the engine of the volume
+type fakeEngineCollection struct {
+	engines map[string]*fakeEngine
+}
+
+func (c *fakeEngineCollection) NewEngineClient(request *engineapi.EngineClientRequest) (engineapi.EngineClient, error) {
+	engine := c.engines[request.VolumeName]
+	if engine == nil {
+		return nil, fmt.Errorf("cannot find engine of volume %v", request.VolumeName)
+	}
+	return engine, nil
+}
+
+type managerTestEnv struct {
+	m        *VolumeManager
+	engines  *fakeEngineCollection
+	lhClient *lhfake.Clientset
+
+	vIndexer  cache.Indexer
+	eIndexer  cache.Indexer
+	sgIndexer cache.Indexer
+	btIndexer cache.Indexer
+	bIndexer  cache.Indexer
+	rjIndexer cache.Indexer
+	biIndexer cache.Indexer
+}
+
+func newManagerTestEnv(c *C) *managerTestEnv {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, controller.NoResyncPeriodFunc())
+	lhClient := lhfake.NewSimpleClientset()
+	lhInformerFactory := lhinformerfactory.NewSharedInformerFactory(lhClient, controller.NoResyncPeriodFunc())
+
+	volumeInformer := lhInformerFactory.Longhorn().V1alpha1().Volumes()
+	engineInformer := lhInformerFactory.Longhorn().V1alpha1().Engines()
+	replicaInformer := lhInformerFactory.Longhorn().V1alpha1().Replicas()
+	engineImageInformer := lhInformerFactory.Longhorn().V1alpha1().EngineImages()
+	nodeInformer := lhInformerFactory.Longhorn().V1alpha1().Nodes()
+	backingImageInformer := lhInformerFactory.Longhorn().V1alpha1().BackingImages()
+	orphanInformer := lhInformerFactory.Longhorn().V1alpha1().Orphans()
+	recurringJobInformer := lhInformerFactory.Longhorn().V1alpha1().RecurringJobs()
+	snapshotGroupInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotGroups()
+	backupTargetInformer := lhInformerFactory.Longhorn().V1alpha1().BackupTargets()
+	backupVolumeInformer := lhInformerFactory.Longhorn().V1alpha1().BackupVolumes()
+	backupInformer := lhInformerFactory.Longhorn().V1alpha1().Backups()
+	backupOperationInformer := lhInformerFactory.Longhorn().V1alpha1().BackupOperations()
+	snapshotExportInformer := lhInformerFactory.Longhorn().V1alpha1().SnapshotExports()
+
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+	cronJobInformer := kubeInformerFactory.Batch().V1beta1().CronJobs()
+	daemonSetInformer := kubeInformerFactory.Apps().V1beta2().DaemonSets()
+	eventInformer := kubeInformerFactory.Core().V1().Events()
+
+	ds := datastore.NewDataStore(volumeInformer, engineInformer, replicaInformer, engineImageInformer, lhClient,
+		podInformer, cronJobInformer, daemonSetInformer, kubeClient, TestNamespace, nodeInformer, backingImageInformer, orphanInformer, eventInformer, recurringJobInformer, snapshotGroupInformer, backupTargetInformer, backupVolumeInformer, backupInformer, backupOperationInformer, snapshotExportInformer)
+	_, err := ds.CreateSetting(&longhorn.Setting{
+		SettingsInfo: types.SettingsInfo{
+			DefaultEngineImage: TestEngineImage,
+		},
+	})
+	c.Assert(err, IsNil)
+
+	engines := &fakeEngineCollection{
+		engines: map[string]*fakeEngine{},
+	}
+	m := NewVolumeManager(TestNode1, ds)
+	m.engines = engines
+
+	return &managerTestEnv{
+		m:        m,
+		engines:  engines,
+		lhClient: lhClient,
+
+		vIndexer:  volumeInformer.Informer().GetIndexer(),
+		eIndexer:  engineInformer.Informer().GetIndexer(),
+		sgIndexer: snapshotGroupInformer.Informer().GetIndexer(),
+		btIndexer: backupTargetInformer.Informer().GetIndexer(),
+		bIndexer:  backupInformer.Informer().GetIndexer(),
+		rjIndexer: recurringJobInformer.Informer().GetIndexer(),
+		biIndexer: backingImageInformer.Informer().GetIndexer(),
+	}
+}
+
+// addVolume adds the volume with the running engine
+func (env *managerTestEnv) addVolume(v *longhorn.Volume, c *C) *fakeEngine {
+	if v.Namespace == "" {
+		v.Namespace = TestNamespace
+	}
+	if v.Spec.Size == 0 {
+		v.Spec.Size = TestVolumeSize
+	}
+	v, err := env.lhClient.LonghornV1alpha1().Volumes(TestNamespace).Create(v)
+	c.Assert(err, IsNil)
+	err = env.vIndexer.Add(v)
+	c.Assert(err, IsNil)
+
+	e := &longhorn.Engine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      v.Name + "-e",
+			Namespace: TestNamespace,
+			Labels: map[string]string{
+				"longhornvolume": v.Name,
+			},
+		},
+		Spec: types.EngineSpec{
+			InstanceSpec: types.InstanceSpec{
+				VolumeName:  v.Name,
+				EngineImage: TestEngineImage,
+			},
+		},
+		Status: types.EngineStatus{
+			InstanceStatus: types.InstanceStatus{
+				CurrentState: types.InstanceStateRunning,
+				CurrentImage: TestEngineImage,
+				IP:           "1.2.3.4",
+			},
+		},
+	}
+	err = env.eIndexer.Add(e)
+	c.Assert(err, IsNil)
+
+	engine := &fakeEngine{
+		snapshots: map[string]*engineapi.Snapshot{},
+	}
+	env.engines.engines[v.Name] = engine
+	return engine
+}
+
+func newVolume(name string) *longhorn.Volume {
+	return &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: types.VolumeSpec{
+			NumberOfReplicas: 3,
+			Frontend:         types.VolumeFrontendBlockDev,
+			EngineImage:      TestEngineImage,
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+func (m *VolumeManager) ListOrphans() (map[string]*longhorn.Orphan, error) {
+	return m.ds.ListOrphans()
+}
+
+func (m *VolumeManager) GetOrphan(name string) (*longhorn.Orphan, error) {
+	return m.ds.GetOrphan(name)
+}
+
+// DeleteOrphan deletes the orphan record, the data would be cleaned up by
+// the manager on the node of the orphan
+func (m *VolumeManager) DeleteOrphan(name string) error {
+	o, err := m.GetOrphan(name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get orphan '%s'", name)
+	}
+	if o == nil {
+		return nil
+	}
+	if err := m.ds.DeleteOrphan(name); err != nil {
+		return err
+	}
+	logrus.Debugf("Deleting orphan %v, data %v on node %v", o.Name, o.Spec.DataPath, o.Spec.NodeID)
+	return nil
+}
+
+// KeepOrphan prevents the orphaned data from being deleted automatically
+func (m *VolumeManager) KeepOrphan(name string, keep bool) (*longhorn.Orphan, error) {
+	o, err := m.GetOrphan(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get orphan '%s'", name)
+	}
+	if o == nil {
+		return nil, fmt.Errorf("cannot find orphan %v", name)
+	}
+	if o.DeletionTimestamp != nil {
+		return nil, fmt.Errorf("orphan %v is being deleted", name)
+	}
+	o.Spec.Keep = keep
+	o, err = m.ds.UpdateOrphan(o)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Updated orphan %v to keep %v", o.Name, o.Spec.Keep)
+	return o, nil
+}
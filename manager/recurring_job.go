@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+func (m *VolumeManager) ListRecurringJobs() (map[string]*longhorn.RecurringJob, error) {
+	return m.ds.ListRecurringJobs()
+}
+
+func (m *VolumeManager) GetRecurringJob(name string) (*longhorn.RecurringJob, error) {
+	return m.ds.GetRecurringJob(name)
+}
+
+func (m *VolumeManager) CreateRecurringJob(name string, spec *types.RecurringJobSpec) (rj *longhorn.RecurringJob, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to create recurring job %v", name)
+	}()
+
+	if err := checkRecurringJobSpec(name, spec); err != nil {
+		return nil, err
+	}
+	if err := m.checkBackupTargetExists(spec.BackupTarget); err != nil {
+		return nil, err
+	}
+	rj = &longhorn.RecurringJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: types.RecurringJobSpec{
+			Task:        spec.Task,
+			Cron:        spec.Cron,
+			Retain:      spec.Retain,
+			Retention:   spec.Retention,
+			Freeze:      spec.Freeze,
+			Hooks:       spec.Hooks,
+			Concurrency: spec.Concurrency,
+
+			BackupTarget: spec.BackupTarget,
+			Selector:     spec.Selector,
+			Groups:       spec.Groups,
+		},
+	}
+	rj, err = m.ds.CreateRecurringJob(rj)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Created recurring job %v", rj.Name)
+	return rj, nil
+}
+
+func (m *VolumeManager) UpdateRecurringJob(name string, spec *types.RecurringJobSpec) (rj *longhorn.RecurringJob, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update recurring job %v", name)
+	}()
+
+	if err := checkRecurringJobSpec(name, spec); err != nil {
+		return nil, err
+	}
+	if err := m.checkBackupTargetExists(spec.BackupTarget); err != nil {
+		return nil, err
+	}
+	rj, err = m.ds.GetRecurringJob(name)
+	if err != nil {
+		return nil, err
+	}
+	if rj == nil {
+		return nil, fmt.Errorf("cannot find recurring job %v", name)
+	}
+	rj.Spec.Task = spec.Task
+	rj.Spec.Cron = spec.Cron
+	rj.Spec.Retain = spec.Retain
+	rj.Spec.Retention = spec.Retention
+	rj.Spec.Freeze = spec.Freeze
+	rj.Spec.Hooks = spec.Hooks
+	rj.Spec.BackupTarget = spec.BackupTarget
+	rj.Spec.Concurrency = spec.Concurrency
+	rj.Spec.Selector = spec.Selector
+	rj.Spec.Groups = spec.Groups
+	rj, err = m.ds.UpdateRecurringJob(rj)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Updated recurring job %v", rj.Name)
+	return rj, nil
+}
+
+// DeleteRecurringJob deletes the recurring job, the cron jobs created for it
+// will be removed from the selected volumes
+func (m *VolumeManager) DeleteRecurringJob(name string) error {
+	if err := m.ds.DeleteRecurringJob(name); err != nil {
+		return errors.Wrapf(err, "unable to delete recurring job %v", name)
+	}
+	logrus.Debugf("Deleted recurring job %v", name)
+	return nil
+}
+
+func checkRecurringJobSpec(name string, spec *types.RecurringJobSpec) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) != 0 {
+		return fmt.Errorf("invalid recurring job name %v: %v", name, errs)
+	}
+	if err := checkRecurringJob(types.RecurringJob{
+		Name:      name,
+		Type:      spec.Task,
+		Cron:      spec.Cron,
+		Retain:    spec.Retain,
+		Retention: spec.Retention,
+		Freeze:    spec.Freeze,
+		Hooks:     spec.Hooks,
+
+		BackupTarget: spec.BackupTarget,
+	}); err != nil {
+		return err
+	}
+	if spec.Concurrency < 0 {
+		return fmt.Errorf("invalid concurrency %v", spec.Concurrency)
+	}
+	if errs := metavalidation.ValidateLabels(spec.Selector, field.NewPath("selector")); len(errs) != 0 {
+		return fmt.Errorf("invalid selector: %v", errs.ToAggregate())
+	}
+	for _, group := range spec.Groups {
+		if errs := validation.IsQualifiedName(types.GetRecurringJobGroupLabelKey(group)); len(errs) != 0 {
+			return fmt.Errorf("invalid group %v: %v", group, errs)
+		}
+	}
+	if len(spec.Selector) == 0 && len(spec.Groups) == 0 {
+		return fmt.Errorf("selector or groups is required to choose the volumes")
+	}
+	return nil
+}
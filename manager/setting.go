@@ -3,6 +3,8 @@ package manager
 import (
 	"github.com/pkg/errors"
 
+	"github.com/rancher/longhorn-manager/types"
+
 	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
 )
 
@@ -10,16 +12,15 @@ func (m *VolumeManager) GetSetting() (*longhorn.Setting, error) {
 	return m.ds.GetSetting()
 }
 
-func (m *VolumeManager) getBackupTargetURL() (string, error) {
-	settings, err := m.ds.GetSetting()
+func (m *VolumeManager) getBackupTargetSpec(backupTargetName string) (*types.BackupTargetSpec, error) {
+	spec, err := m.ds.GetBackupTargetSpec(backupTargetName)
 	if err != nil {
-		return "", errors.New("cannot backup: unable to read settings")
+		return nil, errors.Wrap(err, "cannot backup: unable to get backup target")
 	}
-	backupTarget := settings.BackupTarget
-	if backupTarget == "" {
-		return "", errors.New("cannot backup: backupTarget not set")
+	if spec.URL == "" {
+		return nil, errors.New("cannot backup: backupTarget not set")
 	}
-	return backupTarget, nil
+	return spec, nil
 }
 
 func (m *VolumeManager) GetDefaultEngineImage() (string, error) {
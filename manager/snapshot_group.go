@@ -0,0 +1,485 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/rancher/longhorn-manager/engineapi"
+	"github.com/rancher/longhorn-manager/types"
+	"github.com/rancher/longhorn-manager/util"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+// VolumeFreezer freezes and unfreezes the filesystems on the volumes, on the
+// nodes the volumes are attached to
+type VolumeFreezer interface {
+	FreezeVolume(volumeName string) (bool, error)
+	UnfreezeVolume(volumeName string) error
+}
+
+// GroupSnapshot is the set of the snapshots with the same name taken for a
+// SnapshotGroup
+type GroupSnapshot struct {
+	Name    string   `json:"name"`
+	Created string   `json:"created"`
+	Volumes []string `json:"volumes"`
+}
+
+func (m *VolumeManager) ListSnapshotGroups() (map[string]*longhorn.SnapshotGroup, error) {
+	return m.ds.ListSnapshotGroups()
+}
+
+func (m *VolumeManager) GetSnapshotGroup(name string) (*longhorn.SnapshotGroup, error) {
+	return m.ds.GetSnapshotGroup(name)
+}
+
+func (m *VolumeManager) CreateSnapshotGroup(name string, spec *types.SnapshotGroupSpec) (sg *longhorn.SnapshotGroup, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to create snapshot group %v", name)
+	}()
+
+	if err := m.checkSnapshotGroupSpec(name, spec); err != nil {
+		return nil, err
+	}
+	sg = &longhorn.SnapshotGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: types.SnapshotGroupSpec{
+			Volumes: spec.Volumes,
+			Freeze:  spec.Freeze,
+		},
+	}
+	sg, err = m.ds.CreateSnapshotGroup(sg)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Created snapshot group %v", sg.Name)
+	return sg, nil
+}
+
+func (m *VolumeManager) UpdateSnapshotGroup(name string, spec *types.SnapshotGroupSpec) (sg *longhorn.SnapshotGroup, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update snapshot group %v", name)
+	}()
+
+	if err := m.checkSnapshotGroupSpec(name, spec); err != nil {
+		return nil, err
+	}
+	sg, err = m.ds.GetSnapshotGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	if sg == nil {
+		return nil, fmt.Errorf("cannot find snapshot group %v", name)
+	}
+	sg.Spec.Volumes = spec.Volumes
+	sg.Spec.Freeze = spec.Freeze
+	sg, err = m.ds.UpdateSnapshotGroup(sg)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Updated snapshot group %v to %+v", name, sg.Spec)
+	return sg, nil
+}
+
+// DeleteSnapshotGroup deletes the snapshot group, the snapshots and backups
+// taken for it are kept
+func (m *VolumeManager) DeleteSnapshotGroup(name string) error {
+	if err := m.ds.DeleteSnapshotGroup(name); err != nil {
+		return errors.Wrapf(err, "unable to delete snapshot group %v", name)
+	}
+	logrus.Debugf("Deleted snapshot group %v", name)
+	return nil
+}
+
+func (m *VolumeManager) checkSnapshotGroupSpec(name string, spec *types.SnapshotGroupSpec) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) != 0 {
+		return fmt.Errorf("invalid snapshot group name %v: %v", name, errs)
+	}
+	if len(spec.Volumes) == 0 {
+		return fmt.Errorf("snapshot group %v has no volume", name)
+	}
+	seen := map[string]struct{}{}
+	for _, volumeName := range spec.Volumes {
+		if _, exists := seen[volumeName]; exists {
+			return fmt.Errorf("duplicate volume %v in snapshot group %v", volumeName, name)
+		}
+		seen[volumeName] = struct{}{}
+		v, err := m.ds.GetVolume(volumeName)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			return fmt.Errorf("cannot find volume %v", volumeName)
+		}
+	}
+	return nil
+}
+
+// FreezeVolume freezes the filesystem on the volume attached to the current
+// node, until UnfreezeVolume is called or types.SnapshotFreezeTimeout
+// expired. It returns false if the volume has no mounted filesystem to freeze.
+func (m *VolumeManager) FreezeVolume(volumeName string) (bool, error) {
+	v, err := m.ds.GetVolume(volumeName)
+	if err != nil {
+		return false, err
+	}
+	if v == nil {
+		return false, fmt.Errorf("cannot find volume %v", volumeName)
+	}
+	if v.Spec.NodeID != m.currentNodeID {
+		return false, fmt.Errorf("cannot freeze filesystem of volume %v attached to %v on %v", volumeName, v.Spec.NodeID, m.currentNodeID)
+	}
+	if v.Spec.Frontend != types.VolumeFrontendBlockDev || v.Spec.DisableFrontend {
+		return false, nil
+	}
+	mountPoint, err := util.GetDeviceMountPoint(util.GetHostDevicePath(v.Status.Endpoint))
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot find the filesystem of volume %v to freeze", volumeName)
+	}
+	if mountPoint == "" {
+		return false, nil
+	}
+
+	m.frozenLock.Lock()
+	defer m.frozenLock.Unlock()
+	if _, exists := m.frozenVolumes[volumeName]; exists {
+		return false, fmt.Errorf("filesystem of volume %v is already frozen", volumeName)
+	}
+	release, err := util.HoldFilesystemFrozen(mountPoint, types.SnapshotFreezeTimeout)
+	if err != nil {
+		return false, err
+	}
+	m.frozenVolumes[volumeName] = release
+	logrus.Debugf("Froze filesystem of volume %v at %v", volumeName, mountPoint)
+	return true, nil
+}
+
+// UnfreezeVolume unfreezes the filesystem frozen by FreezeVolume. It returns
+// util.ErrFreezeTimeout if the filesystem had been unfrozen by the timeout.
+func (m *VolumeManager) UnfreezeVolume(volumeName string) error {
+	m.frozenLock.Lock()
+	release, exists := m.frozenVolumes[volumeName]
+	delete(m.frozenVolumes, volumeName)
+	m.frozenLock.Unlock()
+
+	if !exists {
+		return fmt.Errorf("filesystem of volume %v is not frozen", volumeName)
+	}
+	if err := release(); err != nil {
+		return errors.Wrapf(err, "cannot unfreeze filesystem of volume %v", volumeName)
+	}
+	logrus.Debugf("Unfroze filesystem of volume %v", volumeName)
+	return nil
+}
+
+// CreateGroupSnapshot snapshots all the volumes in the group at the same
+// point in time. If the group has Freeze set, the filesystems on all the
+// volumes are frozen by freezer before the first snapshot is taken, and
+// unfrozen after the last one. The snapshots share the same name and the
+// types.SnapshotLabelGroup label. If any volume failed, the snapshots already
+// taken are deleted.
+func (m *VolumeManager) CreateGroupSnapshot(name string, freezer VolumeFreezer) (snapshotName string, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to create snapshot of group %v", name)
+	}()
+
+	sg, err := m.ds.GetSnapshotGroup(name)
+	if err != nil {
+		return "", err
+	}
+	if sg == nil {
+		return "", fmt.Errorf("cannot find snapshot group %v", name)
+	}
+	engines := map[string]engineapi.EngineClient{}
+	for _, volumeName := range sg.Spec.Volumes {
+		engine, err := m.GetEngineClient(volumeName)
+		if err != nil {
+			return "", err
+		}
+		engines[volumeName] = engine
+	}
+
+	snapshotName = name + "-" + util.RandomID()
+	labels := map[string]map[string]string{}
+	for _, volumeName := range sg.Spec.Volumes {
+		labels[volumeName] = map[string]string{
+			types.SnapshotLabelGroup: name,
+		}
+	}
+
+	if sg.Spec.Freeze {
+		frozen := []string{}
+		unfreeze := func() error {
+			failures := []string{}
+			for _, volumeName := range frozen {
+				if err := freezer.UnfreezeVolume(volumeName); err != nil {
+					failures = append(failures, err.Error())
+				}
+			}
+			if len(failures) != 0 {
+				return fmt.Errorf("%v", strings.Join(failures, "; "))
+			}
+			return nil
+		}
+		for _, volumeName := range sg.Spec.Volumes {
+			isFrozen, err := freezer.FreezeVolume(volumeName)
+			if err != nil {
+				if unfreezeErr := unfreeze(); unfreezeErr != nil {
+					logrus.Warnf("Failed to unfreeze the volumes of snapshot group %v: %v", name, unfreezeErr)
+				}
+				return "", err
+			}
+			if isFrozen {
+				frozen = append(frozen, volumeName)
+				labels[volumeName][types.SnapshotLabelFreeze] = types.SnapshotFreezeResultFrozen
+			} else {
+				labels[volumeName][types.SnapshotLabelFreeze] = types.SnapshotFreezeResultSkipped
+			}
+		}
+		created, err := createGroupSnapshots(engines, snapshotName, labels)
+		// the snapshots taken after the timeout unfroze a filesystem are
+		// not consistent
+		if unfreezeErr := unfreeze(); unfreezeErr != nil && err == nil {
+			err = unfreezeErr
+		}
+		if err != nil {
+			deleteGroupSnapshots(engines, created, snapshotName)
+			return "", err
+		}
+	} else {
+		created, err := createGroupSnapshots(engines, snapshotName, labels)
+		if err != nil {
+			deleteGroupSnapshots(engines, created, snapshotName)
+			return "", err
+		}
+	}
+
+	sg.Status.LastSnapshot = snapshotName
+	if _, err := m.ds.UpdateSnapshotGroup(sg); err != nil {
+		logrus.Warnf("Failed to update status of snapshot group %v: %v", name, err)
+	}
+	logrus.Debugf("Created snapshot %v of group %v", snapshotName, name)
+	return snapshotName, nil
+}
+
+// createGroupSnapshots takes the snapshots of all the volumes concurrently, and
+// returns the volumes the snapshots were taken for
+func createGroupSnapshots(engines map[string]engineapi.EngineClient, snapshotName string, labels map[string]map[string]string) ([]string, error) {
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		created  []string
+		failures []string
+	)
+	for volumeName, engine := range engines {
+		wg.Add(1)
+		go func(volumeName string, engine engineapi.EngineClient) {
+			defer wg.Done()
+			_, err := engine.SnapshotCreate(snapshotName, labels[volumeName])
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("volume %v: %v", volumeName, err))
+				return
+			}
+			created = append(created, volumeName)
+		}(volumeName, engine)
+	}
+	wg.Wait()
+	if len(failures) != 0 {
+		return created, fmt.Errorf("%v", strings.Join(failures, "; "))
+	}
+	return created, nil
+}
+
+func deleteGroupSnapshots(engines map[string]engineapi.EngineClient, volumes []string, snapshotName string) {
+	for _, volumeName := range volumes {
+		if err := engines[volumeName].SnapshotDelete(snapshotName); err != nil {
+			logrus.Warnf("Failed to delete snapshot %v of volume %v: %v", snapshotName, volumeName, err)
+		}
+	}
+}
+
+// ListGroupSnapshots returns the snapshots taken for the group, oldest first
+func (m *VolumeManager) ListGroupSnapshots(name string) ([]*GroupSnapshot, error) {
+	sg, err := m.ds.GetSnapshotGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	if sg == nil {
+		return nil, fmt.Errorf("cannot find snapshot group %v", name)
+	}
+	groupSnapshots := map[string]*GroupSnapshot{}
+	for _, volumeName := range sg.Spec.Volumes {
+		snapshots, err := m.ListSnapshots(volumeName)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range snapshots {
+			if s.Removed || s.Labels[types.SnapshotLabelGroup] != name {
+				continue
+			}
+			gs := groupSnapshots[s.Name]
+			if gs == nil {
+				gs = &GroupSnapshot{Name: s.Name, Created: s.Created}
+				groupSnapshots[s.Name] = gs
+			}
+			if s.Created < gs.Created {
+				gs.Created = s.Created
+			}
+			gs.Volumes = append(gs.Volumes, volumeName)
+		}
+	}
+	result := []*GroupSnapshot{}
+	for _, gs := range groupSnapshots {
+		result = append(result, gs)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Created < result[j].Created
+	})
+	return result, nil
+}
+
+// BackupGroupSnapshot requests backing up the snapshots of all the volumes
+// in the group taken by the group snapshot to the backup target
+// backupTargetName. The progress of each backup is reported in the returned
+// BackupOperations. If a backup failed to be requested, the ones requested
+// are cancelled if they're not started yet.
+func (m *VolumeManager) BackupGroupSnapshot(name, snapshotName, backupTargetName string) (bos map[string]*longhorn.BackupOperation, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to back up snapshot %v of group %v", snapshotName, name)
+	}()
+
+	sg, err := m.ds.GetSnapshotGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	if sg == nil {
+		return nil, fmt.Errorf("cannot find snapshot group %v", name)
+	}
+	for _, volumeName := range sg.Spec.Volumes {
+		snapshot, err := m.GetSnapshot(snapshotName, volumeName)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.Labels[types.SnapshotLabelGroup] != name {
+			return nil, fmt.Errorf("snapshot %v of volume %v is not taken for group %v", snapshotName, volumeName, name)
+		}
+	}
+
+	bos = map[string]*longhorn.BackupOperation{}
+	for _, volumeName := range sg.Spec.Volumes {
+		bo, err := m.CreateBackupOperation(snapshotName, nil, volumeName, backupTargetName)
+		if err != nil {
+			for boName := range bos {
+				if _, err := m.CancelBackupOperation(boName); err != nil {
+					logrus.Warnf("Failed to cancel backup operation %v of group %v: %v", boName, name, err)
+				}
+			}
+			return nil, err
+		}
+		bos[bo.Name] = bo
+	}
+
+	sg.Status.LastBackup = snapshotName
+	if _, err := m.ds.UpdateSnapshotGroup(sg); err != nil {
+		logrus.Warnf("Failed to update status of snapshot group %v: %v", name, err)
+	}
+	logrus.Debugf("Requested backing up snapshot %v of group %v", snapshotName, name)
+	return bos, nil
+}
+
+// RestoreGroupSnapshot creates a volume from the backup of each volume in the
+// group taken by the group snapshot. The restored volume is named by the
+// volume prefixed by volumePrefix, and has the replica settings of the
+// volume if the volume still exists. The backups are looked up in the backup
+// target backupTargetName. If any volume failed to be created, the ones
+// created are deleted.
+func (m *VolumeManager) RestoreGroupSnapshot(name, snapshotName, volumePrefix, backupTargetName string) (volumes []*longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to restore snapshot %v of group %v", snapshotName, name)
+	}()
+
+	// the restored volumes cannot take the names of the volumes in the group
+	if volumePrefix == "" {
+		return nil, fmt.Errorf("volume prefix required")
+	}
+	sg, err := m.ds.GetSnapshotGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	if sg == nil {
+		return nil, fmt.Errorf("cannot find snapshot group %v", name)
+	}
+	for _, volumeName := range sg.Spec.Volumes {
+		restored, err := m.ds.GetVolume(volumePrefix + volumeName)
+		if err != nil {
+			return nil, err
+		}
+		if restored != nil {
+			return nil, fmt.Errorf("volume %v already exists", restored.Name)
+		}
+	}
+	backupURLs := map[string]string{}
+	for _, volumeName := range sg.Spec.Volumes {
+		backups, err := m.ListBackupsForVolume(backupTargetName, volumeName)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range backups {
+			if b.Status.SnapshotName == snapshotName {
+				backupURLs[volumeName] = b.Status.URL
+				break
+			}
+		}
+		if backupURLs[volumeName] == "" {
+			return nil, fmt.Errorf("cannot find backup of snapshot %v of volume %v", snapshotName, volumeName)
+		}
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, v := range volumes {
+			if err := m.ds.DeleteVolume(v.Name); err != nil && !apierrors.IsNotFound(err) {
+				logrus.Warnf("Failed to delete volume %v restored for group %v: %v", v.Name, name, err)
+			}
+		}
+		volumes = nil
+	}()
+	for _, volumeName := range sg.Spec.Volumes {
+		spec := &types.VolumeSpec{
+			Frontend:   types.VolumeFrontendBlockDev,
+			FromBackup: backupURLs[volumeName],
+		}
+		v, err := m.ds.GetVolume(volumeName)
+		if err != nil {
+			return volumes, err
+		}
+		if v != nil {
+			spec.Frontend = v.Spec.Frontend
+			spec.NumberOfReplicas = v.Spec.NumberOfReplicas
+			spec.StaleReplicaTimeout = v.Spec.StaleReplicaTimeout
+		}
+		restored, err := m.Create(volumePrefix+volumeName, spec, nil)
+		if err != nil {
+			return volumes, err
+		}
+		volumes = append(volumes, restored)
+	}
+	logrus.Debugf("Restoring snapshot %v of group %v", snapshotName, name)
+	return volumes, nil
+}
@@ -0,0 +1,193 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+
+	. "gopkg.in/check.v1"
+)
+
+// fakeVolumeFreezer records the frozen volumes, it fails freezing the
+// volumes in freezeErrs
+type fakeVolumeFreezer struct {
+	frozen     map[string]bool
+	unfrozen   []string
+	freezeErrs map[string]error
+}
+
+func newFakeVolumeFreezer() *fakeVolumeFreezer {
+	return &fakeVolumeFreezer{
+		frozen:     map[string]bool{},
+		freezeErrs: map[string]error{},
+	}
+}
+
+func (f *fakeVolumeFreezer) FreezeVolume(volumeName string) (bool, error) {
+	if err := f.freezeErrs[volumeName]; err != nil {
+		return false, err
+	}
+	f.frozen[volumeName] = true
+	return true, nil
+}
+
+func (f *fakeVolumeFreezer) UnfreezeVolume(volumeName string) error {
+	if !f.frozen[volumeName] {
+		return fmt.Errorf("filesystem of volume %v is not frozen", volumeName)
+	}
+	delete(f.frozen, volumeName)
+	f.unfrozen = append(f.unfrozen, volumeName)
+	return nil
+}
+
+func (env *managerTestEnv) addSnapshotGroup(name string, freeze bool, volumes []string, c *C) {
+	sg := &longhorn.SnapshotGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: TestNamespace,
+		},
+		Spec: types.SnapshotGroupSpec{
+			Volumes: volumes,
+			Freeze:  freeze,
+		},
+	}
+	sg, err := env.lhClient.LonghornV1alpha1().SnapshotGroups(TestNamespace).Create(sg)
+	c.Assert(err, IsNil)
+	err = env.sgIndexer.Add(sg)
+	c.Assert(err, IsNil)
+}
+
+func (s *TestSuite) TestCreateGroupSnapshot(c *C) {
+	env := newManagerTestEnv(c)
+	engineA := env.addVolume(newVolume("vol-a"), c)
+	engineB := env.addVolume(newVolume("vol-b"), c)
+	env.addSnapshotGroup("group", true, []string{"vol-a", "vol-b"}, c)
+
+	freezer := newFakeVolumeFreezer()
+	snapshotName, err := env.m.CreateGroupSnapshot("group", freezer)
+	c.Assert(err, IsNil)
+	c.Assert(freezer.frozen, HasLen, 0)
+	c.Assert(freezer.unfrozen, HasLen, 2)
+	for _, engine := range []*fakeEngine{engineA, engineB} {
+		c.Assert(engine.snapshots[snapshotName], NotNil)
+		c.Assert(engine.snapshots[snapshotName].Labels, DeepEquals, map[string]string{
+			types.SnapshotLabelGroup:  "group",
+			types.SnapshotLabelFreeze: types.SnapshotFreezeResultFrozen,
+		})
+	}
+
+	sg, err := env.lhClient.LonghornV1alpha1().SnapshotGroups(TestNamespace).Get("group", metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(sg.Status.LastSnapshot, Equals, snapshotName)
+}
+
+func (s *TestSuite) TestCreateGroupSnapshotRollback(c *C) {
+	env := newManagerTestEnv(c)
+	engineA := env.addVolume(newVolume("vol-a"), c)
+	engineB := env.addVolume(newVolume("vol-b"), c)
+	engineB.createErr = fmt.Errorf("replica is rebuilding")
+	env.addSnapshotGroup("group", false, []string{"vol-a", "vol-b"}, c)
+
+	// the snapshot taken for the other volume is deleted
+	_, err := env.m.CreateGroupSnapshot("group", newFakeVolumeFreezer())
+	c.Assert(err, ErrorMatches, ".*volume vol-b: replica is rebuilding.*")
+	c.Assert(engineA.snapshots, HasLen, 0)
+	c.Assert(engineB.snapshots, HasLen, 0)
+
+	sg, err := env.lhClient.LonghornV1alpha1().SnapshotGroups(TestNamespace).Get("group", metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(sg.Status.LastSnapshot, Equals, "")
+}
+
+func (s *TestSuite) TestCreateGroupSnapshotUnfreezeOnError(c *C) {
+	env := newManagerTestEnv(c)
+	engineA := env.addVolume(newVolume("vol-a"), c)
+	engineB := env.addVolume(newVolume("vol-b"), c)
+	env.addSnapshotGroup("group", true, []string{"vol-a", "vol-b"}, c)
+
+	// the snapshot failed while the filesystems are frozen
+	engineB.createErr = fmt.Errorf("replica is rebuilding")
+	freezer := newFakeVolumeFreezer()
+	_, err := env.m.CreateGroupSnapshot("group", freezer)
+	c.Assert(err, NotNil)
+	c.Assert(freezer.frozen, HasLen, 0)
+	sort.Strings(freezer.unfrozen)
+	c.Assert(freezer.unfrozen, DeepEquals, []string{"vol-a", "vol-b"})
+	c.Assert(engineA.snapshots, HasLen, 0)
+
+	// the filesystems frozen before the failure are unfrozen, no snapshot
+	// is taken
+	engineB.createErr = nil
+	freezer = newFakeVolumeFreezer()
+	freezer.freezeErrs["vol-b"] = fmt.Errorf("device is busy")
+	_, err = env.m.CreateGroupSnapshot("group", freezer)
+	c.Assert(err, ErrorMatches, ".*device is busy.*")
+	c.Assert(freezer.frozen, HasLen, 0)
+	c.Assert(freezer.unfrozen, DeepEquals, []string{"vol-a"})
+	c.Assert(engineA.snapshots, HasLen, 0)
+	c.Assert(engineB.snapshots, HasLen, 0)
+}
+
+func (s *TestSuite) TestBackupGroupSnapshot(c *C) {
+	env := newManagerTestEnv(c)
+	engineA := env.addVolume(newVolume("vol-a"), c)
+	engineB := env.addVolume(newVolume("vol-b"), c)
+	env.addSnapshotGroup("group", false, []string{"vol-a", "vol-b"}, c)
+
+	setting, err := env.m.ds.GetSetting()
+	c.Assert(err, IsNil)
+	setting.BackupTarget = TestBackupTargetURL
+	_, err = env.m.ds.UpdateSetting(setting)
+	c.Assert(err, IsNil)
+
+	snapshotName, err := env.m.CreateGroupSnapshot("group", newFakeVolumeFreezer())
+	c.Assert(err, IsNil)
+
+	// the backups run in the background
+	bos, err := env.m.BackupGroupSnapshot("group", snapshotName, "")
+	c.Assert(err, IsNil)
+	c.Assert(bos, HasLen, 2)
+	volumes := []string{}
+	for _, bo := range bos {
+		c.Assert(bo.Spec.SnapshotName, Equals, snapshotName)
+		c.Assert(bo.Spec.BackupTarget, Equals, types.DefaultBackupTargetName)
+		c.Assert(bo.Status.State, Equals, types.BackupOperationStatePending)
+		volumes = append(volumes, bo.Spec.VolumeName)
+	}
+	sort.Strings(volumes)
+	c.Assert(volumes, DeepEquals, []string{"vol-a", "vol-b"})
+	sg, err := env.lhClient.LonghornV1alpha1().SnapshotGroups(TestNamespace).Get("group", metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(sg.Status.LastBackup, Equals, snapshotName)
+
+	// the snapshot not taken for the group is rejected
+	_, err = engineA.SnapshotCreate("other", nil)
+	c.Assert(err, IsNil)
+	_, err = engineB.SnapshotCreate("other", nil)
+	c.Assert(err, IsNil)
+	_, err = env.m.BackupGroupSnapshot("group", "other", "")
+	c.Assert(err, ErrorMatches, ".*is not taken for group group.*")
+}
+
+func (s *TestSuite) TestRestoreGroupSnapshotRejectsNameCollision(c *C) {
+	env := newManagerTestEnv(c)
+	env.addVolume(newVolume("vol-a"), c)
+	env.addVolume(newVolume("vol-b"), c)
+	env.addVolume(newVolume("restored-vol-b"), c)
+	env.addSnapshotGroup("group", false, []string{"vol-a", "vol-b"}, c)
+
+	_, err := env.m.RestoreGroupSnapshot("group", "snap", "", "")
+	c.Assert(err, ErrorMatches, ".*volume prefix required.*")
+
+	// no volume is created if any name is taken
+	_, err = env.m.RestoreGroupSnapshot("group", "snap", "restored-", "")
+	c.Assert(err, ErrorMatches, ".*volume restored-vol-b already exists.*")
+	volumes, err := env.lhClient.LonghornV1alpha1().Volumes(TestNamespace).List(metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(volumes.Items, HasLen, 3)
+}
@@ -2,13 +2,18 @@ package manager
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/pkg/errors"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/engineapi"
 	"github.com/rancher/longhorn-manager/types"
 	"github.com/rancher/longhorn-manager/util"
 
@@ -19,6 +24,14 @@ type VolumeManager struct {
 	ds *datastore.DataStore
 
 	currentNodeID string
+
+	engines engineapi.EngineClientCollection
+
+	// frozenVolumes are the volumes frozen on the current node for the
+	// group snapshots, keyed by the volume name, the value releases the
+	// freeze
+	frozenLock    sync.Mutex
+	frozenVolumes map[string]func() error
 }
 
 func NewVolumeManager(currentNodeID string, ds *datastore.DataStore) *VolumeManager {
@@ -26,6 +39,10 @@ func NewVolumeManager(currentNodeID string, ds *datastore.DataStore) *VolumeMana
 		ds: ds,
 
 		currentNodeID: currentNodeID,
+
+		engines: &engineapi.EngineCollection{},
+
+		frozenVolumes: map[string]func() error{},
 	}
 }
 
@@ -49,6 +66,112 @@ func (m *VolumeManager) List() (map[string]*longhorn.Volume, error) {
 	return m.ds.ListVolumes()
 }
 
+const (
+	VolumeSortByName    = "name"
+	VolumeSortBySize    = "size"
+	VolumeSortByCreated = "created"
+	VolumeSortByState   = "state"
+)
+
+// VolumeListOptions filters, sorts and paginates the volume list. Empty
+// fields match all the volumes.
+type VolumeListOptions struct {
+	LabelSelector labels.Selector
+	State         types.VolumeState
+	Robustness    types.VolumeRobustness
+	NodeID        string
+	EngineImage   string
+
+	SortBy     string
+	Descending bool
+
+	// Marker is the number of volumes to skip, Limit is the maximum number
+	// of volumes returned. Limit 0 means no limit.
+	Marker int
+	Limit  int
+}
+
+// ListWithOptions returns the volumes in the page selected by opts, and the
+// number of all the volumes matched
+func (m *VolumeManager) ListWithOptions(opts *VolumeListOptions) ([]*longhorn.Volume, int, error) {
+	selector := opts.LabelSelector
+	if selector == nil {
+		selector = labels.Everything()
+	}
+	volumes, err := m.ds.ListVolumesBySelector(selector)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "unable to list volumes")
+	}
+
+	result := []*longhorn.Volume{}
+	for _, v := range volumes {
+		if matchVolumeListOptions(v, opts) {
+			result = append(result, v)
+		}
+	}
+	if err := sortVolumes(result, opts.SortBy, opts.Descending); err != nil {
+		return nil, 0, err
+	}
+
+	total := len(result)
+	marker := opts.Marker
+	if marker > total {
+		marker = total
+	}
+	result = result[marker:]
+	if opts.Limit > 0 && opts.Limit < len(result) {
+		result = result[:opts.Limit]
+	}
+	return result, total, nil
+}
+
+func matchVolumeListOptions(v *longhorn.Volume, opts *VolumeListOptions) bool {
+	if opts.State != "" && v.Status.State != opts.State {
+		return false
+	}
+	if opts.Robustness != "" && v.Status.Robustness != opts.Robustness {
+		return false
+	}
+	if opts.NodeID != "" && v.Spec.NodeID != opts.NodeID {
+		return false
+	}
+	if opts.EngineImage != "" && v.Status.CurrentImage != opts.EngineImage {
+		return false
+	}
+	return true
+}
+
+func sortVolumes(volumes []*longhorn.Volume, sortBy string, descending bool) error {
+	var less func(a, b *longhorn.Volume) bool
+	switch sortBy {
+	case "", VolumeSortByName:
+		less = func(a, b *longhorn.Volume) bool { return a.Name < b.Name }
+	case VolumeSortBySize:
+		less = func(a, b *longhorn.Volume) bool { return a.Spec.Size < b.Spec.Size }
+	case VolumeSortByCreated:
+		less = func(a, b *longhorn.Volume) bool { return a.CreationTimestamp.Before(&b.CreationTimestamp) }
+	case VolumeSortByState:
+		less = func(a, b *longhorn.Volume) bool { return a.Status.State < b.Status.State }
+	default:
+		return fmt.Errorf("invalid sort key %v", sortBy)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		a, b := volumes[i], volumes[j]
+		if descending {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		// keep the order stable between the pages
+		return a.Name < b.Name
+	})
+	return nil
+}
+
 func (m *VolumeManager) Get(vName string) (*longhorn.Volume, error) {
 	return m.ds.GetVolume(vName)
 }
@@ -61,14 +184,18 @@ func (m *VolumeManager) GetReplicas(vName string) (map[string]*longhorn.Replica,
 	return m.ds.GetVolumeReplicas(vName)
 }
 
-func (m *VolumeManager) Create(name string, spec *types.VolumeSpec) (v *longhorn.Volume, err error) {
+func (m *VolumeManager) Create(name string, spec *types.VolumeSpec, labels map[string]string) (v *longhorn.Volume, err error) {
 	defer func() {
 		err = errors.Wrapf(err, "unable to create volume %v: %+v", name, spec)
 	}()
 
 	size := spec.Size
 	if spec.FromBackup != "" {
-		backupTarget, err := m.getBackupTarget()
+		targetSpec, err := m.ds.GetBackupTargetSpecForURL(spec.FromBackup)
+		if err != nil {
+			return nil, err
+		}
+		backupTarget, err := m.newBackupTarget(targetSpec)
 		if err != nil {
 			return nil, err
 		}
@@ -107,18 +234,73 @@ func (m *VolumeManager) Create(name string, spec *types.VolumeSpec) (v *longhorn
 		return nil, fmt.Errorf("invalid volume frontend specified: %v", spec.Frontend)
 	}
 
+	ownerID := ""
+	if spec.FromImage != "" {
+		if spec.FromBackup != "" || spec.BackingImage != "" {
+			return nil, fmt.Errorf("cannot import volume from image together with backup or backing image")
+		}
+		source, err := types.ParseImageURL(spec.FromImage)
+		if err != nil {
+			return nil, err
+		}
+		if spec.FromImageFormat == "" {
+			spec.FromImageFormat = types.VolumeImageFormatRaw
+		}
+		if spec.FromImageFormat != types.VolumeImageFormatRaw && spec.FromImageFormat != types.VolumeImageFormatQcow2 {
+			return nil, fmt.Errorf("invalid image format specified: %v", spec.FromImageFormat)
+		}
+		// the data will be written through the block device on the node
+		if spec.Frontend != types.VolumeFrontendBlockDev {
+			return nil, fmt.Errorf("importing image requires frontend %v", types.VolumeFrontendBlockDev)
+		}
+		if source.IsLocalFile {
+			if spec.FromImageNodeID == "" {
+				return nil, fmt.Errorf("node is required for importing local image file %v", spec.FromImage)
+			}
+			node, err := m.ds.GetNode(spec.FromImageNodeID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to get node %v", spec.FromImageNodeID)
+			}
+			if node == nil {
+				return nil, fmt.Errorf("cannot find node %v", spec.FromImageNodeID)
+			}
+			// the import can only be done by the manager on the node
+			ownerID = spec.FromImageNodeID
+		}
+	}
+
+	if spec.BackingImage != "" {
+		if spec.FromBackup != "" {
+			return nil, fmt.Errorf("cannot create volume from both backup and backing image")
+		}
+		bi, err := m.GetBackingImage(spec.BackingImage)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get backing image %v", spec.BackingImage)
+		}
+		if bi == nil {
+			return nil, fmt.Errorf("cannot find backing image %v", spec.BackingImage)
+		}
+	}
+
 	v = &longhorn.Volume{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:   name,
+			Labels: labels,
 		},
 		Spec: types.VolumeSpec{
-			OwnerID:             "", // the first controller who see it will pick it up
+			OwnerID:             ownerID, // the first controller who see it will pick it up if empty
 			Size:                size,
 			Frontend:            spec.Frontend,
 			EngineImage:         defaultEngineImage,
 			FromBackup:          spec.FromBackup,
+			FromImage:           spec.FromImage,
+			FromImageFormat:     spec.FromImageFormat,
+			FromImageNodeID:     spec.FromImageNodeID,
+			BackingImage:        spec.BackingImage,
 			NumberOfReplicas:    spec.NumberOfReplicas,
 			StaleReplicaTimeout: spec.StaleReplicaTimeout,
+			DeletionProtection:  spec.DeletionProtection,
+			DisableAutoAttach:   spec.DisableAutoAttach,
 		},
 	}
 	v, err = m.ds.CreateVolume(v)
@@ -129,8 +311,261 @@ func (m *VolumeManager) Create(name string, spec *types.VolumeSpec) (v *longhorn
 	return v, nil
 }
 
-func (m *VolumeManager) Delete(name string) error {
-	return m.ds.DeleteVolume(name)
+// Delete removes the volume, or moves it into the recycle bin if
+// SettingsInfo.RecycleBinRetention is set. Deleting a recycled volume
+// removes it immediately.
+func (m *VolumeManager) Delete(name string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to delete volume %v", name)
+	}()
+
+	v, err := m.ds.GetVolume(name)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return m.ds.DeleteVolume(name)
+	}
+	if v.Spec.DeletionProtection {
+		return fmt.Errorf("volume %v is protected from deletion, deletionProtection must be cleared first", name)
+	}
+	if v.Spec.RecycledAt != "" {
+		return m.ds.DeleteVolume(name)
+	}
+
+	settings, err := m.ds.GetSetting()
+	if err != nil {
+		return err
+	}
+	if settings.RecycleBinRetention <= 0 {
+		return m.ds.DeleteVolume(name)
+	}
+
+	// detach the volume, the replicas will be stopped
+	v.Spec.NodeID = ""
+	v.Spec.RecycledAt = util.Now()
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return err
+	}
+	m.recordVolumeEvent(v, corev1.EventTypeNormal, types.EventReasonRecycled,
+		fmt.Sprintf("Moved volume %v into the recycle bin for %v hours", name, settings.RecycleBinRetention))
+	logrus.Infof("Moved volume %v into the recycle bin", name)
+	return nil
+}
+
+// Recover brings the volume back from the recycle bin
+func (m *VolumeManager) Recover(name string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to recover volume %v", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", name)
+	}
+	if v.Spec.RecycledAt == "" {
+		return nil, fmt.Errorf("volume %v is not in the recycle bin", name)
+	}
+	if v.DeletionTimestamp != nil {
+		return nil, fmt.Errorf("volume %v is being deleted", name)
+	}
+
+	v.Spec.RecycledAt = ""
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	m.recordVolumeEvent(v, corev1.EventTypeNormal, types.EventReasonRecovered,
+		fmt.Sprintf("Recovered volume %v from the recycle bin", name))
+	logrus.Infof("Recovered volume %v from the recycle bin", name)
+	return v, nil
+}
+
+// RetryImport resets the failed image import of the volume, the volume
+// controller will attach the volume and run the import job again
+func (m *VolumeManager) RetryImport(name string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to retry image import for volume %v", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", name)
+	}
+	if v.Status.ImportState != types.VolumeImportStateFailed {
+		return nil, fmt.Errorf("invalid import state to retry: %v", v.Status.ImportState)
+	}
+	if v.Status.State != types.VolumeStateDetached {
+		return nil, fmt.Errorf("invalid volume state to retry import: %v", v.Status.State)
+	}
+
+	v.Status.ImportState = types.VolumeImportStatePending
+	v.Status.ImportProgress = 0
+	v.Status.ImportError = ""
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	m.recordVolumeEvent(v, corev1.EventTypeNormal, types.EventReasonRetryImport,
+		fmt.Sprintf("Retrying to import volume %v from %v", name, v.Spec.FromImage))
+	logrus.Infof("Retrying to import volume %v from %v", name, v.Spec.FromImage)
+	return v, nil
+}
+
+// RetryRestore resets the failed restore of the volume, the volume
+// controller will replace the failed replicas, attach the volume and restore
+// from the backup again
+func (m *VolumeManager) RetryRestore(name string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to retry restore for volume %v", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", name)
+	}
+	cond, ok := v.Status.Conditions[types.VolumeConditionTypeRestoring]
+	if !ok || cond.Reason != types.VolumeConditionReasonRestoreFailure {
+		return nil, fmt.Errorf("cannot retry restore for volume %v, the restore didn't fail", name)
+	}
+	if v.Status.State != types.VolumeStateDetached {
+		return nil, fmt.Errorf("invalid volume state to retry restore: %v", v.Status.State)
+	}
+
+	cond.Status = types.ConditionStatusTrue
+	cond.Reason = types.VolumeConditionReasonRestoreInProgress
+	cond.Message = fmt.Sprintf("Restoring from %v", v.Spec.FromBackup)
+	cond.LastTransitionTime = util.Now()
+	v.Status.Conditions[types.VolumeConditionTypeRestoring] = cond
+	v.Status.RestoreStatus = types.RestoreStatus{}
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	m.recordVolumeEvent(v, corev1.EventTypeNormal, types.EventReasonRetryRestore,
+		fmt.Sprintf("Retrying to restore volume %v from %v", name, v.Spec.FromBackup))
+	logrus.Infof("Retrying to restore volume %v from %v", name, v.Spec.FromBackup)
+	return v, nil
+}
+
+func (m *VolumeManager) UpdateDeletionProtection(name string, deletionProtection bool) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update deletion protection for volume %v", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", name)
+	}
+	if v.Spec.DeletionProtection == deletionProtection {
+		return v, nil
+	}
+
+	v.Spec.DeletionProtection = deletionProtection
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Updated deletion protection of volume %v to %v", name, deletionProtection)
+	return v, nil
+}
+
+// UpdateDisableAutoAttach prevents or allows the recurring jobs attaching the
+// detached volume. The volume already attached for the jobs is detached once
+// they're done.
+func (m *VolumeManager) UpdateDisableAutoAttach(name string, disableAutoAttach bool) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update auto attach for volume %v", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", name)
+	}
+	if v.Spec.DisableAutoAttach == disableAutoAttach {
+		return v, nil
+	}
+
+	v.Spec.DisableAutoAttach = disableAutoAttach
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Updated disable auto attach of volume %v to %v", name, disableAutoAttach)
+	return v, nil
+}
+
+// UpdateSnapshotHooks replaces the snapshot hooks of the volume, nil or empty
+// hooks remove them
+func (m *VolumeManager) UpdateSnapshotHooks(name string, hooks *types.SnapshotHooks) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update snapshot hooks for volume %v", name)
+	}()
+
+	if hooks.IsEmpty() {
+		hooks = nil
+	} else if err := checkSnapshotHooks(hooks); err != nil {
+		return nil, err
+	}
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", name)
+	}
+
+	v.Spec.SnapshotHooks = hooks
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Updated snapshot hooks of volume %v to %+v", name, hooks)
+	return v, nil
+}
+
+// UpdateLabels replaces the labels set by the user on the volume
+func (m *VolumeManager) UpdateLabels(name string, labels map[string]string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update labels for volume %v", name)
+	}()
+
+	if err := datastore.CheckVolumeLabels(name, labels); err != nil {
+		return nil, err
+	}
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("cannot find volume %v", name)
+	}
+
+	// the label tracking the volume will be added back by the datastore
+	v.Labels = labels
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Updated labels of volume %v to %v", name, labels)
+	return v, nil
 }
 
 func (m *VolumeManager) Attach(name, nodeID string) (v *longhorn.Volume, err error) {
@@ -145,9 +580,23 @@ func (m *VolumeManager) Attach(name, nodeID string) (v *longhorn.Volume, err err
 	if v == nil {
 		return nil, fmt.Errorf("cannot find volume %v", name)
 	}
+	if len(v.Spec.AutoAttachedForJobs) != 0 {
+		// the workload takes over the volume attached for the recurring
+		// jobs, the engine will be restarted with the frontend
+		return m.takeOverAutoAttachedVolume(v, nodeID)
+	}
 	if v.Status.State != types.VolumeStateDetached {
 		return nil, fmt.Errorf("invalid state to attach %v: %v", name, v.Status.State)
 	}
+	if v.Spec.FromImage != "" && v.Status.ImportState != types.VolumeImportStateCompleted {
+		return nil, fmt.Errorf("cannot attach volume %v before the image import is completed, import state %v", name, v.Status.ImportState)
+	}
+	if v.Status.RestoreRequired {
+		return nil, fmt.Errorf("cannot attach volume %v before the restore from %v is completed", name, v.Spec.FromBackup)
+	}
+	if v.Spec.RecycledAt != "" {
+		return nil, fmt.Errorf("cannot attach volume %v in the recycle bin, recover it first", name)
+	}
 	// already desired to be attached
 	if v.Spec.NodeID != "" {
 		if v.Spec.NodeID != nodeID {
@@ -170,6 +619,23 @@ func (m *VolumeManager) Attach(name, nodeID string) (v *longhorn.Volume, err err
 	return v, nil
 }
 
+func (m *VolumeManager) takeOverAutoAttachedVolume(v *longhorn.Volume, nodeID string) (*longhorn.Volume, error) {
+	jobs := v.Spec.AutoAttachedForJobs
+	v, err := m.updateVolumeOwner(nodeID, v)
+	if err != nil {
+		return nil, err
+	}
+	v.Spec.NodeID = nodeID
+	v.Spec.DisableFrontend = false
+	v.Spec.AutoAttachedForJobs = nil
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Attaching volume %v to %v, taken over from recurring jobs %v", v.Name, v.Spec.NodeID, jobs)
+	return v, nil
+}
+
 func (m *VolumeManager) Detach(name string) (v *longhorn.Volume, err error) {
 	defer func() {
 		err = errors.Wrapf(err, "unable to detach volume %v", name)
@@ -185,6 +651,12 @@ func (m *VolumeManager) Detach(name string) (v *longhorn.Volume, err error) {
 	if v.Status.State != types.VolumeStateAttached && v.Status.State != types.VolumeStateAttaching {
 		return nil, fmt.Errorf("invalid state to detach %v: %v", v.Name, v.Status.State)
 	}
+	if isVolumeImporting(v) {
+		return nil, fmt.Errorf("cannot detach volume %v during the image import", v.Name)
+	}
+	if isVolumeRestoring(v) {
+		return nil, fmt.Errorf("cannot detach volume %v during the restore", v.Name)
+	}
 
 	oldNodeID := v.Spec.NodeID
 	if oldNodeID == "" {
@@ -199,6 +671,8 @@ func (m *VolumeManager) Detach(name string) (v *longhorn.Volume, err error) {
 	}
 
 	v.Spec.NodeID = ""
+	v.Spec.DisableFrontend = false
+	v.Spec.AutoAttachedForJobs = nil
 	v, err = m.ds.UpdateVolume(v)
 	if err != nil {
 		return nil, err
@@ -260,11 +734,11 @@ func (m *VolumeManager) UpdateRecurringJobs(volumeName string, jobs []types.Recu
 	}()
 
 	for _, job := range jobs {
-		if job.Cron == "" || job.Type == "" || job.Name == "" || job.Retain == 0 {
-			return nil, fmt.Errorf("invalid job %+v", job)
+		if err := checkRecurringJob(job); err != nil {
+			return nil, err
 		}
-		if len(job.Name) > types.MaximumJobNameSize {
-			return nil, fmt.Errorf("job name %v is too long, must be %v characters or less", job.Name, types.MaximumJobNameSize)
+		if err := m.checkBackupTargetExists(job.BackupTarget); err != nil {
+			return nil, errors.Wrapf(err, "invalid job %v", job.Name)
 		}
 	}
 
@@ -275,6 +749,11 @@ func (m *VolumeManager) UpdateRecurringJobs(volumeName string, jobs []types.Recu
 	if v == nil {
 		return nil, fmt.Errorf("cannot find volume %v", volumeName)
 	}
+	for _, job := range jobs {
+		if job.Type == types.RecurringJobTypeTrim && v.Spec.Frontend != types.VolumeFrontendBlockDev {
+			return nil, fmt.Errorf("cannot trim volume %v with frontend %v, only %v is supported", volumeName, v.Spec.Frontend, types.VolumeFrontendBlockDev)
+		}
+	}
 
 	v.Spec.RecurringJobs = jobs
 	v, err = m.ds.UpdateVolume(v)
@@ -285,6 +764,68 @@ func (m *VolumeManager) UpdateRecurringJobs(volumeName string, jobs []types.Recu
 	return v, nil
 }
 
+func checkRecurringJob(job types.RecurringJob) error {
+	if job.Cron == "" || job.Type == "" || job.Name == "" {
+		return fmt.Errorf("invalid job %+v", job)
+	}
+	if _, err := util.ParseCronSchedule(job.Cron); err != nil {
+		return errors.Wrapf(err, "invalid job %v", job.Name)
+	}
+	// trim job doesn't create anything to retain
+	if job.Retain == 0 && job.Retention.IsEmpty() && job.Type != types.RecurringJobTypeTrim {
+		return fmt.Errorf("invalid job %+v", job)
+	}
+	if job.Retention != nil {
+		if job.Type == types.RecurringJobTypeTrim {
+			return fmt.Errorf("retention is not supported by %v job %v", job.Type, job.Name)
+		}
+		if job.Retention.Hourly < 0 || job.Retention.Daily < 0 ||
+			job.Retention.Weekly < 0 || job.Retention.Monthly < 0 {
+			return fmt.Errorf("invalid retention %+v of job %v", *job.Retention, job.Name)
+		}
+	}
+	if job.BackupTarget != "" && job.Type != types.RecurringJobTypeBackup {
+		return fmt.Errorf("backup target is not supported by %v job %v", job.Type, job.Name)
+	}
+	if job.Freeze && job.Type == types.RecurringJobTypeTrim {
+		return fmt.Errorf("freeze is not supported by %v job %v", job.Type, job.Name)
+	}
+	if job.Hooks != nil {
+		if job.Type == types.RecurringJobTypeTrim {
+			return fmt.Errorf("hooks are not supported by %v job %v", job.Type, job.Name)
+		}
+		if err := checkSnapshotHooks(job.Hooks); err != nil {
+			return errors.Wrapf(err, "invalid job %v", job.Name)
+		}
+	}
+	if job.Type != types.RecurringJobTypeSnapshot &&
+		job.Type != types.RecurringJobTypeBackup &&
+		job.Type != types.RecurringJobTypeTrim {
+		return fmt.Errorf("invalid job type %v", job.Type)
+	}
+	if len(job.Name) > types.MaximumJobNameSize {
+		return fmt.Errorf("job name %v is too long, must be %v characters or less", job.Name, types.MaximumJobNameSize)
+	}
+	return nil
+}
+
+func checkSnapshotHooks(hooks *types.SnapshotHooks) error {
+	if hooks.FailurePolicy != "" &&
+		hooks.FailurePolicy != types.SnapshotHookFailurePolicyAbort &&
+		hooks.FailurePolicy != types.SnapshotHookFailurePolicyContinue {
+		return fmt.Errorf("invalid snapshot hook failure policy %v", hooks.FailurePolicy)
+	}
+	for _, hook := range append(append([]types.SnapshotHook{}, hooks.Pre...), hooks.Post...) {
+		if len(hook.Command) == 0 {
+			return fmt.Errorf("invalid snapshot hook %+v, command is required", hook)
+		}
+		if hook.Timeout < 0 {
+			return fmt.Errorf("invalid snapshot hook %+v, timeout cannot be negative", hook)
+		}
+	}
+	return nil
+}
+
 func (m *VolumeManager) DeleteReplica(replicaName string) error {
 	return m.ds.DeleteReplica(replicaName)
 }
@@ -375,3 +916,14 @@ func (m *VolumeManager) GetManagerNode() ([]*longhorn.Node, error) {
 	}
 	return nodeList, nil
 }
+
+func isVolumeImporting(v *longhorn.Volume) bool {
+	return v.Spec.FromImage != "" &&
+		v.Status.ImportState != types.VolumeImportStateCompleted &&
+		v.Status.ImportState != types.VolumeImportStateFailed
+}
+
+func isVolumeRestoring(v *longhorn.Volume) bool {
+	cond, ok := v.Status.Conditions[types.VolumeConditionTypeRestoring]
+	return ok && cond.Status == types.ConditionStatusTrue
+}
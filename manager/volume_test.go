@@ -0,0 +1,210 @@
+package manager
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+
+	. "gopkg.in/check.v1"
+)
+
+// newListedVolume returns the volume named name, created at created
+func newListedVolume(name string, size int64, state types.VolumeState, nodeID string, created time.Time) *longhorn.Volume {
+	v := newVolume(name)
+	v.Namespace = TestNamespace
+	v.CreationTimestamp = metav1.NewTime(created)
+	v.Spec.Size = size
+	v.Spec.NodeID = nodeID
+	v.Status.State = state
+	if state == types.VolumeStateAttached {
+		v.Status.Robustness = types.VolumeRobustnessHealthy
+		v.Status.CurrentImage = TestEngineImage
+	}
+	return v
+}
+
+func volumeNames(volumes []*longhorn.Volume) []string {
+	names := []string{}
+	for _, v := range volumes {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func (s *TestSuite) TestMatchVolumeListOptions(c *C) {
+	v := newListedVolume("vol-a", TestVolumeSize, types.VolumeStateAttached, TestNode1, time.Now())
+
+	testCases := map[string]struct {
+		opts   VolumeListOptions
+		expect bool
+	}{
+		"empty":                {VolumeListOptions{}, true},
+		"state":                {VolumeListOptions{State: types.VolumeStateAttached}, true},
+		"other state":          {VolumeListOptions{State: types.VolumeStateDetached}, false},
+		"robustness":           {VolumeListOptions{Robustness: types.VolumeRobustnessHealthy}, true},
+		"other robustness":     {VolumeListOptions{Robustness: types.VolumeRobustnessDegraded}, false},
+		"node":                 {VolumeListOptions{NodeID: TestNode1}, true},
+		"other node":           {VolumeListOptions{NodeID: TestNode2}, false},
+		"engine image":         {VolumeListOptions{EngineImage: TestEngineImage}, true},
+		"other engine image":   {VolumeListOptions{EngineImage: "longhorn-engine:v2"}, false},
+		"all matched":          {VolumeListOptions{State: types.VolumeStateAttached, NodeID: TestNode1}, true},
+		"one of all unmatched": {VolumeListOptions{State: types.VolumeStateAttached, NodeID: TestNode2}, false},
+	}
+	for name, tc := range testCases {
+		c.Logf("testing %v", name)
+		c.Assert(matchVolumeListOptions(v, &tc.opts), Equals, tc.expect)
+	}
+}
+
+func (s *TestSuite) TestSortVolumes(c *C) {
+	now := time.Now()
+	volumes := []*longhorn.Volume{
+		newListedVolume("vol-b", 2*TestVolumeSize, types.VolumeStateDetached, "", now.Add(-time.Hour)),
+		newListedVolume("vol-c", TestVolumeSize, types.VolumeStateAttached, TestNode1, now.Add(-2*time.Hour)),
+		newListedVolume("vol-a", 2*TestVolumeSize, types.VolumeStateAttached, TestNode2, now),
+	}
+
+	testCases := map[string]struct {
+		sortBy     string
+		descending bool
+		expect     []string
+		expectErr  string
+	}{
+		"default":              {"", false, []string{"vol-a", "vol-b", "vol-c"}, ""},
+		"name descending":      {VolumeSortByName, true, []string{"vol-c", "vol-b", "vol-a"}, ""},
+		"size with name ties":  {VolumeSortBySize, false, []string{"vol-c", "vol-a", "vol-b"}, ""},
+		"size descending":      {VolumeSortBySize, true, []string{"vol-b", "vol-a", "vol-c"}, ""},
+		"created":              {VolumeSortByCreated, false, []string{"vol-c", "vol-b", "vol-a"}, ""},
+		"state with name ties": {VolumeSortByState, false, []string{"vol-a", "vol-c", "vol-b"}, ""},
+		"invalid key":          {"robustness", false, nil, "invalid sort key robustness"},
+	}
+	for name, tc := range testCases {
+		c.Logf("testing %v", name)
+		sorted := append([]*longhorn.Volume{}, volumes...)
+		err := sortVolumes(sorted, tc.sortBy, tc.descending)
+		if tc.expectErr != "" {
+			c.Assert(err, ErrorMatches, tc.expectErr)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(volumeNames(sorted), DeepEquals, tc.expect)
+	}
+}
+
+func (s *TestSuite) TestListWithOptions(c *C) {
+	env := newManagerTestEnv(c)
+	now := time.Now()
+	for i, v := range []*longhorn.Volume{
+		newListedVolume("vol-a", TestVolumeSize, types.VolumeStateAttached, TestNode1, now),
+		newListedVolume("vol-b", 3*TestVolumeSize, types.VolumeStateDetached, "", now),
+		newListedVolume("vol-c", 2*TestVolumeSize, types.VolumeStateAttached, TestNode2, now),
+		newListedVolume("vol-d", 4*TestVolumeSize, types.VolumeStateAttached, TestNode1, now),
+		newListedVolume("vol-e", 5*TestVolumeSize, types.VolumeStateDetached, "", now),
+	} {
+		if i%2 == 0 {
+			v.Labels = map[string]string{"app": "db"}
+		}
+		err := env.vIndexer.Add(v)
+		c.Assert(err, IsNil)
+	}
+
+	testCases := map[string]struct {
+		opts        VolumeListOptions
+		expect      []string
+		expectTotal int
+		expectErr   string
+	}{
+		"all": {
+			VolumeListOptions{},
+			[]string{"vol-a", "vol-b", "vol-c", "vol-d", "vol-e"}, 5, "",
+		},
+		"label selector": {
+			VolumeListOptions{LabelSelector: labels.SelectorFromSet(labels.Set{"app": "db"})},
+			[]string{"vol-a", "vol-c", "vol-e"}, 3, "",
+		},
+		"state and node": {
+			VolumeListOptions{State: types.VolumeStateAttached, NodeID: TestNode1},
+			[]string{"vol-a", "vol-d"}, 2, "",
+		},
+		"sorted by size descending": {
+			VolumeListOptions{SortBy: VolumeSortBySize, Descending: true},
+			[]string{"vol-e", "vol-d", "vol-b", "vol-c", "vol-a"}, 5, "",
+		},
+		"first page": {
+			VolumeListOptions{Limit: 2},
+			[]string{"vol-a", "vol-b"}, 5, "",
+		},
+		"middle page": {
+			VolumeListOptions{Marker: 2, Limit: 2},
+			[]string{"vol-c", "vol-d"}, 5, "",
+		},
+		"last page": {
+			VolumeListOptions{Marker: 4, Limit: 2},
+			[]string{"vol-e"}, 5, "",
+		},
+		"marker past the end": {
+			VolumeListOptions{Marker: 10, Limit: 2},
+			[]string{}, 5, "",
+		},
+		"filtered page": {
+			VolumeListOptions{State: types.VolumeStateAttached, SortBy: VolumeSortBySize, Marker: 1, Limit: 1},
+			[]string{"vol-c"}, 3, "",
+		},
+		"invalid sort key": {
+			VolumeListOptions{SortBy: "replicas"},
+			nil, 0, "invalid sort key replicas",
+		},
+	}
+	for name, tc := range testCases {
+		c.Logf("testing %v", name)
+		volumes, total, err := env.m.ListWithOptions(&tc.opts)
+		if tc.expectErr != "" {
+			c.Assert(err, ErrorMatches, tc.expectErr)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(volumeNames(volumes), DeepEquals, tc.expect)
+		c.Assert(total, Equals, tc.expectTotal)
+	}
+}
+
+func (s *TestSuite) TestRetryRestore(c *C) {
+	env := newManagerTestEnv(c)
+	v := newVolume("vol-restored")
+	v.Spec.FromBackup = "s3://backupbucket@us-east-1/backupstore?backup=backup-1&volume=vol"
+	v.Status.State = types.VolumeStateDetached
+	v.Status.RestoreRequired = true
+	v.Status.RestoreStatus = types.RestoreStatus{Progress: 50, Error: "backup not found"}
+	v.Status.Conditions = map[string]types.Condition{
+		types.VolumeConditionTypeRestoring: {
+			Type:    types.VolumeConditionTypeRestoring,
+			Status:  types.ConditionStatusFalse,
+			Reason:  types.VolumeConditionReasonRestoreFailure,
+			Message: "backup not found",
+		},
+	}
+	env.addVolume(v, c)
+
+	// attach is refused until the restore is completed
+	_, err := env.m.Attach(v.Name, TestNode1)
+	c.Assert(err, ErrorMatches, ".*cannot attach volume vol-restored before the restore .* is completed")
+
+	v, err = env.m.RetryRestore(v.Name)
+	c.Assert(err, IsNil)
+	cond := v.Status.Conditions[types.VolumeConditionTypeRestoring]
+	c.Assert(cond.Status, Equals, types.ConditionStatusTrue)
+	c.Assert(cond.Reason, Equals, types.VolumeConditionReasonRestoreInProgress)
+	c.Assert(v.Status.RestoreStatus, DeepEquals, types.RestoreStatus{})
+	c.Assert(v.Status.RestoreRequired, Equals, true)
+
+	// only the failed restore can be retried
+	err = env.vIndexer.Update(v)
+	c.Assert(err, IsNil)
+	_, err = env.m.RetryRestore(v.Name)
+	c.Assert(err, ErrorMatches, ".*the restore didn't fail")
+}
@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/rancher/longhorn-manager/datastore"
+	"github.com/rancher/longhorn-manager/types"
+
+	longhorn "github.com/rancher/longhorn-manager/k8s/pkg/apis/longhorn/v1alpha1"
+)
+
+var (
+	volumeStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "volume", "state"),
+		"State of the volume, the value is 1 for the current state",
+		[]string{LabelVolume, LabelNode, LabelEngineImage, LabelState}, nil)
+	volumeRobustnessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "volume", "robustness"),
+		"Robustness of the volume, the value is 1 for the current robustness",
+		[]string{LabelVolume, LabelNode, LabelEngineImage, LabelRobustness}, nil)
+	volumeSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "volume", "size_bytes"),
+		"Configured size of the volume",
+		[]string{LabelVolume, LabelNode, LabelEngineImage}, nil)
+	volumeActualSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "volume", "actual_size_bytes"),
+		"Disk space used by the snapshots and the volume head of the volume",
+		[]string{LabelVolume, LabelNode, LabelEngineImage}, nil)
+	volumeReplicasDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "volume", "replicas"),
+		"Number of replicas of the volume by the replica state",
+		[]string{LabelVolume, LabelNode, LabelEngineImage, LabelState}, nil)
+	volumeReplicasDesiredDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "volume", "replicas_desired"),
+		"Number of replicas configured for the volume",
+		[]string{LabelVolume, LabelNode, LabelEngineImage}, nil)
+	engineImageRefCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "engine_image", "ref_count"),
+		"Number of volumes using the engine image",
+		[]string{LabelEngineImage, LabelState}, nil)
+	nodeStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "node", "state"),
+		"State of the node, the value is 1 for the current state",
+		[]string{LabelNode, LabelState}, nil)
+	nodeStorageMaximumDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "node", "storage_maximum_bytes"),
+		"Disk space of the filesystem holding the replicas on the node",
+		[]string{LabelNode}, nil)
+	nodeStorageAvailableDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "node", "storage_available_bytes"),
+		"Available disk space of the filesystem holding the replicas on the node",
+		[]string{LabelNode}, nil)
+	nodeStorageUsedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "node", "storage_used_bytes"),
+		"Disk space used by the replicas on the node",
+		[]string{LabelNode}, nil)
+
+	volumeStates = []types.VolumeState{
+		types.VolumeStateAttached,
+		types.VolumeStateDetached,
+		types.VolumeStateAttaching,
+		types.VolumeStateDetaching,
+		types.VolumeStateDeleting,
+	}
+	volumeRobustnesses = []types.VolumeRobustness{
+		types.VolumeRobustnessHealthy,
+		types.VolumeRobustnessDegraded,
+		types.VolumeRobustnessFaulted,
+		types.VolumeRobustnessUnknown,
+	}
+	replicaStates = []types.InstanceState{
+		types.InstanceStateRunning,
+		types.InstanceStateStopped,
+		types.InstanceStateError,
+		types.InstanceStateStarting,
+		types.InstanceStateStopping,
+	}
+	nodeStates = []types.NodeState{
+		types.NodeStateUp,
+		types.NodeStateDown,
+	}
+)
+
+// Collector reports the state of the Longhorn resources from the datastore
+// at scrape time. Every manager only reports the resources it owns, so the
+// series won't be duplicated when all the managers are scraped. The nodes
+// are all reported by one manager, so the down nodes are reported too.
+type Collector struct {
+	controllerID string
+	ds           *datastore.DataStore
+}
+
+func NewCollector(controllerID string, ds *datastore.DataStore) *Collector {
+	return &Collector{
+		controllerID: controllerID,
+		ds:           ds,
+	}
+}
+
+// RegisterCollector starts reporting the resources owned by the manager
+func RegisterCollector(controllerID string, ds *datastore.DataStore) error {
+	if err := prometheus.Register(NewCollector(controllerID, ds)); err != nil {
+		return errors.Wrapf(err, "cannot register metrics collector")
+	}
+	return nil
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- volumeStateDesc
+	ch <- volumeRobustnessDesc
+	ch <- volumeSizeDesc
+	ch <- volumeActualSizeDesc
+	ch <- volumeReplicasDesc
+	ch <- volumeReplicasDesiredDesc
+	ch <- engineImageRefCountDesc
+	ch <- nodeStateDesc
+	ch <- nodeStorageMaximumDesc
+	ch <- nodeStorageAvailableDesc
+	ch <- nodeStorageUsedDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	if err := c.collectVolumes(ch); err != nil {
+		logrus.Warnf("Failed to collect volume metrics: %v", err)
+	}
+	if err := c.collectEngineImages(ch); err != nil {
+		logrus.Warnf("Failed to collect engine image metrics: %v", err)
+	}
+	if err := c.collectNodes(ch); err != nil {
+		logrus.Warnf("Failed to collect node metrics: %v", err)
+	}
+}
+
+func (c *Collector) collectVolumes(ch chan<- prometheus.Metric) error {
+	volumes, err := c.ds.ListVolumes()
+	if err != nil {
+		return err
+	}
+	replicas, err := c.ds.ListReplicasRO()
+	if err != nil {
+		return err
+	}
+	volumeReplicaStates := map[string]map[types.InstanceState]int{}
+	for _, r := range replicas {
+		if volumeReplicaStates[r.Spec.VolumeName] == nil {
+			volumeReplicaStates[r.Spec.VolumeName] = map[types.InstanceState]int{}
+		}
+		volumeReplicaStates[r.Spec.VolumeName][r.Status.CurrentState]++
+	}
+
+	for _, v := range volumes {
+		if v.Spec.OwnerID != c.controllerID {
+			continue
+		}
+		labels := []string{v.Name, v.Spec.NodeID, v.Status.CurrentImage}
+		for _, state := range volumeStates {
+			ch <- prometheus.MustNewConstMetric(volumeStateDesc, prometheus.GaugeValue,
+				boolToFloat64(v.Status.State == state), append(labels, string(state))...)
+		}
+		for _, robustness := range volumeRobustnesses {
+			ch <- prometheus.MustNewConstMetric(volumeRobustnessDesc, prometheus.GaugeValue,
+				boolToFloat64(v.Status.Robustness == robustness), append(labels, string(robustness))...)
+		}
+		ch <- prometheus.MustNewConstMetric(volumeSizeDesc, prometheus.GaugeValue, float64(v.Spec.Size), labels...)
+		ch <- prometheus.MustNewConstMetric(volumeActualSizeDesc, prometheus.GaugeValue, float64(v.Status.ActualSize), labels...)
+		for _, state := range replicaStates {
+			ch <- prometheus.MustNewConstMetric(volumeReplicasDesc, prometheus.GaugeValue,
+				float64(volumeReplicaStates[v.Name][state]), append(labels, string(state))...)
+		}
+		ch <- prometheus.MustNewConstMetric(volumeReplicasDesiredDesc, prometheus.GaugeValue, float64(v.Spec.NumberOfReplicas), labels...)
+	}
+	return nil
+}
+
+func (c *Collector) collectEngineImages(ch chan<- prometheus.Metric) error {
+	engineImages, err := c.ds.ListEngineImages()
+	if err != nil {
+		return err
+	}
+	for _, ei := range engineImages {
+		if ei.Spec.OwnerID != c.controllerID {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(engineImageRefCountDesc, prometheus.GaugeValue,
+			float64(ei.Status.RefCount), ei.Spec.Image, string(ei.Status.State))
+	}
+	return nil
+}
+
+// collectNodes reports all the nodes if the manager is the node reporter
+func (c *Collector) collectNodes(ch chan<- prometheus.Metric) error {
+	nodes, err := c.ds.ListNodes()
+	if err != nil {
+		return err
+	}
+	if getNodeReporter(nodes) != c.controllerID {
+		return nil
+	}
+	for _, node := range nodes {
+		for _, state := range nodeStates {
+			ch <- prometheus.MustNewConstMetric(nodeStateDesc, prometheus.GaugeValue,
+				boolToFloat64(node.Status.State == state), node.Name, string(state))
+		}
+		ch <- prometheus.MustNewConstMetric(nodeStorageMaximumDesc, prometheus.GaugeValue, float64(node.Status.StorageMaximum), node.Name)
+		ch <- prometheus.MustNewConstMetric(nodeStorageAvailableDesc, prometheus.GaugeValue, float64(node.Status.StorageAvailable), node.Name)
+		ch <- prometheus.MustNewConstMetric(nodeStorageUsedDesc, prometheus.GaugeValue, float64(node.Status.StorageUsed), node.Name)
+	}
+	return nil
+}
+
+// getNodeReporter elects the manager reporting the nodes, the up node first
+// by name. Every manager elects the same one from the same node states.
+func getNodeReporter(nodes []*longhorn.Node) string {
+	reporter := ""
+	for _, node := range nodes {
+		if node.Status.State != types.NodeStateUp {
+			continue
+		}
+		if reporter == "" || node.Name < reporter {
+			reporter = node.Name
+		}
+	}
+	return reporter
+}
+
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	Namespace = "longhorn"
+
+	LabelVolume      = "volume"
+	LabelNode        = "node"
+	LabelEngineImage = "engine_image"
+	LabelState       = "state"
+	LabelRobustness  = "robustness"
+	LabelQueue       = "queue"
+)
+
+var (
+	ReplicaRebuildTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "replica",
+		Name:      "rebuild_total",
+		Help:      "Number of replica rebuilds started for the volume",
+	}, []string{LabelVolume, LabelNode, LabelEngineImage})
+
+	SnapshotCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "volume",
+		Name:      "snapshot_count",
+		Help:      "Number of snapshots of the volume, including the volume head",
+	}, []string{LabelVolume, LabelNode, LabelEngineImage})
+
+	SnapshotActualSizeBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "volume",
+		Name:      "snapshot_actual_size_bytes",
+		Help:      "Disk space used by the snapshots of the volume",
+	}, []string{LabelVolume, LabelNode, LabelEngineImage})
+
+	BackupDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "backup",
+		Name:      "duration_seconds",
+		Help:      "Time taken to back up a snapshot of the volume",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{LabelVolume, LabelNode, LabelEngineImage})
+
+	BackupFailureTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "backup",
+		Name:      "failure_total",
+		Help:      "Number of failed backups of the volume",
+	}, []string{LabelVolume, LabelNode, LabelEngineImage})
+)
+
+func init() {
+	prometheus.MustRegister(ReplicaRebuildTotal)
+	prometheus.MustRegister(SnapshotCount)
+	prometheus.MustRegister(SnapshotActualSizeBytes)
+	prometheus.MustRegister(BackupDurationSeconds)
+	prometheus.MustRegister(BackupFailureTotal)
+
+	// the provider has to be set before any queue is created
+	workqueue.SetProvider(newWorkqueueMetricsProvider())
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return prometheus.Handler()
+}
+
+// DeleteVolumeMetrics drops the series of the volume, e.g. after the
+// volume was detached from the node so it's no longer reported here
+func DeleteVolumeMetrics(volume, node, engineImage string) {
+	labels := prometheus.Labels{
+		LabelVolume:      volume,
+		LabelNode:        node,
+		LabelEngineImage: engineImage,
+	}
+	SnapshotCount.Delete(labels)
+	SnapshotActualSizeBytes.Delete(labels)
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// workqueueMetricsProvider exports the metrics of the controller queues,
+// labelled by the queue name. The workqueue observes the latencies in
+// microseconds.
+type workqueueMetricsProvider struct {
+	depth        *prometheus.GaugeVec
+	adds         *prometheus.CounterVec
+	latency      *prometheus.SummaryVec
+	workDuration *prometheus.SummaryVec
+	retries      *prometheus.CounterVec
+}
+
+func newWorkqueueMetricsProvider() *workqueueMetricsProvider {
+	p := &workqueueMetricsProvider{
+		depth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: "workqueue",
+			Name:      "depth",
+			Help:      "Current depth of the controller workqueue",
+		}, []string{LabelQueue}),
+		adds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "workqueue",
+			Name:      "adds_total",
+			Help:      "Number of adds handled by the controller workqueue",
+		}, []string{LabelQueue}),
+		latency: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Namespace: Namespace,
+			Subsystem: "workqueue",
+			Name:      "queue_latency_microseconds",
+			Help:      "How long an item stays in the controller workqueue before being processed",
+		}, []string{LabelQueue}),
+		workDuration: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Namespace: Namespace,
+			Subsystem: "workqueue",
+			Name:      "work_duration_microseconds",
+			Help:      "How long processing an item from the controller workqueue takes",
+		}, []string{LabelQueue}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "workqueue",
+			Name:      "retries_total",
+			Help:      "Number of retries handled by the controller workqueue",
+		}, []string{LabelQueue}),
+	}
+	prometheus.MustRegister(p.depth, p.adds, p.latency, p.workDuration, p.retries)
+	return p
+}
+
+func (p *workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return p.depth.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return p.adds.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return p.latency.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return p.workDuration.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return p.retries.WithLabelValues(name)
+}
@@ -2,12 +2,58 @@ package types
 
 func (v *VolumeSpec) DeepCopyInto(to *VolumeSpec) {
 	*to = *v
+	if v.AutoAttachedForJobs != nil {
+		to.AutoAttachedForJobs = make(map[string]string, len(v.AutoAttachedForJobs))
+		for job, attachedAt := range v.AutoAttachedForJobs {
+			to.AutoAttachedForJobs[job] = attachedAt
+		}
+	}
+	to.SnapshotHooks = v.SnapshotHooks.DeepCopy()
 	if v.RecurringJobs == nil {
 		return
 	}
 	to.RecurringJobs = make([]RecurringJob, len(v.RecurringJobs))
 	for i := 0; i < len(v.RecurringJobs); i++ {
-		to.RecurringJobs[i] = v.RecurringJobs[i]
+		v.RecurringJobs[i].DeepCopyInto(&to.RecurringJobs[i])
+	}
+}
+
+func (r *RecurringJob) DeepCopyInto(to *RecurringJob) {
+	*to = *r
+	if r.Retention != nil {
+		retention := *r.Retention
+		to.Retention = &retention
+	}
+	to.Hooks = r.Hooks.DeepCopy()
+}
+
+func (h *SnapshotHooks) DeepCopy() *SnapshotHooks {
+	if h == nil {
+		return nil
+	}
+	to := &SnapshotHooks{
+		FailurePolicy: h.FailurePolicy,
+	}
+	if h.Pre != nil {
+		to.Pre = make([]SnapshotHook, len(h.Pre))
+		for i := range h.Pre {
+			h.Pre[i].DeepCopyInto(&to.Pre[i])
+		}
+	}
+	if h.Post != nil {
+		to.Post = make([]SnapshotHook, len(h.Post))
+		for i := range h.Post {
+			h.Post[i].DeepCopyInto(&to.Post[i])
+		}
+	}
+	return to
+}
+
+func (h *SnapshotHook) DeepCopyInto(to *SnapshotHook) {
+	*to = *h
+	if h.Command != nil {
+		to.Command = make([]string, len(h.Command))
+		copy(to.Command, h.Command)
 	}
 }
 
@@ -24,11 +70,120 @@ func (e *EngineSpec) DeepCopyInto(to *EngineSpec) {
 
 func (e *EngineStatus) DeepCopyInto(to *EngineStatus) {
 	*to = *e
-	if e.ReplicaModeMap == nil {
+	if e.ReplicaModeMap != nil {
+		to.ReplicaModeMap = make(map[string]ReplicaMode)
+		for key, value := range e.ReplicaModeMap {
+			to.ReplicaModeMap[key] = value
+		}
+	}
+	if e.RebuildStatus != nil {
+		to.RebuildStatus = make(map[string]RebuildStatus)
+		for key, value := range e.RebuildStatus {
+			to.RebuildStatus[key] = value
+		}
+	}
+}
+
+func (b *BackingImageStatus) DeepCopyInto(to *BackingImageStatus) {
+	*to = *b
+	if b.DiskStatusMap == nil {
 		return
 	}
-	to.ReplicaModeMap = make(map[string]ReplicaMode)
-	for key, value := range e.ReplicaModeMap {
-		to.ReplicaModeMap[key] = value
+	to.DiskStatusMap = make(map[string]BackingImageDiskStatus)
+	for key, value := range b.DiskStatusMap {
+		to.DiskStatusMap[key] = value
+	}
+}
+
+func (v *VolumeStatus) DeepCopyInto(to *VolumeStatus) {
+	*to = *v
+	if v.Conditions != nil {
+		to.Conditions = make(map[string]Condition)
+		for key, value := range v.Conditions {
+			to.Conditions[key] = value
+		}
+	}
+	if v.RecurringJobStatus != nil {
+		to.RecurringJobStatus = make(map[string]RecurringJobRunStatus)
+		for key, value := range v.RecurringJobStatus {
+			if value.Runs != nil {
+				runs := make([]RecurringJobRun, len(value.Runs))
+				copy(runs, value.Runs)
+				value.Runs = runs
+			}
+			to.RecurringJobStatus[key] = value
+		}
+	}
+}
+
+func (n *NodeStatus) DeepCopyInto(to *NodeStatus) {
+	*to = *n
+	if n.ReplicaSizeMap == nil {
+		return
+	}
+	to.ReplicaSizeMap = make(map[string]int64)
+	for key, value := range n.ReplicaSizeMap {
+		to.ReplicaSizeMap[key] = value
+	}
+}
+
+func (r *RecurringJobSpec) DeepCopyInto(to *RecurringJobSpec) {
+	*to = *r
+	if r.Retention != nil {
+		retention := *r.Retention
+		to.Retention = &retention
+	}
+	to.Hooks = r.Hooks.DeepCopy()
+	if r.Selector != nil {
+		to.Selector = make(map[string]string)
+		for key, value := range r.Selector {
+			to.Selector[key] = value
+		}
+	}
+	if r.Groups != nil {
+		to.Groups = make([]string, len(r.Groups))
+		copy(to.Groups, r.Groups)
+	}
+}
+
+func (r *RecurringJobStatus) DeepCopyInto(to *RecurringJobStatus) {
+	*to = *r
+	if r.Volumes != nil {
+		to.Volumes = make([]string, len(r.Volumes))
+		copy(to.Volumes, r.Volumes)
+	}
+	if r.Running != nil {
+		to.Running = make(map[string]string)
+		for key, value := range r.Running {
+			to.Running[key] = value
+		}
+	}
+}
+
+func (s *SnapshotGroupSpec) DeepCopyInto(to *SnapshotGroupSpec) {
+	*to = *s
+	if s.Volumes != nil {
+		to.Volumes = make([]string, len(s.Volumes))
+		copy(to.Volumes, s.Volumes)
+	}
+}
+
+func (b *BackupStatus) DeepCopyInto(to *BackupStatus) {
+	*to = *b
+	if b.Labels != nil {
+		to.Labels = make(map[string]string)
+		for key, value := range b.Labels {
+			to.Labels[key] = value
+		}
+	}
+}
+
+func (b *BackupOperationSpec) DeepCopyInto(to *BackupOperationSpec) {
+	*to = *b
+	if b.Labels != nil {
+		to.Labels = make(map[string]string)
+		for key, value := range b.Labels {
+			to.Labels[key] = value
+		}
 	}
 }
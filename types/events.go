@@ -0,0 +1,51 @@
+package types
+
+const (
+	EventReasonCreate         = "Create"
+	EventReasonFailedCreating = "FailedCreating"
+	EventReasonDelete         = "Delete"
+	EventReasonFailedDeleting = "FailedDeleting"
+	EventReasonStart          = "Start"
+	EventReasonFailedStarting = "FailedStarting"
+	EventReasonStop           = "Stop"
+	EventReasonFailedStopping = "FailedStopping"
+
+	EventReasonRebuilded        = "Rebuilded"
+	EventReasonRebuilding       = "Rebuilding"
+	EventReasonFailedRebuilding = "FailedRebuilding"
+	EventReasonRebuildStalled   = "RebuildStalled"
+
+	EventReasonAttached = "Attached"
+	EventReasonDetached = "Detached"
+	EventReasonHealthy  = "Healthy"
+	EventReasonFaulted  = "Faulted"
+	EventReasonDegraded = "Degraded"
+
+	EventReasonImporting       = "Importing"
+	EventReasonImported        = "Imported"
+	EventReasonFailedImporting = "FailedImporting"
+	EventReasonRetryImport     = "RetryImport"
+
+	EventReasonRestoring       = "Restoring"
+	EventReasonRestored        = "Restored"
+	EventReasonFailedRestoring = "FailedRestoring"
+	EventReasonRetryRestore    = "RetryRestore"
+
+	EventReasonNodeUp   = "NodeUp"
+	EventReasonNodeDown = "NodeDown"
+
+	EventReasonRecurringJobFailed    = "RecurringJobFailed"
+	EventReasonRecurringJobRecovered = "RecurringJobRecovered"
+	EventReasonMissedRecurringJob    = "MissedRecurringJob"
+	EventReasonFailedSnapshotHook    = "FailedSnapshotHook"
+
+	EventReasonBackupTargetAvailable   = "BackupTargetAvailable"
+	EventReasonBackupTargetUnavailable = "BackupTargetUnavailable"
+	EventReasonFailedBackup            = "FailedBackup"
+	EventReasonFailedExport            = "FailedExport"
+
+	EventReasonTrimmed        = "Trimmed"
+	EventReasonFailedTrimming = "FailedTrimming"
+	EventReasonRecycled       = "Recycled"
+	EventReasonRecovered      = "Recovered"
+)
@@ -26,23 +26,139 @@ const (
 	VolumeFrontendISCSI    = VolumeFrontend("iscsi")
 )
 
+type VolumeImageFormat string
+
+const (
+	VolumeImageFormatRaw   = VolumeImageFormat("raw")
+	VolumeImageFormatQcow2 = VolumeImageFormat("qcow2")
+)
+
+type VolumeImportState string
+
+const (
+	VolumeImportStatePending   = VolumeImportState("pending")
+	VolumeImportStateImporting = VolumeImportState("importing")
+	VolumeImportStateCompleted = VolumeImportState("completed")
+	VolumeImportStateFailed    = VolumeImportState("failed")
+)
+
+type ConditionStatus string
+
+const (
+	ConditionStatusTrue    = ConditionStatus("True")
+	ConditionStatusFalse   = ConditionStatus("False")
+	ConditionStatusUnknown = ConditionStatus("Unknown")
+)
+
+type Condition struct {
+	Type               string          `json:"type"`
+	Status             ConditionStatus `json:"status"`
+	LastTransitionTime string          `json:"lastTransitionTime"`
+	Reason             string          `json:"reason"`
+	Message            string          `json:"message"`
+}
+
+const (
+	VolumeConditionTypeRestoring = "Restoring"
+
+	VolumeConditionReasonRestoreInProgress = "RestoreInProgress"
+	VolumeConditionReasonRestoreCompleted  = "RestoreCompleted"
+	VolumeConditionReasonRestoreFailure    = "RestoreFailure"
+
+	VolumeConditionTypeRecurringJobFailing = "RecurringJobFailing"
+
+	VolumeConditionReasonRecurringJobFailed    = "RecurringJobFailed"
+	VolumeConditionReasonRecurringJobRecovered = "RecurringJobRecovered"
+)
+
+type RestoreStatus struct {
+	IsRestoring   bool   `json:"isRestoring"`
+	Progress      int    `json:"progress"`
+	RestoredBytes int64  `json:"restoredBytes,string"`
+	CurrentBackup string `json:"currentBackup"`
+	Error         string `json:"error"`
+}
+
 type VolumeSpec struct {
-	OwnerID             string         `json:"ownerID"`
-	Size                int64          `json:"size,string"`
-	Frontend            VolumeFrontend `json:"frontend"`
-	FromBackup          string         `json:"fromBackup"`
-	NumberOfReplicas    int            `json:"numberOfReplicas"`
-	StaleReplicaTimeout int            `json:"staleReplicaTimeout"`
-	NodeID              string         `json:"nodeID"`
-	EngineImage         string         `json:"engineImage"`
-	RecurringJobs       []RecurringJob `json:"recurringJobs"`
+	OwnerID             string            `json:"ownerID"`
+	Size                int64             `json:"size,string"`
+	Frontend            VolumeFrontend    `json:"frontend"`
+	FromBackup          string            `json:"fromBackup"`
+	FromImage           string            `json:"fromImage"`
+	FromImageFormat     VolumeImageFormat `json:"fromImageFormat"`
+	FromImageNodeID     string            `json:"fromImageNodeID"`
+	NumberOfReplicas    int               `json:"numberOfReplicas"`
+	StaleReplicaTimeout int               `json:"staleReplicaTimeout"`
+	NodeID              string            `json:"nodeID"`
+	EngineImage         string            `json:"engineImage"`
+	BackingImage        string            `json:"backingImage"`
+	RecurringJobs       []RecurringJob    `json:"recurringJobs"`
+	// DeletionProtection must be cleared before the volume can be deleted
+	DeletionProtection bool `json:"deletionProtection"`
+	// RecycledAt is set when the volume was deleted into the recycle bin.
+	// The volume is kept detached and will be removed after
+	// SettingsInfo.RecycleBinRetention hours unless it's recovered.
+	RecycledAt string `json:"recycledAt"`
+	// DisableFrontend starts the engine without the frontend, e.g. the
+	// volume is attached for the recurring jobs only
+	DisableFrontend bool `json:"disableFrontend"`
+	// AutoAttachedForJobs are the recurring jobs running on the volume
+	// which was attached by them temporarily without the frontend, with
+	// the time each job attached it. It's cleared once a workload
+	// attached the volume. The jobs not done after RecurringJobRunTimeout
+	// are dropped, in case the job pod died without detaching.
+	AutoAttachedForJobs map[string]string `json:"autoAttachedForJobs"`
+	// DisableAutoAttach prevents the recurring jobs from attaching the
+	// detached volume, the jobs won't run until the volume is attached
+	DisableAutoAttach bool `json:"disableAutoAttach"`
+	// SnapshotHooks run in the workload pods using the volume around the
+	// snapshots, unless the recurring job has its own hooks
+	SnapshotHooks *SnapshotHooks `json:"snapshotHooks,omitempty"`
 }
 
 type VolumeStatus struct {
-	State        VolumeState      `json:"state"`
-	Robustness   VolumeRobustness `json:"robustness"`
-	Endpoint     string           `json:"endpoint"`
-	CurrentImage string           `json:"currentImage"`
+	State          VolumeState       `json:"state"`
+	Robustness     VolumeRobustness  `json:"robustness"`
+	Endpoint       string            `json:"endpoint"`
+	CurrentImage   string            `json:"currentImage"`
+	ImportState    VolumeImportState `json:"importState"`
+	ImportProgress int               `json:"importProgress"`
+	ImportError    string            `json:"importError"`
+	// RestoreRequired is set until the restore from Spec.FromBackup
+	// completed. The restore runs while the volume is attached for the
+	// first time, and again on the next attach if it failed
+	RestoreRequired bool                 `json:"restoreRequired"`
+	RestoreStatus   RestoreStatus        `json:"restoreStatus"`
+	Conditions      map[string]Condition `json:"conditions"`
+	// ActualSize is the disk space used by the snapshots and the volume
+	// head, which can be less than Size since the replicas are sparse
+	ActualSize int64 `json:"actualSize,string"`
+	// RecurringJobStatus is keyed by the recurring job name, recorded by
+	// the job pods
+	RecurringJobStatus map[string]RecurringJobRunStatus `json:"recurringJobStatus"`
+}
+
+type RecurringJobRunResult string
+
+const (
+	RecurringJobRunResultSucceeded = RecurringJobRunResult("succeeded")
+	RecurringJobRunResultFailed    = RecurringJobRunResult("failed")
+)
+
+type RecurringJobRun struct {
+	StartTime string                `json:"startTime"`
+	EndTime   string                `json:"endTime"`
+	Result    RecurringJobRunResult `json:"result"`
+	Snapshot  string                `json:"snapshot"`
+	Backup    string                `json:"backup"`
+	Error     string                `json:"error"`
+}
+
+type RecurringJobRunStatus struct {
+	// Runs is ordered from the newest, at most
+	// SettingsInfo.RecurringJobHistoryLimit runs are kept
+	Runs                []RecurringJobRun `json:"runs"`
+	ConsecutiveFailures int               `json:"consecutiveFailures"`
 }
 
 type RecurringJobType string
@@ -50,6 +166,16 @@ type RecurringJobType string
 const (
 	RecurringJobTypeSnapshot = RecurringJobType("snapshot")
 	RecurringJobTypeBackup   = RecurringJobType("backup")
+	RecurringJobTypeTrim     = RecurringJobType("trim")
+)
+
+type RecurringJobMode string
+
+const (
+	// RecurringJobModeCronJob creates a CronJob for each job of each volume
+	RecurringJobModeCronJob = RecurringJobMode("cronjob")
+	// RecurringJobModeManager runs the jobs in the manager owning the volume
+	RecurringJobModeManager = RecurringJobMode("manager")
 )
 
 type RecurringJob struct {
@@ -57,6 +183,67 @@ type RecurringJob struct {
 	Type   RecurringJobType `json:"task"`
 	Cron   string           `json:"cron"`
 	Retain int              `json:"retain"`
+	// Retention keeps the snapshots or backups in addition to the newest
+	// Retain ones
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+	// Freeze freezes the filesystem on the volume while taking the
+	// snapshot, see engineapi.SnapshotCreateWithFreeze
+	Freeze bool `json:"freeze"`
+	// Hooks override VolumeSpec.SnapshotHooks for the job
+	Hooks *SnapshotHooks `json:"hooks,omitempty"`
+	// BackupTarget is the name of the BackupTarget of the backup job, the
+	// default target if it's empty
+	BackupTarget string `json:"backupTarget"`
+}
+
+type SnapshotHookFailurePolicy string
+
+const (
+	// SnapshotHookFailurePolicyAbort skips the snapshot if any pre hook
+	// failed, and fails the snapshot if any post hook failed
+	SnapshotHookFailurePolicyAbort = SnapshotHookFailurePolicy("abort")
+	// SnapshotHookFailurePolicyContinue records the failures and takes the
+	// snapshot anyway
+	SnapshotHookFailurePolicyContinue = SnapshotHookFailurePolicy("continue")
+)
+
+// SnapshotHook is a command executed in the pods mounting the volume, e.g. to
+// flush and quiesce a database before the snapshot
+type SnapshotHook struct {
+	// Container defaults to the first container of the pod
+	Container string   `json:"container"`
+	Command   []string `json:"command"`
+	// Timeout is in seconds, 0 means SnapshotHookDefaultTimeout
+	Timeout int `json:"timeout"`
+}
+
+// SnapshotHooks are executed in order in each pod mounting the volume. The
+// Post hooks always run once any Pre hook ran, so they should undo the Pre
+// hooks regardless of how far those went.
+type SnapshotHooks struct {
+	Pre  []SnapshotHook `json:"pre"`
+	Post []SnapshotHook `json:"post"`
+	// FailurePolicy defaults to SnapshotHookFailurePolicyAbort
+	FailurePolicy SnapshotHookFailurePolicy `json:"failurePolicy"`
+}
+
+func (h *SnapshotHooks) IsEmpty() bool {
+	return h == nil || (len(h.Pre) == 0 && len(h.Post) == 0)
+}
+
+// RetentionPolicy is the grandfather-father-son retention. For each tier, the
+// newest copy of each of the most recent periods is kept, e.g. Daily 7 keeps
+// the newest copy of each of the last 7 days having any. A copy is kept if
+// any tier keeps it.
+type RetentionPolicy struct {
+	Hourly  int `json:"hourly"`
+	Daily   int `json:"daily"`
+	Weekly  int `json:"weekly"`
+	Monthly int `json:"monthly"`
+}
+
+func (p *RetentionPolicy) IsEmpty() bool {
+	return p == nil || (p.Hourly == 0 && p.Daily == 0 && p.Weekly == 0 && p.Monthly == 0)
 }
 
 type InstanceState string
@@ -88,6 +275,7 @@ type InstanceStatus struct {
 type EngineSpec struct {
 	InstanceSpec
 	Frontend                  VolumeFrontend    `json:"frontend"`
+	DisableFrontend           bool              `json:"disableFrontend"`
 	ReplicaAddressMap         map[string]string `json:"replicaAddressMap"`
 	UpgradedReplicaAddressMap map[string]string `json:"upgradedReplicaAddressMap"`
 }
@@ -96,20 +284,38 @@ type EngineStatus struct {
 	InstanceStatus
 	ReplicaModeMap map[string]ReplicaMode `json:"replicaModeMap"`
 	Endpoint       string                 `json:"endpoint"`
+	// RebuildStatus is keyed by the replica name, only the replicas in WO
+	// mode have the entries
+	RebuildStatus map[string]RebuildStatus `json:"rebuildStatus"`
+	ActualSize    int64                    `json:"actualSize,string"`
+}
+
+type RebuildStatus struct {
+	IsRebuilding bool   `json:"isRebuilding"`
+	Progress     int    `json:"progress"`
+	Error        string `json:"error"`
+	FromReplica  string `json:"fromReplica"`
+	// LastProgressAt is the last time the progress was changed
+	LastProgressAt string `json:"lastProgressAt"`
+	// Stalled is set if the progress wasn't changed for longer than
+	// SettingsInfo.ReplicaRebuildStallTimeout
+	Stalled bool `json:"stalled"`
 }
 
 type ReplicaSpec struct {
 	InstanceSpec
-	RestoreFrom string `json:"restoreFrom"`
-	RestoreName string `json:"restoreName"`
-	HealthyAt   string `json:"healthyAt"`
-	FailedAt    string `json:"failedAt"`
-	DataPath    string `json:"dataPath"`
-	Cleanup     bool   `json:"cleanup"`
+	RestoreFrom  string `json:"restoreFrom"`
+	RestoreName  string `json:"restoreName"`
+	HealthyAt    string `json:"healthyAt"`
+	FailedAt     string `json:"failedAt"`
+	DataPath     string `json:"dataPath"`
+	BackingImage string `json:"backingImage"`
+	Cleanup      bool   `json:"cleanup"`
 }
 
 type ReplicaStatus struct {
 	InstanceStatus
+	RestoreStatus RestoreStatus `json:"restoreStatus"`
 }
 
 const (
@@ -117,12 +323,71 @@ const (
 	SettingDefaultEngineImage           = "defaultEngineImage"
 	SettingEngineUpgradeImage           = "engineUpgradeImage"
 	SettingBackupTargetCredentialSecret = "backupTargetCredentialSecret"
+	SettingAutoDeleteOrphans            = "autoDeleteOrphans"
+	SettingReplicaRebuildStallTimeout   = "replicaRebuildStallTimeout"
+	SettingNotificationWebhookURLs      = "notificationWebhookURLs"
+	SettingNotificationEventReasons     = "notificationEventReasons"
+	SettingNotificationSecret           = "notificationSecret"
+	SettingRecycleBinRetention          = "recycleBinRetention"
+	SettingRecurringJobHistoryLimit     = "recurringJobHistoryLimit"
+	SettingRecurringJobFailureThreshold = "recurringJobFailureThreshold"
+	SettingRecurringJobMode             = "recurringJobMode"
+	SettingRecurringJobConcurrentLimit  = "recurringJobConcurrentLimit"
+
+	SettingBackupConcurrentLimitPerNode    = "backupConcurrentLimitPerNode"
+	SettingBackupConcurrentLimitPerCluster = "backupConcurrentLimitPerCluster"
+	SettingBackupBandwidthLimit            = "backupBandwidthLimit"
+)
+
+const (
+	// DefaultReplicaRebuildStallTimeout is in minutes
+	DefaultReplicaRebuildStallTimeout = 30
+	DefaultNotificationEventReasons   = "Faulted,Degraded,FailedBackup,NodeDown,RecurringJobFailed"
+
+	DefaultRecurringJobHistoryLimit     = 10
+	DefaultRecurringJobFailureThreshold = 3
+	DefaultRecurringJobMode             = RecurringJobModeManager
+	DefaultRecurringJobConcurrentLimit  = 5
 )
 
 type SettingsInfo struct {
 	BackupTarget                 string `json:"backupTarget"`
 	DefaultEngineImage           string `json:"defaultEngineImage"`
 	BackupTargetCredentialSecret string `json:"backupTargetCredentialSecret"`
+	AutoDeleteOrphans            bool   `json:"autoDeleteOrphans"`
+	// ReplicaRebuildStallTimeout is in minutes
+	ReplicaRebuildStallTimeout int `json:"replicaRebuildStallTimeout"`
+	// NotificationWebhookURLs and NotificationEventReasons are comma
+	// separated lists. The events with the selected reasons are posted to
+	// all the webhooks, signed using the HMAC key in the secret named by
+	// NotificationSecret if it's set.
+	NotificationWebhookURLs  string `json:"notificationWebhookURLs"`
+	NotificationEventReasons string `json:"notificationEventReasons"`
+	NotificationSecret       string `json:"notificationSecret"`
+	// RecycleBinRetention is in hours. The deleted volumes are kept in the
+	// recycle bin for the period if it's set. Once it's cleared, the
+	// recycled volumes are kept until they're deleted again.
+	RecycleBinRetention int `json:"recycleBinRetention"`
+	// RecurringJobHistoryLimit is the number of runs kept for each
+	// recurring job of a volume. The volume condition RecurringJobFailing
+	// is raised once a job failed RecurringJobFailureThreshold times in a
+	// row.
+	RecurringJobHistoryLimit     int `json:"recurringJobHistoryLimit"`
+	RecurringJobFailureThreshold int `json:"recurringJobFailureThreshold"`
+	// RecurringJobMode decides whether the snapshot and backup jobs run in
+	// the manager owning the volume, at most RecurringJobConcurrentLimit of
+	// them at the same time, or in CronJob pods. The existing installations
+	// keep using the CronJobs after upgrade.
+	RecurringJobMode            RecurringJobMode `json:"recurringJobMode"`
+	RecurringJobConcurrentLimit int              `json:"recurringJobConcurrentLimit"`
+	// BackupConcurrentLimitPerNode and BackupConcurrentLimitPerCluster
+	// are the maximum numbers of backups running at the same time, the
+	// other backups wait in pending state. 0 means unlimited.
+	BackupConcurrentLimitPerNode    int `json:"backupConcurrentLimitPerNode"`
+	BackupConcurrentLimitPerCluster int `json:"backupConcurrentLimitPerCluster"`
+	// BackupBandwidthLimit is in MiB per second, used by the backup
+	// targets without their own limit. 0 means unlimited.
+	BackupBandwidthLimit int `json:"backupBandwidthLimit"`
 }
 
 type EngineImageState string
@@ -164,6 +429,42 @@ type EngineVersionDetails struct {
 	DataFormatMinVersion    int `json:"dataFormatMinVersion"`
 }
 
+type BackingImageState string
+
+const (
+	BackingImageStateDownloading = BackingImageState("downloading")
+	BackingImageStateReady       = BackingImageState("ready")
+	BackingImageStateUnused      = BackingImageState("unused")
+	BackingImageStateError       = BackingImageState("error")
+)
+
+type BackingImageSpec struct {
+	OwnerID  string `json:"ownerID"`
+	ImageURL string `json:"imageURL"`
+}
+
+type BackingImageDiskState string
+
+const (
+	BackingImageDiskStateDownloading = BackingImageDiskState("downloading")
+	BackingImageDiskStateReady       = BackingImageDiskState("ready")
+	BackingImageDiskStateFailed      = BackingImageDiskState("failed")
+)
+
+type BackingImageDiskStatus struct {
+	State   BackingImageDiskState `json:"state"`
+	Message string                `json:"message"`
+}
+
+type BackingImageStatus struct {
+	State      BackingImageState `json:"state"`
+	RefCount   int               `json:"refCount"`
+	NoRefSince string            `json:"noRefSince"`
+	// DiskStatusMap is keyed by node ID, since every node has a single
+	// data disk for now
+	DiskStatusMap map[string]BackingImageDiskStatus `json:"diskStatusMap"`
+}
+
 type NodeSpec struct {
 	Name            string `json:"name"`
 	AllowScheduling bool   `json:"allowScheduling"`
@@ -178,4 +479,221 @@ const (
 
 type NodeStatus struct {
 	State NodeState
+	// the disk space of the filesystem holding the replica directory
+	StorageMaximum   int64 `json:"storageMaximum,string"`
+	StorageAvailable int64 `json:"storageAvailable,string"`
+	// StorageUsed is the total disk space used by the replicas on the node
+	StorageUsed int64 `json:"storageUsed,string"`
+	// ReplicaSizeMap is the disk space used by each replica on the node,
+	// keyed by the replica name
+	ReplicaSizeMap map[string]int64 `json:"replicaSizeMap"`
+}
+
+type OrphanSpec struct {
+	NodeID   string `json:"nodeID"`
+	DataPath string `json:"dataPath"`
+	// Keep prevents the orphan data from being deleted automatically
+	Keep bool `json:"keep"`
+}
+
+type OrphanStatus struct {
+	Size         int64  `json:"size"`
+	LastModified string `json:"lastModified"`
+}
+
+type RecurringJobSpec struct {
+	OwnerID string           `json:"ownerID"`
+	Task    RecurringJobType `json:"task"`
+	Cron    string           `json:"cron"`
+	Retain  int              `json:"retain"`
+	// Retention keeps the snapshots or backups in addition to the newest
+	// Retain ones
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+	Freeze    bool             `json:"freeze"`
+	Hooks     *SnapshotHooks   `json:"hooks,omitempty"`
+	// BackupTarget is the same as RecurringJob.BackupTarget
+	BackupTarget string `json:"backupTarget"`
+	// Concurrency is the maximum number of volumes running the job at the
+	// same time, 0 means no limit
+	Concurrency int `json:"concurrency"`
+	// Selector and Groups choose the volumes the job applies to. A volume
+	// is selected if it has all the labels in Selector, or it's in any of
+	// the Groups.
+	Selector map[string]string `json:"selector"`
+	Groups   []string          `json:"groups"`
+}
+
+type RecurringJobStatus struct {
+	// Volumes are the names of the volumes selected by the job
+	Volumes []string `json:"volumes"`
+	// Running is keyed by the volumes running the job, the value is the
+	// time the run started
+	Running map[string]string `json:"running"`
+}
+
+type SnapshotGroupSpec struct {
+	// Volumes are snapshotted at the same point in time, their filesystems
+	// are frozen together if Freeze is set
+	Volumes []string `json:"volumes"`
+	Freeze  bool     `json:"freeze"`
+}
+
+type SnapshotGroupStatus struct {
+	// LastSnapshot is the name of the snapshots taken by the latest group
+	// snapshot, LastBackup is the name of the snapshots requested to be
+	// backed up latest
+	LastSnapshot string `json:"lastSnapshot"`
+	LastBackup   string `json:"lastBackup"`
+}
+
+type BackupTargetSpec struct {
+	// URL of the default target can be empty to follow the backupTarget
+	// settings
+	URL string `json:"url"`
+	// CredentialSecret is the secret with the credential of the S3 target
+	CredentialSecret string `json:"credentialSecret"`
+	// PollInterval is in seconds, the interval the target is checked and
+	// its content is refreshed, 0 means DefaultBackupTargetPollInterval
+	PollInterval int `json:"pollInterval"`
+	// BandwidthLimit is in MiB per second, the backups to the target are
+	// throttled to it. 0 means SettingsInfo.BackupBandwidthLimit.
+	BandwidthLimit int `json:"bandwidthLimit"`
+	// OwnerID is the manager checking the target
+	OwnerID string `json:"ownerID"`
+	// SyncRequestedAt requests refreshing the content of the target before
+	// the poll interval passes
+	SyncRequestedAt string `json:"syncRequestedAt"`
+}
+
+type BackupTargetStatus struct {
+	// CheckedURL is the URL checked latest
+	CheckedURL string `json:"checkedURL"`
+	Available  bool   `json:"available"`
+	// Latency is in milliseconds, the time the latest check took to list
+	// the backup volumes
+	Latency           int64  `json:"latency"`
+	LastError         string `json:"lastError"`
+	LastCheckTime     string `json:"lastCheckTime"`
+	LastAvailableTime string `json:"lastAvailableTime"`
+	// LastSyncedAt is the time the BackupVolumes and Backups of the target
+	// were refreshed, LastSyncRequestedAt is the latest request handled
+	LastSyncedAt        string `json:"lastSyncedAt"`
+	LastSyncRequestedAt string `json:"lastSyncRequestedAt"`
+	// LastSyncError is why the content of the latest sync is incomplete,
+	// e.g. the backups of some volumes cannot be listed. The target is
+	// still available.
+	LastSyncError string `json:"lastSyncError"`
+}
+
+// BackupVolumeSpec identifies the volume in the backup target. The
+// BackupVolume is maintained by the backup target controller.
+type BackupVolumeSpec struct {
+	BackupTarget string `json:"backupTarget"`
+	VolumeName   string `json:"volumeName"`
+}
+
+type BackupVolumeStatus struct {
+	Size           string `json:"size"`
+	Created        string `json:"created"`
+	LastBackupName string `json:"lastBackupName"`
+	LastBackupAt   string `json:"lastBackupAt"`
+}
+
+// BackupSpec identifies the backup in the backup target. The Backup is
+// maintained by the backup target controller.
+type BackupSpec struct {
+	BackupTarget string `json:"backupTarget"`
+	VolumeName   string `json:"volumeName"`
+	BackupName   string `json:"backupName"`
+}
+
+type BackupStatus struct {
+	URL             string            `json:"url"`
+	SnapshotName    string            `json:"snapshotName"`
+	SnapshotCreated string            `json:"snapshotCreated"`
+	Created         string            `json:"created"`
+	Size            string            `json:"size"`
+	Labels          map[string]string `json:"labels"`
+	VolumeSize      string            `json:"volumeSize"`
+	VolumeCreated   string            `json:"volumeCreated"`
+}
+
+type BackupOperationState string
+
+const (
+	// BackupOperationStatePending is waiting for the owner manager to
+	// start the backup
+	BackupOperationStatePending    = BackupOperationState("pending")
+	BackupOperationStateInProgress = BackupOperationState("in_progress")
+	BackupOperationStateCompleted  = BackupOperationState("completed")
+	BackupOperationStateError      = BackupOperationState("error")
+	BackupOperationStateCancelled  = BackupOperationState("cancelled")
+)
+
+// BackupOperationSpec is a backup of the snapshot to the backup target, run
+// by the manager owning the volume. The BackupOperation is kept for
+// BackupOperationRetention after it finished.
+type BackupOperationSpec struct {
+	VolumeName   string            `json:"volumeName"`
+	SnapshotName string            `json:"snapshotName"`
+	BackupTarget string            `json:"backupTarget"`
+	Labels       map[string]string `json:"labels"`
+	// OwnerID is the manager running the backup
+	OwnerID string `json:"ownerID"`
+	// Cancel requests stopping the backup
+	Cancel bool `json:"cancel"`
+}
+
+type BackupOperationStatus struct {
+	State BackupOperationState `json:"state"`
+	// Progress is in percent, polled from the engine
+	Progress         int    `json:"progress"`
+	BytesTransferred int64  `json:"bytesTransferred"`
+	StartTime        string `json:"startTime"`
+	EndTime          string `json:"endTime"`
+	Error            string `json:"error"`
+	BackupURL        string `json:"backupURL"`
+}
+
+// IsFinished returns true if the backup won't make any progress
+func (s *BackupOperationStatus) IsFinished() bool {
+	return s.State == BackupOperationStateCompleted ||
+		s.State == BackupOperationStateError ||
+		s.State == BackupOperationStateCancelled
+}
+
+type SnapshotExportState string
+
+const (
+	SnapshotExportStatePending    = SnapshotExportState("pending")
+	SnapshotExportStateInProgress = SnapshotExportState("in_progress")
+	SnapshotExportStateCompleted  = SnapshotExportState("completed")
+	SnapshotExportStateError      = SnapshotExportState("error")
+)
+
+// SnapshotExportSpec is an export of the snapshot into an image file on the
+// node, which is downloaded from the manager on the node. The file is
+// removed with the SnapshotExport, which is deleted with the snapshot or
+// SnapshotExportRetention after the export finished.
+type SnapshotExportSpec struct {
+	VolumeName   string `json:"volumeName"`
+	SnapshotName string `json:"snapshotName"`
+	// NodeID is the node running the export and keeping the file
+	NodeID string `json:"nodeID"`
+}
+
+type SnapshotExportStatus struct {
+	State     SnapshotExportState `json:"state"`
+	StartTime string              `json:"startTime"`
+	EndTime   string              `json:"endTime"`
+	Error     string              `json:"error"`
+	Size      int64               `json:"size,string"`
+	// Checksum is the SHA512 of the exported image
+	Checksum string `json:"checksum"`
+}
+
+// IsFinished returns true if the export won't make any progress
+func (s *SnapshotExportStatus) IsFinished() bool {
+	return s.State == SnapshotExportStateCompleted ||
+		s.State == SnapshotExportStateError
 }
@@ -2,10 +2,12 @@ package types
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rancher/longhorn-manager/util"
 )
@@ -16,6 +18,14 @@ const (
 	DefaultEngineBinaryPath          = "/usr/local/bin/longhorn"
 	EngineBinaryDirectoryInContainer = "/engine-binaries/"
 	EngineBinaryDirectoryOnHost      = "/var/lib/rancher/longhorn/engine-binaries/"
+
+	BackingImageDirectoryOnHost      = "/var/lib/rancher/longhorn/backing-images/"
+	BackingImageDirectoryInContainer = "/backing-image/"
+	BackingImageFileName             = "backing"
+
+	SnapshotExportDirectory = "/var/lib/rancher/longhorn/exports/"
+
+	ReplicaDirectoryOnHost = "/var/lib/rancher/longhorn/replicas/"
 )
 
 type ReplicaMode string
@@ -38,7 +48,15 @@ const (
 	OptionStaleReplicaTimeout = "staleReplicaTimeout"
 	OptionFrontend            = "frontend"
 
-	EngineImageChecksumNameLength = 8
+	EngineImageChecksumNameLength    = 8
+	OrphanChecksumNameLength         = 16
+	BackupChecksumNameLength         = 16
+	SnapshotExportChecksumNameLength = 16
+	NotificationWebhookIDLength      = 8
+
+	// NotificationHMACKey is the key of the HMAC key in the secret named
+	// by SettingNotificationSecret
+	NotificationHMACKey = "NOTIFICATION_HMAC_KEY"
 )
 
 type NotFoundError struct {
@@ -53,6 +71,7 @@ const (
 	engineSuffix    = "-e"
 	replicaSuffix   = "-r"
 	recurringSuffix = "-c"
+	importSuffix    = "-import"
 
 	// MaximumJobNameSize is calculated using
 	// 1. NameMaximumLength is 40
@@ -62,7 +81,70 @@ const (
 	// 5. Dash and buffer for 2
 	MaximumJobNameSize = 8
 
-	engineImagePrefix = "ei-"
+	engineImagePrefix  = "ei-"
+	orphanPrefix       = "orphan-"
+	backupVolumePrefix = "bv-"
+	backupPrefix       = "backup-"
+
+	backupOperationSuffix = "-bo-"
+	snapshotExportPrefix  = "export-"
+
+	// a volume joins a recurring job group by having the label
+	// RecurringJobGroupLabelPrefix + group with value RecurringJobGroupLabelValue
+	RecurringJobGroupLabelPrefix = "recurring-job-group.longhorn.rancher.io/"
+	RecurringJobGroupLabelValue  = "enabled"
+
+	// RecurringJobRunTimeout is how long a run keeps its slot in
+	// RecurringJobStatus.Running, in case the job pod died without
+	// releasing it
+	RecurringJobRunTimeout = 6 * time.Hour
+
+	// SnapshotLabelFreeze records if the filesystem on the volume was
+	// frozen when the snapshot was taken, see
+	// engineapi.SnapshotCreateWithFreeze
+	SnapshotLabelFreeze         = "FilesystemFreeze"
+	SnapshotFreezeResultFrozen  = "frozen"
+	SnapshotFreezeResultSkipped = "skipped"
+	SnapshotFreezeResultFailed  = "failed"
+	// SnapshotFreezeTimeout is the longest time the filesystem is kept
+	// frozen, the workload on the volume is blocked in the meantime
+	SnapshotFreezeTimeout = 1 * time.Minute
+
+	// SnapshotHookDefaultTimeout applies to the hooks without the timeout
+	SnapshotHookDefaultTimeout = 30 * time.Second
+
+	// DefaultBackupTargetName is the backup target used if none is
+	// specified. If there is no BackupTarget with the name or its URL is
+	// empty, it's the backupTarget and backupTargetCredentialSecret
+	// settings.
+	DefaultBackupTargetName = "default"
+	// DefaultBackupTargetPollInterval is in seconds
+	DefaultBackupTargetPollInterval = 300
+	// BackupOperationRetention is how long a BackupOperation is kept
+	// after it finished
+	BackupOperationRetention = 24 * time.Hour
+	// BackupOperationWaitTimeout is how long the callers wait for a
+	// BackupOperation to finish, the same as the engine waits for the
+	// backup
+	BackupOperationWaitTimeout = 6 * time.Hour
+	// SnapshotExportRetention is how long the SnapshotExport and the
+	// exported image are kept after the export finished
+	SnapshotExportRetention = 24 * time.Hour
+	// BackupSlotsConfigMapName is the ConfigMap holding the backups
+	// counted by the concurrent backup limits, shared by the managers
+	BackupSlotsConfigMapName = "longhorn-backup-slots"
+	// NotificationDedupConfigMapName is the ConfigMap holding the
+	// notifications sent recently, shared by the managers
+	NotificationDedupConfigMapName = "longhorn-notification-dedup"
+
+	// SnapshotLabelGroup is the SnapshotGroup the snapshot was taken for,
+	// the snapshots of the volumes in the group share the same name
+	SnapshotLabelGroup = "SnapshotGroup"
+
+	// LonghornFlexVolumeDriver and DefaultCSIDriverName are the drivers of
+	// the persistent volumes backed by Longhorn volumes
+	LonghornFlexVolumeDriver = "rancher.io/longhorn"
+	DefaultCSIDriverName     = "io.rancher.longhorn"
 )
 
 func GetEngineNameForVolume(vName string) string {
@@ -100,10 +182,59 @@ func EngineBinaryExistOnHostForImage(image string) bool {
 	return err == nil && !st.IsDir()
 }
 
+func GetBackingImageDirectoryOnHost(name string) string {
+	return filepath.Join(BackingImageDirectoryOnHost, name)
+}
+
+func GetBackingImageFileInContainer() string {
+	return filepath.Join(BackingImageDirectoryInContainer, BackingImageFileName)
+}
+
+func GetSnapshotExportFile(directory, volumeName, snapshotName string) string {
+	return filepath.Join(directory, volumeName, snapshotName+".img")
+}
+
+type ImageSource struct {
+	IsLocalFile bool
+	Path        string
+}
+
+// ParseImageURL accepts http(s) URL, file:// URL or absolute path pointing to
+// a file on the node
+func ParseImageURL(imageURL string) (*ImageSource, error) {
+	if filepath.IsAbs(imageURL) {
+		imageURL = "file://" + imageURL
+	}
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image URL %v: %v", imageURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid image URL %v: missing host", imageURL)
+		}
+		return &ImageSource{Path: imageURL}, nil
+	case "file":
+		if !filepath.IsAbs(u.Path) || strings.HasSuffix(u.Path, "/") {
+			return nil, fmt.Errorf("invalid image URL %v: require absolute file path", imageURL)
+		}
+		return &ImageSource{IsLocalFile: true, Path: filepath.Clean(u.Path)}, nil
+	}
+	return nil, fmt.Errorf("invalid image URL %v: unsupported scheme %v", imageURL, u.Scheme)
+}
+
+func GetImportJobNameForVolume(vName string) string {
+	return vName + importSuffix
+}
+
 var (
-	LonghornSystemKey              = "longhorn"
-	LonghornSystemValueManager     = "manager"
-	LonghornSystemValueEngineImage = "engine-image"
+	LonghornSystemKey               = "longhorn"
+	LonghornSystemValueManager      = "manager"
+	LonghornSystemValueEngineImage  = "engine-image"
+	LonghornSystemValueBackingImage = "backing-image"
+
+	LonghornBackingImageKey = "longhorn-backing-image"
 )
 
 func GetEngineImageLabel() map[string]string {
@@ -112,6 +243,83 @@ func GetEngineImageLabel() map[string]string {
 	}
 }
 
+func GetBackingImageLabel(name string) map[string]string {
+	return map[string]string{
+		LonghornSystemKey:       LonghornSystemValueBackingImage,
+		LonghornBackingImageKey: name,
+	}
+}
+
 func GetEngineImageChecksumName(image string) string {
 	return engineImagePrefix + util.GetStringChecksum(strings.TrimSpace(image))[:EngineImageChecksumNameLength]
 }
+
+func GetOrphanChecksumName(nodeID, dataPath string) string {
+	return orphanPrefix + util.GetStringChecksum(nodeID + ":" + filepath.Clean(dataPath))[:OrphanChecksumNameLength]
+}
+
+// GetBackupVolumeName returns the name of the BackupVolume of the volume in
+// the backup target
+func GetBackupVolumeName(backupTargetName, volumeName string) string {
+	return backupVolumePrefix + util.GetStringChecksum(backupTargetName + ":" + volumeName)[:BackupChecksumNameLength]
+}
+
+// GetBackupName returns the name of the Backup of the backup in the backup
+// target
+func GetBackupName(backupTargetName, volumeName, backupName string) string {
+	return backupPrefix + util.GetStringChecksum(backupTargetName + ":" + volumeName + ":" + backupName)[:BackupChecksumNameLength]
+}
+
+// GetSnapshotExportName returns the name of the SnapshotExport of the
+// snapshot, there is at most one export for each snapshot
+func GetSnapshotExportName(volumeName, snapshotName string) string {
+	return snapshotExportPrefix + util.GetStringChecksum(volumeName + ":" + snapshotName)[:SnapshotExportChecksumNameLength]
+}
+
+// GetBackupOperationName returns a new name for the BackupOperation of the
+// volume
+func GetBackupOperationName(volumeName string) string {
+	return volumeName + backupOperationSuffix + util.RandomID()
+}
+
+func GetRecurringJobGroupLabelKey(group string) string {
+	return RecurringJobGroupLabelPrefix + group
+}
+
+// AppliedRecurringJob is a job applied to a volume, either set on the volume
+// or from a RecurringJob selecting the volume
+type AppliedRecurringJob struct {
+	RecurringJob
+	// FromRecurringJob is set if it's from the RecurringJob of the same name
+	FromRecurringJob bool
+}
+
+// IsRecurringJobRunByManager returns true if the owner manager of the volume
+// runs the job in process instead of creating a CronJob for it. Trim jobs
+// always run in the CronJob pods, which have the host devices mounted.
+func IsRecurringJobRunByManager(mode RecurringJobMode, jobType RecurringJobType) bool {
+	return mode == RecurringJobModeManager && jobType != RecurringJobTypeTrim
+}
+
+// IsVolumeSelectedByRecurringJob returns true if the volume labels match the
+// selector of the recurring job, or the volume is in one of its groups
+func IsVolumeSelectedByRecurringJob(spec *RecurringJobSpec, volumeLabels map[string]string) bool {
+	if len(spec.Selector) != 0 {
+		selected := true
+		for key, value := range spec.Selector {
+			if v, exists := volumeLabels[key]; !exists || v != value {
+				selected = false
+				break
+			}
+		}
+		if selected {
+			return true
+		}
+	}
+	for _, group := range spec.Groups {
+		if volumeLabels[GetRecurringJobGroupLabelKey(group)] == RecurringJobGroupLabelValue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a standard 5 fields cron schedule: minute, hour, day of
+// month, month and day of week. Each field accepts `*`, numbers, ranges
+// `a-b`, steps `*/n` or `a-b/n` and the comma separated lists of them. The
+// descriptors @hourly, @daily, @midnight, @weekly, @monthly, @yearly and
+// @annually are supported as well.
+type CronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// the day matches either of day of month or day of week if both of
+	// them are restricted, as cron does
+	dayOfMonthStar bool
+	dayOfWeekStar  bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var (
+	cronFields = []cronField{
+		{"minute", 0, 59},
+		{"hour", 0, 23},
+		{"day of month", 1, 31},
+		{"month", 1, 12},
+		{"day of week", 0, 6},
+	}
+
+	cronDescriptors = map[string]string{
+		"@hourly":   "0 * * * *",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@weekly":   "0 0 * * 0",
+		"@monthly":  "0 0 1 * *",
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+	}
+
+	// a schedule like `0 0 30 2 *` never fires
+	cronSearchLimit = 5 * 366 * 24 * time.Hour
+)
+
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	if descriptor, ok := cronDescriptors[strings.TrimSpace(spec)]; ok {
+		spec = descriptor
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron schedule %v, expect %v fields", spec, len(cronFields))
+	}
+	bits := make([]uint64, len(cronFields))
+	for i, field := range cronFields {
+		b, err := parseCronField(fields[i], field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron schedule %v: %v", spec, err)
+		}
+		bits[i] = b
+	}
+	// 7 is Sunday as well
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return &CronSchedule{
+		minute:         bits[0],
+		hour:           bits[1],
+		dayOfMonth:     bits[2],
+		month:          bits[3],
+		dayOfWeek:      bits[4],
+		dayOfMonthStar: strings.HasPrefix(fields[2], "*"),
+		dayOfWeekStar:  strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(value string, field cronField) (uint64, error) {
+	max := field.max
+	if field.name == "day of week" {
+		max = 7
+	}
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rangeValue, step := item, 1
+		if i := strings.Index(item, "/"); i != -1 {
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step in %v %v", field.name, item)
+			}
+			rangeValue, step = item[:i], s
+		}
+
+		start, end := field.min, max
+		if rangeValue != "*" {
+			bounds := strings.SplitN(rangeValue, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid %v %v", field.name, item)
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid %v %v", field.name, item)
+				}
+			} else if step != 1 {
+				// `a/n` means from a to the max
+				end = max
+			}
+		}
+		if start < field.min || end > max || start > end {
+			return 0, fmt.Errorf("%v %v is out of range %v-%v", field.name, item, field.min, max)
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time matching the schedule after t, in the location
+// of t. It returns the zero time if there is none.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) matchDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthStar || s.dayOfWeekStar {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
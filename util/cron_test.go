@@ -0,0 +1,83 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	assert := require.New(t)
+
+	for _, spec := range []string{
+		"* * * * *",
+		"0 1 * * *",
+		"*/15 0-6,18-23 1,15 */2 1-5",
+		"5/10 * * * 7",
+		"@daily",
+		" @weekly ",
+	} {
+		_, err := ParseCronSchedule(spec)
+		assert.Nil(err, spec)
+	}
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 1h",
+	} {
+		_, err := ParseCronSchedule(spec)
+		assert.NotNil(err, spec)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	assert := require.New(t)
+
+	// Monday
+	now := time.Date(2018, 1, 1, 10, 30, 20, 0, time.UTC)
+	for spec, expect := range map[string]string{
+		"* * * * *":        "2018-01-01T10:31:00Z",
+		"30 * * * *":       "2018-01-01T11:30:00Z",
+		"*/20 * * * *":     "2018-01-01T10:40:00Z",
+		"0 1 * * *":        "2018-01-02T01:00:00Z",
+		"@hourly":          "2018-01-01T11:00:00Z",
+		"@weekly":          "2018-01-07T00:00:00Z",
+		"0 0 * * 7":        "2018-01-07T00:00:00Z",
+		"@monthly":         "2018-02-01T00:00:00Z",
+		"@yearly":          "2019-01-01T00:00:00Z",
+		"0 9 * * 1-5":      "2018-01-02T09:00:00Z",
+		"0 0 29 2 *":       "2020-02-29T00:00:00Z",
+		"0 0 31 * *":       "2018-01-31T00:00:00Z",
+		"0 0 13 * 5":       "2018-01-05T00:00:00Z",
+		"15 10-12/2 * * *": "2018-01-01T12:15:00Z",
+	} {
+		next := mustParseCronSchedule(t, spec).Next(now)
+		assert.Equal(expect, next.Format(time.RFC3339), spec)
+	}
+
+	// April and June have no 31st
+	next := mustParseCronSchedule(t, "0 12 31 4,6 *").Next(now)
+	assert.True(next.IsZero())
+
+	// in the location of the time
+	loc := time.FixedZone("UTC+5:30", 5*3600+1800)
+	next = mustParseCronSchedule(t, "0 * * * *").Next(time.Date(2018, 1, 1, 10, 30, 0, 0, loc))
+	assert.Equal("2018-01-01T11:00:00+05:30", next.Format(time.RFC3339))
+}
+
+func mustParseCronSchedule(t *testing.T, spec string) *CronSchedule {
+	s, err := ParseCronSchedule(spec)
+	require.Nil(t, err, spec)
+	return s
+}
@@ -0,0 +1,214 @@
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	HostProcPath = "/host/proc"
+	HostDevPath  = "/host/dev"
+
+	trimTimeout   = 30 * time.Minute
+	freezeTimeout = 30 * time.Second
+)
+
+var (
+	fstrimOutputRegex = regexp.MustCompile(`\((\d+) bytes\) trimmed`)
+
+	ErrFreezeTimeout = errors.New("timeout waiting with the filesystem frozen, it has been unfrozen")
+)
+
+// GetHostDevicePath returns the path of the host device inside the container
+func GetHostDevicePath(device string) string {
+	return filepath.Join(HostDevPath, strings.TrimPrefix(device, "/dev/"))
+}
+
+// GetDeviceMountPoint returns one of the mount points of the block device in
+// the host mount namespace, or "" if the device is not mounted
+func GetDeviceMountPoint(device string) (string, error) {
+	st := syscall.Stat_t{}
+	if err := syscall.Stat(device, &st); err != nil {
+		return "", errors.Wrapf(err, "cannot stat device %v", device)
+	}
+	if st.Mode&syscall.S_IFMT != syscall.S_IFBLK {
+		return "", fmt.Errorf("%v is not a block device", device)
+	}
+	major := (st.Rdev>>8)&0xfff | (st.Rdev>>32)&^0xfff
+	minor := st.Rdev&0xff | (st.Rdev>>12)&^0xff
+
+	mountInfo, err := ioutil.ReadFile(filepath.Join(HostProcPath, "1", "mountinfo"))
+	if err != nil {
+		return "", errors.Wrap(err, "cannot read host mount info")
+	}
+	return parseMountInfo(string(mountInfo), fmt.Sprintf("%d:%d", major, minor)), nil
+}
+
+func parseMountInfo(mountInfo, devNumber string) string {
+	for _, line := range strings.Split(mountInfo, "\n") {
+		// e.g. 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		if fields[2] == devNumber && fields[3] == "/" {
+			return fields[4]
+		}
+	}
+	return ""
+}
+
+// TrimFilesystem discards the unused blocks of the filesystem mounted at the
+// host mount point, and returns the number of bytes trimmed
+func TrimFilesystem(mountPoint string) (int64, error) {
+	output, err := ExecuteWithTimeout(trimTimeout, "nsenter",
+		"--mount="+filepath.Join(HostProcPath, "1", "ns", "mnt"),
+		"fstrim", "-v", mountPoint)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot trim filesystem at %v", mountPoint)
+	}
+	return parseFstrimOutput(output)
+}
+
+// FreezeFilesystem suspends the access to the filesystem mounted at the host
+// mount point, after flushing the data to the device
+func FreezeFilesystem(mountPoint string) error {
+	if _, err := ExecuteWithTimeout(freezeTimeout, "nsenter",
+		"--mount="+filepath.Join(HostProcPath, "1", "ns", "mnt"),
+		"fsfreeze", "--freeze", mountPoint); err != nil {
+		return errors.Wrapf(err, "cannot freeze filesystem at %v", mountPoint)
+	}
+	return nil
+}
+
+func UnfreezeFilesystem(mountPoint string) error {
+	if _, err := ExecuteWithTimeout(freezeTimeout, "nsenter",
+		"--mount="+filepath.Join(HostProcPath, "1", "ns", "mnt"),
+		"fsfreeze", "--unfreeze", mountPoint); err != nil {
+		return errors.Wrapf(err, "cannot unfreeze filesystem at %v", mountPoint)
+	}
+	return nil
+}
+
+// RunWithFilesystemFrozen calls f with the filesystem mounted at the host
+// mount point frozen. f is not called if the filesystem cannot be frozen. The
+// filesystem is unfrozen once the timeout expired even if f hasn't returned,
+// then ErrFreezeTimeout is returned after f returned.
+func RunWithFilesystemFrozen(mountPoint string, timeout time.Duration, f func() error) error {
+	return runFrozen(func() error {
+		return FreezeFilesystem(mountPoint)
+	}, func() error {
+		return UnfreezeFilesystem(mountPoint)
+	}, timeout, f)
+}
+
+// HoldFilesystemFrozen freezes the filesystem mounted at the host mount point
+// until the returned release function is called, or the timeout expired. The
+// release function returns ErrFreezeTimeout if the filesystem had been
+// unfrozen by the timeout.
+func HoldFilesystemFrozen(mountPoint string, timeout time.Duration) (func() error, error) {
+	return holdFrozen(func() error {
+		return FreezeFilesystem(mountPoint)
+	}, func() error {
+		return UnfreezeFilesystem(mountPoint)
+	}, timeout)
+}
+
+func runFrozen(freeze, unfreeze func() error, timeout time.Duration, f func() error) error {
+	release, err := holdFrozen(freeze, unfreeze, timeout)
+	if err != nil {
+		return err
+	}
+	err = f()
+	releaseErr := release()
+	if releaseErr != nil && releaseErr != ErrFreezeTimeout {
+		return releaseErr
+	}
+	if err != nil {
+		return err
+	}
+	return releaseErr
+}
+
+func holdFrozen(freeze, unfreeze func() error, timeout time.Duration) (func() error, error) {
+	if err := freeze(); err != nil {
+		return nil, err
+	}
+
+	var (
+		once        sync.Once
+		unfreezeErr error
+	)
+	doUnfreeze := func() {
+		once.Do(func() {
+			unfreezeErr = unfreeze()
+		})
+	}
+	timer := time.AfterFunc(timeout, doUnfreeze)
+	return func() error {
+		timedOut := !timer.Stop()
+		doUnfreeze()
+		if unfreezeErr != nil {
+			return unfreezeErr
+		}
+		if timedOut {
+			return ErrFreezeTimeout
+		}
+		return nil
+	}, nil
+}
+
+func parseFstrimOutput(output string) (int64, error) {
+	// e.g. /mnt: 1 GiB (1073741824 bytes) trimmed
+	matches := fstrimOutputRegex.FindStringSubmatch(output)
+	if matches == nil {
+		return 0, fmt.Errorf("cannot parse fstrim output: %v", output)
+	}
+	return strconv.ParseInt(matches[1], 10, 64)
+}
+
+// GetDirectoryUsage returns the disk space actually used by the files in the
+// directory, which could be much less than the file sizes for sparse files,
+// and the latest modification time of them
+func GetDirectoryUsage(path string) (int64, time.Time, error) {
+	usage := int64(0)
+	lastModified := time.Time{}
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			usage += st.Blocks * 512
+		} else {
+			usage += info.Size()
+		}
+		if info.ModTime().After(lastModified) {
+			lastModified = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, time.Time{}, errors.Wrapf(err, "cannot get usage of %v", path)
+	}
+	return usage, lastModified, nil
+}
+
+// GetDiskStat returns the total and available space of the filesystem which
+// the path is on
+func GetDiskStat(path string) (int64, int64, error) {
+	st := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, errors.Wrapf(err, "cannot get filesystem stat of %v", path)
+	}
+	return int64(st.Blocks) * st.Bsize, int64(st.Bavail) * st.Bsize, nil
+}
@@ -1,7 +1,11 @@
 package util
 
 import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 )
@@ -58,3 +62,26 @@ func FinalizerExists(name string, obj runtime.Object) bool {
 	}
 	return false
 }
+
+// NewEvent builds an event for the referenced object. It's used by the
+// callers which cannot rely on an event broadcaster to deliver the event,
+// e.g. the short lived recurring job
+func NewEvent(ref *v1.ObjectReference, eventType, reason, message, component string) *v1.Event {
+	now := metav1.Now()
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v.%x", ref.Name, now.UnixNano()),
+			Namespace: ref.Namespace,
+		},
+		InvolvedObject: *ref,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source: v1.EventSource{
+			Component: component,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+}
@@ -0,0 +1,113 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// the streaming protocol of `kubectl exec` over websocket, the first byte
+	// of each message is the channel
+	execProtocol      = "v4.channel.k8s.io"
+	execChannelStdout = 1
+	execChannelStderr = 2
+	execChannelError  = 3
+
+	execOutputLimit = 4096
+)
+
+// ExecInPod runs the command in the container of the pod through the
+// Kubernetes API, the same way as `kubectl exec` does. It returns the
+// combined stdout and stderr of the command, truncated to the last 4KiB, and
+// an error if the command failed or didn't complete within the timeout.
+// The command isn't killed on timeout.
+func ExecInPod(config *rest.Config, kubeClient clientset.Interface, namespace, podName, container string, command []string, timeout time.Duration) (string, error) {
+	req := kubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		Param("container", container).
+		Param("stdout", "true").
+		Param("stderr", "true")
+	for _, c := range command {
+		req = req.Param("command", c)
+	}
+	u := req.URL()
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot get TLS config")
+	}
+	dialer := &websocket.Dialer{
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: timeout,
+		Subprotocols:     []string{execProtocol},
+	}
+	header := http.Header{}
+	if config.BearerToken != "" {
+		header.Set("Authorization", "Bearer "+config.BearerToken)
+	}
+	conn, resp, err := dialer.Dial(u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return "", errors.Wrapf(err, "cannot exec in pod %v/%v: %v", namespace, podName, resp.Status)
+		}
+		return "", errors.Wrapf(err, "cannot exec in pod %v/%v", namespace, podName)
+	}
+	defer conn.Close()
+
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+	output := &bytes.Buffer{}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return tailOutput(output), nil
+			}
+			return tailOutput(output), errors.Wrapf(err, "failed to exec %v in pod %v/%v", command, namespace, podName)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		switch data[0] {
+		case execChannelStdout, execChannelStderr:
+			output.Write(data[1:])
+		case execChannelError:
+			status := &metav1.Status{}
+			if err := json.Unmarshal(data[1:], status); err != nil {
+				return tailOutput(output), errors.Wrapf(err, "cannot parse the exec status %v", string(data[1:]))
+			}
+			if status.Status != metav1.StatusSuccess {
+				return tailOutput(output), fmt.Errorf("failed to exec %v in pod %v/%v: %v", command, namespace, podName, status.Message)
+			}
+			return tailOutput(output), nil
+		}
+	}
+}
+
+func tailOutput(output *bytes.Buffer) string {
+	b := output.Bytes()
+	if len(b) > execOutputLimit {
+		b = b[len(b)-execOutputLimit:]
+	}
+	return string(b)
+}
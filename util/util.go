@@ -5,6 +5,7 @@ import (
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -301,6 +302,15 @@ func SplitStringToMap(str, separator string) map[string]struct{} {
 	return ret
 }
 
+func Contains(array []string, item string) bool {
+	for _, v := range array {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
+
 func GetStringChecksum(data string) string {
 	return GetChecksumSHA512([]byte(data))
 }
@@ -310,6 +320,20 @@ func GetChecksumSHA512(data []byte) string {
 	return hex.EncodeToString(checksum[:])
 }
 
+func GetFileChecksumSHA512(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha512.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func CheckBackupType(backupTarget string) (string, error) {
 	u, err := url.Parse(backupTarget)
 	if err != nil {
@@ -1,8 +1,10 @@
 package util
 
 import (
+	"errors"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestConvertSize(t *testing.T) {
@@ -51,3 +53,125 @@ func TestReplicaName(t *testing.T) {
 	assert.Equal("replica-XX", ReplicaName("tcp://replica-XX.rancher.internal:9502", "tt"))
 	assert.Equal("replica-XX", ReplicaName("tcp://replica-XX.volume-tt:9502", "tt"))
 }
+
+func TestParseMountInfo(t *testing.T) {
+	assert := require.New(t)
+
+	mountInfo := `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw,data=ordered
+36 22 8:16 /sub /mnt/bind rw,relatime shared:20 - ext4 /dev/longhorn/vol1 rw
+37 22 8:16 / /var/lib/kubelet/pods/xx/volumes/vol1 rw,relatime shared:20 - ext4 /dev/longhorn/vol1 rw
+`
+	assert.Equal("/var/lib/kubelet/pods/xx/volumes/vol1", parseMountInfo(mountInfo, "8:16"))
+	assert.Equal("/", parseMountInfo(mountInfo, "8:1"))
+	assert.Equal("", parseMountInfo(mountInfo, "8:32"))
+}
+
+func TestParseFstrimOutput(t *testing.T) {
+	assert := require.New(t)
+
+	trimmed, err := parseFstrimOutput("/mnt: 1 GiB (1073741824 bytes) trimmed\n")
+	assert.Nil(err)
+	assert.Equal(int64(1073741824), trimmed)
+
+	trimmed, err = parseFstrimOutput("/mnt: 0 B (0 bytes) trimmed on /dev/longhorn/vol1\n")
+	assert.Nil(err)
+	assert.Equal(int64(0), trimmed)
+
+	_, err = parseFstrimOutput("fstrim: /mnt: the discard operation is not supported")
+	assert.NotNil(err)
+}
+
+func TestRunFrozen(t *testing.T) {
+	assert := require.New(t)
+
+	var calls []string
+	freeze := func() error {
+		calls = append(calls, "freeze")
+		return nil
+	}
+	unfreeze := func() error {
+		calls = append(calls, "unfreeze")
+		return nil
+	}
+
+	err := runFrozen(freeze, unfreeze, time.Minute, func() error {
+		calls = append(calls, "snapshot")
+		return nil
+	})
+	assert.Nil(err)
+	assert.Equal([]string{"freeze", "snapshot", "unfreeze"}, calls)
+
+	// not called if the freeze failed
+	calls = nil
+	err = runFrozen(func() error {
+		return errors.New("not supported")
+	}, unfreeze, time.Minute, func() error {
+		calls = append(calls, "snapshot")
+		return nil
+	})
+	assert.NotNil(err)
+	assert.Len(calls, 0)
+
+	// unfrozen even if it failed
+	calls = nil
+	err = runFrozen(freeze, unfreeze, time.Minute, func() error {
+		return errors.New("engine error")
+	})
+	assert.EqualError(err, "engine error")
+	assert.Equal([]string{"freeze", "unfreeze"}, calls)
+
+	// unfrozen on timeout before it returned
+	unfrozen := make(chan struct{})
+	err = runFrozen(func() error {
+		return nil
+	}, func() error {
+		close(unfrozen)
+		return nil
+	}, 10*time.Millisecond, func() error {
+		select {
+		case <-unfrozen:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for unfreeze")
+		}
+		return nil
+	})
+	assert.Equal(ErrFreezeTimeout, err)
+}
+
+func TestHoldFrozen(t *testing.T) {
+	assert := require.New(t)
+
+	unfreezes := 0
+	freeze := func() error {
+		return nil
+	}
+	unfreeze := func() error {
+		unfreezes++
+		return nil
+	}
+
+	release, err := holdFrozen(freeze, unfreeze, time.Minute)
+	assert.Nil(err)
+	assert.Equal(0, unfreezes)
+	assert.Nil(release())
+	assert.Equal(1, unfreezes)
+
+	// released by the timeout before the caller
+	unfrozen := make(chan struct{})
+	release, err = holdFrozen(freeze, func() error {
+		close(unfrozen)
+		return nil
+	}, 10*time.Millisecond)
+	assert.Nil(err)
+	select {
+	case <-unfrozen:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for unfreeze")
+	}
+	assert.Equal(ErrFreezeTimeout, release())
+
+	_, err = holdFrozen(func() error {
+		return errors.New("not supported")
+	}, unfreeze, time.Minute)
+	assert.NotNil(err)
+}